@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// embedded holds everything the server reads at runtime, so the binary can
+// be deployed on its own and started from any directory.
+//
+//go:embed templates static locales db.sql db_postgres.sql
+var embedded embed.FS
+
+// Assets serves templates and static files. In production they come from
+// the binary and every static URL carries a content hash so browsers can
+// cache it forever. In dev mode they are read from the working directory
+// on every use, so edits show up without a rebuild.
+type Assets struct {
+	fsys   fs.FS
+	static fs.FS
+	dev    bool
+	// hashes maps a static file such as "css/style.css" to the short
+	// content hash put into its URL.
+	hashes map[string]string
+}
+
+func NewAssets(dev bool) (*Assets, error) {
+	a := &Assets{fsys: embedded, dev: dev, hashes: make(map[string]string)}
+	if dev {
+		if _, err := os.Stat("templates"); err == nil {
+			a.fsys = os.DirFS(".")
+		} else {
+			slog.Warn("templates directory not found, serving embedded assets", "err", err)
+			a.dev = false
+		}
+	}
+
+	static, err := fs.Sub(a.fsys, "static")
+	if err != nil {
+		return nil, fmt.Errorf("opening static files: %w", err)
+	}
+	a.static = static
+	if a.dev {
+		return a, nil
+	}
+
+	err = fs.WalkDir(static, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(static, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		a.hashes[name] = hex.EncodeToString(sum[:4])
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fingerprinting static files: %w", err)
+	}
+	return a, nil
+}
+
+// URL returns the path a page should use for the static file name, e.g.
+// "css/style.css" becomes "/static/css/style.1a2b3c4d.css".
+func (a *Assets) URL(name string) string {
+	hash, ok := a.hashes[name]
+	if !ok {
+		return "/static/" + name
+	}
+	ext := path.Ext(name)
+	return "/static/" + strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// StaticHandler serves the static files below /static/, which must already
+// be stripped from the request path. Fingerprinted URLs are cached for a
+// year; anything else must be revalidated.
+func (a *Assets) StaticHandler() http.Handler {
+	files := http.FileServerFS(a.static)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := a.unfingerprint(r.URL.Path); ok {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			r = r.Clone(r.Context())
+			r.URL.Path = name
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// unfingerprint maps "css/style.1a2b3c4d.css" back to "css/style.css" when
+// the hash is the current one.
+func (a *Assets) unfingerprint(p string) (string, bool) {
+	ext := path.Ext(p)
+	stem, hash, ok := cutLast(strings.TrimSuffix(p, ext), ".")
+	if !ok {
+		return "", false
+	}
+	name := stem + ext
+	if a.hashes[name] != hash {
+		return "", false
+	}
+	return name, true
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// embeddedSchema returns the schema file that ships with the binary for d.
+func embeddedSchema(d Dialect) ([]byte, string, error) {
+	name := DatabaseConfig{Driver: string(d)}.schemaFile()
+	b, err := embedded.ReadFile(name)
+	return b, name, err
+}
+
+// parseTemplates parses every page under templates/dashboard together
+// with base.html and the table partials, with funcs available to them.
+// Pages are keyed by file name.
+func (a *Assets) parseTemplates(funcs template.FuncMap) (map[string]*template.Template, error) {
+	tmpls := make(map[string]*template.Template)
+	baseFile := "templates/dashboard/base.html"
+	files := []string{}
+	funcs["asset"] = a.URL
+
+	partials, err := fs.Glob(a.fsys, "templates/partials/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("globbing partials: %w", err)
+	}
+
+	err = fs.WalkDir(a.fsys, "templates/dashboard", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		files = append(files, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking templates: %w", err)
+	}
+	for _, file := range files {
+		name := path.Base(file)
+		if name == "base.html" {
+			continue
+		}
+		allFiles := append([]string{baseFile, file}, partials...)
+		tmpl, err := template.New(path.Base(baseFile)).Funcs(funcs).ParseFS(a.fsys, allFiles...)
+		if err != nil {
+			return nil, err
+		}
+		tmpls[name] = tmpl
+	}
+	return tmpls, nil
+}
+
+// templateDirs returns every directory holding templates or message
+// catalogs, for the dev mode watcher, which does not descend into
+// subdirectories by itself.
+func (a *Assets) templateDirs() ([]string, error) {
+	dirs := []string{"locales"}
+	err := fs.WalkDir(a.fsys, "templates", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, name)
+		}
+		return err
+	})
+	return dirs, err
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testTemplates parses the templates embedded in the binary.
+func testTemplates(t *testing.T) *TemplateManager {
+	t.Helper()
+	assets, err := NewAssets(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := NewTemplateManager(assets, defaultConfig().I18n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
+
+func TestFingerprintedAssets(t *testing.T) {
+	assets, err := NewAssets(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := http.StripPrefix("/static/", assets.StaticHandler())
+
+	url := assets.URL("css/style.css")
+	if url == "/static/css/style.css" || !strings.HasSuffix(url, ".css") {
+		t.Fatalf("URL(css/style.css) = %q, want a fingerprinted name", url)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d, want %d", url, w.Code, http.StatusOK)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("Cache-Control = %q, want a long-lived immutable policy", cc)
+	}
+	want, _ := embedded.ReadFile("static/css/style.css")
+	if got, _ := io.ReadAll(w.Body); string(got) != string(want) {
+		t.Error("fingerprinted URL did not serve css/style.css")
+	}
+
+	// Unversioned names still work but must be revalidated.
+	for _, target := range []string{"/static/css/style.css", "/static/js/htmx.min.js"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-cache" {
+			t.Errorf("GET %s: status = %d, Cache-Control = %q, want 200 and no-cache", target, w.Code, w.Header().Get("Cache-Control"))
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/css/style.00000000.css", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET with a stale hash: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestPagesUseFingerprintedAssets(t *testing.T) {
+	h, _ := newTestApp(t)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/departments", nil))
+	if strings.Contains(w.Body.String(), `href="/static/css/style.css"`) {
+		t.Error("page links the unversioned stylesheet")
+	}
+}
@@ -0,0 +1,704 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attendance statuses of one employee's day.
+const (
+	attendancePresent    = "present"
+	attendanceLate       = "late"
+	attendanceAbsent     = "absent"
+	attendanceOnLeave    = "on_leave"
+	attendanceDayOff     = "day_off"
+	attendanceIncomplete = "incomplete" // clocked in or out without the other half
+	attendanceExpected   = "expected"   // a workday still under way, no clock-in yet
+)
+
+// defaultSchedule applies when no work schedule has been set up at all.
+var defaultSchedule = WorkSchedule{
+	Name:         "Default",
+	StartTime:    "09:00",
+	EndTime:      "17:00",
+	Workdays:     "mon,tue,wed,thu,fri",
+	GraceMinutes: 10,
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// WorksOn reports whether d is one of the schedule's workdays.
+func (ws WorkSchedule) WorksOn(d time.Weekday) bool {
+	return ws.HasWorkday(weekdayNames[d])
+}
+
+// HasWorkday reports whether the schedule lists the weekday named day,
+// e.g. "mon".
+func (ws WorkSchedule) HasWorkday(day string) bool {
+	return slices.Contains(strings.Split(ws.Workdays, ","), day)
+}
+
+// at returns the time of day hhmm on day, in day's location.
+func (ws WorkSchedule) at(day time.Time, hhmm string) time.Time {
+	t, _ := time.Parse("15:04", hhmm)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
+
+func (ws *WorkSchedule) validate() error {
+	if strings.TrimSpace(ws.Name) == "" {
+		return errors.New("Name is required")
+	}
+	start, err := time.Parse("15:04", ws.StartTime)
+	if err != nil {
+		return errors.New("Invalid start time")
+	}
+	end, err := time.Parse("15:04", ws.EndTime)
+	if err != nil {
+		return errors.New("Invalid end time")
+	}
+	if !end.After(start) {
+		return errors.New("End time must be after start time")
+	}
+	if ws.Workdays == "" {
+		return errors.New("Pick at least one workday")
+	}
+	for _, d := range strings.Split(ws.Workdays, ",") {
+		if !slices.Contains(weekdayNames, d) {
+			return errors.New("Invalid workdays")
+		}
+	}
+	if ws.GraceMinutes < 0 {
+		return errors.New("Grace period can't be negative")
+	}
+	return nil
+}
+
+// scheduleFor returns the schedule of emp's department, else the
+// company-wide one, else defaultSchedule.
+func scheduleFor(schedules []WorkSchedule, emp Employee) WorkSchedule {
+	fallback := defaultSchedule
+	for _, ws := range schedules {
+		if ws.DepartmentID != 0 && ws.DepartmentID == emp.DepartmentID {
+			return ws
+		}
+		if ws.DepartmentID == 0 && fallback.ID == 0 {
+			fallback = ws
+		}
+	}
+	return fallback
+}
+
+// dayStart returns midnight at the start of t's day in the local zone,
+// which is the zone attendance days are counted in.
+func dayStart(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// civilDate drops the time of day and zone of t, so that dates stored
+// without a zone, such as leave and hire dates, compare with local days.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// today returns the current civil date.
+func today() time.Time {
+	return civilDate(time.Now())
+}
+
+// dayKind returns whether day is a holiday, a weekend day or a weekday.
+// A holiday on a weekend counts as a holiday.
+func (c CalendarConfig) dayKind(day time.Time) string {
+	switch {
+	case slices.Contains(c.Holidays, day.Format("2006-01-02")):
+		return "holiday"
+	case slices.Contains(c.WeekendDays, weekdayNames[day.Weekday()]):
+		return "weekend"
+	}
+	return "weekday"
+}
+
+// leaveDays returns the working days of a leave: the days from its start
+// to its end that are neither weekend days nor holidays.
+func (c CalendarConfig) leaveDays(l Leave) int {
+	n := 0
+	for day := civilDate(l.StartDate); !day.After(civilDate(l.EndDate)); day = day.AddDate(0, 0, 1) {
+		if c.dayKind(day) == "weekday" {
+			n++
+		}
+	}
+	return n
+}
+
+// onLeave reports whether the employee has an approved leave covering day.
+func onLeave(leaves []Leave, employeeID int, day time.Time) bool {
+	date := civilDate(day)
+	for _, l := range leaves {
+		if l.EmployeeID == employeeID && l.Status == "approved" &&
+			!date.Before(civilDate(l.StartDate)) && !date.After(civilDate(l.EndDate)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DailyAttendance is one employee's day on the attendance page and in the
+// monthly export.
+type DailyAttendance struct {
+	Employee  Employee
+	Date      time.Time
+	Schedule  WorkSchedule
+	FirstIn   time.Time
+	LastOut   time.Time
+	Worked    time.Duration
+	Late      time.Duration
+	ClockedIn bool // the last event is a clock-in
+	Status    string
+}
+
+// summarizeDay pairs the day's events, which must be sorted by time, into
+// worked intervals and compares them with the schedule. A second clock-in
+// without a clock-out in between is ignored, as is a clock-out without a
+// clock-in before it.
+func summarizeDay(emp Employee, day time.Time, ws WorkSchedule, events []AttendanceEvent, leave bool, now time.Time) DailyAttendance {
+	d := DailyAttendance{Employee: emp, Date: day, Schedule: ws}
+	var open time.Time
+	unpaired := false
+	for _, e := range events {
+		at := e.OccurredAt.Local()
+		switch e.Kind {
+		case "in":
+			if d.FirstIn.IsZero() {
+				d.FirstIn = at
+			}
+			if open.IsZero() {
+				open = at
+			}
+		case "out":
+			if open.IsZero() {
+				unpaired = true
+				continue
+			}
+			d.Worked += at.Sub(open)
+			d.LastOut = at
+			open = time.Time{}
+		}
+	}
+	d.ClockedIn = !open.IsZero()
+	dayOver := !now.Before(day.AddDate(0, 0, 1))
+	if d.ClockedIn && !dayOver {
+		d.Worked += now.Sub(open)
+	}
+
+	start := ws.at(day, ws.StartTime)
+	end := ws.at(day, ws.EndTime)
+	workday := ws.WorksOn(day.Weekday())
+	switch {
+	case leave:
+		d.Status = attendanceOnLeave
+	case len(events) == 0 && !workday:
+		d.Status = attendanceDayOff
+	case len(events) == 0 && now.Before(end):
+		d.Status = attendanceExpected
+	case len(events) == 0:
+		d.Status = attendanceAbsent
+	case d.FirstIn.IsZero() || unpaired || d.ClockedIn && dayOver:
+		d.Status = attendanceIncomplete
+	case workday && d.FirstIn.After(start.Add(time.Duration(ws.GraceMinutes)*time.Minute)):
+		d.Status = attendanceLate
+		d.Late = d.FirstIn.Sub(start)
+	default:
+		d.Status = attendancePresent
+	}
+	return d
+}
+
+// attendanceFor summarizes every day in [from, to) that has begun by now,
+// for the employees matching q. Employees show from their hire date on;
+// inactive ones only on days they clocked.
+func (app *App) attendanceFor(ctx context.Context, from, to time.Time, q string, now time.Time) ([]DailyAttendance, error) {
+	employees, err := app.EmployeeRepository.GetEmployees(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := app.ScheduleRepository.GetWorkSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := app.LeaveRepository.GetLeaves(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	events, err := app.AttendanceRepository.GetAttendanceEvents(ctx, 0, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		employeeID int
+		day        time.Time
+	}
+	byDay := make(map[key][]AttendanceEvent)
+	for _, e := range events {
+		k := key{e.EmployeeID, dayStart(e.OccurredAt)}
+		byDay[k] = append(byDay[k], e)
+	}
+
+	var days []DailyAttendance
+	for day := dayStart(from); day.Before(to) && !day.After(now); day = day.AddDate(0, 0, 1) {
+		for _, emp := range employees {
+			dayEvents := byDay[key{emp.ID, day}]
+			active := emp.Status == "" || emp.Status == "active"
+			hired := emp.HireDate.IsZero() || !civilDate(emp.HireDate).After(civilDate(day))
+			if len(dayEvents) == 0 && (!active || !hired) {
+				continue
+			}
+			ws := scheduleFor(schedules, emp)
+			days = append(days, summarizeDay(emp, day, ws, dayEvents, onLeave(leaves, emp.ID, day), now))
+		}
+	}
+	return days, nil
+}
+
+func (app *App) handleAttendance(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	day := dayStart(now)
+	if s := r.URL.Query().Get("date"); s != "" {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+			return
+		}
+		day = d
+	}
+	q := r.URL.Query().Get("q")
+
+	days, err := app.attendanceFor(r.Context(), day, day.AddDate(0, 0, 1), q, now)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch attendance", err)
+		return
+	}
+	counts := make(map[string]int)
+	for _, d := range days {
+		counts[d.Status]++
+	}
+
+	data := map[string]any{
+		"ActivePage": "attendance",
+		"Days":       days,
+		"Counts":     counts,
+		"Date":       day,
+		"Prev":       day.AddDate(0, 0, -1),
+		"Next":       day.AddDate(0, 0, 1),
+		"IsToday":    day.Equal(dayStart(now)),
+		"Month":      day.Format("2006-01"),
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		app.render(w, r, "attendance.html", "attendance_partial", data)
+		return
+	}
+
+	app.render(w, r, "attendance.html", "", data)
+}
+
+// handleClock records a clock-in or clock-out for now. Clocking in twice
+// or out without being in is refused, since it is almost always a
+// double click.
+func (app *App) handleClock(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	kind := r.FormValue("kind")
+	if kind != "in" && kind != "out" {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid clock event")
+		return
+	}
+
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	if employee == nil {
+		app.clientError(w, r, http.StatusNotFound, "Employee not found")
+		return
+	}
+
+	now := time.Now().Truncate(time.Second)
+	today := dayStart(now)
+	events, err := app.AttendanceRepository.GetAttendanceEvents(r.Context(), employeeID, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch attendance", err)
+		return
+	}
+	clockedIn := len(events) > 0 && events[len(events)-1].Kind == "in"
+	if kind == "in" && clockedIn {
+		app.clientError(w, r, http.StatusConflict, "Already clocked in")
+		return
+	}
+	if kind == "out" && !clockedIn {
+		app.clientError(w, r, http.StatusConflict, "Not clocked in")
+		return
+	}
+
+	event := AttendanceEvent{EmployeeID: employeeID, Kind: kind, OccurredAt: now, Source: "web"}
+	if err := app.AttendanceRepository.CreateAttendanceEvent(r.Context(), &event); err != nil {
+		app.serverError(w, r, "Failed to record attendance", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleExportAttendance(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if s := r.URL.Query().Get("month"); s != "" {
+		m, err := time.ParseInLocation("2006-01", s, time.Local)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid month")
+			return
+		}
+		month = m
+	}
+	q := r.URL.Query().Get("q")
+
+	days, err := app.attendanceFor(r.Context(), month, month.AddDate(0, 1, 0), q, now)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch attendance", err)
+		return
+	}
+
+	headers := []string{"Date", "Employee ID", "Employee", "Schedule", "Status", "Clock In", "Clock Out", "Worked Hours", "Late Minutes"}
+	mapper := func(d DailyAttendance) []string {
+		return []string{
+			d.Date.Format("2006-01-02"),
+			fmt.Sprintf("%d", d.Employee.ID),
+			d.Employee.FirstName + " " + d.Employee.LastName,
+			d.Schedule.Name,
+			d.Status,
+			formatClock(d.FirstIn),
+			formatClock(d.LastOut),
+			fmt.Sprintf("%.2f", d.Worked.Hours()),
+			fmt.Sprintf("%d", int(d.Late.Minutes())),
+		}
+	}
+
+	writeExport(w, r, "Attendance", days, headers, mapper)
+}
+
+// formatClock formats the time of day of t, or "" for the zero time.
+func formatClock(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("15:04")
+}
+
+// employeesByID indexes employees for templates that show names next to
+// employee IDs.
+func (app *App) employeesByID(ctx context.Context) ([]Employee, map[int]Employee, error) {
+	employees, err := app.EmployeeRepository.GetEmployees(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int]Employee, len(employees))
+	for _, e := range employees {
+		byID[e.ID] = e
+	}
+	return employees, byID, nil
+}
+
+func (app *App) handleCorrections(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	corrections, err := app.CorrectionRepository.GetAttendanceCorrections(r.Context(), status)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch corrections", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage":  "attendance",
+		"Corrections": corrections,
+		"Employees":   employees,
+		"Status":      status,
+	}
+	app.render(w, r, "corrections.html", "", data)
+}
+
+func (app *App) handleAddCorrection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage": "attendance",
+			"Employees":  employees,
+			"EmployeeID": r.URL.Query().Get("employee_id"),
+			"Date":       r.URL.Query().Get("date"),
+		}
+		app.render(w, r, "add_correction.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", r.FormValue("date"), time.Local)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	clockIn, err1 := time.Parse("15:04", r.FormValue("clock_in"))
+	clockOut, err2 := time.Parse("15:04", r.FormValue("clock_out"))
+	if err1 != nil || err2 != nil || !clockOut.After(clockIn) {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid clock times")
+		return
+	}
+
+	correction := AttendanceCorrection{
+		EmployeeID: employeeID,
+		Date:       civilDate(day),
+		ClockIn:    day.Add(time.Duration(clockIn.Hour())*time.Hour + time.Duration(clockIn.Minute())*time.Minute),
+		ClockOut:   day.Add(time.Duration(clockOut.Hour())*time.Hour + time.Duration(clockOut.Minute())*time.Minute),
+		Reason:     r.FormValue("reason"),
+		Status:     "pending",
+	}
+	if err := app.CorrectionRepository.CreateAttendanceCorrection(r.Context(), &correction); err != nil {
+		app.serverError(w, r, "Failed to add correction", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance/corrections")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleReviewCorrection approves or rejects a pending correction. An
+// approved correction replaces the clock events of its day.
+func (app *App) handleReviewCorrection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	decision := r.PathValue("decision")
+	if decision != "approve" && decision != "reject" {
+		http.NotFound(w, r)
+		return
+	}
+
+	correction, err := app.CorrectionRepository.GetAttendanceCorrectionByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch correction", err)
+		return
+	}
+	if correction == nil {
+		app.clientError(w, r, http.StatusNotFound, "Correction not found")
+		return
+	}
+	if correction.Status != "pending" {
+		app.clientError(w, r, http.StatusConflict, "Correction was already reviewed")
+		return
+	}
+
+	correction.Status = "rejected"
+	if decision == "approve" {
+		day := dayStart(correction.ClockIn)
+		events := []AttendanceEvent{
+			{Kind: "in", OccurredAt: correction.ClockIn, Source: "correction"},
+			{Kind: "out", OccurredAt: correction.ClockOut, Source: "correction"},
+		}
+		err := app.AttendanceRepository.ReplaceAttendanceEvents(r.Context(), correction.EmployeeID, day, day.AddDate(0, 0, 1), events)
+		if err != nil {
+			app.serverError(w, r, "Failed to apply correction", err)
+			return
+		}
+		correction.Status = "approved"
+	}
+	if err := app.CorrectionRepository.UpdateAttendanceCorrection(r.Context(), correction); err != nil {
+		app.serverError(w, r, "Failed to update correction", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance/corrections")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := app.ScheduleRepository.GetWorkSchedules(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch schedules", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage":  "attendance",
+		"Schedules":   schedules,
+		"Departments": departments,
+		"Default":     defaultSchedule,
+		"Weekdays":    weekdayNames,
+	}
+	app.render(w, r, "schedules.html", "", data)
+}
+
+func (app *App) departmentsByID(ctx context.Context) (map[int]Department, error) {
+	departments, err := app.DepartmentRepository.GetDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Department, len(departments))
+	for _, d := range departments {
+		byID[d.ID] = d
+	}
+	return byID, nil
+}
+
+// scheduleForm reads the fields of the add and update schedule forms.
+func scheduleForm(r *http.Request) WorkSchedule {
+	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	grace, _ := strconv.Atoi(r.FormValue("grace_minutes"))
+	return WorkSchedule{
+		Name:         r.FormValue("name"),
+		DepartmentID: deptID,
+		StartTime:    r.FormValue("start_time"),
+		EndTime:      r.FormValue("end_time"),
+		Workdays:     strings.Join(r.Form["workdays"], ","),
+		GraceMinutes: grace,
+	}
+}
+
+func (app *App) handleAddSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":  "attendance",
+			"Departments": departments,
+			"Schedule":    defaultSchedule,
+			"Weekdays":    weekdayNames,
+		}
+		app.render(w, r, "add_schedule.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	schedule := scheduleForm(r)
+	if err := schedule.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.ScheduleRepository.CreateWorkSchedule(r.Context(), &schedule); err != nil {
+		app.serverError(w, r, "Failed to add schedule", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance/schedules")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		schedule, err := app.ScheduleRepository.GetWorkScheduleByID(r.Context(), id)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch schedule", err)
+			return
+		}
+		if schedule == nil {
+			app.clientError(w, r, http.StatusNotFound, "Schedule not found")
+			return
+		}
+		departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":  "attendance",
+			"Departments": departments,
+			"Schedule":    schedule,
+			"Weekdays":    weekdayNames,
+		}
+		app.render(w, r, "update_schedule.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	schedule := scheduleForm(r)
+	schedule.ID = id
+	if err := schedule.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.ScheduleRepository.UpdateWorkSchedule(r.Context(), &schedule); err != nil {
+		app.serverError(w, r, "Failed to update schedule", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance/schedules")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	if err := app.ScheduleRepository.DeleteWorkSchedule(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete schedule", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance/schedules")
+	w.WriteHeader(http.StatusSeeOther)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSummarizeDay(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	saturday := time.Date(2024, 3, 9, 0, 0, 0, 0, time.Local)
+	at := func(day time.Time, hhmm string) time.Time { return defaultSchedule.at(day, hhmm) }
+	events := func(day time.Time, kinds ...string) []AttendanceEvent {
+		var evs []AttendanceEvent
+		for i := 0; i < len(kinds); i += 2 {
+			evs = append(evs, AttendanceEvent{Kind: kinds[i], OccurredAt: at(day, kinds[i+1])})
+		}
+		return evs
+	}
+	later := time.Date(2024, 4, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		day        time.Time
+		events     []AttendanceEvent
+		leave      bool
+		now        time.Time
+		wantStatus string
+		wantWorked time.Duration
+		wantLate   time.Duration
+	}{
+		{name: "on time", day: monday, events: events(monday, "in", "08:58", "out", "17:02"), now: later,
+			wantStatus: attendancePresent, wantWorked: 8*time.Hour + 4*time.Minute},
+		{name: "within grace", day: monday, events: events(monday, "in", "09:10", "out", "17:00"), now: later,
+			wantStatus: attendancePresent, wantWorked: 7*time.Hour + 50*time.Minute},
+		{name: "late", day: monday, events: events(monday, "in", "09:25", "out", "17:00"), now: later,
+			wantStatus: attendanceLate, wantWorked: 7*time.Hour + 35*time.Minute, wantLate: 25 * time.Minute},
+		{name: "lunch break", day: monday, events: events(monday, "in", "09:00", "out", "12:00", "in", "13:00", "out", "17:00"), now: later,
+			wantStatus: attendancePresent, wantWorked: 7 * time.Hour},
+		{name: "double clock-in", day: monday, events: events(monday, "in", "09:00", "in", "09:01", "out", "17:00"), now: later,
+			wantStatus: attendancePresent, wantWorked: 8 * time.Hour},
+		{name: "absent", day: monday, now: later, wantStatus: attendanceAbsent},
+		{name: "approved leave", day: monday, leave: true, now: later, wantStatus: attendanceOnLeave},
+		{name: "weekend", day: saturday, now: later, wantStatus: attendanceDayOff},
+		{name: "weekend overtime", day: saturday, events: events(saturday, "in", "11:00", "out", "13:00"), now: later,
+			wantStatus: attendancePresent, wantWorked: 2 * time.Hour},
+		{name: "never clocked out", day: monday, events: events(monday, "in", "09:00"), now: later,
+			wantStatus: attendanceIncomplete},
+		{name: "clock-out only", day: monday, events: events(monday, "out", "17:00"), now: later,
+			wantStatus: attendanceIncomplete},
+		{name: "still at work", day: monday, events: events(monday, "in", "09:00"), now: at(monday, "11:30"),
+			wantStatus: attendancePresent, wantWorked: 2*time.Hour + 30*time.Minute},
+		{name: "not in yet", day: monday, now: at(monday, "10:00"), wantStatus: attendanceExpected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := summarizeDay(Employee{ID: 1}, tt.day, defaultSchedule, tt.events, tt.leave, tt.now)
+			if d.Status != tt.wantStatus || d.Worked != tt.wantWorked || d.Late != tt.wantLate {
+				t.Errorf("summarizeDay() = %s worked %v late %v, want %s worked %v late %v",
+					d.Status, d.Worked, d.Late, tt.wantStatus, tt.wantWorked, tt.wantLate)
+			}
+		})
+	}
+}
+
+func TestScheduleFor(t *testing.T) {
+	company := WorkSchedule{ID: 1, Name: "Office"}
+	support := WorkSchedule{ID: 2, Name: "Support", DepartmentID: 7}
+	schedules := []WorkSchedule{company, support}
+
+	if got := scheduleFor(schedules, Employee{DepartmentID: 7}); got.ID != support.ID {
+		t.Errorf("department schedule = %+v, want %+v", got, support)
+	}
+	if got := scheduleFor(schedules, Employee{DepartmentID: 3}); got.ID != company.ID {
+		t.Errorf("company schedule = %+v, want %+v", got, company)
+	}
+	if got := scheduleFor(nil, Employee{}); got.Name != defaultSchedule.Name {
+		t.Errorf("no schedules = %+v, want the default", got)
+	}
+}
+
+func TestAttendanceHandlers(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	hired := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, e := range []Employee{
+		{FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com", HireDate: hired, Status: "active"},
+		{FirstName: "Omar", LastName: "Saleh", Email: "omar@example.com", HireDate: hired, Status: "active"},
+	} {
+		if err := repos.Employees.CreateEmployee(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+	lina, omar := employees[0].ID, employees[1].ID
+	clock := func(id int, kind string) int {
+		form := url.Values{"employee_id": {strconv.Itoa(id)}, "kind": {kind}}
+		return send(h, "POST", "/attendance/clock", form, hrHeaders).Code
+	}
+
+	t.Run("clock in and out", func(t *testing.T) {
+		if code := clock(lina, "out"); code != http.StatusConflict {
+			t.Errorf("clock out before in = %d, want 409", code)
+		}
+		if code := clock(lina, "in"); code != http.StatusSeeOther {
+			t.Fatalf("clock in = %d, want 303", code)
+		}
+		if code := clock(lina, "in"); code != http.StatusConflict {
+			t.Errorf("second clock in = %d, want 409", code)
+		}
+		if code := clock(lina, "out"); code != http.StatusSeeOther {
+			t.Errorf("clock out = %d, want 303", code)
+		}
+		today := dayStart(time.Now())
+		events, _ := repos.Attendance.GetAttendanceEvents(ctx, lina, today, today.AddDate(0, 0, 1))
+		if len(events) != 2 || events[0].Kind != "in" || events[1].Kind != "out" || events[0].Source != "web" {
+			t.Errorf("events = %+v, want in and out from web", events)
+		}
+		if code := clock(999, "in"); code != http.StatusNotFound {
+			t.Errorf("clock unknown employee = %d, want 404", code)
+		}
+	})
+
+	t.Run("daily page", func(t *testing.T) {
+		w := send(h, "GET", "/attendance?date=2024-03-04", nil, hrHeaders)
+		body := w.Body.String()
+		if w.Code != http.StatusOK || !strings.Contains(body, "Lina Haddad") || !strings.Contains(body, "Absent") {
+			t.Errorf("GET /attendance = %d, want both employees absent:\n%s", w.Code, body)
+		}
+		w = send(h, "GET", "/attendance?date=2024-03-04&q=omar", nil, map[string]string{"HX-Request": "true", "X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"})
+		if body := w.Body.String(); strings.Contains(body, "Lina") || !strings.Contains(body, "Omar") || strings.Contains(body, "<html") {
+			t.Errorf("search partial = %s", body)
+		}
+		if w := send(h, "GET", "/attendance?date=March", nil, hrHeaders); w.Code != http.StatusBadRequest {
+			t.Errorf("bad date = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("correction approval", func(t *testing.T) {
+		day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+		stray := AttendanceEvent{EmployeeID: omar, Kind: "in", OccurredAt: day.Add(9*time.Hour + 45*time.Minute), Source: "web"}
+		if err := repos.Attendance.CreateAttendanceEvent(ctx, &stray); err != nil {
+			t.Fatal(err)
+		}
+
+		form := url.Values{"employee_id": {strconv.Itoa(omar)}, "date": {"2024-03-04"}, "clock_in": {"09:00"}, "clock_out": {"17:30"}, "reason": {"Badge reader was down"}}
+		if w := send(h, "POST", "/attendance/corrections/add", form, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add correction = %d %s", w.Code, w.Body)
+		}
+		form.Set("clock_out", "08:00")
+		if w := send(h, "POST", "/attendance/corrections/add", form, hrHeaders); w.Code != http.StatusBadRequest {
+			t.Errorf("clock out before in = %d, want 400", w.Code)
+		}
+
+		pending, _ := repos.Corrections.GetAttendanceCorrections(ctx, "pending")
+		if len(pending) != 1 {
+			t.Fatalf("pending corrections = %+v", pending)
+		}
+		id := strconv.Itoa(pending[0].ID)
+		if w := send(h, "POST", "/attendance/corrections/"+id+"/approve", nil, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("approve = %d %s", w.Code, w.Body)
+		}
+		if w := send(h, "POST", "/attendance/corrections/"+id+"/reject", nil, hrHeaders); w.Code != http.StatusConflict {
+			t.Errorf("second review = %d, want 409", w.Code)
+		}
+
+		events, _ := repos.Attendance.GetAttendanceEvents(ctx, omar, day, day.AddDate(0, 0, 1))
+		if len(events) != 2 || events[0].Source != "correction" || !events[1].OccurredAt.Equal(day.Add(17*time.Hour+30*time.Minute)) {
+			t.Fatalf("events after approval = %+v", events)
+		}
+		w := send(h, "GET", "/attendance?date=2024-03-04&q=omar", nil, map[string]string{"HX-Request": "true", "X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"})
+		if body := w.Body.String(); !strings.Contains(body, "Present") || !strings.Contains(body, "8h 30m") {
+			t.Errorf("corrected day = %s", body)
+		}
+	})
+
+	t.Run("monthly export", func(t *testing.T) {
+		w := send(h, "GET", "/attendance/export?month=2024-03", nil, hrHeaders)
+		if w.Code != http.StatusOK {
+			t.Fatalf("export = %d", w.Code)
+		}
+		f, err := excelize.OpenReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, _ := f.GetRows("Sheet1")
+		// Header plus both employees on each of the 31 days of March.
+		if len(rows) != 1+2*31 {
+			t.Fatalf("export has %d rows, want %d", len(rows), 1+2*31)
+		}
+		var found bool
+		for _, row := range rows {
+			if row[0] == "2024-03-04" && row[2] == "Omar Saleh" {
+				found = true
+				if row[4] != attendancePresent || row[5] != "09:00" || row[6] != "17:30" || row[7] != "8.50" {
+					t.Errorf("Omar's corrected day = %v", row)
+				}
+			}
+		}
+		if !found {
+			t.Error("export is missing Omar's corrected day")
+		}
+	})
+
+	t.Run("schedules", func(t *testing.T) {
+		form := url.Values{"name": {"Early"}, "start_time": {"07:00"}, "end_time": {"15:00"}, "workdays": {"mon", "tue"}, "grace_minutes": {"0"}}
+		if w := send(h, "POST", "/attendance/schedules/add", form, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add schedule = %d %s", w.Code, w.Body)
+		}
+		schedules, _ := repos.Schedules.GetWorkSchedules(ctx)
+		if len(schedules) != 1 || schedules[0].Workdays != "mon,tue" {
+			t.Fatalf("schedules = %+v", schedules)
+		}
+		if w := send(h, "GET", "/attendance/schedules", nil, hrHeaders); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Early") {
+			t.Errorf("GET /attendance/schedules = %d", w.Code)
+		}
+
+		form.Set("end_time", "06:00")
+		if w := send(h, "PUT", "/attendance/schedules/update/"+strconv.Itoa(schedules[0].ID), form, hrHeaders); w.Code != http.StatusBadRequest {
+			t.Errorf("update with end before start = %d, want 400", w.Code)
+		}
+		form.Del("workdays")
+		form.Set("end_time", "15:00")
+		if w := send(h, "POST", "/attendance/schedules/add", form, hrHeaders); w.Code != http.StatusBadRequest {
+			t.Errorf("add without workdays = %d, want 400", w.Code)
+		}
+	})
+}
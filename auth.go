@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Viewer is the user a request is made by, as told by the authenticating
+// proxy. Requests that came without the headers have an empty User and
+// no groups.
+type Viewer struct {
+	User   string
+	Groups []string
+}
+
+// viewer returns who made r.
+func (app *App) viewer(r *http.Request) Viewer {
+	auth := app.Config.Auth
+	return Viewer{
+		User:   strings.TrimSpace(r.Header.Get(auth.UserHeader)),
+		Groups: splitList(r.Header.Get(auth.GroupsHeader)),
+	}
+}
+
+// isHR reports whether v belongs to one of the HR groups.
+func (app *App) isHR(v Viewer) bool {
+	return slices.ContainsFunc(v.Groups, func(g string) bool {
+		return slices.Contains(app.Config.Auth.HRGroups, g)
+	})
+}
+
+// hrOnly serves next to HR and answers everyone else with 403.
+func (app *App) hrOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.requireHR(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isFinance reports whether v belongs to one of the finance groups.
+func (app *App) isFinance(v Viewer) bool {
+	return slices.ContainsFunc(v.Groups, func(g string) bool {
+		return slices.Contains(app.Config.Auth.FinanceGroups, g)
+	})
+}
+
+// splitList splits a comma separated list, dropping blanks around and
+// between the items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const snapshotPrefix = "hr-"
+
+// Snapshot is a backup file in the backup directory.
+type Snapshot struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// BackupManager writes consistent copies of a live SQLite database into a
+// directory and keeps the newest Retain of them. Each snapshot comes with
+// a copy of the uploaded files the database refers to.
+type BackupManager struct {
+	db      *DB
+	dir     string
+	uploads string
+	retain  int
+}
+
+func NewBackupManager(db *DB, cfg BackupConfig, uploads UploadConfig) *BackupManager {
+	return &BackupManager{db: db, dir: cfg.Dir, uploads: uploads.Dir, retain: cfg.Retain}
+}
+
+// uploadsBackup is the directory holding the uploaded files of the
+// backup at path: hr-20240101T000000.000Z.db keeps them in
+// hr-20240101T000000.000Z.uploads.
+func uploadsBackup(path string) string {
+	return strings.TrimSuffix(path, ".db") + ".uploads"
+}
+
+// backupUploads copies the upload directory next to the database backup
+// at dest. The database is copied first, so a file deleted in between may
+// be missing while its rows are kept; files uploaded in between are
+// copied without rows, which is harmless.
+func backupUploads(uploads, dest string) error {
+	if uploads == "" {
+		return nil
+	}
+	if _, err := os.Stat(uploads); errors.Is(err, os.ErrNotExist) {
+		return nil // nothing uploaded yet
+	}
+	if err := copyDir(uploads, uploadsBackup(dest)); err != nil {
+		os.RemoveAll(uploadsBackup(dest))
+		return fmt.Errorf("copying uploads: %w", err)
+	}
+	return nil
+}
+
+// backupDB copies the database into dest with VACUUM INTO, which reads a
+// single transaction so writers are never blocked, then checks the copy.
+// PostgreSQL databases are backed up with pg_dump instead.
+func backupDB(ctx context.Context, db *DB, dest string) error {
+	if db.Dialect != DialectSQLite {
+		return errors.New("built-in backups are only available for SQLite; use pg_dump for PostgreSQL")
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup target %s already exists", dest)
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?;", dest); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	if err := verifyBackup(ctx, dest); err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return nil
+}
+
+// Snapshot writes a timestamped backup and prunes old ones.
+func (m *BackupManager) Snapshot(ctx context.Context) (*Snapshot, error) {
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	name := snapshotPrefix + now.Format("20060102T150405.000Z") + ".db"
+	path := filepath.Join(m.dir, name)
+	if err := backupDB(ctx, m.db, path); err != nil {
+		return nil, err
+	}
+	if err := backupUploads(m.uploads, path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "database snapshot written", "file", path, "bytes", info.Size())
+
+	if err := m.prune(); err != nil {
+		slog.WarnContext(ctx, "pruning old snapshots failed", "err", err)
+	}
+	return &Snapshot{Name: name, Size: info.Size(), CreatedAt: now}, nil
+}
+
+// List returns the snapshots in the backup directory, newest first.
+func (m *BackupManager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing backups: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, e := range entries {
+		if e.IsDir() || !isSnapshotName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Name: e.Name(), Size: info.Size(), CreatedAt: info.ModTime()})
+	}
+	// Names embed a sortable UTC timestamp.
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name > snapshots[j].Name })
+	return snapshots, nil
+}
+
+// Open returns the named snapshot for download. name must be one returned
+// by List; anything else is rejected so callers can pass user input.
+func (m *BackupManager) Open(name string) (*os.File, error) {
+	if !isSnapshotName(name) || filepath.Base(name) != name {
+		return nil, os.ErrNotExist
+	}
+	return os.Open(filepath.Join(m.dir, name))
+}
+
+func isSnapshotName(name string) bool {
+	return strings.HasPrefix(name, snapshotPrefix) && strings.HasSuffix(name, ".db")
+}
+
+func (m *BackupManager) prune() error {
+	if m.retain <= 0 {
+		return nil
+	}
+	snapshots, err := m.List()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range snapshots[min(m.retain, len(snapshots)):] {
+		if err := os.Remove(filepath.Join(m.dir, s.Name)); err != nil {
+			errs = append(errs, err)
+		}
+		if err := os.RemoveAll(uploadsBackup(filepath.Join(m.dir, s.Name))); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Schedule takes a snapshot every interval until ctx is cancelled.
+func (m *BackupManager) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.Snapshot(ctx); err != nil {
+				slog.Error("scheduled snapshot failed", "err", err)
+			}
+		}
+	}
+}
+
+// checkIntegrity runs SQLite's integrity check on db.
+func checkIntegrity(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check;")
+	if err != nil {
+		return fmt.Errorf("running integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("reading integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading integrity check: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// verifyBackup opens the file at path read-only and checks that it is an
+// intact database with a schema version this build can run on.
+func verifyBackup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("opening backup: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("opening backup: %w", err)
+	}
+	defer db.Close()
+
+	if err := checkIntegrity(ctx, db); err != nil {
+		return err
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version;").Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if version < 1 || version > schemaVersion {
+		return fmt.Errorf("backup has schema version %d, this build supports 1 to %d", version, schemaVersion)
+	}
+
+	for _, table := range []string{"departments", "employees", "applications", "leaves", "positions"} {
+		var n int
+		err := db.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?;", table).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("inspecting backup schema: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("backup is missing table %s", table)
+		}
+	}
+	return nil
+}
+
+// restoreDB replaces the database file at dbPath with the backup at src.
+// The server must not be running. The current database is kept next to it
+// as <dbPath>.pre-restore.
+func restoreDB(ctx context.Context, src, dbPath string) error {
+	if err := verifyBackup(ctx, src); err != nil {
+		return fmt.Errorf("refusing to restore: %w", err)
+	}
+
+	tmp := dbPath + ".restore-tmp"
+	if err := copyFile(src, tmp); err != nil {
+		return fmt.Errorf("copying backup: %w", err)
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		if err := os.Rename(dbPath, dbPath+".pre-restore"); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("keeping current database: %w", err)
+		}
+	}
+	// A leftover WAL would be replayed on top of the restored file.
+	os.Remove(dbPath + "-wal")
+	os.Remove(dbPath + "-shm")
+
+	if err := os.Rename(tmp, dbPath); err != nil {
+		return fmt.Errorf("moving restored database into place: %w", err)
+	}
+	return nil
+}
+
+// restoreUploads replaces the upload directory with the files backed up
+// with the database at src. The current files are kept as
+// <dir>.pre-restore. Backups taken before uploads were backed up have no
+// files, and leave the directory alone.
+func restoreUploads(src, dir string) error {
+	backup := uploadsBackup(src)
+	if _, err := os.Stat(backup); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	tmp := dir + ".restore-tmp"
+	os.RemoveAll(tmp)
+	if err := copyDir(backup, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return fmt.Errorf("copying uploads: %w", err)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		os.RemoveAll(dir + ".pre-restore")
+		if err := os.Rename(dir, dir+".pre-restore"); err != nil {
+			os.RemoveAll(tmp)
+			return fmt.Errorf("keeping current uploads: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return fmt.Errorf("moving restored uploads into place: %w", err)
+	}
+	return nil
+}
+
+// copyDir copies the files under src into dst, which must not exist.
+func copyDir(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o750)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// runBackup implements "backup [file] [flags]". Without a file the backup
+// is written as a snapshot into the configured backup directory. With
+// several tenants configured, -tenant selects the database.
+func runBackup(args []string) error {
+	dest, args := positionalArg(args)
+	cfg, err := loadConfig("backup", args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if err := initLogging(cfg); err != nil {
+		return err
+	}
+
+	tenant, err := cfg.selectedTenant()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Backup.Timeout))
+	defer cancel()
+	db, err := connectToDB(ctx, cfg.tenantDatabase(tenant))
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	uploads := cfg.tenantUploads(tenant)
+	if dest == "" {
+		backupCfg := cfg.tenantBackup(tenant)
+		s, err := NewBackupManager(db, backupCfg, uploads).Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(filepath.Join(backupCfg.Dir, s.Name))
+		return nil
+	}
+	if err := backupDB(ctx, db, dest); err != nil {
+		return err
+	}
+	if err := backupUploads(uploads.Dir, dest); err != nil {
+		os.Remove(dest)
+		return err
+	}
+	fmt.Println(dest)
+	return nil
+}
+
+// runVerify implements "verify <file>".
+func runVerify(args []string) error {
+	src, _ := positionalArg(args)
+	if src == "" {
+		return errors.New("usage: verify <backup file>")
+	}
+	if err := verifyBackup(context.Background(), src); err != nil {
+		return err
+	}
+	fmt.Printf("%s: ok\n", src)
+	return nil
+}
+
+// runRestore implements "restore <file> [flags]".
+func runRestore(args []string) error {
+	src, args := positionalArg(args)
+	if src == "" {
+		return errors.New("usage: restore <backup file> [flags]")
+	}
+	cfg, err := loadConfig("restore", args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if err := initLogging(cfg); err != nil {
+		return err
+	}
+	tenant, err := cfg.selectedTenant()
+	if err != nil {
+		return err
+	}
+	if err := restoreDB(context.Background(), src, tenant.DBPath); err != nil {
+		return err
+	}
+	fmt.Printf("restored %s from %s (previous database kept as %s.pre-restore)\n", tenant.DBPath, src, tenant.DBPath)
+	uploads := cfg.tenantUploads(tenant).Dir
+	if err := restoreUploads(src, uploads); err != nil {
+		return err
+	}
+	if _, err := os.Stat(uploadsBackup(src)); err == nil {
+		fmt.Printf("restored %s from %s (previous files kept as %s.pre-restore)\n", uploads, uploadsBackup(src), uploads)
+	}
+	return nil
+}
+
+// positionalArg splits a leading non-flag argument off args.
+func positionalArg(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+func (app *App) handleBackups(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	snapshots, err := app.Backups.List()
+	if err != nil {
+		app.serverError(w, r, "Failed to list backups", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage": "backups",
+		"Snapshots":  snapshots,
+	}
+	app.render(w, r, "backups.html", "", data)
+}
+
+func (app *App) handleCreateBackup(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	if _, err := app.Backups.Snapshot(r.Context()); err != nil {
+		app.serverError(w, r, "Failed to create backup", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/admin/backups")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDownloadBackup(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	f, err := app.Backups.Open(r.PathValue("name"))
+	if errors.Is(err, os.ErrNotExist) {
+		app.clientError(w, r, http.StatusNotFound, "Backup not found")
+		return
+	}
+	if err != nil {
+		app.serverError(w, r, "Failed to open backup", err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		app.serverError(w, r, "Failed to open backup", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", info.Name()))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
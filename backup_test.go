@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := connectToDB(ctx, DatabaseConfig{Driver: "sqlite", Path: filepath.Join(dir, "live.db"), SchemaFile: "db.sql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := NewDepartmentRepository(db).CreateDepartment(ctx, &Department{Name: "Engineering"}); err != nil {
+		t.Fatal(err)
+	}
+
+	uploads := filepath.Join(dir, "uploads")
+	if err := os.MkdirAll(filepath.Join(uploads, "documents"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(uploads, "documents", "payslip.pdf"), []byte("v1"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewBackupManager(db, BackupConfig{Dir: filepath.Join(dir, "backups"), Retain: 2}, UploadConfig{Dir: filepath.Join(dir, "uploads")})
+	var last *Snapshot
+	for range 3 {
+		if last, err = m.Snapshot(ctx); err != nil {
+			t.Fatalf("Snapshot() error = %v", err)
+		}
+	}
+	snapshots, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Errorf("List() returned %d snapshots, want 2 after pruning", len(snapshots))
+	}
+	if snapshots[0].Name != last.Name {
+		t.Errorf("List()[0] = %s, want newest snapshot %s", snapshots[0].Name, last.Name)
+	}
+	if kept, _ := filepath.Glob(filepath.Join(dir, "backups", "*.uploads")); len(kept) != 2 {
+		t.Errorf("uploads backups = %v, want one per snapshot kept", kept)
+	}
+
+	if err := os.WriteFile(filepath.Join(uploads, "documents", "payslip.pdf"), []byte("v2"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreUploads(filepath.Join(dir, "backups", last.Name), uploads); err != nil {
+		t.Fatalf("restoreUploads() error = %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(uploads, "documents", "payslip.pdf")); err != nil || string(b) != "v1" {
+		t.Errorf("restored upload = %q, %v, want v1", b, err)
+	}
+	if b, err := os.ReadFile(filepath.Join(uploads+".pre-restore", "documents", "payslip.pdf")); err != nil || string(b) != "v2" {
+		t.Errorf("kept upload = %q, %v, want v2", b, err)
+	}
+
+	target := filepath.Join(dir, "restored.db")
+	if err := restoreDB(ctx, filepath.Join(dir, "backups", last.Name), target); err != nil {
+		t.Fatalf("restoreDB() error = %v", err)
+	}
+	restored, err := sql.Open("sqlite", target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	departments, err := NewDepartmentRepository(&DB{DB: restored, Dialect: DialectSQLite}).GetDepartments(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(departments) != 1 || departments[0].Name != "Engineering" {
+		t.Errorf("restored departments = %+v, want Engineering", departments)
+	}
+}
+
+func TestVerifyBackupRejectsNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "future.db")
+
+	db, err := connectToDB(ctx, DatabaseConfig{Driver: "sqlite", Path: path, SchemaFile: "db.sql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("PRAGMA user_version = 9999;"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if err := verifyBackup(ctx, path); err == nil {
+		t.Error("verifyBackup() error = nil, want schema version error")
+	}
+}
+
+func TestBackupsRequireHR(t *testing.T) {
+	h, _ := newTestApp(t)
+	staff := map[string]string{"X-Forwarded-User": "sam", "X-Forwarded-Groups": "staff"}
+	for _, route := range []struct{ method, target string }{
+		{"GET", "/admin/backups"},
+		{"POST", "/admin/backups"},
+		{"GET", "/admin/backups/hr-20240101T000000.000Z.db"},
+	} {
+		if w := send(h, route.method, route.target, nil, staff); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s as staff = %d, want 403", route.method, route.target, w.Code)
+		}
+	}
+}
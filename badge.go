@@ -0,0 +1,563 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// badgeSource marks the attendance events created from badge swipes.
+const badgeSource = "badge"
+
+// maxLineErrors caps the skipped rows listed in a report; the counts still
+// cover all of them.
+const maxLineErrors = 100
+
+// maxBadgeUpload bounds uploaded badge files. A day of swipes is far
+// smaller.
+const maxBadgeUpload = 10 << 20
+
+// badgeColumns lists the header names door-access systems use for each
+// column we read, after normalizeHeader.
+var badgeColumns = map[string][]string{
+	"card":      {"card", "card_number", "card_no", "card_id", "badge", "badge_number", "badge_id"},
+	"time":      {"timestamp", "time", "datetime", "date_time", "swiped_at", "occurred_at"},
+	"date":      {"date"},
+	"direction": {"direction", "in_out", "event", "kind", "type"},
+}
+
+// badgeSwipe is one row of a badge file.
+type badgeSwipe struct {
+	card string
+	at   time.Time
+	kind string // in, out or "" when the file doesn't say
+}
+
+// badgeFileError is returned for a badge file that can't be read at all.
+// Single bad rows are listed in the report instead.
+type badgeFileError struct {
+	msg string
+}
+
+func (e badgeFileError) Error() string {
+	return e.msg
+}
+
+// Skipped returns the number of rows that were not read as swipes.
+func (r BadgeReport) Skipped() int {
+	return r.Rows - r.Swipes
+}
+
+func (r *BadgeReport) addError(line int, msg string) {
+	if len(r.Errors) < maxLineErrors {
+		r.Errors = append(r.Errors, LineError{Line: line, Err: msg})
+	}
+}
+
+// BadgeImporter turns the CSV files of the door-access system into
+// attendance events. Swipes are matched to employees by card number.
+type BadgeImporter struct {
+	employees  EmployeeRepository
+	attendance AttendanceRepository
+	imports    BadgeImportRepository
+	layout     string
+	window     time.Duration
+}
+
+func NewBadgeImporter(repos Repositories, cfg BadgeConfig) *BadgeImporter {
+	return &BadgeImporter{
+		employees:  repos.Employees,
+		attendance: repos.Attendance,
+		imports:    repos.BadgeImports,
+		layout:     cfg.TimeLayout,
+		window:     time.Duration(cfg.DedupeWindow),
+	}
+}
+
+// Import reads the badge file name from r, records its swipes as clock
+// events and stores the report. source says how the file arrived. Importing
+// a file again adds nothing, so overlapping dumps are safe.
+func (bi *BadgeImporter) Import(ctx context.Context, name, source string, r io.Reader) (*BadgeImport, error) {
+	swipes, report, err := parseBadgeFile(r, bi.layout)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := bi.employees.GetEmployees(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	byCard := make(map[string]int)
+	for _, e := range employees {
+		if e.CardNumber != "" {
+			byCard[normalizeCard(e.CardNumber)] = e.ID
+		}
+	}
+
+	swipes, report.Duplicates = dedupeSwipes(swipes, bi.window)
+
+	type employeeDay struct {
+		employeeID int
+		date       string
+	}
+	var keys []employeeDay
+	days := make(map[employeeDay][]badgeSwipe)
+	unmatched := make(map[string]*UnmatchedCard)
+	for _, s := range swipes {
+		id, ok := byCard[s.card]
+		if !ok {
+			u := unmatched[s.card]
+			if u == nil {
+				u = &UnmatchedCard{CardNumber: s.card, First: s.at}
+				unmatched[s.card] = u
+			}
+			u.Swipes++
+			u.Last = s.at
+			continue
+		}
+		k := employeeDay{id, dayStart(s.at).Format("2006-01-02")}
+		if _, ok := days[k]; !ok {
+			keys = append(keys, k)
+		}
+		days[k] = append(days[k], s)
+	}
+
+	for _, k := range keys {
+		daySwipes := days[k]
+		added, err := bi.mergeDay(ctx, k.employeeID, dayStart(daySwipes[0].at), daySwipes)
+		if err != nil {
+			return nil, err
+		}
+		if added > 0 {
+			report.Events += added
+			report.Days++
+		}
+	}
+	for _, u := range unmatched {
+		report.Unmatched = append(report.Unmatched, *u)
+	}
+	slices.SortFunc(report.Unmatched, func(a, b UnmatchedCard) int { return strings.Compare(a.CardNumber, b.CardNumber) })
+
+	record := &BadgeImport{FileName: name, Source: source, Report: report}
+	if err := bi.imports.CreateBadgeImport(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// mergeDay adds the swipes of one employee's day to the badge events
+// already recorded for it and pairs them all again. Events from other
+// sources, e.g. the web clock, are kept as they are. It returns the number
+// of swipes that were new.
+func (bi *BadgeImporter) mergeDay(ctx context.Context, employeeID int, day time.Time, swipes []badgeSwipe) (int, error) {
+	next := day.AddDate(0, 0, 1)
+	existing, err := bi.attendance.GetAttendanceEvents(ctx, employeeID, day, next)
+	if err != nil {
+		return 0, err
+	}
+	var kept []AttendanceEvent
+	var recorded []badgeSwipe
+	for _, e := range existing {
+		if e.Source == badgeSource {
+			recorded = append(recorded, badgeSwipe{at: e.OccurredAt, kind: e.Kind})
+		} else {
+			kept = append(kept, e)
+		}
+	}
+
+	fresh := slices.DeleteFunc(slices.Clone(swipes), func(s badgeSwipe) bool {
+		return slices.ContainsFunc(recorded, func(r badgeSwipe) bool {
+			d := s.at.Sub(r.at)
+			return d >= -bi.window && d <= bi.window
+		})
+	})
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+
+	all := append(recorded, fresh...)
+	slices.SortStableFunc(all, func(a, b badgeSwipe) int { return a.at.Compare(b.at) })
+	events := append(kept, pairSwipes(all)...)
+	if err := bi.attendance.ReplaceAttendanceEvents(ctx, employeeID, day, next, events); err != nil {
+		return 0, err
+	}
+	return len(fresh), nil
+}
+
+// pairSwipes turns one day's swipes, in time order, into clock events.
+// Swipes without a direction alternate between in and out, starting with
+// in, so every in opens an interval the next swipe closes. A night shift
+// spans two days and so needs a direction column.
+func pairSwipes(swipes []badgeSwipe) []AttendanceEvent {
+	var events []AttendanceEvent
+	next := "in"
+	for _, s := range swipes {
+		kind := cmp.Or(s.kind, next)
+		next = "in"
+		if kind == "in" {
+			next = "out"
+		}
+		events = append(events, AttendanceEvent{Kind: kind, OccurredAt: s.at, Source: badgeSource})
+	}
+	return events
+}
+
+// dedupeSwipes drops the swipes of a card that repeat the previous one in
+// the same direction within window, and returns the rest ordered by card
+// and time along with the number dropped.
+func dedupeSwipes(swipes []badgeSwipe, window time.Duration) ([]badgeSwipe, int) {
+	sorted := slices.Clone(swipes)
+	slices.SortStableFunc(sorted, func(a, b badgeSwipe) int {
+		return cmp.Or(strings.Compare(a.card, b.card), a.at.Compare(b.at))
+	})
+	var kept []badgeSwipe
+	for _, s := range sorted {
+		if n := len(kept); n > 0 {
+			prev := kept[n-1]
+			if prev.card == s.card && prev.kind == s.kind && s.at.Sub(prev.at) <= window {
+				continue
+			}
+		}
+		kept = append(kept, s)
+	}
+	return kept, len(sorted) - len(kept)
+}
+
+// parseBadgeFile reads the swipes of a badge file. The first row must name
+// the columns: a card number, the swipe time either in one column or split
+// into date and time, and optionally the direction. Columns may be
+// separated by commas or semicolons.
+func parseBadgeFile(r io.Reader, layout string) ([]badgeSwipe, BadgeReport, error) {
+	var report BadgeReport
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, report, fmt.Errorf("reading badge file: %w", err)
+	}
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	header, _, _ := bytes.Cut(b, []byte("\n"))
+
+	cr := csv.NewReader(bytes.NewReader(b))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	names, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, report, badgeFileError{"Badge file is empty"}
+	}
+	if err != nil {
+		return nil, report, badgeFileError{"Badge file has no readable header row"}
+	}
+	col := make(map[string]int)
+	for i, name := range names {
+		name = normalizeHeader(name)
+		for field, aliases := range badgeColumns {
+			if _, ok := col[field]; !ok && slices.Contains(aliases, name) {
+				col[field] = i
+			}
+		}
+	}
+	if _, ok := col["card"]; !ok {
+		return nil, report, badgeFileError{"Badge file has no card number column"}
+	}
+	if i, ok := col["date"]; ok {
+		if _, ok := col["time"]; !ok {
+			// A lone date column holds the whole timestamp.
+			col["time"] = i
+			delete(col, "date")
+		}
+	}
+	if _, ok := col["time"]; !ok {
+		return nil, report, badgeFileError{"Badge file has no time column"}
+	}
+
+	field := func(rec []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var swipes []badgeSwipe
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Rows++
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			report.addError(perr.StartLine, "Malformed row")
+			continue
+		}
+		if err != nil {
+			return nil, report, fmt.Errorf("reading badge file: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		card := normalizeCard(field(rec, "card"))
+		if card == "" {
+			report.addError(line, "Missing card number")
+			continue
+		}
+		when := field(rec, "time")
+		if date := field(rec, "date"); date != "" {
+			when = date + " " + when
+		}
+		at, err := parseSwipeTime(when, layout)
+		if err != nil {
+			report.addError(line, "Invalid time")
+			continue
+		}
+		kind, ok := swipeKind(field(rec, "direction"))
+		if !ok {
+			report.addError(line, "Unknown direction")
+			continue
+		}
+		swipes = append(swipes, badgeSwipe{card: card, at: at, kind: kind})
+	}
+	report.Swipes = len(swipes)
+	return swipes, report, nil
+}
+
+// normalizeHeader turns a column name such as "Card No" into card_no.
+func normalizeHeader(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_", ".", "").Replace(s)
+}
+
+// normalizeCard makes card numbers compare equal however the door system
+// and HR happened to write them.
+func normalizeCard(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// parseSwipeTime reads a swipe time in layout or a common ISO 8601 form.
+// Times without a zone are in the server's zone, like the door system's.
+func parseSwipeTime(s, layout string) (time.Time, error) {
+	var err error
+	for _, l := range []string{layout, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", time.RFC3339} {
+		var t time.Time
+		if t, err = time.ParseInLocation(l, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// swipeKind maps the direction column to in or out. An empty direction is
+// valid and leaves the choice to pairSwipes.
+func swipeKind(s string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", true
+	case "in", "i", "entry", "enter":
+		return "in", true
+	case "out", "o", "exit":
+		return "out", true
+	}
+	return "", false
+}
+
+// BadgeWatcher imports the badge files that appear in a directory. Once
+// read, a file is moved to processed/, or to failed/ when it couldn't be
+// imported, so the directory only holds what is still waiting.
+type BadgeWatcher struct {
+	dir      string
+	importer *BadgeImporter
+	// settle is how long a file must be left alone before it is read, as
+	// door systems write their dumps in pieces.
+	settle time.Duration
+}
+
+func NewBadgeWatcher(dir string, importer *BadgeImporter) *BadgeWatcher {
+	return &BadgeWatcher{dir: dir, importer: importer, settle: 2 * time.Second}
+}
+
+// Run imports the files already waiting, then the ones that arrive, until
+// ctx is done.
+func (w *BadgeWatcher) Run(ctx context.Context) error {
+	for _, sub := range []string{"processed", "failed"} {
+		if err := os.MkdirAll(filepath.Join(w.dir, sub), 0o750); err != nil {
+			return fmt.Errorf("creating badge import directory: %w", err)
+		}
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	defer watcher.Close()
+	// Watch before listing so no file slips in between.
+	if err := watcher.Add(w.dir); err != nil {
+		return fmt.Errorf("watching %s: %w", w.dir, err)
+	}
+	slog.Info("watching for badge files", "dir", w.dir)
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return fmt.Errorf("listing badge files: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() && isBadgeFile(e.Name()) {
+			w.importFile(ctx, filepath.Join(w.dir, e.Name()))
+		}
+	}
+
+	ready := make(chan string)
+	timers := make(map[string]*time.Timer)
+	// done releases the callbacks of timers that fired but were not
+	// received, as Run may return before ctx is done.
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		for _, t := range timers {
+			t.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) || !isBadgeFile(filepath.Base(event.Name)) {
+				continue
+			}
+			if t, ok := timers[event.Name]; ok {
+				t.Reset(w.settle)
+				continue
+			}
+			name := event.Name
+			timers[name] = time.AfterFunc(w.settle, func() {
+				select {
+				case ready <- name:
+				case <-ctx.Done():
+				case <-done:
+				}
+			})
+		case name := <-ready:
+			delete(timers, name)
+			w.importFile(ctx, name)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Error("badge file watcher error", "dir", w.dir, "err", err)
+		}
+	}
+}
+
+func (w *BadgeWatcher) importFile(ctx context.Context, path string) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return // already imported and moved
+	}
+	if err != nil {
+		slog.Error("cannot open badge file", "file", path, "err", err)
+		return
+	}
+	record, err := w.importer.Import(ctx, filepath.Base(path), "watch", f)
+	f.Close()
+
+	dest := "processed"
+	if err != nil {
+		slog.Error("badge import failed", "file", path, "err", err)
+		dest = "failed"
+	} else {
+		slog.Info("badge file imported", "file", path, "import", record.ID,
+			"events", record.Report.Events, "unmatched_cards", len(record.Report.Unmatched))
+	}
+	target := filepath.Join(w.dir, dest, time.Now().Format("20060102T150405")+"-"+filepath.Base(path))
+	if err := os.Rename(path, target); err != nil {
+		slog.Error("cannot move badge file", "file", path, "err", err)
+	}
+}
+
+// isBadgeFile skips hidden files, which door systems and editors use
+// while writing.
+func isBadgeFile(name string) bool {
+	return !strings.HasPrefix(name, ".") && strings.EqualFold(filepath.Ext(name), ".csv")
+}
+
+func (app *App) handleBadgeImports(w http.ResponseWriter, r *http.Request) {
+	imports, err := app.BadgeImportRepository.GetBadgeImports(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch badge imports", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "attendance",
+		"Imports":    imports,
+		"Watching":   app.Config.Badge.ImportDir != "",
+	}
+	app.render(w, r, "badge_imports.html", "", data)
+}
+
+func (app *App) handleUploadBadgeFile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBadgeUpload)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			app.clientError(w, r, http.StatusRequestEntityTooLarge, "Badge file is too large")
+			return
+		}
+		app.clientError(w, r, http.StatusBadRequest, "Choose a badge file to upload")
+		return
+	}
+	defer file.Close()
+
+	record, err := app.Badges.Import(r.Context(), header.Filename, "upload", file)
+	var fileErr badgeFileError
+	if errors.As(err, &fileErr) {
+		app.clientError(w, r, http.StatusBadRequest, fileErr.msg)
+		return
+	}
+	if err != nil {
+		app.serverError(w, r, "Failed to import badge file", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/attendance/imports/"+strconv.Itoa(record.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleBadgeImport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	record, err := app.BadgeImportRepository.GetBadgeImportByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch badge import", err)
+		return
+	}
+	if record == nil {
+		app.clientError(w, r, http.StatusNotFound, "Badge import not found")
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "attendance",
+		"Import":     record,
+	}
+	app.render(w, r, "badge_import.html", "", data)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBadgeFile(t *testing.T) {
+	layout := defaultConfig().Badge.TimeLayout
+	tests := []struct {
+		name       string
+		file       string
+		wantSwipes int
+		wantErrors []LineError
+		wantFail   string
+	}{
+		{
+			name:       "comma separated with direction",
+			file:       "Card No,Timestamp,Direction\n0004521,2024-03-04 08:58:12,Entry\n0004521,2024-03-04 17:02:40,Exit\n",
+			wantSwipes: 2,
+		},
+		{
+			name:       "semicolons and split date and time",
+			file:       "\xef\xbb\xbfBadge ID;Date;Time\n0004521;2024-03-04;08:58:12\n",
+			wantSwipes: 1,
+		},
+		{
+			name:       "bad rows are listed",
+			file:       "card,time,direction\n,2024-03-04 08:58:12,in\n7,yesterday,in\n7,2024-03-04 09:00:00,denied\n7,2024-03-04T09:05:00+03:00,\n",
+			wantSwipes: 1,
+			wantErrors: []LineError{{2, "Missing card number"}, {3, "Invalid time"}, {4, "Unknown direction"}},
+		},
+		{name: "empty file", file: "", wantFail: "Badge file is empty"},
+		{name: "no card column", file: "employee,time\n1,2024-03-04 08:58:12\n", wantFail: "Badge file has no card number column"},
+		{name: "no time column", file: "card,door\n7,main\n", wantFail: "Badge file has no time column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swipes, report, err := parseBadgeFile(strings.NewReader(tt.file), layout)
+			if tt.wantFail != "" {
+				if err == nil || err.Error() != tt.wantFail {
+					t.Fatalf("parseBadgeFile() error = %v, want %q", err, tt.wantFail)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseBadgeFile() error = %v", err)
+			}
+			if len(swipes) != tt.wantSwipes || report.Swipes != tt.wantSwipes {
+				t.Errorf("swipes = %+v, want %d", swipes, tt.wantSwipes)
+			}
+			if len(report.Errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %+v, want %+v", report.Errors, tt.wantErrors)
+			}
+			for i, want := range tt.wantErrors {
+				if report.Errors[i] != want {
+					t.Errorf("error %d = %+v, want %+v", i, report.Errors[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestBadgeImport(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemoryRepositories()
+	hired := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, e := range []Employee{
+		{FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com", HireDate: hired, CardNumber: "a100"},
+		{FirstName: "Omar", LastName: "Saleh", Email: "omar@example.com", HireDate: hired, CardNumber: "B200"},
+	} {
+		if err := repos.Employees.CreateEmployee(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+	lina, omar := employees[0].ID, employees[1].ID
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	at := func(hhmm string) time.Time { return defaultSchedule.at(day, hhmm) }
+
+	// Omar clocked in on the web before the badge file came in.
+	web := AttendanceEvent{EmployeeID: omar, Kind: "in", OccurredAt: at("08:00"), Source: "web"}
+	if err := repos.Attendance.CreateAttendanceEvent(ctx, &web); err != nil {
+		t.Fatal(err)
+	}
+
+	importer := NewBadgeImporter(repos, defaultConfig().Badge)
+	file := `card,timestamp
+A100,2024-03-04 08:58:00
+A100,2024-03-04 08:58:20
+A100,2024-03-04 12:01:00
+A100,2024-03-04 12:58:00
+A100,2024-03-04 17:05:00
+B200,2024-03-04 17:30:00
+Z999,2024-03-04 07:00:00
+Z999,2024-03-04 19:00:00
+`
+	record, err := importer.Import(ctx, "door.csv", "upload", strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	r := record.Report
+	if r.Rows != 8 || r.Swipes != 8 || r.Duplicates != 1 || r.Events != 5 || r.Days != 2 {
+		t.Errorf("report = %+v, want 8 rows, 1 duplicate, 5 events over 2 days", r)
+	}
+	if len(r.Unmatched) != 1 || r.Unmatched[0].CardNumber != "Z999" || r.Unmatched[0].Swipes != 2 || !r.Unmatched[0].Last.Equal(at("19:00")) {
+		t.Errorf("unmatched = %+v, want Z999 with 2 swipes", r.Unmatched)
+	}
+
+	events, _ := repos.Attendance.GetAttendanceEvents(ctx, lina, day, day.AddDate(0, 0, 1))
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "in,out,in,out" {
+		t.Errorf("Lina's events = %s, want in,out,in,out", got)
+	}
+	if d := summarizeDay(employees[0], day, defaultSchedule, events, false, day.AddDate(0, 1, 0)); d.Worked != 7*time.Hour+10*time.Minute {
+		t.Errorf("Lina worked %v, want 7h10m", d.Worked)
+	}
+	events, _ = repos.Attendance.GetAttendanceEvents(ctx, omar, day, day.AddDate(0, 0, 1))
+	if len(events) != 2 || events[0].Source != "web" || events[1].Source != badgeSource || events[1].Kind != "in" {
+		t.Errorf("Omar's events = %+v, want the web clock-in kept beside the badge swipe", events)
+	}
+
+	again, err := importer.Import(ctx, "door.csv", "watch", strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	if again.Report.Events != 0 || again.Report.Days != 0 {
+		t.Errorf("second import report = %+v, want nothing added", again.Report)
+	}
+	if events, _ := repos.Attendance.GetAttendanceEvents(ctx, lina, day, day.AddDate(0, 0, 1)); len(events) != 4 {
+		t.Errorf("Lina has %d events after importing twice, want 4", len(events))
+	}
+
+	// The evening dump only has the swipe out.
+	later, err := importer.Import(ctx, "door-evening.csv", "watch", strings.NewReader("card,timestamp\nb200,2024-03-04 18:00:00\n"))
+	if err != nil || later.Report.Events != 1 {
+		t.Fatalf("evening Import() = %+v, %v", later, err)
+	}
+	events, _ = repos.Attendance.GetAttendanceEvents(ctx, omar, day, day.AddDate(0, 0, 1))
+	if len(events) != 3 || events[2].Kind != "out" {
+		t.Errorf("Omar's events = %+v, want the evening swipe paired as out", events)
+	}
+
+	imports, _ := repos.BadgeImports.GetBadgeImports(ctx)
+	if len(imports) != 3 || imports[2].FileName != "door.csv" {
+		t.Errorf("stored imports = %+v, want all three", imports)
+	}
+}
+
+func TestBadgeUpload(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	hired := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	if err := repos.Employees.CreateEmployee(ctx, &Employee{FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com", HireDate: hired, CardNumber: "A100"}); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := func(name, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if name != "" {
+			fw, _ := mw.CreateFormFile("file", name)
+			fw.Write([]byte(content))
+		}
+		mw.Close()
+		r := httptest.NewRequest("POST", "/attendance/imports", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		r.Header.Set("X-Forwarded-Groups", "hr")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := upload("door.csv", "card;time;direction\nA100;2024-03-04 09:00:00;in\nQ7;2024-03-04 09:01:00;in\n")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("upload = %d %s", w.Code, w.Body)
+	}
+	imports, _ := repos.BadgeImports.GetBadgeImports(ctx)
+	if len(imports) != 1 {
+		t.Fatalf("imports = %+v, want one", imports)
+	}
+	report := "/attendance/imports/" + strconv.Itoa(imports[0].ID)
+	if loc := w.Header().Get("HX-Redirect"); loc != report {
+		t.Errorf("HX-Redirect = %q, want %q", loc, report)
+	}
+
+	w = send(h, "GET", report, nil, hrHeaders)
+	if body := w.Body.String(); w.Code != http.StatusOK || !strings.Contains(body, "Q7") || !strings.Contains(body, "door.csv") {
+		t.Errorf("GET %s = %d, want the unmatched card Q7:\n%s", report, w.Code, body)
+	}
+	if w := send(h, "GET", "/attendance/imports", nil, hrHeaders); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "door.csv") {
+		t.Errorf("GET /attendance/imports = %d", w.Code)
+	}
+	if w := send(h, "GET", "/attendance/imports/99", nil, hrHeaders); w.Code != http.StatusNotFound {
+		t.Errorf("missing report = %d, want 404", w.Code)
+	}
+
+	if w := upload("door.csv", "who,when\n"); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "no card number column") {
+		t.Errorf("upload without card column = %d %s, want 400", w.Code, w.Body)
+	}
+	if w := upload("", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("upload without a file = %d, want 400", w.Code)
+	}
+}
+
+func TestBadgeWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repos := NewMemoryRepositories()
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("waiting.csv", "card,timestamp\nA100,2024-03-04 09:00:00\n")
+	write("notes.txt", "not a badge file")
+
+	w := NewBadgeWatcher(dir, NewBadgeImporter(repos, defaultConfig().Badge))
+	w.settle = 10 * time.Millisecond
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	waitFor := func(what string, ok func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !ok() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	moved := func(sub, suffix string) func() bool {
+		return func() bool {
+			matches, _ := filepath.Glob(filepath.Join(dir, sub, "*-"+suffix))
+			return len(matches) == 1
+		}
+	}
+	waitFor("the waiting file", moved("processed", "waiting.csv"))
+
+	write("dropped.csv", "card,timestamp\nA100,2024-03-04 17:00:00\n")
+	write("broken.csv", "nothing useful\n")
+	waitFor("the dropped file", moved("processed", "dropped.csv"))
+	waitFor("the broken file", moved("failed", "broken.csv"))
+
+	imports, _ := repos.BadgeImports.GetBadgeImports(context.Background())
+	if len(imports) != 2 || imports[0].Source != "watch" {
+		t.Errorf("imports = %+v, want two from the watched directory", imports)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("notes.txt was touched: %v", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
@@ -0,0 +1,740 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// benefitKinds are the kinds of benefit plans, in the order they are
+// offered.
+var benefitKinds = []string{"health", "dental", "vision", "pension", "life", "other"}
+
+// dependentRelationships are how a dependent is related to the employee.
+var dependentRelationships = []string{"spouse", "partner", "child", "parent", "other"}
+
+// employeeStatuses are the statuses the employee forms offer, which plans
+// can be limited to.
+var employeeStatuses = []string{"active", "inactive", "suspended"}
+
+func (p *BenefitPlan) validate() error {
+	if p.Name == "" {
+		return errors.New("Name is required")
+	}
+	if !slices.Contains(benefitKinds, p.Kind) {
+		return errors.New("Invalid benefit kind")
+	}
+	for _, s := range splitList(p.Statuses) {
+		if !slices.Contains(employeeStatuses, s) {
+			return errors.New("Invalid employee status")
+		}
+	}
+	if p.MinTenureMonths < 0 {
+		return errors.New("Tenure can't be negative")
+	}
+	if p.OpensOn.IsZero() || p.ClosesOn.IsZero() {
+		return errors.New("The enrollment window is required")
+	}
+	if p.ClosesOn.Before(p.OpensOn) {
+		return errors.New("The enrollment window can't close before it opens")
+	}
+	return nil
+}
+
+// Open reports whether day falls in the plan's open-enrollment window.
+func (p BenefitPlan) Open(day time.Time) bool {
+	return !day.Before(p.OpensOn) && !day.After(p.ClosesOn)
+}
+
+// tenureMonths returns the whole months from hire to day, or 0 when the
+// hire date is unknown.
+func tenureMonths(hire, day time.Time) int {
+	if hire.IsZero() {
+		return 0
+	}
+	months := (day.Year()-hire.Year())*12 + int(day.Month()-hire.Month())
+	if day.Day() < hire.Day() {
+		months--
+	}
+	return max(months, 0)
+}
+
+// Allows reports whether employees with the status can join the plan.
+func (p BenefitPlan) Allows(status string) bool {
+	return p.Statuses == "" || slices.Contains(splitList(p.Statuses), status)
+}
+
+// eligibility returns why e can't be covered by the plan on day, or nil
+// if they can.
+func (p BenefitPlan) eligibility(e Employee, day time.Time) error {
+	if !p.Allows(cmp.Or(e.Status, "active")) {
+		return errors.New("The employee's status is not eligible for this plan")
+	}
+	if p.DepartmentID != 0 && p.DepartmentID != e.DepartmentID {
+		return errors.New("The plan is for another department")
+	}
+	if tenureMonths(e.HireDate, day) < p.MinTenureMonths {
+		return errors.New("The employee hasn't been employed long enough for this plan")
+	}
+	return nil
+}
+
+// Ended reports whether the coverage was ended.
+func (e BenefitEnrollment) Ended() bool {
+	return !e.EffectiveTo.IsZero()
+}
+
+// Covers reports whether the enrollment covers the dependent.
+func (e BenefitEnrollment) Covers(dependentID int) bool {
+	return slices.Contains(e.DependentIDs, dependentID)
+}
+
+// currentEnrollment returns the enrollment of an employee in a plan that
+// was not ended, and whether there is one.
+func currentEnrollment(enrollments []BenefitEnrollment, planID, employeeID int) (BenefitEnrollment, bool) {
+	for _, e := range enrollments {
+		if e.PlanID == planID && e.EmployeeID == employeeID && !e.Ended() {
+			return e, true
+		}
+	}
+	return BenefitEnrollment{}, false
+}
+
+// BenefitEnrollmentView is an enrollment with its plan, employee and the
+// dependents it covers.
+type BenefitEnrollmentView struct {
+	BenefitEnrollment
+	Plan       BenefitPlan
+	Employee   Employee
+	Dependents []Dependent
+}
+
+// benefitEnrollmentViews joins the enrollments with their plans, people
+// and covered dependents.
+func benefitEnrollmentViews(enrollments []BenefitEnrollment, plans map[int]BenefitPlan, employees map[int]Employee, dependents []Dependent) []BenefitEnrollmentView {
+	byID := make(map[int]Dependent, len(dependents))
+	for _, d := range dependents {
+		byID[d.ID] = d
+	}
+	views := make([]BenefitEnrollmentView, len(enrollments))
+	for i, e := range enrollments {
+		views[i] = BenefitEnrollmentView{BenefitEnrollment: e, Plan: plans[e.PlanID], Employee: employees[e.EmployeeID]}
+		for _, id := range e.DependentIDs {
+			if d, ok := byID[id]; ok {
+				views[i].Dependents = append(views[i].Dependents, d)
+			}
+		}
+	}
+	return views
+}
+
+func (app *App) benefitPlansByID(ctx context.Context) ([]BenefitPlan, map[int]BenefitPlan, error) {
+	plans, err := app.BenefitPlanRepository.GetBenefitPlans(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int]BenefitPlan, len(plans))
+	for _, p := range plans {
+		byID[p.ID] = p
+	}
+	return plans, byID, nil
+}
+
+// BenefitPlanSummary is a plan on the plan list.
+type BenefitPlanSummary struct {
+	BenefitPlan
+	Open     bool
+	Enrolled int
+}
+
+func (app *App) handleBenefits(w http.ResponseWriter, r *http.Request) {
+	plans, err := app.BenefitPlanRepository.GetBenefitPlans(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit plans", err)
+		return
+	}
+	enrollments, err := app.CoverageRepository.GetBenefitEnrollments(r.Context(), 0, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollments", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	enrolled := make(map[int]int)
+	for _, e := range enrollments {
+		if !e.Ended() {
+			enrolled[e.PlanID]++
+		}
+	}
+	now := today()
+	summaries := make([]BenefitPlanSummary, len(plans))
+	for i, p := range plans {
+		summaries[i] = BenefitPlanSummary{BenefitPlan: p, Open: p.Open(now), Enrolled: enrolled[p.ID]}
+	}
+	data := map[string]any{
+		"ActivePage":  "benefits",
+		"Plans":       summaries,
+		"Departments": departments,
+	}
+	app.render(w, r, "benefits.html", "", data)
+}
+
+// benefitPlanForm reads the fields of the add and update plan forms.
+func benefitPlanForm(r *http.Request) (BenefitPlan, error) {
+	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	tenure, _ := strconv.Atoi(r.FormValue("min_tenure_months"))
+	plan := BenefitPlan{
+		Name:             strings.TrimSpace(r.FormValue("name")),
+		Kind:             r.FormValue("kind"),
+		Description:      strings.TrimSpace(r.FormValue("description")),
+		Statuses:         strings.Join(r.Form["statuses"], ","),
+		DepartmentID:     deptID,
+		MinTenureMonths:  tenure,
+		CoversDependents: r.FormValue("covers_dependents") != "",
+	}
+	var err error
+	if plan.OpensOn, err = time.Parse("2006-01-02", r.FormValue("opens_on")); err != nil {
+		return plan, errors.New("Invalid date")
+	}
+	if plan.ClosesOn, err = time.Parse("2006-01-02", r.FormValue("closes_on")); err != nil {
+		return plan, errors.New("Invalid date")
+	}
+	return plan, plan.validate()
+}
+
+// benefitPlanData returns the page data of the add and update plan forms.
+func (app *App) benefitPlanData(ctx context.Context, plan *BenefitPlan) (map[string]any, error) {
+	departments, err := app.DepartmentRepository.GetDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"ActivePage":  "benefits",
+		"Plan":        plan,
+		"Kinds":       benefitKinds,
+		"Statuses":    employeeStatuses,
+		"Departments": departments,
+	}, nil
+}
+
+func (app *App) handleAddBenefitPlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		now := today()
+		data, err := app.benefitPlanData(r.Context(), &BenefitPlan{Kind: "health", Statuses: "active", OpensOn: now, ClosesOn: now.AddDate(0, 1, 0)})
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		app.render(w, r, "add_benefit_plan.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	plan, err := benefitPlanForm(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.BenefitPlanRepository.CreateBenefitPlan(r.Context(), &plan); err != nil {
+		app.serverError(w, r, "Failed to add benefit plan", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/benefits")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// benefitPlan fetches the plan named by the request's id path value and
+// writes the error response when there is none.
+func (app *App) benefitPlan(w http.ResponseWriter, r *http.Request) (*BenefitPlan, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	plan, err := app.BenefitPlanRepository.GetBenefitPlanByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit plan", err)
+		return nil, false
+	}
+	if plan == nil {
+		app.clientError(w, r, http.StatusNotFound, "Benefit plan not found")
+		return nil, false
+	}
+	return plan, true
+}
+
+// handleUpdateBenefitPlan edits a plan, such as to set the window of the
+// next open enrollment. Current enrollments are left alone even if their
+// employees are no longer eligible.
+func (app *App) handleUpdateBenefitPlan(w http.ResponseWriter, r *http.Request) {
+	plan, ok := app.benefitPlan(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		data, err := app.benefitPlanData(r.Context(), plan)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		app.render(w, r, "update_benefit_plan.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated, err := benefitPlanForm(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated.ID = plan.ID
+	if err := app.BenefitPlanRepository.UpdateBenefitPlan(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update benefit plan", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/benefits")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteBenefitPlan deletes a plan nobody was ever enrolled in;
+// plans with a history are kept for the record.
+func (app *App) handleDeleteBenefitPlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	enrollments, err := app.CoverageRepository.GetBenefitEnrollments(r.Context(), id, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollments", err)
+		return
+	}
+	if len(enrollments) > 0 {
+		app.clientError(w, r, http.StatusConflict, "Plan has enrollments")
+		return
+	}
+	if err := app.BenefitPlanRepository.DeleteBenefitPlan(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete benefit plan", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/benefits")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// planEnrollments loads the enrollments in a plan, newest first. The
+// error response has been written when it returns false.
+func (app *App) planEnrollments(w http.ResponseWriter, r *http.Request, plan *BenefitPlan) ([]BenefitEnrollmentView, []Employee, bool) {
+	enrollments, err := app.CoverageRepository.GetBenefitEnrollments(r.Context(), plan.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollments", err)
+		return nil, nil, false
+	}
+	employees, byID, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return nil, nil, false
+	}
+	dependents, err := app.DependentRepository.GetDependents(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch dependents", err)
+		return nil, nil, false
+	}
+	plans := map[int]BenefitPlan{plan.ID: *plan}
+	return benefitEnrollmentViews(enrollments, plans, byID, dependents), employees, true
+}
+
+// handleBenefitPlan is the enrollment report of a plan: who is covered,
+// who could join and who left.
+func (app *App) handleBenefitPlan(w http.ResponseWriter, r *http.Request) {
+	plan, ok := app.benefitPlan(w, r)
+	if !ok {
+		return
+	}
+	enrollments, employees, ok := app.planEnrollments(w, r, plan)
+	if !ok {
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	var current, ended []BenefitEnrollmentView
+	covered := make(map[int]bool)
+	dependents := 0
+	for _, e := range enrollments {
+		if e.Ended() {
+			ended = append(ended, e)
+			continue
+		}
+		current = append(current, e)
+		covered[e.EmployeeID] = true
+		dependents += len(e.Dependents)
+	}
+	now := today()
+	eligible := slices.DeleteFunc(employees, func(e Employee) bool {
+		return covered[e.ID] || plan.eligibility(e, now) != nil
+	})
+
+	data := map[string]any{
+		"ActivePage":  "benefits",
+		"Plan":        plan,
+		"Open":        plan.Open(now),
+		"Current":     current,
+		"Ended":       ended,
+		"Dependents":  dependents,
+		"Eligible":    eligible,
+		"Departments": departments,
+	}
+	app.render(w, r, "benefit_plan.html", "", data)
+}
+
+func (app *App) handleExportBenefitPlan(w http.ResponseWriter, r *http.Request) {
+	plan, ok := app.benefitPlan(w, r)
+	if !ok {
+		return
+	}
+	enrollments, _, ok := app.planEnrollments(w, r, plan)
+	if !ok {
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	headers := []string{"Plan", "Employee ID", "Employee", "Email", "Department", "Effective From", "Effective To", "Dependents", "Enrolled By"}
+	mapper := func(e BenefitEnrollmentView) []string {
+		effectiveTo := ""
+		if e.Ended() {
+			effectiveTo = e.EffectiveTo.Format("2006-01-02")
+		}
+		names := make([]string, len(e.Dependents))
+		for i, d := range e.Dependents {
+			names[i] = d.FirstName + " " + d.LastName
+		}
+		return []string{
+			plan.Name,
+			fmt.Sprintf("%d", e.Employee.ID),
+			e.Employee.FirstName + " " + e.Employee.LastName,
+			e.Employee.Email,
+			departments[e.Employee.DepartmentID].Name,
+			e.EffectiveFrom.Format("2006-01-02"),
+			effectiveTo,
+			strings.Join(names, "; "),
+			e.EnrolledBy,
+		}
+	}
+
+	writeExport(w, r, "Benefit Enrollments", enrollments, headers, mapper)
+}
+
+// BenefitOption is a plan on the benefits page of an employee.
+type BenefitOption struct {
+	Plan    BenefitPlan
+	Open    bool
+	Reason  string            // why the employee is not eligible, empty if they are
+	Current BenefitEnrollment // zero when not enrolled
+}
+
+// handleEmployeeBenefits shows the dependents of an employee, the plans
+// they can enroll in and their enrollment history.
+func (app *App) handleEmployeeBenefits(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	plans, byID, err := app.benefitPlansByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit plans", err)
+		return
+	}
+	enrollments, err := app.CoverageRepository.GetBenefitEnrollments(r.Context(), 0, employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollments", err)
+		return
+	}
+	dependents, err := app.DependentRepository.GetDependents(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch dependents", err)
+		return
+	}
+
+	now := today()
+	options := make([]BenefitOption, len(plans))
+	for i, p := range plans {
+		options[i] = BenefitOption{Plan: p, Open: p.Open(now)}
+		if err := p.eligibility(*employee, now); err != nil {
+			options[i].Reason = err.Error()
+		}
+		options[i].Current, _ = currentEnrollment(enrollments, p.ID, employee.ID)
+	}
+	employees := map[int]Employee{employee.ID: *employee}
+	data := map[string]any{
+		"ActivePage":    "benefits",
+		"Employee":      employee,
+		"Options":       options,
+		"Dependents":    dependents,
+		"History":       benefitEnrollmentViews(enrollments, byID, employees, dependents),
+		"Relationships": dependentRelationships,
+		"Today":         now,
+	}
+	app.render(w, r, "employee_benefits.html", "", data)
+}
+
+func (app *App) handleAddDependent(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	dependent := Dependent{
+		EmployeeID:   employee.ID,
+		FirstName:    strings.TrimSpace(r.FormValue("first_name")),
+		LastName:     strings.TrimSpace(r.FormValue("last_name")),
+		Relationship: r.FormValue("relationship"),
+	}
+	if dependent.FirstName == "" || dependent.LastName == "" {
+		app.clientError(w, r, http.StatusBadRequest, "Name is required")
+		return
+	}
+	if !slices.Contains(dependentRelationships, dependent.Relationship) {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid relationship")
+		return
+	}
+	if s := r.FormValue("birth_date"); s != "" {
+		birthDate, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+			return
+		}
+		if birthDate.After(today()) {
+			app.clientError(w, r, http.StatusBadRequest, "The birth date can't be in the future")
+			return
+		}
+		dependent.BirthDate = birthDate
+	}
+
+	if err := app.DependentRepository.CreateDependent(r.Context(), &dependent); err != nil {
+		app.serverError(w, r, "Failed to add dependent", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/benefits/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteDependent removes a dependent who is not covered by any
+// current enrollment.
+func (app *App) handleDeleteDependent(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	dependent, err := app.DependentRepository.GetDependentByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch dependent", err)
+		return
+	}
+	if dependent == nil || dependent.EmployeeID != employee.ID {
+		app.clientError(w, r, http.StatusNotFound, "Dependent not found")
+		return
+	}
+	enrollments, err := app.CoverageRepository.GetBenefitEnrollments(r.Context(), 0, employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollments", err)
+		return
+	}
+	for _, e := range enrollments {
+		if !e.Ended() && e.Covers(dependent.ID) {
+			app.clientError(w, r, http.StatusConflict, "Dependent is covered by a current enrollment")
+			return
+		}
+	}
+	if err := app.DependentRepository.DeleteDependent(r.Context(), dependent.ID); err != nil {
+		app.serverError(w, r, "Failed to delete dependent", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/benefits/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleEnrollBenefit enrolls an employee in a plan during its open
+// enrollment, or changes the dependents they cover: the current
+// enrollment then ends the day before the new one takes effect.
+func (app *App) handleEnrollBenefit(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	planID, err := strconv.Atoi(r.FormValue("plan_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Benefit plan not found")
+		return
+	}
+	plan, err := app.BenefitPlanRepository.GetBenefitPlanByID(r.Context(), planID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit plan", err)
+		return
+	}
+	if plan == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Benefit plan not found")
+		return
+	}
+	effectiveFrom, err := formDate(r, "effective_from")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	if !plan.Open(today()) {
+		app.clientError(w, r, http.StatusConflict, "Enrollment is closed for this plan")
+		return
+	}
+	if err := plan.eligibility(*employee, effectiveFrom); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var dependentIDs []int
+	for _, v := range r.Form["dependent_id"] {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid dependent")
+			return
+		}
+		dependent, err := app.DependentRepository.GetDependentByID(r.Context(), id)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch dependent", err)
+			return
+		}
+		if dependent == nil || dependent.EmployeeID != employee.ID {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid dependent")
+			return
+		}
+		if !slices.Contains(dependentIDs, id) {
+			dependentIDs = append(dependentIDs, id)
+		}
+	}
+	if len(dependentIDs) > 0 && !plan.CoversDependents {
+		app.clientError(w, r, http.StatusBadRequest, "This plan doesn't cover dependents")
+		return
+	}
+
+	enrollments, err := app.CoverageRepository.GetBenefitEnrollments(r.Context(), plan.ID, employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollments", err)
+		return
+	}
+	if current, ok := currentEnrollment(enrollments, plan.ID, employee.ID); ok {
+		if !effectiveFrom.After(current.EffectiveFrom) {
+			app.clientError(w, r, http.StatusBadRequest, "The change must take effect after the current enrollment started")
+			return
+		}
+		current.EffectiveTo = effectiveFrom.AddDate(0, 0, -1)
+		if err := app.CoverageRepository.UpdateBenefitEnrollment(r.Context(), &current); err != nil {
+			app.serverError(w, r, "Failed to update benefit enrollment", err)
+			return
+		}
+	}
+
+	enrollment := BenefitEnrollment{
+		PlanID:        plan.ID,
+		EmployeeID:    employee.ID,
+		DependentIDs:  dependentIDs,
+		EffectiveFrom: effectiveFrom,
+		EnrolledBy:    app.viewer(r).User,
+	}
+	if err := app.CoverageRepository.CreateBenefitEnrollment(r.Context(), &enrollment); err != nil {
+		app.serverError(w, r, "Failed to add benefit enrollment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/benefits/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleEndBenefitEnrollment ends a coverage, such as when the employee
+// opts out or leaves. It is allowed outside the enrollment window.
+func (app *App) handleEndBenefitEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	enrollment, err := app.CoverageRepository.GetBenefitEnrollmentByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch benefit enrollment", err)
+		return
+	}
+	if enrollment == nil {
+		app.clientError(w, r, http.StatusNotFound, "Enrollment not found")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	effectiveTo, err := formDate(r, "effective_to")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	if enrollment.Ended() {
+		app.clientError(w, r, http.StatusConflict, "Enrollment has already ended")
+		return
+	}
+	if effectiveTo.Before(enrollment.EffectiveFrom) {
+		app.clientError(w, r, http.StatusBadRequest, "The end date must not be before the start date")
+		return
+	}
+
+	enrollment.EffectiveTo = effectiveTo
+	if err := app.CoverageRepository.UpdateBenefitEnrollment(r.Context(), enrollment); err != nil {
+		app.serverError(w, r, "Failed to update benefit enrollment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/benefits/employees/%d", enrollment.EmployeeID))
+	w.WriteHeader(http.StatusSeeOther)
+}
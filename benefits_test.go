@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestBenefitEligibility(t *testing.T) {
+	day := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	plan := BenefitPlan{Statuses: "active,suspended", DepartmentID: 2, MinTenureMonths: 6}
+	tests := []struct {
+		name     string
+		employee Employee
+		want     string
+	}{
+		{"eligible", Employee{Status: "active", DepartmentID: 2, HireDate: time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC)}, ""},
+		{"no status is active", Employee{DepartmentID: 2, HireDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}, ""},
+		{"status", Employee{Status: "inactive", DepartmentID: 2, HireDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}, "status"},
+		{"department", Employee{Status: "active", DepartmentID: 3, HireDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}, "department"},
+		{"a day short", Employee{Status: "active", DepartmentID: 2, HireDate: time.Date(2024, 12, 16, 0, 0, 0, 0, time.UTC)}, "long enough"},
+		{"no hire date", Employee{Status: "active", DepartmentID: 2}, "long enough"},
+	}
+	for _, tt := range tests {
+		err := plan.eligibility(tt.employee, day)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: eligibility() = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if err := (BenefitPlan{}).eligibility(Employee{Status: "suspended"}, day); err != nil {
+		t.Errorf("eligibility() of a plan without rules = %v, want nil", err)
+	}
+}
+
+func TestBenefits(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	now := today()
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active", HireDate: now.AddDate(-2, 0, 0)}
+	newHire := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active", HireDate: now.AddDate(0, -1, 0)}
+	for _, e := range []*Employee{&omar, &newHire} {
+		if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan := url.Values{
+		"name": {"Family Health"}, "kind": {"health"}, "statuses": {"active"}, "min_tenure_months": {"3"}, "covers_dependents": {"1"},
+		"opens_on": {now.AddDate(0, 0, -7).Format("2006-01-02")}, "closes_on": {now.AddDate(0, 0, 7).Format("2006-01-02")},
+	}
+	bad := url.Values{"name": {"X"}, "kind": {"health"}, "opens_on": plan["closes_on"], "closes_on": plan["opens_on"]}
+	if w := send(h, "POST", "/benefits/add", bad, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add with the window closing before it opens = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", "/benefits/add", plan, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add = %d %s", w.Code, w.Body)
+	}
+	pension := url.Values{"name": {"Pension"}, "kind": {"pension"}, "opens_on": {"2025-01-01"}, "closes_on": {"2025-01-31"}}
+	if w := send(h, "POST", "/benefits/add", pension, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add = %d %s", w.Code, w.Body)
+	}
+	plans, _ := repos.BenefitPlans.GetBenefitPlans(ctx)
+	if len(plans) != 2 || plans[0].Kind != "health" || plans[0].Statuses != "active" || !plans[0].CoversDependents {
+		t.Fatalf("plans = %+v, want the health plan and the pension", plans)
+	}
+	health, closed := strconv.Itoa(plans[0].ID), strconv.Itoa(plans[1].ID)
+
+	employee := "/benefits/employees/" + strconv.Itoa(omar.ID)
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+	if w := send(h, "POST", employee+"/dependents", url.Values{"first_name": {"Sara"}, "last_name": {"Khalil"}, "relationship": {"spouse"}, "birth_date": {tomorrow}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add a dependent born tomorrow = %d, want 400", w.Code)
+	}
+	for _, name := range []string{"Sara", "Adam"} {
+		if w := send(h, "POST", employee+"/dependents", url.Values{"first_name": {name}, "last_name": {"Khalil"}, "relationship": {"child"}}, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add dependent = %d %s", w.Code, w.Body)
+		}
+	}
+	dependents, _ := repos.Dependents.GetDependents(ctx, omar.ID)
+	if len(dependents) != 2 {
+		t.Fatalf("dependents = %+v, want two", dependents)
+	}
+	sara, adam := strconv.Itoa(dependents[0].ID), strconv.Itoa(dependents[1].ID)
+
+	if w := send(h, "POST", employee+"/enrollments", url.Values{"plan_id": {closed}}, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("enroll outside the window = %d, want 409", w.Code)
+	}
+	if w := send(h, "POST", "/benefits/employees/"+strconv.Itoa(newHire.ID)+"/enrollments", url.Values{"plan_id": {health}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("enroll a new hire = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", employee+"/enrollments", url.Values{"plan_id": {health}, "dependent_id": {"999"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("enroll with an unknown dependent = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", employee+"/enrollments", url.Values{"plan_id": {health}, "dependent_id": {sara}, "effective_from": {now.Format("2006-01-02")}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("enroll = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", employee+"/enrollments", url.Values{"plan_id": {health}, "dependent_id": {sara, adam}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("change taking effect on the same day = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", employee+"/enrollments", url.Values{"plan_id": {health}, "dependent_id": {sara, adam}, "effective_from": {tomorrow}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("change dependents = %d %s", w.Code, w.Body)
+	}
+	history, _ := repos.BenefitEnrollments.GetBenefitEnrollments(ctx, 0, omar.ID)
+	if len(history) != 2 || history[0].Ended() || len(history[0].DependentIDs) != 2 || !history[1].EffectiveTo.Equal(now) || history[1].EnrolledBy != "hana" {
+		t.Fatalf("history = %+v, want the change after the first enrollment ending today", history)
+	}
+	if w := send(h, "DELETE", employee+"/dependents?id="+adam, nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete a covered dependent = %d, want 409", w.Code)
+	}
+	if w := send(h, "DELETE", "/benefits/delete?id="+health, nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete a plan with enrollments = %d, want 409", w.Code)
+	}
+
+	w := send(h, "GET", "/benefits/plans/"+health+"/export", nil, hrHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export = %d %s", w.Code, w.Body)
+	}
+	f, err := excelize.OpenReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := f.GetRows("Sheet1")
+	if len(rows) != 3 || rows[1][2] != "Omar Khalil" || rows[1][7] != "Sara Khalil; Adam Khalil" || len(rows[2]) < 7 || rows[2][6] != now.Format("2006-01-02") {
+		t.Errorf("export rows = %v", rows)
+	}
+
+	end := "/benefits/enrollments/" + strconv.Itoa(history[0].ID) + "/end"
+	if w := send(h, "POST", end, url.Values{"effective_to": {now.Format("2006-01-02")}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("end before the start = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", end, url.Values{"effective_to": {tomorrow}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("end = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", end, nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("end twice = %d, want 409", w.Code)
+	}
+	if w := send(h, "DELETE", employee+"/dependents?id="+adam, nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Errorf("delete a dependent no longer covered = %d %s", w.Code, w.Body)
+	}
+	if left, _ := repos.Dependents.GetDependents(ctx, omar.ID); len(left) != 1 || !slices.ContainsFunc(left, func(d Dependent) bool { return d.FirstName == "Sara" }) {
+		t.Errorf("dependents = %+v, want Sara", left)
+	}
+
+	w = send(h, "GET", "/benefits/plans/"+health, nil, hrHeaders)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Omar") {
+		t.Errorf("report = %d, want Omar among the eligible", w.Code)
+	}
+	for _, page := range []string{"/benefits", "/benefits/add", "/benefits/update/" + health, employee} {
+		if w := send(h, "GET", page, nil, hrHeaders); w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", page, w.Code)
+		}
+	}
+}
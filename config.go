@@ -0,0 +1,965 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/text/currency"
+)
+
+// Config holds every tunable of the application. Values are resolved in
+// this order, later sources overriding earlier ones:
+//
+//  1. the defaults returned by defaultConfig
+//  2. a JSON config file (-config flag or HR_CONFIG env var)
+//  3. environment variables (a .env file is loaded first if present)
+//  4. command line flags
+type Config struct {
+	Server   ServerConfig   `json:"server"`
+	Database DatabaseConfig `json:"database"`
+	Log      LogConfig      `json:"log"`
+	Backup   BackupConfig   `json:"backup"`
+	I18n     I18nConfig     `json:"i18n"`
+	Badge    BadgeConfig    `json:"badge"`
+	Uploads  UploadConfig   `json:"uploads"`
+	Auth     AuthConfig     `json:"auth"`
+	Shifts   ShiftConfig    `json:"shifts"`
+	Calendar CalendarConfig `json:"calendar"`
+	Leave    LeaveConfig    `json:"leave"`
+	Overtime OvertimeConfig `json:"overtime"`
+	// Tenants lists the companies served by this process, each with its
+	// own database. When empty a single tenant uses Database.Path.
+	Tenants []TenantConfig `json:"tenants"`
+	// DefaultTenant serves requests whose host matches no tenant and is
+	// the tenant the backup and restore commands operate on.
+	DefaultTenant string `json:"default_tenant"`
+}
+
+type TenantConfig struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Hosts are matched exactly against the request host. Requests are
+	// also routed by subdomain: acme.hr.example.com goes to tenant acme.
+	Hosts []string `json:"hosts"`
+	// DBPath is the SQLite file of the tenant, <id>.db by default.
+	DBPath string `json:"db_path"`
+	// DBName is the PostgreSQL database of the tenant, <id> by default.
+	DBName string `json:"db_name"`
+}
+
+type ServerConfig struct {
+	Addr            string   `json:"addr"`
+	DevMode         bool     `json:"dev_mode"`
+	StartupTimeout  Duration `json:"startup_timeout"`
+	ReadTimeout     Duration `json:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout"`
+	IdleTimeout     Duration `json:"idle_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string `json:"tls_cert_file"`
+	TLSKeyFile  string `json:"tls_key_file"`
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error.
+	Level string `json:"level"`
+	// Format is json or text.
+	Format string `json:"format"`
+}
+
+type BackupConfig struct {
+	// Dir holds the snapshots: a copy of the database and, next to it, a
+	// copy of the upload directory.
+	Dir string `json:"dir"`
+	// Interval between scheduled snapshots; 0 disables the schedule.
+	Interval Duration `json:"interval"`
+	// Retain is how many snapshots to keep; 0 keeps all of them.
+	Retain  int      `json:"retain"`
+	Timeout Duration `json:"timeout"`
+}
+
+type I18nConfig struct {
+	// Locale is used when neither the lang cookie nor Accept-Language
+	// names a language we have a catalog for.
+	Locale string `json:"locale"`
+	// Currency is the ISO 4217 code salaries are shown in.
+	Currency string `json:"currency"`
+}
+
+type BadgeConfig struct {
+	// ImportDir is watched for badge-reader CSV files; empty disables the
+	// watch, leaving uploads as the only way in.
+	ImportDir string `json:"import_dir"`
+	// TimeLayout is the Go time layout of the swipe times in the files.
+	// ISO 8601 times are accepted as well.
+	TimeLayout string `json:"time_layout"`
+	// DedupeWindow drops swipes of a card that follow the previous one
+	// within this time, e.g. a badge held against the reader twice.
+	DedupeWindow Duration `json:"dedupe_window"`
+}
+
+type UploadConfig struct {
+	// Dir holds uploaded files, such as the proof of certificates.
+	Dir string `json:"dir"`
+	// MaxSize is the largest file accepted, in bytes.
+	MaxSize int64 `json:"max_size"`
+}
+
+// AuthConfig tells who makes a request. The app has no sign-in of its
+// own: it trusts headers set by an authenticating proxy in front of it,
+// which must drop any such headers sent by clients.
+type AuthConfig struct {
+	// UserHeader holds the name of the signed-in user.
+	UserHeader string `json:"user_header"`
+	// GroupsHeader holds the user's groups, separated by commas.
+	GroupsHeader string `json:"groups_header"`
+	// HRGroups are the groups allowed to see confidential documents.
+	HRGroups []string `json:"hr_groups"`
+	// FinanceGroups are the groups that give the final approval of
+	// expense claims and mark them reimbursed.
+	FinanceGroups []string `json:"finance_groups"`
+}
+
+// ShiftConfig holds the working-time rules the rota is checked against.
+// A limit of 0 turns its check off.
+type ShiftConfig struct {
+	// MaxDailyHours caps the hours worked on one day.
+	MaxDailyHours int `json:"max_daily_hours"`
+	// MaxWeeklyHours caps the hours worked from Monday to Sunday.
+	MaxWeeklyHours int `json:"max_weekly_hours"`
+	// MinRestHours is the least time off between two shifts.
+	MinRestHours int `json:"min_rest_hours"`
+}
+
+// CalendarConfig holds the days nobody works, shared by leave and
+// overtime.
+type CalendarConfig struct {
+	// WeekendDays are the days off of the week, e.g. sat and sun.
+	WeekendDays []string `json:"weekend_days"`
+	// Holidays are the public holidays, as YYYY-MM-DD.
+	Holidays []string `json:"holidays"`
+}
+
+// LeaveConfig holds the leave employees are entitled to.
+type LeaveConfig struct {
+	// Entitlements are the working days of each leave type an employee
+	// may take in a year. Types left out, like toil, have no yearly cap.
+	// A config file overrides the defaults type by type.
+	Entitlements map[string]int `json:"entitlements"`
+}
+
+// OvertimeConfig holds the multipliers overtime is paid or credited at,
+// depending on the kind of day it was worked.
+type OvertimeConfig struct {
+	WeekdayRate float64 `json:"weekday_rate"`
+	WeekendRate float64 `json:"weekend_rate"`
+	HolidayRate float64 `json:"holiday_rate"`
+	// DayHours is the length of a day of leave, turning the hours of
+	// time off in lieu into days.
+	DayHours float64 `json:"day_hours"`
+}
+
+type DatabaseConfig struct {
+	// Driver is sqlite or postgres.
+	Driver string `json:"driver"`
+	// Name is the PostgreSQL database, or the SQLite file name without
+	// the .db extension.
+	Name string `json:"name"`
+	// Path is the SQLite database file.
+	Path string `json:"path"`
+	// SchemaFile defaults to db.sql for SQLite and db_postgres.sql for
+	// PostgreSQL.
+	SchemaFile string `json:"schema_file"`
+	// The remaining settings are used by PostgreSQL only. DSN, when set,
+	// replaces the individual connection settings.
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	SSLMode  string `json:"sslmode"`
+	DSN      string `json:"dsn"`
+}
+
+func (c DatabaseConfig) schemaFile() string {
+	if c.SchemaFile != "" {
+		return c.SchemaFile
+	}
+	if Dialect(c.Driver) == DialectPostgres {
+		return "db_postgres.sql"
+	}
+	return "db.sql"
+}
+
+// Duration is a time.Duration that reads and writes as a string such as
+// "10s" in the JSON config file.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			DevMode:         true,
+			StartupTimeout:  Duration(10 * time.Second),
+			ReadTimeout:     Duration(15 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(2 * time.Minute),
+			ShutdownTimeout: Duration(20 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Driver:  "sqlite",
+			Name:    "hr",
+			Host:    "localhost",
+			Port:    "5432",
+			SSLMode: "disable",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Backup: BackupConfig{
+			Dir:     "backups",
+			Retain:  7,
+			Timeout: Duration(5 * time.Minute),
+		},
+		I18n: I18nConfig{
+			Locale:   "en",
+			Currency: "USD",
+		},
+		Badge: BadgeConfig{
+			TimeLayout:   "2006-01-02 15:04:05",
+			DedupeWindow: Duration(time.Minute),
+		},
+		Uploads: UploadConfig{
+			Dir:     "uploads",
+			MaxSize: 10 << 20,
+		},
+		Auth: AuthConfig{
+			UserHeader:    "X-Forwarded-User",
+			GroupsHeader:  "X-Forwarded-Groups",
+			HRGroups:      []string{"hr"},
+			FinanceGroups: []string{"finance"},
+		},
+		Shifts: ShiftConfig{
+			MaxDailyHours:  12,
+			MaxWeeklyHours: 48,
+			MinRestHours:   11,
+		},
+		Calendar: CalendarConfig{
+			WeekendDays: []string{"sat", "sun"},
+		},
+		Leave: LeaveConfig{
+			Entitlements: map[string]int{"vacation": 20, "sick": 10, "personal": 3},
+		},
+		Overtime: OvertimeConfig{
+			WeekdayRate: 1.5,
+			WeekendRate: 2,
+			HolidayRate: 2.5,
+			DayHours:    8,
+		},
+	}
+}
+
+// configField describes one setting that can be overridden from the
+// environment or the command line.
+type configField struct {
+	flag   string
+	env    string
+	usage  string
+	secret bool // masked by config print
+	get    func(c *Config) string
+	set    func(c *Config, v string) error
+}
+
+func setDuration(dst func(c *Config) *Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*dst(c) = Duration(d)
+		return nil
+	}
+}
+
+func setString(dst func(c *Config) *string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		*dst(c) = v
+		return nil
+	}
+}
+
+func setInt(dst func(c *Config) *int) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*dst(c) = n
+		return nil
+	}
+}
+
+func setFloat(dst func(c *Config) *float64) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*dst(c) = f
+		return nil
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// parseEntitlements reads leave entitlements written as
+// vacation=20,sick=10.
+func parseEntitlements(v string) (map[string]int, error) {
+	entitlements := make(map[string]int)
+	for _, pair := range splitList(v) {
+		leaveType, days, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not type=days", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil {
+			return nil, err
+		}
+		entitlements[strings.TrimSpace(leaveType)] = n
+	}
+	return entitlements, nil
+}
+
+func formatEntitlements(entitlements map[string]int) string {
+	pairs := make([]string, 0, len(entitlements))
+	for leaveType, days := range entitlements {
+		pairs = append(pairs, leaveType+"="+strconv.Itoa(days))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+var configFields = []configField{
+	{
+		flag: "addr", env: "HR_ADDR", usage: "HTTP listen address",
+		get: func(c *Config) string { return c.Server.Addr },
+		set: setString(func(c *Config) *string { return &c.Server.Addr }),
+	},
+	{
+		flag: "dev", env: "HR_DEV_MODE", usage: "enable template and asset hot reload",
+		get: func(c *Config) string { return strconv.FormatBool(c.Server.DevMode) },
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			c.Server.DevMode = b
+			return nil
+		},
+	},
+	{
+		flag: "startup-timeout", env: "HR_STARTUP_TIMEOUT", usage: "time allowed for connecting to the database at startup",
+		get: func(c *Config) string { return c.Server.StartupTimeout.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Server.StartupTimeout }),
+	},
+	{
+		flag: "read-timeout", env: "HR_READ_TIMEOUT", usage: "maximum duration for reading a whole request",
+		get: func(c *Config) string { return c.Server.ReadTimeout.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Server.ReadTimeout }),
+	},
+	{
+		flag: "write-timeout", env: "HR_WRITE_TIMEOUT", usage: "maximum duration for writing a response (dev reload streams are exempt)",
+		get: func(c *Config) string { return c.Server.WriteTimeout.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Server.WriteTimeout }),
+	},
+	{
+		flag: "idle-timeout", env: "HR_IDLE_TIMEOUT", usage: "how long keep-alive connections may sit idle",
+		get: func(c *Config) string { return c.Server.IdleTimeout.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Server.IdleTimeout }),
+	},
+	{
+		flag: "shutdown-timeout", env: "HR_SHUTDOWN_TIMEOUT", usage: "time allowed for in-flight requests to finish on SIGINT/SIGTERM",
+		get: func(c *Config) string { return c.Server.ShutdownTimeout.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Server.ShutdownTimeout }),
+	},
+	{
+		flag: "tls-cert", env: "HR_TLS_CERT_FILE", usage: "TLS certificate file; enables HTTPS together with -tls-key",
+		get: func(c *Config) string { return c.Server.TLSCertFile },
+		set: setString(func(c *Config) *string { return &c.Server.TLSCertFile }),
+	},
+	{
+		flag: "tls-key", env: "HR_TLS_KEY_FILE", usage: "TLS private key file",
+		get: func(c *Config) string { return c.Server.TLSKeyFile },
+		set: setString(func(c *Config) *string { return &c.Server.TLSKeyFile }),
+	},
+	{
+		flag: "db-driver", env: "DB_DRIVER", usage: "database backend: sqlite or postgres",
+		get: func(c *Config) string { return c.Database.Driver },
+		set: setString(func(c *Config) *string { return &c.Database.Driver }),
+	},
+	{
+		flag: "db-name", env: "DB_NAME", usage: "database name; the SQLite file is <name>.db unless -db-path is set",
+		get: func(c *Config) string { return c.Database.Name },
+		set: setString(func(c *Config) *string { return &c.Database.Name }),
+	},
+	{
+		flag: "db-path", env: "DB_PATH", usage: "path of the SQLite database file",
+		get: func(c *Config) string { return c.Database.Path },
+		set: setString(func(c *Config) *string { return &c.Database.Path }),
+	},
+	{
+		flag: "db-schema", env: "DB_SCHEMA_FILE", usage: "SQL file applied at startup instead of the schema built into the binary",
+		get: func(c *Config) string { return c.Database.SchemaFile },
+		set: setString(func(c *Config) *string { return &c.Database.SchemaFile }),
+	},
+	{
+		flag: "db-host", env: "DB_HOST", usage: "PostgreSQL host",
+		get: func(c *Config) string { return c.Database.Host },
+		set: setString(func(c *Config) *string { return &c.Database.Host }),
+	},
+	{
+		flag: "db-port", env: "DB_PORT", usage: "PostgreSQL port",
+		get: func(c *Config) string { return c.Database.Port },
+		set: setString(func(c *Config) *string { return &c.Database.Port }),
+	},
+	{
+		flag: "db-user", env: "DB_USER", usage: "PostgreSQL user",
+		get: func(c *Config) string { return c.Database.User },
+		set: setString(func(c *Config) *string { return &c.Database.User }),
+	},
+	{
+		flag: "db-password", env: "DB_PASSWORD", usage: "PostgreSQL password", secret: true,
+		get: func(c *Config) string { return c.Database.Password },
+		set: setString(func(c *Config) *string { return &c.Database.Password }),
+	},
+	{
+		flag: "db-sslmode", env: "DB_SSLMODE", usage: "PostgreSQL sslmode",
+		get: func(c *Config) string { return c.Database.SSLMode },
+		set: setString(func(c *Config) *string { return &c.Database.SSLMode }),
+	},
+	{
+		flag: "db-dsn", env: "DB_DSN", usage: "PostgreSQL connection URL, overrides the other PostgreSQL settings", secret: true,
+		get: func(c *Config) string { return c.Database.DSN },
+		set: setString(func(c *Config) *string { return &c.Database.DSN }),
+	},
+	{
+		flag: "log-level", env: "HR_LOG_LEVEL", usage: "minimum log level: debug, info, warn or error",
+		get: func(c *Config) string { return c.Log.Level },
+		set: setString(func(c *Config) *string { return &c.Log.Level }),
+	},
+	{
+		flag: "log-format", env: "HR_LOG_FORMAT", usage: "log output format: json or text",
+		get: func(c *Config) string { return c.Log.Format },
+		set: setString(func(c *Config) *string { return &c.Log.Format }),
+	},
+	{
+		flag: "locale", env: "HR_LOCALE", usage: "default UI language, e.g. en or ar",
+		get: func(c *Config) string { return c.I18n.Locale },
+		set: setString(func(c *Config) *string { return &c.I18n.Locale }),
+	},
+	{
+		flag: "currency", env: "HR_CURRENCY", usage: "ISO 4217 currency salaries are shown in",
+		get: func(c *Config) string { return c.I18n.Currency },
+		set: setString(func(c *Config) *string { return &c.I18n.Currency }),
+	},
+	{
+		flag: "backup-dir", env: "HR_BACKUP_DIR", usage: "directory for snapshots of the database and uploaded files",
+		get: func(c *Config) string { return c.Backup.Dir },
+		set: setString(func(c *Config) *string { return &c.Backup.Dir }),
+	},
+	{
+		flag: "backup-interval", env: "HR_BACKUP_INTERVAL", usage: "interval between scheduled snapshots, 0 to disable",
+		get: func(c *Config) string { return c.Backup.Interval.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Backup.Interval }),
+	},
+	{
+		flag: "backup-retain", env: "HR_BACKUP_RETAIN", usage: "number of snapshots to keep, 0 to keep all",
+		get: func(c *Config) string { return strconv.Itoa(c.Backup.Retain) },
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return err
+			}
+			c.Backup.Retain = n
+			return nil
+		},
+	},
+	{
+		flag: "backup-timeout", env: "HR_BACKUP_TIMEOUT", usage: "time allowed for a single backup",
+		get: func(c *Config) string { return c.Backup.Timeout.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Backup.Timeout }),
+	},
+	{
+		flag: "badge-import-dir", env: "HR_BADGE_IMPORT_DIR", usage: "directory watched for badge-reader CSV files, empty to disable",
+		get: func(c *Config) string { return c.Badge.ImportDir },
+		set: setString(func(c *Config) *string { return &c.Badge.ImportDir }),
+	},
+	{
+		flag: "badge-time-layout", env: "HR_BADGE_TIME_LAYOUT", usage: "Go time layout of the swipe times in badge files",
+		get: func(c *Config) string { return c.Badge.TimeLayout },
+		set: setString(func(c *Config) *string { return &c.Badge.TimeLayout }),
+	},
+	{
+		flag: "badge-dedupe-window", env: "HR_BADGE_DEDUPE_WINDOW", usage: "repeated swipes of a card within this time count once",
+		get: func(c *Config) string { return c.Badge.DedupeWindow.String() },
+		set: setDuration(func(c *Config) *Duration { return &c.Badge.DedupeWindow }),
+	},
+	{
+		flag: "upload-dir", env: "HR_UPLOAD_DIR", usage: "directory uploaded files are stored in",
+		get: func(c *Config) string { return c.Uploads.Dir },
+		set: setString(func(c *Config) *string { return &c.Uploads.Dir }),
+	},
+	{
+		flag: "upload-max-size", env: "HR_UPLOAD_MAX_SIZE", usage: "largest uploaded file accepted, in bytes",
+		get: func(c *Config) string { return strconv.FormatInt(c.Uploads.MaxSize, 10) },
+		set: func(c *Config, v string) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			c.Uploads.MaxSize = n
+			return nil
+		},
+	},
+	{
+		flag: "auth-user-header", env: "HR_AUTH_USER_HEADER", usage: "request header the authenticating proxy puts the user name in",
+		get: func(c *Config) string { return c.Auth.UserHeader },
+		set: setString(func(c *Config) *string { return &c.Auth.UserHeader }),
+	},
+	{
+		flag: "auth-groups-header", env: "HR_AUTH_GROUPS_HEADER", usage: "request header the authenticating proxy puts the user's groups in",
+		get: func(c *Config) string { return c.Auth.GroupsHeader },
+		set: setString(func(c *Config) *string { return &c.Auth.GroupsHeader }),
+	},
+	{
+		flag: "auth-hr-groups", env: "HR_AUTH_HR_GROUPS", usage: "comma separated groups allowed to see confidential documents",
+		get: func(c *Config) string { return strings.Join(c.Auth.HRGroups, ",") },
+		set: func(c *Config, v string) error {
+			c.Auth.HRGroups = splitList(v)
+			return nil
+		},
+	},
+	{
+		flag: "auth-finance-groups", env: "HR_AUTH_FINANCE_GROUPS", usage: "comma separated groups allowed to approve expense claims for payment",
+		get: func(c *Config) string { return strings.Join(c.Auth.FinanceGroups, ",") },
+		set: func(c *Config, v string) error {
+			c.Auth.FinanceGroups = splitList(v)
+			return nil
+		},
+	},
+	{
+		flag: "shift-max-daily-hours", env: "HR_SHIFT_MAX_DAILY_HOURS", usage: "most hours an employee can be rostered on one day, 0 for no limit",
+		get: func(c *Config) string { return strconv.Itoa(c.Shifts.MaxDailyHours) },
+		set: setInt(func(c *Config) *int { return &c.Shifts.MaxDailyHours }),
+	},
+	{
+		flag: "shift-max-weekly-hours", env: "HR_SHIFT_MAX_WEEKLY_HOURS", usage: "most hours an employee can be rostered from Monday to Sunday, 0 for no limit",
+		get: func(c *Config) string { return strconv.Itoa(c.Shifts.MaxWeeklyHours) },
+		set: setInt(func(c *Config) *int { return &c.Shifts.MaxWeeklyHours }),
+	},
+	{
+		flag: "shift-min-rest-hours", env: "HR_SHIFT_MIN_REST_HOURS", usage: "least hours off between two shifts of an employee, 0 for no limit",
+		get: func(c *Config) string { return strconv.Itoa(c.Shifts.MinRestHours) },
+		set: setInt(func(c *Config) *int { return &c.Shifts.MinRestHours }),
+	},
+	{
+		flag: "overtime-weekday-rate", env: "HR_OVERTIME_WEEKDAY_RATE", usage: "multiplier of overtime worked on a weekday",
+		get: func(c *Config) string { return formatFloat(c.Overtime.WeekdayRate) },
+		set: setFloat(func(c *Config) *float64 { return &c.Overtime.WeekdayRate }),
+	},
+	{
+		flag: "overtime-weekend-rate", env: "HR_OVERTIME_WEEKEND_RATE", usage: "multiplier of overtime worked on a weekend day",
+		get: func(c *Config) string { return formatFloat(c.Overtime.WeekendRate) },
+		set: setFloat(func(c *Config) *float64 { return &c.Overtime.WeekendRate }),
+	},
+	{
+		flag: "overtime-holiday-rate", env: "HR_OVERTIME_HOLIDAY_RATE", usage: "multiplier of overtime worked on a public holiday",
+		get: func(c *Config) string { return formatFloat(c.Overtime.HolidayRate) },
+		set: setFloat(func(c *Config) *float64 { return &c.Overtime.HolidayRate }),
+	},
+	{
+		flag: "weekend-days", env: "HR_WEEKEND_DAYS", usage: "comma separated days off of the week, e.g. sat,sun",
+		get: func(c *Config) string { return strings.Join(c.Calendar.WeekendDays, ",") },
+		set: func(c *Config, v string) error {
+			c.Calendar.WeekendDays = splitList(v)
+			return nil
+		},
+	},
+	{
+		flag: "holidays", env: "HR_HOLIDAYS", usage: "comma separated public holidays as YYYY-MM-DD",
+		get: func(c *Config) string { return strings.Join(c.Calendar.Holidays, ",") },
+		set: func(c *Config, v string) error {
+			c.Calendar.Holidays = splitList(v)
+			return nil
+		},
+	},
+	{
+		flag: "leave-entitlements", env: "HR_LEAVE_ENTITLEMENTS", usage: "comma separated working days of leave a year by type, e.g. vacation=20,sick=10",
+		get: func(c *Config) string { return formatEntitlements(c.Leave.Entitlements) },
+		set: func(c *Config, v string) error {
+			entitlements, err := parseEntitlements(v)
+			if err != nil {
+				return err
+			}
+			c.Leave.Entitlements = entitlements
+			return nil
+		},
+	},
+	{
+		flag: "leave-day-hours", env: "HR_LEAVE_DAY_HOURS", usage: "hours in a day of leave taken as time off in lieu",
+		get: func(c *Config) string { return formatFloat(c.Overtime.DayHours) },
+		set: setFloat(func(c *Config) *float64 { return &c.Overtime.DayHours }),
+	},
+	{
+		flag: "tenant", env: "HR_DEFAULT_TENANT", usage: "tenant for unmatched hosts and for backup/restore",
+		get: func(c *Config) string { return c.DefaultTenant },
+		set: setString(func(c *Config) *string { return &c.DefaultTenant }),
+	},
+}
+
+// loadConfig resolves the configuration from defaults, the optional config
+// file, the environment and args (command line flags without the program
+// name). The returned config has been validated.
+func loadConfig(name string, args []string, output io.Writer) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loading .env: %w", err)
+	}
+
+	defaults := defaultConfig()
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	configPath := fs.String("config", os.Getenv("HR_CONFIG"), "path of a JSON config file")
+	for _, f := range configFields {
+		fs.String(f.flag, f.get(&defaults), fmt.Sprintf("%s (env %s)", f.usage, f.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := defaults
+	if *configPath != "" {
+		if err := cfg.readFile(*configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range configFields {
+		if v, ok := os.LookupEnv(f.env); ok {
+			if err := f.set(&cfg, v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		for _, f := range configFields {
+			if f.flag == fl.Name && flagErr == nil {
+				if err := f.set(&cfg, fl.Value.String()); err != nil {
+					flagErr = fmt.Errorf("invalid -%s: %w", f.flag, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if cfg.Database.Path == "" {
+		cfg.Database.Path = cfg.Database.Name + ".db"
+	}
+	for i := range cfg.Tenants {
+		if cfg.Tenants[i].DBPath == "" {
+			cfg.Tenants[i].DBPath = cfg.Tenants[i].ID + ".db"
+		}
+		if cfg.Tenants[i].DBName == "" {
+			cfg.Tenants[i].DBName = cfg.Tenants[i].ID
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr must not be empty"))
+	}
+	if c.Server.StartupTimeout <= 0 {
+		errs = append(errs, errors.New("server.startup_timeout must be positive"))
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must be positive"))
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("server.tls_cert_file and server.tls_key_file must be set together"))
+	}
+	switch Dialect(c.Database.Driver) {
+	case DialectSQLite:
+		if c.Database.Name == "" && c.Database.Path == "" {
+			errs = append(errs, errors.New("database.name or database.path must be set"))
+		}
+	case DialectPostgres:
+		if c.Database.DSN == "" && (c.Database.Host == "" || c.Database.User == "" || c.Database.Name == "") {
+			errs = append(errs, errors.New("postgres needs database.dsn or database.host, database.user and database.name"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver %q must be sqlite or postgres", c.Database.Driver))
+	}
+	if c.Backup.Dir == "" {
+		errs = append(errs, errors.New("backup.dir must not be empty"))
+	}
+	if c.Backup.Interval < 0 || c.Backup.Retain < 0 {
+		errs = append(errs, errors.New("backup.interval and backup.retain must not be negative"))
+	}
+	if c.Backup.Timeout <= 0 {
+		errs = append(errs, errors.New("backup.timeout must be positive"))
+	}
+	if c.I18n.Locale == "" {
+		errs = append(errs, errors.New("i18n.locale must not be empty"))
+	}
+	if _, err := currency.ParseISO(c.I18n.Currency); err != nil {
+		errs = append(errs, fmt.Errorf("i18n.currency %q is not an ISO 4217 code", c.I18n.Currency))
+	}
+	if c.Badge.TimeLayout == "" {
+		errs = append(errs, errors.New("badge.time_layout must not be empty"))
+	}
+	if c.Badge.DedupeWindow < 0 {
+		errs = append(errs, errors.New("badge.dedupe_window must not be negative"))
+	}
+	if c.Uploads.Dir == "" {
+		errs = append(errs, errors.New("uploads.dir must not be empty"))
+	}
+	if c.Uploads.MaxSize <= 0 {
+		errs = append(errs, errors.New("uploads.max_size must be positive"))
+	}
+	if c.Auth.UserHeader == "" || c.Auth.GroupsHeader == "" {
+		errs = append(errs, errors.New("auth.user_header and auth.groups_header must not be empty"))
+	}
+	if c.Shifts.MaxDailyHours < 0 || c.Shifts.MaxWeeklyHours < 0 || c.Shifts.MinRestHours < 0 {
+		errs = append(errs, errors.New("shifts limits must not be negative"))
+	}
+	if c.Overtime.WeekdayRate < 1 || c.Overtime.WeekendRate < 1 || c.Overtime.HolidayRate < 1 {
+		errs = append(errs, errors.New("overtime rates must be at least 1"))
+	}
+	for _, d := range c.Calendar.WeekendDays {
+		if !slices.Contains(weekdayNames, d) {
+			errs = append(errs, fmt.Errorf("calendar.weekend_days: %q is not a day like mon", d))
+		}
+	}
+	for _, d := range c.Calendar.Holidays {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			errs = append(errs, fmt.Errorf("calendar.holidays: %q is not a YYYY-MM-DD date", d))
+		}
+	}
+	for leaveType, days := range c.Leave.Entitlements {
+		if leaveType == "toil" || !slices.Contains(selfServiceLeaveTypes, leaveType) {
+			errs = append(errs, fmt.Errorf("leave.entitlements: %q is not a leave type with a yearly entitlement", leaveType))
+		}
+		if days < 0 {
+			errs = append(errs, fmt.Errorf("leave.entitlements: %s must not be negative", leaveType))
+		}
+	}
+	if c.Overtime.DayHours <= 0 {
+		errs = append(errs, errors.New("overtime.day_hours must be positive"))
+	}
+	errs = append(errs, c.validateTenants()...)
+	if _, err := newLogger(io.Discard, c.Log); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateTenants() []error {
+	var errs []error
+	ids := make(map[string]bool)
+	hosts := make(map[string]string)
+	for _, t := range c.Tenants {
+		if !validTenantID(t.ID) {
+			errs = append(errs, fmt.Errorf("tenant id %q must be lowercase letters, digits and dashes", t.ID))
+		}
+		if ids[t.ID] {
+			errs = append(errs, fmt.Errorf("tenant id %q is used twice", t.ID))
+		}
+		ids[t.ID] = true
+		for _, h := range t.Hosts {
+			h = strings.ToLower(h)
+			if other, ok := hosts[h]; ok {
+				errs = append(errs, fmt.Errorf("host %q belongs to both %s and %s", h, other, t.ID))
+			}
+			hosts[h] = t.ID
+		}
+	}
+	if c.DefaultTenant != "" && len(c.Tenants) > 0 && !ids[c.DefaultTenant] {
+		errs = append(errs, fmt.Errorf("default_tenant %q is not a configured tenant", c.DefaultTenant))
+	}
+	return errs
+}
+
+func validTenantID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// singleTenantID names the implicit tenant used when Tenants is empty.
+const singleTenantID = "default"
+
+// TenantList returns the configured tenants, or the implicit single
+// tenant backed by Database.Path.
+func (c *Config) TenantList() []TenantConfig {
+	if len(c.Tenants) == 0 {
+		return []TenantConfig{{ID: singleTenantID, DBPath: c.Database.Path, DBName: c.Database.Name}}
+	}
+	return c.Tenants
+}
+
+// defaultTenantID is the tenant serving unmatched hosts, or "" when those
+// requests should be rejected.
+func (c *Config) defaultTenantID() string {
+	if len(c.Tenants) == 0 {
+		return singleTenantID
+	}
+	return c.DefaultTenant
+}
+
+// tenantDatabase returns the database settings for tenant t.
+func (c *Config) tenantDatabase(t TenantConfig) DatabaseConfig {
+	db := c.Database
+	db.Path = t.DBPath
+	if db.Name != t.DBName {
+		// A DSN names a single database, so it can't serve several tenants.
+		db.DSN = ""
+		db.Name = t.DBName
+	}
+	return db
+}
+
+// tenantBackup returns the backup settings for tenant t. Tenants keep
+// their snapshots in a subdirectory named after their id.
+func (c *Config) tenantBackup(t TenantConfig) BackupConfig {
+	b := c.Backup
+	if len(c.Tenants) > 0 {
+		b.Dir = filepath.Join(b.Dir, t.ID)
+	}
+	return b
+}
+
+// tenantBadge returns the badge import settings for tenant t. Like their
+// backups, tenants have their own subdirectory of the import directory.
+func (c *Config) tenantBadge(t TenantConfig) BadgeConfig {
+	b := c.Badge
+	if len(c.Tenants) > 0 && b.ImportDir != "" {
+		b.ImportDir = filepath.Join(b.ImportDir, t.ID)
+	}
+	return b
+}
+
+// tenantUploads returns the upload settings for tenant t, whose files go
+// in their own subdirectory.
+func (c *Config) tenantUploads(t TenantConfig) UploadConfig {
+	u := c.Uploads
+	if len(c.Tenants) > 0 {
+		u.Dir = filepath.Join(u.Dir, t.ID)
+	}
+	return u
+}
+
+// selectedTenant is the tenant CLI commands operate on.
+func (c *Config) selectedTenant() (TenantConfig, error) {
+	id := c.defaultTenantID()
+	for _, t := range c.TenantList() {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return TenantConfig{}, errors.New("several tenants are configured; choose one with -tenant")
+}
+
+// Print writes the effective configuration, one setting per line.
+func (c *Config) Print(w io.Writer) {
+	for _, f := range configFields {
+		v := f.get(c)
+		if f.secret && v != "" {
+			v = "********"
+		}
+		fmt.Fprintf(w, "%-20s %-23s %s\n", f.flag, f.env, v)
+	}
+	for _, t := range c.Tenants {
+		fmt.Fprintf(w, "tenant %-13s %-23s %s hosts=%s\n", t.ID, t.Name, t.DBPath, strings.Join(t.Hosts, ","))
+	}
+}
+
+// runConfig implements the "config" command.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New("usage: config print [flags]")
+	}
+	cfg, err := loadConfig("config print", args[1:], os.Stderr)
+	if err != nil {
+		return err
+	}
+	cfg.Print(os.Stdout)
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadConfigPrecedence checks that flags beat env vars, which beat the
+// config file, which beats the defaults.
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	file := `{"server": {"addr": ":7000", "startup_timeout": "3s"}, "database": {"name": "fromfile"}, "leave": {"entitlements": {"vacation": 25}}}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DB_NAME", "fromenv")
+	t.Setenv("HR_ADDR", ":7001")
+
+	cfg, err := loadConfig("test", []string{"-config", path, "-addr", ":7002"}, io.Discard)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	if cfg.Server.Addr != ":7002" {
+		t.Errorf("Addr = %q, want flag value :7002", cfg.Server.Addr)
+	}
+	if cfg.Database.Name != "fromenv" {
+		t.Errorf("Database.Name = %q, want env value fromenv", cfg.Database.Name)
+	}
+	if cfg.Database.Path != "fromenv.db" {
+		t.Errorf("Database.Path = %q, want fromenv.db", cfg.Database.Path)
+	}
+	if got := time.Duration(cfg.Server.StartupTimeout); got != 3*time.Second {
+		t.Errorf("StartupTimeout = %v, want file value 3s", got)
+	}
+	if got := formatEntitlements(cfg.Leave.Entitlements); got != "personal=3,sick=10,vacation=25" {
+		t.Errorf("Leave.Entitlements = %s, want the file's vacation over the defaults", got)
+	}
+	if got := cfg.Database.schemaFile(); got != "db.sql" {
+		t.Errorf("schemaFile() = %q, want default db.sql", got)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "empty address", args: []string{"-addr", ""}},
+		{name: "zero timeout", args: []string{"-startup-timeout", "0s"}},
+		{name: "bad bool", args: []string{"-dev", "maybe"}},
+		{name: "no user header", args: []string{"-auth-user-header", ""}},
+		{name: "overtime rate below 1", args: []string{"-overtime-weekend-rate", "0.5"}},
+		{name: "unknown weekend day", args: []string{"-weekend-days", "fri,saturday"}},
+		{name: "bad holiday", args: []string{"-holidays", "2026-13-01"}},
+		{name: "entitlement without days", args: []string{"-leave-entitlements", "vacation"}},
+		{name: "negative entitlement", args: []string{"-leave-entitlements", "vacation=-1"}},
+		{name: "entitlement of toil", args: []string{"-leave-entitlements", "toil=5"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := loadConfig("test", tc.args, io.Discard); err == nil {
+				t.Error("loadConfig() error = nil, want an error")
+			}
+		})
+	}
+}
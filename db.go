@@ -4,35 +4,134 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
-	"github.com/joho/godotenv"
-	_ "modernc.org/sqlite" // CGO-free SQLite driver
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+	_ "modernc.org/sqlite"             // CGO-free SQLite driver
 )
 
-func connectToDB(ctx context.Context) (*sql.DB, error) {
-	// Load .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Note: .env file not found, using system environment variables")
+// schemaVersion is recorded once the schema file has been applied: in
+// PRAGMA user_version on SQLite and in the schema_version table on
+// PostgreSQL. Bump it whenever the schema changes so restores of backups
+// taken by a newer build are refused.
+const schemaVersion = 16
+
+// addedColumn is a column added to a table after the table first shipped.
+// CREATE TABLE IF NOT EXISTS leaves existing tables alone, so these are
+// added with ALTER TABLE before the schema file runs; the schema file must
+// list them too, for new databases.
+type addedColumn struct {
+	table, column, definition string
+}
+
+var addedColumns = []addedColumn{
+	{table: "employees", column: "card_number", definition: "TEXT"},
+	{table: "onboarding_templates", column: "kind", definition: "TEXT NOT NULL DEFAULT 'onboarding'"},
+	{table: "onboarding_tasks", column: "kind", definition: "TEXT NOT NULL DEFAULT 'onboarding'"},
+	{table: "document_categories", column: "self_service", definition: "BOOLEAN NOT NULL DEFAULT FALSE"},
+	{table: "employees", column: "manager_id", definition: "INTEGER REFERENCES employees(id)"},
+}
+
+// Dialect identifies the SQL flavour spoken by a database.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// DB is a database handle that rewrites the ? placeholders used by the
+// repositories into the form its dialect expects, so the same queries run
+// on SQLite and PostgreSQL.
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// Rebind converts ? placeholders to $1, $2, ... for PostgreSQL. Queries
+// must not contain a literal question mark.
+func (db *DB) Rebind(query string) string {
+	if db.Dialect != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
 	}
+	return b.String()
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, db.Rebind(query), args...)
+}
 
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbName := os.Getenv("DB_NAME")
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, db.Rebind(query), args...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.DB.QueryRowContext(ctx, db.Rebind(query), args...)
+}
 
-	if dbHost == "" || dbPort == "" || dbUser == "" || dbName == "" {
-		return nil, fmt.Errorf("database connection details are missing. Please set DB_HOST, DB_PORT, DB_USER, DB_NAME")
+// postgresDSN builds a connection URL from the individual settings unless
+// a full DSN was configured.
+func postgresDSN(cfg DatabaseConfig) string {
+	if cfg.DSN != "" {
+		return cfg.DSN
 	}
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.Name,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+	if cfg.Password != "" {
+		u.User = url.UserPassword(cfg.User, cfg.Password)
+	} else {
+		u.User = url.User(cfg.User)
+	}
+	return u.String()
+}
 
-	dbPath := fmt.Sprintf("%s.db", dbName)
+// sqliteDSN turns on foreign keys for every connection to the SQLite file
+// at path, which SQLite leaves off by default, so both backends enforce
+// the REFERENCES of the schema.
+func sqliteDSN(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=foreign_keys(1)"
+}
 
-	db, err := sql.Open("sqlite", dbPath)
+func connectToDB(ctx context.Context, cfg DatabaseConfig) (*DB, error) {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+	switch Dialect(cfg.Driver) {
+	case DialectPostgres:
+		sqlDB, err = sql.Open("pgx", postgresDSN(cfg))
+	case DialectSQLite:
+		sqlDB, err = sql.Open("sqlite", sqliteDSN(cfg.Path))
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
+	db := &DB{DB: sqlDB, Dialect: Dialect(cfg.Driver)}
 
 	// Verify connection
 	if err := db.PingContext(ctx); err != nil {
@@ -40,21 +139,72 @@ func connectToDB(ctx context.Context) (*sql.DB, error) {
 		return nil, fmt.Errorf("cannot connect to database: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully connected to the SQLite database: %s\n", dbPath)
-
-	// Initialize database schema from .sql file
-	sqlFile := "db.sql"
-	schema, err := os.ReadFile(sqlFile)
-	if err != nil {
-		log.Printf("Warning: Could not read schema file %s: %v", sqlFile, err)
+	if db.Dialect == DialectPostgres {
+		slog.Info("connected to PostgreSQL database", "host", cfg.Host, "name", cfg.Name)
 	} else {
-		_, err = db.ExecContext(ctx, string(schema))
-		if err != nil {
-			db.Close()
-			return nil, fmt.Errorf("executing schema: %w", err)
-		}
-		fmt.Println("🚀 Database schema initialized successfully!")
+		slog.Info("connected to SQLite database", "path", cfg.Path)
 	}
 
+	if err := applySchema(ctx, db, cfg.SchemaFile); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return db, nil
 }
+
+// applySchema runs the idempotent schema for the database's dialect and
+// records schemaVersion. The schema embedded in the binary is used unless
+// sqlFile names one on disk.
+func applySchema(ctx context.Context, db *DB, sqlFile string) error {
+	var schema []byte
+	var err error
+	if sqlFile != "" {
+		schema, err = os.ReadFile(sqlFile)
+	} else {
+		schema, sqlFile, err = embeddedSchema(db.Dialect)
+	}
+	if err != nil {
+		return fmt.Errorf("reading schema file: %w", err)
+	}
+	if err := addColumns(ctx, db); err != nil {
+		return err
+	}
+	if _, err := db.DB.ExecContext(ctx, string(schema)); err != nil {
+		return fmt.Errorf("executing schema: %w", err)
+	}
+
+	switch db.Dialect {
+	case DialectSQLite:
+		_, err = db.DB.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d;", schemaVersion))
+	case DialectPostgres:
+		_, err = db.ExecContext(ctx, "UPDATE schema_version SET version = ?;", schemaVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("recording schema version: %w", err)
+	}
+	slog.Info("database schema initialized", "file", sqlFile, "version", schemaVersion)
+	return nil
+}
+
+// addColumns brings tables created by an older schema up to date with
+// addedColumns. Tables that don't exist yet are left to the schema file.
+func addColumns(ctx context.Context, db *DB) error {
+	query := "SELECT COUNT(*), COALESCE(SUM(name = ?), 0) FROM pragma_table_info(?);"
+	if db.Dialect == DialectPostgres {
+		query = "SELECT COUNT(*), COUNT(*) FILTER (WHERE column_name = ?) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ?;"
+	}
+	for _, c := range addedColumns {
+		var columns, found int
+		if err := db.QueryRowContext(ctx, query, c.column, c.table).Scan(&columns, &found); err != nil {
+			return fmt.Errorf("inspecting table %s: %w", c.table, err)
+		}
+		if columns == 0 || found > 0 {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("adding column %s.%s: %w", c.table, c.column, err)
+		}
+		slog.Info("database column added", "table", c.table, "column", c.column)
+	}
+	return nil
+}
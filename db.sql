@@ -19,8 +19,11 @@ CREATE TABLE IF NOT EXISTS employees (
     hire_date DATE,
     salary REAL,
     status TEXT DEFAULT 'active', -- e.g., active, inactive, suspended
+    card_number TEXT, -- access badge, NULL when the employee has none
+    manager_id INTEGER, -- who they report to, NULL for nobody
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (department_id) REFERENCES departments(id)
+    FOREIGN KEY (department_id) REFERENCES departments(id),
+    FOREIGN KEY (manager_id) REFERENCES employees(id)
 );
 
 -- 3. Applications table (Job applications)
@@ -56,9 +59,505 @@ CREATE TABLE IF NOT EXISTS positions (
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 6. Work schedules (expected hours, per department or company-wide)
+CREATE TABLE IF NOT EXISTS work_schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    department_id INTEGER, -- NULL for the company-wide schedule
+    start_time TEXT NOT NULL, -- HH:MM
+    end_time TEXT NOT NULL, -- HH:MM
+    workdays TEXT NOT NULL, -- e.g., mon,tue,wed,thu,fri
+    grace_minutes INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id)
+);
+
+-- 7. Attendance events (clock-in / clock-out)
+CREATE TABLE IF NOT EXISTS attendance_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    kind TEXT NOT NULL, -- in, out
+    occurred_at DATETIME NOT NULL,
+    source TEXT DEFAULT 'web', -- e.g., web, correction
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 8. Attendance corrections (requests to rewrite one day's events)
+CREATE TABLE IF NOT EXISTS attendance_corrections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    clock_in DATETIME NOT NULL,
+    clock_out DATETIME NOT NULL,
+    reason TEXT,
+    status TEXT DEFAULT 'pending', -- e.g., pending, approved, rejected
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 9. Badge imports (badge-reader files fed into attendance)
+CREATE TABLE IF NOT EXISTS badge_imports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_name TEXT NOT NULL,
+    source TEXT NOT NULL, -- upload, watch
+    report TEXT NOT NULL, -- JSON counts, unmatched cards and line errors
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 10. Review cycles (performance appraisal rounds)
+CREATE TABLE IF NOT EXISTS review_cycles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    department_id INTEGER, -- NULL when the cycle covers everyone
+    self_due DATE NOT NULL,
+    manager_due DATE NOT NULL,
+    peer_due DATE NOT NULL,
+    status TEXT DEFAULT 'draft', -- e.g., draft, open, closed
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id)
+);
+
+-- 11. Review questions (the questionnaire of a cycle)
+CREATE TABLE IF NOT EXISTS review_questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    scale INTEGER NOT NULL, -- rated from 1 to scale
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (cycle_id) REFERENCES review_cycles(id)
+);
+
+-- 12. Review assessments (self, manager and peer forms)
+CREATE TABLE IF NOT EXISTS review_assessments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL, -- under review
+    reviewer_id INTEGER NOT NULL,
+    kind TEXT NOT NULL, -- self, manager, peer
+    due_date DATE NOT NULL,
+    status TEXT DEFAULT 'pending', -- e.g., pending, submitted
+    ratings TEXT NOT NULL, -- JSON, question id to rating
+    comment TEXT NOT NULL DEFAULT '',
+    submitted_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (cycle_id, employee_id, reviewer_id, kind),
+    FOREIGN KEY (cycle_id) REFERENCES review_cycles(id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (reviewer_id) REFERENCES employees(id)
+);
+
+-- 13. Employee ratings (final rating per cycle, the review history)
+CREATE TABLE IF NOT EXISTS employee_ratings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    cycle_id INTEGER NOT NULL,
+    rating INTEGER NOT NULL, -- 1 to 5
+    comment TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (employee_id, cycle_id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (cycle_id) REFERENCES review_cycles(id)
+);
+
+-- 14. Objectives (goals per quarter, owned by an employee or a department)
+CREATE TABLE IF NOT EXISTS objectives (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    quarter TEXT NOT NULL, -- e.g., 2025-Q1
+    employee_id INTEGER,
+    department_id INTEGER,
+    parent_id INTEGER, -- aligned department objective
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (department_id) REFERENCES departments(id),
+    FOREIGN KEY (parent_id) REFERENCES objectives(id)
+);
+
+-- 15. Key results (measurable results of an objective)
+CREATE TABLE IF NOT EXISTS key_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    objective_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    unit TEXT NOT NULL DEFAULT '',
+    start_value REAL NOT NULL DEFAULT 0,
+    target_value REAL NOT NULL,
+    current_value REAL NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (objective_id) REFERENCES objectives(id)
+);
+
+-- 16. Check-ins (progress updates of a key result)
+CREATE TABLE IF NOT EXISTS check_ins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key_result_id INTEGER NOT NULL,
+    value REAL NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (key_result_id) REFERENCES key_results(id)
+);
+
+-- 17. Certifications (kinds of certificate and who must hold them)
+CREATE TABLE IF NOT EXISTS certifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    validity_months INTEGER NOT NULL DEFAULT 0, -- 0 never expires
+    reminder_days INTEGER NOT NULL DEFAULT 30,
+    departments TEXT NOT NULL DEFAULT '[]', -- JSON, ids of the departments requiring it
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 18. Courses (the training catalog)
+CREATE TABLE IF NOT EXISTS courses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    provider TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    hours REAL NOT NULL DEFAULT 0,
+    certification_id INTEGER, -- granted on completion
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (certification_id) REFERENCES certifications(id)
+);
+
+-- 19. Enrollments (employees taking courses)
+CREATE TABLE IF NOT EXISTS enrollments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    status TEXT DEFAULT 'enrolled', -- e.g., enrolled, completed
+    enrolled_on DATE NOT NULL,
+    completed_on DATE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (course_id, employee_id),
+    FOREIGN KEY (course_id) REFERENCES courses(id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 20. Employee certifications (certificates held, with uploaded proof)
+CREATE TABLE IF NOT EXISTS employee_certifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    certification_id INTEGER NOT NULL,
+    issued_on DATE NOT NULL,
+    expires_on DATE, -- NULL never expires
+    proof_key TEXT NOT NULL DEFAULT '', -- key in the upload store
+    proof_name TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (certification_id) REFERENCES certifications(id)
+);
+
+-- 21. Document categories (contracts, IDs, signed policies, ...)
+CREATE TABLE IF NOT EXISTS document_categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    confidential BOOLEAN NOT NULL DEFAULT 0, -- shown to HR only
+    self_service BOOLEAN NOT NULL DEFAULT 0, -- employees download their own, e.g. payslips
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 22. Documents (files kept for employees)
+CREATE TABLE IF NOT EXISTS documents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    category_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    expires_on DATE, -- NULL never expires
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (category_id) REFERENCES document_categories(id)
+);
+
+-- 23. Document versions (every upload of a document)
+CREATE TABLE IF NOT EXISTS document_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    document_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    file_key TEXT NOT NULL, -- key in the upload store
+    file_name TEXT NOT NULL,
+    size INTEGER NOT NULL DEFAULT 0,
+    uploaded_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (document_id, version),
+    FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+-- 24. Document downloads (audit of who downloaded what; kept when the
+-- document is deleted)
+CREATE TABLE IF NOT EXISTS document_downloads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    document_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    user_name TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 25. Onboarding templates (reusable checklists for new hires and leavers)
+CREATE TABLE IF NOT EXISTS onboarding_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL DEFAULT 'onboarding', -- onboarding or offboarding
+    name TEXT NOT NULL,
+    department_id INTEGER, -- NULL for every department
+    position TEXT NOT NULL DEFAULT '', -- empty for any job title
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id)
+);
+
+-- 26. Onboarding template tasks
+CREATE TABLE IF NOT EXISTS onboarding_template_tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    assignee TEXT NOT NULL DEFAULT '',
+    due_days INTEGER NOT NULL DEFAULT 0, -- relative to the hire date or last day
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (template_id) REFERENCES onboarding_templates(id)
+);
+
+-- 27. Onboarding tasks (the checklist of a new hire or leaver; template_id
+-- is kept when the template is deleted, 0 for built-in tasks)
+CREATE TABLE IF NOT EXISTS onboarding_tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'onboarding',
+    template_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    assignee TEXT NOT NULL DEFAULT '',
+    due_on DATE NOT NULL,
+    done_on DATE, -- NULL while open
+    done_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 28. Terminations (one per employee who left)
+CREATE TABLE IF NOT EXISTS terminations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL UNIQUE,
+    last_day DATE NOT NULL,
+    reason TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    rehire_eligible BOOLEAN NOT NULL DEFAULT 0,
+    unused_leave_days REAL NOT NULL DEFAULT 0,
+    severance REAL NOT NULL DEFAULT 0,
+    deductions REAL NOT NULL DEFAULT 0,
+    processed_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 29. Equipment (company property lent to employees)
+CREATE TABLE IF NOT EXISTS equipment (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category TEXT NOT NULL,
+    name TEXT NOT NULL,
+    serial_number TEXT, -- NULL when the item has none
+    condition TEXT NOT NULL DEFAULT 'good', -- the latest of the condition log
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 30. Equipment Assignments (who held which item and when)
+CREATE TABLE IF NOT EXISTS equipment_assignments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    equipment_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    assigned_on DATE NOT NULL,
+    returned_on DATE, -- NULL while the employee holds the item
+    assigned_by TEXT NOT NULL DEFAULT '',
+    returned_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (equipment_id) REFERENCES equipment(id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 31. Equipment Conditions (the condition log of an item)
+CREATE TABLE IF NOT EXISTS equipment_conditions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    equipment_id INTEGER NOT NULL,
+    condition TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    recorded_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (equipment_id) REFERENCES equipment(id)
+);
+
+-- 32. Expense Claims (money employees spent for work and want back)
+CREATE TABLE IF NOT EXISTS expense_claims (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    manager_id INTEGER NOT NULL, -- the employee who approves it first
+    title TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    submitted_at DATETIME,
+    manager_approved_by TEXT NOT NULL DEFAULT '',
+    manager_approved_at DATETIME,
+    approved_by TEXT NOT NULL DEFAULT '', -- finance
+    approved_at DATETIME, -- decides the payroll period it is paid in
+    reimbursed_on DATE,
+    rejected_by TEXT NOT NULL DEFAULT '',
+    rejection_reason TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (manager_id) REFERENCES employees(id)
+);
+
+-- 33. Expense Items (the line items of a claim, each with its receipt)
+CREATE TABLE IF NOT EXISTS expense_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    claim_id INTEGER NOT NULL,
+    spent_on DATE NOT NULL,
+    category TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount REAL NOT NULL,
+    receipt_key TEXT NOT NULL DEFAULT '',
+    receipt_name TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (claim_id) REFERENCES expense_claims(id)
+);
+
+-- 34. Benefit Plans (health, dental, pension and other benefits)
+CREATE TABLE IF NOT EXISTS benefit_plans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    statuses TEXT NOT NULL DEFAULT '', -- eligible employee statuses, e.g. active,suspended; empty for any
+    department_id INTEGER, -- NULL for every department
+    min_tenure_months INTEGER NOT NULL DEFAULT 0,
+    covers_dependents BOOLEAN NOT NULL DEFAULT 0,
+    opens_on DATE NOT NULL, -- the open-enrollment window
+    closes_on DATE NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id)
+);
+
+-- 35. Dependents (family members benefits can cover)
+CREATE TABLE IF NOT EXISTS dependents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    relationship TEXT NOT NULL,
+    birth_date DATE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 36. Benefit Enrollments (who was covered by which plan and when)
+CREATE TABLE IF NOT EXISTS benefit_enrollments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    dependent_ids TEXT NOT NULL DEFAULT '', -- the covered dependents, e.g. 3,5
+    effective_from DATE NOT NULL,
+    effective_to DATE, -- NULL while current
+    enrolled_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (plan_id) REFERENCES benefit_plans(id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 37. Shift Templates (reusable shifts for the rota, e.g. Early 06:00-14:00)
+CREATE TABLE IF NOT EXISTS shift_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    department_id INTEGER, -- NULL for every department
+    start_time TEXT NOT NULL, -- HH:MM
+    end_time TEXT NOT NULL, -- HH:MM, before start_time when the shift runs past midnight
+    break_minutes INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id)
+);
+
+-- 38. Shifts (the rota: who works when, copied from a template)
+CREATE TABLE IF NOT EXISTS shifts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    department_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    shift_date DATE NOT NULL,
+    start_time TEXT NOT NULL, -- HH:MM
+    end_time TEXT NOT NULL, -- HH:MM
+    break_minutes INTEGER NOT NULL DEFAULT 0,
+    published_at DATETIME, -- NULL while a draft only HR sees
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 39. Overtime Requests (hours beyond contract, paid or taken back as time off in lieu)
+CREATE TABLE IF NOT EXISTS overtime_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    work_date DATE NOT NULL,
+    hours REAL NOT NULL,
+    day_kind TEXT NOT NULL, -- weekday, weekend or holiday
+    multiplier REAL NOT NULL,
+    compensation TEXT NOT NULL, -- pay or toil
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
+-- 40. Contact Changes (contact details employees update from self-service, applied once HR approves)
+CREATE TABLE IF NOT EXISTS contact_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    employee_id INTEGER NOT NULL,
+    phone TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    emergency_name TEXT NOT NULL DEFAULT '',
+    emergency_phone TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (employee_id) REFERENCES employees(id)
+);
+
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_employees_department_id ON employees(department_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_card_number ON employees(card_number);
 CREATE INDEX IF NOT EXISTS idx_leaves_employee_id ON leaves(employee_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_events_employee_occurred_at ON attendance_events(employee_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_attendance_events_occurred_at ON attendance_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_review_questions_cycle_id ON review_questions(cycle_id);
+CREATE INDEX IF NOT EXISTS idx_review_assessments_cycle_id ON review_assessments(cycle_id);
+CREATE INDEX IF NOT EXISTS idx_objectives_quarter ON objectives(quarter);
+CREATE INDEX IF NOT EXISTS idx_key_results_objective_id ON key_results(objective_id);
+CREATE INDEX IF NOT EXISTS idx_check_ins_key_result_id ON check_ins(key_result_id);
+CREATE INDEX IF NOT EXISTS idx_enrollments_employee_id ON enrollments(employee_id);
+CREATE INDEX IF NOT EXISTS idx_employee_certifications_employee_id ON employee_certifications(employee_id);
+CREATE INDEX IF NOT EXISTS idx_documents_employee_id ON documents(employee_id);
+CREATE INDEX IF NOT EXISTS idx_document_downloads_document_id ON document_downloads(document_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_template_tasks_template_id ON onboarding_template_tasks(template_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_employee_id ON onboarding_tasks(employee_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_equipment_serial_number ON equipment(serial_number);
+CREATE INDEX IF NOT EXISTS idx_equipment_assignments_equipment_id ON equipment_assignments(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_assignments_employee_id ON equipment_assignments(employee_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_conditions_equipment_id ON equipment_conditions(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_expense_claims_employee_id ON expense_claims(employee_id);
+CREATE INDEX IF NOT EXISTS idx_expense_claims_approved_at ON expense_claims(approved_at);
+CREATE INDEX IF NOT EXISTS idx_expense_items_claim_id ON expense_items(claim_id);
+CREATE INDEX IF NOT EXISTS idx_dependents_employee_id ON dependents(employee_id);
+CREATE INDEX IF NOT EXISTS idx_benefit_enrollments_plan_id ON benefit_enrollments(plan_id);
+CREATE INDEX IF NOT EXISTS idx_benefit_enrollments_employee_id ON benefit_enrollments(employee_id);
+CREATE INDEX IF NOT EXISTS idx_shifts_department_id_shift_date ON shifts(department_id, shift_date);
+CREATE INDEX IF NOT EXISTS idx_shifts_employee_id_shift_date ON shifts(employee_id, shift_date);
+CREATE INDEX IF NOT EXISTS idx_overtime_requests_employee_id_work_date ON overtime_requests(employee_id, work_date);
+CREATE INDEX IF NOT EXISTS idx_contact_changes_employee_id ON contact_changes(employee_id);
+
+-- Employees without a department used to be stored with department_id 0,
+-- which the foreign key refuses now that it is enforced.
+UPDATE employees SET department_id = NULL WHERE department_id = 0;
 
 -- -- Dummy Data for testing
 -- INSERT OR IGNORE INTO departments (name, description) VALUES 
@@ -0,0 +1,518 @@
+-- Database Schema for HR App (PostgreSQL)
+-- Keep in step with db.sql.
+
+-- 1. Departments table
+CREATE TABLE IF NOT EXISTS departments (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 2. Employees table
+CREATE TABLE IF NOT EXISTS employees (
+    id SERIAL PRIMARY KEY,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    department_id INTEGER REFERENCES departments(id),
+    job_title TEXT,
+    hire_date DATE,
+    salary DOUBLE PRECISION,
+    status TEXT DEFAULT 'active', -- e.g., active, inactive, suspended
+    card_number TEXT, -- access badge, NULL when the employee has none
+    manager_id INTEGER REFERENCES employees(id), -- who they report to, NULL for nobody
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 3. Applications table (Job applications)
+CREATE TABLE IF NOT EXISTS applications (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT,
+    applied_for TEXT, -- Position name
+    resume_url TEXT,
+    status TEXT DEFAULT 'pending', -- e.g., pending, interviewing, accepted, rejected
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 4. Leaves table (Vacation/Sick leave requests)
+CREATE TABLE IF NOT EXISTS leaves (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    leave_type TEXT NOT NULL, -- e.g., vacation, sick, personal
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status TEXT DEFAULT 'pending', -- e.g., pending, approved, rejected
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 5. Positions table
+CREATE TABLE IF NOT EXISTS positions (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 6. Work schedules (expected hours, per department or company-wide)
+CREATE TABLE IF NOT EXISTS work_schedules (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    department_id INTEGER REFERENCES departments(id), -- NULL for the company-wide schedule
+    start_time TEXT NOT NULL, -- HH:MM
+    end_time TEXT NOT NULL, -- HH:MM
+    workdays TEXT NOT NULL, -- e.g., mon,tue,wed,thu,fri
+    grace_minutes INTEGER DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 7. Attendance events (clock-in / clock-out)
+CREATE TABLE IF NOT EXISTS attendance_events (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    kind TEXT NOT NULL, -- in, out
+    occurred_at TIMESTAMPTZ NOT NULL,
+    source TEXT DEFAULT 'web', -- e.g., web, correction
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 8. Attendance corrections (requests to rewrite one day's events)
+CREATE TABLE IF NOT EXISTS attendance_corrections (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    date DATE NOT NULL,
+    clock_in TIMESTAMPTZ NOT NULL,
+    clock_out TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+    status TEXT DEFAULT 'pending', -- e.g., pending, approved, rejected
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 9. Badge imports (badge-reader files fed into attendance)
+CREATE TABLE IF NOT EXISTS badge_imports (
+    id SERIAL PRIMARY KEY,
+    file_name TEXT NOT NULL,
+    source TEXT NOT NULL, -- upload, watch
+    report TEXT NOT NULL, -- JSON counts, unmatched cards and line errors
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 10. Review cycles (performance appraisal rounds)
+CREATE TABLE IF NOT EXISTS review_cycles (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    department_id INTEGER REFERENCES departments(id), -- NULL when the cycle covers everyone
+    self_due DATE NOT NULL,
+    manager_due DATE NOT NULL,
+    peer_due DATE NOT NULL,
+    status TEXT DEFAULT 'draft', -- e.g., draft, open, closed
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 11. Review questions (the questionnaire of a cycle)
+CREATE TABLE IF NOT EXISTS review_questions (
+    id SERIAL PRIMARY KEY,
+    cycle_id INTEGER NOT NULL REFERENCES review_cycles(id),
+    text TEXT NOT NULL,
+    scale INTEGER NOT NULL, -- rated from 1 to scale
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 12. Review assessments (self, manager and peer forms)
+CREATE TABLE IF NOT EXISTS review_assessments (
+    id SERIAL PRIMARY KEY,
+    cycle_id INTEGER NOT NULL REFERENCES review_cycles(id),
+    employee_id INTEGER NOT NULL REFERENCES employees(id), -- under review
+    reviewer_id INTEGER NOT NULL REFERENCES employees(id),
+    kind TEXT NOT NULL, -- self, manager, peer
+    due_date DATE NOT NULL,
+    status TEXT DEFAULT 'pending', -- e.g., pending, submitted
+    ratings TEXT NOT NULL, -- JSON, question id to rating
+    comment TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (cycle_id, employee_id, reviewer_id, kind)
+);
+
+-- 13. Employee ratings (final rating per cycle, the review history)
+CREATE TABLE IF NOT EXISTS employee_ratings (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    cycle_id INTEGER NOT NULL REFERENCES review_cycles(id),
+    rating INTEGER NOT NULL, -- 1 to 5
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (employee_id, cycle_id)
+);
+
+-- 14. Objectives (goals per quarter, owned by an employee or a department)
+CREATE TABLE IF NOT EXISTS objectives (
+    id SERIAL PRIMARY KEY,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    quarter TEXT NOT NULL, -- e.g., 2025-Q1
+    employee_id INTEGER REFERENCES employees(id),
+    department_id INTEGER REFERENCES departments(id),
+    parent_id INTEGER REFERENCES objectives(id), -- aligned department objective
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 15. Key results (measurable results of an objective)
+CREATE TABLE IF NOT EXISTS key_results (
+    id SERIAL PRIMARY KEY,
+    objective_id INTEGER NOT NULL REFERENCES objectives(id),
+    title TEXT NOT NULL,
+    unit TEXT NOT NULL DEFAULT '',
+    start_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    target_value DOUBLE PRECISION NOT NULL,
+    current_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 16. Check-ins (progress updates of a key result)
+CREATE TABLE IF NOT EXISTS check_ins (
+    id SERIAL PRIMARY KEY,
+    key_result_id INTEGER NOT NULL REFERENCES key_results(id),
+    value DOUBLE PRECISION NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 17. Certifications (kinds of certificate and who must hold them)
+CREATE TABLE IF NOT EXISTS certifications (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    validity_months INTEGER NOT NULL DEFAULT 0, -- 0 never expires
+    reminder_days INTEGER NOT NULL DEFAULT 30,
+    departments TEXT NOT NULL DEFAULT '[]', -- JSON, ids of the departments requiring it
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 18. Courses (the training catalog)
+CREATE TABLE IF NOT EXISTS courses (
+    id SERIAL PRIMARY KEY,
+    title TEXT NOT NULL,
+    provider TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    certification_id INTEGER REFERENCES certifications(id), -- granted on completion
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 19. Enrollments (employees taking courses)
+CREATE TABLE IF NOT EXISTS enrollments (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id),
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    status TEXT DEFAULT 'enrolled', -- e.g., enrolled, completed
+    enrolled_on DATE NOT NULL,
+    completed_on DATE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (course_id, employee_id)
+);
+
+-- 20. Employee certifications (certificates held, with uploaded proof)
+CREATE TABLE IF NOT EXISTS employee_certifications (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    certification_id INTEGER NOT NULL REFERENCES certifications(id),
+    issued_on DATE NOT NULL,
+    expires_on DATE, -- NULL never expires
+    proof_key TEXT NOT NULL DEFAULT '', -- key in the upload store
+    proof_name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 21. Document categories (contracts, IDs, signed policies, ...)
+CREATE TABLE IF NOT EXISTS document_categories (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    confidential BOOLEAN NOT NULL DEFAULT FALSE, -- shown to HR only
+    self_service BOOLEAN NOT NULL DEFAULT FALSE, -- employees download their own, e.g. payslips
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 22. Documents (files kept for employees)
+CREATE TABLE IF NOT EXISTS documents (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    category_id INTEGER NOT NULL REFERENCES document_categories(id),
+    title TEXT NOT NULL,
+    expires_on DATE, -- NULL never expires
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 23. Document versions (every upload of a document)
+CREATE TABLE IF NOT EXISTS document_versions (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id),
+    version INTEGER NOT NULL,
+    file_key TEXT NOT NULL, -- key in the upload store
+    file_name TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    uploaded_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (document_id, version)
+);
+
+-- 24. Document downloads (audit of who downloaded what; kept when the
+-- document is deleted)
+CREATE TABLE IF NOT EXISTS document_downloads (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    user_name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 25. Onboarding templates (reusable checklists for new hires and leavers)
+CREATE TABLE IF NOT EXISTS onboarding_templates (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL DEFAULT 'onboarding', -- onboarding or offboarding
+    name TEXT NOT NULL,
+    department_id INTEGER REFERENCES departments(id), -- NULL for every department
+    position TEXT NOT NULL DEFAULT '', -- empty for any job title
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 26. Onboarding template tasks
+CREATE TABLE IF NOT EXISTS onboarding_template_tasks (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES onboarding_templates(id),
+    title TEXT NOT NULL,
+    assignee TEXT NOT NULL DEFAULT '',
+    due_days INTEGER NOT NULL DEFAULT 0, -- relative to the hire date or last day
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 27. Onboarding tasks (the checklist of a new hire or leaver; template_id
+-- is kept when the template is deleted, 0 for built-in tasks)
+CREATE TABLE IF NOT EXISTS onboarding_tasks (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    kind TEXT NOT NULL DEFAULT 'onboarding',
+    template_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    assignee TEXT NOT NULL DEFAULT '',
+    due_on DATE NOT NULL,
+    done_on DATE, -- NULL while open
+    done_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 28. Terminations (one per employee who left)
+CREATE TABLE IF NOT EXISTS terminations (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL UNIQUE REFERENCES employees(id),
+    last_day DATE NOT NULL,
+    reason TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    rehire_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    unused_leave_days DOUBLE PRECISION NOT NULL DEFAULT 0,
+    severance DOUBLE PRECISION NOT NULL DEFAULT 0,
+    deductions DOUBLE PRECISION NOT NULL DEFAULT 0,
+    processed_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 29. Equipment (company property lent to employees)
+CREATE TABLE IF NOT EXISTS equipment (
+    id SERIAL PRIMARY KEY,
+    category TEXT NOT NULL,
+    name TEXT NOT NULL,
+    serial_number TEXT, -- NULL when the item has none
+    condition TEXT NOT NULL DEFAULT 'good', -- the latest of the condition log
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 30. Equipment Assignments (who held which item and when)
+CREATE TABLE IF NOT EXISTS equipment_assignments (
+    id SERIAL PRIMARY KEY,
+    equipment_id INTEGER NOT NULL REFERENCES equipment(id),
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    assigned_on DATE NOT NULL,
+    returned_on DATE, -- NULL while the employee holds the item
+    assigned_by TEXT NOT NULL DEFAULT '',
+    returned_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 31. Equipment Conditions (the condition log of an item)
+CREATE TABLE IF NOT EXISTS equipment_conditions (
+    id SERIAL PRIMARY KEY,
+    equipment_id INTEGER NOT NULL REFERENCES equipment(id),
+    condition TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    recorded_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 32. Expense Claims (money employees spent for work and want back)
+CREATE TABLE IF NOT EXISTS expense_claims (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    manager_id INTEGER NOT NULL REFERENCES employees(id), -- the employee who approves it first
+    title TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    submitted_at TIMESTAMPTZ,
+    manager_approved_by TEXT NOT NULL DEFAULT '',
+    manager_approved_at TIMESTAMPTZ,
+    approved_by TEXT NOT NULL DEFAULT '', -- finance
+    approved_at TIMESTAMPTZ, -- decides the payroll period it is paid in
+    reimbursed_on DATE,
+    rejected_by TEXT NOT NULL DEFAULT '',
+    rejection_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 33. Expense Items (the line items of a claim, each with its receipt)
+CREATE TABLE IF NOT EXISTS expense_items (
+    id SERIAL PRIMARY KEY,
+    claim_id INTEGER NOT NULL REFERENCES expense_claims(id),
+    spent_on DATE NOT NULL,
+    category TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount DOUBLE PRECISION NOT NULL,
+    receipt_key TEXT NOT NULL DEFAULT '',
+    receipt_name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 34. Benefit Plans (health, dental, pension and other benefits)
+CREATE TABLE IF NOT EXISTS benefit_plans (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    statuses TEXT NOT NULL DEFAULT '', -- eligible employee statuses, e.g. active,suspended; empty for any
+    department_id INTEGER REFERENCES departments(id), -- NULL for every department
+    min_tenure_months INTEGER NOT NULL DEFAULT 0,
+    covers_dependents BOOLEAN NOT NULL DEFAULT FALSE,
+    opens_on DATE NOT NULL, -- the open-enrollment window
+    closes_on DATE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 35. Dependents (family members benefits can cover)
+CREATE TABLE IF NOT EXISTS dependents (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    relationship TEXT NOT NULL,
+    birth_date DATE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 36. Benefit Enrollments (who was covered by which plan and when)
+CREATE TABLE IF NOT EXISTS benefit_enrollments (
+    id SERIAL PRIMARY KEY,
+    plan_id INTEGER NOT NULL REFERENCES benefit_plans(id),
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    dependent_ids TEXT NOT NULL DEFAULT '', -- the covered dependents, e.g. 3,5
+    effective_from DATE NOT NULL,
+    effective_to DATE, -- NULL while current
+    enrolled_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 37. Shift Templates (reusable shifts for the rota, e.g. Early 06:00-14:00)
+CREATE TABLE IF NOT EXISTS shift_templates (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    department_id INTEGER REFERENCES departments(id), -- NULL for every department
+    start_time TEXT NOT NULL, -- HH:MM
+    end_time TEXT NOT NULL, -- HH:MM, before start_time when the shift runs past midnight
+    break_minutes INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 38. Shifts (the rota: who works when, copied from a template)
+CREATE TABLE IF NOT EXISTS shifts (
+    id SERIAL PRIMARY KEY,
+    department_id INTEGER NOT NULL REFERENCES departments(id),
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    name TEXT NOT NULL,
+    shift_date DATE NOT NULL,
+    start_time TEXT NOT NULL, -- HH:MM
+    end_time TEXT NOT NULL, -- HH:MM
+    break_minutes INTEGER NOT NULL DEFAULT 0,
+    published_at TIMESTAMPTZ, -- NULL while a draft only HR sees
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 39. Overtime Requests (hours beyond contract, paid or taken back as time off in lieu)
+CREATE TABLE IF NOT EXISTS overtime_requests (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    work_date DATE NOT NULL,
+    hours DOUBLE PRECISION NOT NULL,
+    day_kind TEXT NOT NULL, -- weekday, weekend or holiday
+    multiplier DOUBLE PRECISION NOT NULL,
+    compensation TEXT NOT NULL, -- pay or toil
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 40. Contact Changes (contact details employees update from self-service, applied once HR approves)
+CREATE TABLE IF NOT EXISTS contact_changes (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    phone TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    emergency_name TEXT NOT NULL DEFAULT '',
+    emergency_phone TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes for performance
+CREATE INDEX IF NOT EXISTS idx_employees_department_id ON employees(department_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_card_number ON employees(card_number);
+CREATE INDEX IF NOT EXISTS idx_leaves_employee_id ON leaves(employee_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_events_employee_occurred_at ON attendance_events(employee_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_attendance_events_occurred_at ON attendance_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_review_questions_cycle_id ON review_questions(cycle_id);
+CREATE INDEX IF NOT EXISTS idx_review_assessments_cycle_id ON review_assessments(cycle_id);
+CREATE INDEX IF NOT EXISTS idx_objectives_quarter ON objectives(quarter);
+CREATE INDEX IF NOT EXISTS idx_key_results_objective_id ON key_results(objective_id);
+CREATE INDEX IF NOT EXISTS idx_check_ins_key_result_id ON check_ins(key_result_id);
+CREATE INDEX IF NOT EXISTS idx_enrollments_employee_id ON enrollments(employee_id);
+CREATE INDEX IF NOT EXISTS idx_employee_certifications_employee_id ON employee_certifications(employee_id);
+CREATE INDEX IF NOT EXISTS idx_documents_employee_id ON documents(employee_id);
+CREATE INDEX IF NOT EXISTS idx_document_downloads_document_id ON document_downloads(document_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_template_tasks_template_id ON onboarding_template_tasks(template_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_employee_id ON onboarding_tasks(employee_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_equipment_serial_number ON equipment(serial_number);
+CREATE INDEX IF NOT EXISTS idx_equipment_assignments_equipment_id ON equipment_assignments(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_assignments_employee_id ON equipment_assignments(employee_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_conditions_equipment_id ON equipment_conditions(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_expense_claims_employee_id ON expense_claims(employee_id);
+CREATE INDEX IF NOT EXISTS idx_expense_claims_approved_at ON expense_claims(approved_at);
+CREATE INDEX IF NOT EXISTS idx_expense_items_claim_id ON expense_items(claim_id);
+CREATE INDEX IF NOT EXISTS idx_dependents_employee_id ON dependents(employee_id);
+CREATE INDEX IF NOT EXISTS idx_benefit_enrollments_plan_id ON benefit_enrollments(plan_id);
+CREATE INDEX IF NOT EXISTS idx_benefit_enrollments_employee_id ON benefit_enrollments(employee_id);
+CREATE INDEX IF NOT EXISTS idx_shifts_department_id_shift_date ON shifts(department_id, shift_date);
+CREATE INDEX IF NOT EXISTS idx_shifts_employee_id_shift_date ON shifts(employee_id, shift_date);
+CREATE INDEX IF NOT EXISTS idx_overtime_requests_employee_id_work_date ON overtime_requests(employee_id, work_date);
+CREATE INDEX IF NOT EXISTS idx_contact_changes_employee_id ON contact_changes(employee_id);
+
+-- Schema version, the counterpart of SQLite's PRAGMA user_version
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER NOT NULL
+);
+INSERT INTO schema_version (version) SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM schema_version);
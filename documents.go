@@ -0,0 +1,676 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// documentReminderDays is how long before their expiry documents are
+// listed as expiring.
+const documentReminderDays = 30
+
+// DocumentView is a document with its category and latest version.
+type DocumentView struct {
+	Document
+	Category DocumentCategory
+	Latest   DocumentVersion
+	Status   string
+}
+
+// canSee reports whether v may see documents of the category.
+func (app *App) canSee(v Viewer, c DocumentCategory) bool {
+	return !c.Confidential || app.isHR(v)
+}
+
+// documentViews joins documents with their categories and latest
+// versions, leaving out the ones v may not see.
+func (app *App) documentViews(ctx context.Context, v Viewer, documents []Document, today time.Time) ([]DocumentView, error) {
+	return app.documentViewsIn(ctx, func(c DocumentCategory) bool { return app.canSee(v, c) }, documents, today)
+}
+
+// documentViewsIn is documentViews keeping the documents of the
+// categories keep accepts.
+func (app *App) documentViewsIn(ctx context.Context, keep func(DocumentCategory) bool, documents []Document, today time.Time) ([]DocumentView, error) {
+	categories, err := app.documentCategoriesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := app.DocVersionRepository.GetDocumentVersions(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Versions come newest first, so the first seen is the latest.
+	latest := make(map[int]DocumentVersion)
+	for _, dv := range versions {
+		if _, ok := latest[dv.DocumentID]; !ok {
+			latest[dv.DocumentID] = dv
+		}
+	}
+
+	var views []DocumentView
+	for _, d := range documents {
+		c := categories[d.CategoryID]
+		if !keep(c) {
+			continue
+		}
+		views = append(views, DocumentView{
+			Document: d,
+			Category: c,
+			Latest:   latest[d.ID],
+			Status:   expiryStatus(d.ExpiresOn, today, documentReminderDays),
+		})
+	}
+	return views, nil
+}
+
+func (app *App) documentCategoriesByID(ctx context.Context) (map[int]DocumentCategory, error) {
+	categories, err := app.DocCategoryRepository.GetDocumentCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]DocumentCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	return byID, nil
+}
+
+// visibleCategories returns the categories v may file documents in.
+func (app *App) visibleCategories(ctx context.Context, v Viewer) ([]DocumentCategory, error) {
+	categories, err := app.DocCategoryRepository.GetDocumentCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(categories, func(c DocumentCategory) bool { return !app.canSee(v, c) }), nil
+}
+
+// requireHR writes a 403 response unless the request was made by HR.
+func (app *App) requireHR(w http.ResponseWriter, r *http.Request) bool {
+	if !app.isHR(app.viewer(r)) {
+		app.clientError(w, r, http.StatusForbidden, "Only HR can do this")
+		return false
+	}
+	return true
+}
+
+// handleDocuments lists the documents that have expired or expire soon.
+func (app *App) handleDocuments(w http.ResponseWriter, r *http.Request) {
+	documents, err := app.DocumentRepository.GetDocuments(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	viewer := app.viewer(r)
+	views, err := app.documentViews(r.Context(), viewer, documents, today())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	views = slices.DeleteFunc(views, func(d DocumentView) bool { return d.Status == expiryValid })
+	slices.SortStableFunc(views, func(a, b DocumentView) int { return a.ExpiresOn.Compare(b.ExpiresOn) })
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage": "documents",
+		"Documents":  views,
+		"Employees":  employees,
+		"HR":         app.isHR(viewer),
+	}
+	app.render(w, r, "documents.html", "", data)
+}
+
+func (app *App) handleDocumentCategories(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	categories, err := app.DocCategoryRepository.GetDocumentCategories(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document categories", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "documents",
+		"Categories": categories,
+		"HR":         true,
+	}
+	app.render(w, r, "document_categories.html", "", data)
+}
+
+func documentCategoryForm(r *http.Request) DocumentCategory {
+	return DocumentCategory{
+		Name:         strings.TrimSpace(r.FormValue("name")),
+		Confidential: r.FormValue("confidential") != "",
+		SelfService:  r.FormValue("self_service") != "",
+	}
+}
+
+// checkCategoryName writes the error response when the name of c is
+// missing or taken by another category.
+func (app *App) checkCategoryName(w http.ResponseWriter, r *http.Request, c DocumentCategory) bool {
+	if c.Name == "" {
+		app.clientError(w, r, http.StatusBadRequest, "Name is required")
+		return false
+	}
+	categories, err := app.DocCategoryRepository.GetDocumentCategories(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document categories", err)
+		return false
+	}
+	for _, other := range categories {
+		if other.ID != c.ID && strings.EqualFold(other.Name, c.Name) {
+			app.clientError(w, r, http.StatusConflict, "A category with this name already exists")
+			return false
+		}
+	}
+	return true
+}
+
+func (app *App) handleAddDocumentCategory(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		data := map[string]any{
+			"ActivePage": "documents",
+			"Category":   DocumentCategory{},
+		}
+		app.render(w, r, "add_document_category.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	category := documentCategoryForm(r)
+	if !app.checkCategoryName(w, r, category) {
+		return
+	}
+	if err := app.DocCategoryRepository.CreateDocumentCategory(r.Context(), &category); err != nil {
+		app.serverError(w, r, "Failed to add document category", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/documents/categories")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleUpdateDocumentCategory(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	category, err := app.DocCategoryRepository.GetDocumentCategoryByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document category", err)
+		return
+	}
+	if category == nil {
+		app.clientError(w, r, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		data := map[string]any{
+			"ActivePage": "documents",
+			"Category":   category,
+		}
+		app.render(w, r, "update_document_category.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated := documentCategoryForm(r)
+	updated.ID = category.ID
+	if !app.checkCategoryName(w, r, updated) {
+		return
+	}
+	if err := app.DocCategoryRepository.UpdateDocumentCategory(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update document category", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/documents/categories")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteDocumentCategory deletes a category no document is filed
+// in.
+func (app *App) handleDeleteDocumentCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+	if !app.requireHR(w, r) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	documents, err := app.DocumentRepository.GetDocuments(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	if slices.ContainsFunc(documents, func(d Document) bool { return d.CategoryID == id }) {
+		app.clientError(w, r, http.StatusConflict, "Documents are filed in this category")
+		return
+	}
+	if err := app.DocCategoryRepository.DeleteDocumentCategory(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete document category", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/documents/categories")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleEmployeeDocuments(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	documents, err := app.DocumentRepository.GetDocuments(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	viewer := app.viewer(r)
+	views, err := app.documentViews(r.Context(), viewer, documents, today())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	categories, err := app.visibleCategories(r.Context(), viewer)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document categories", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage": "documents",
+		"Employee":   employee,
+		"Documents":  views,
+		"Categories": categories,
+		"HR":         app.isHR(viewer),
+	}
+	app.render(w, r, "employee_documents.html", "", data)
+}
+
+// documentForm reads the category, title and expiry date of a document
+// and checks that the viewer may file documents in the category. The
+// error response has been written when ok is false.
+func (app *App) documentForm(w http.ResponseWriter, r *http.Request) (d Document, ok bool) {
+	categoryID, _ := strconv.Atoi(r.FormValue("category_id"))
+	category, err := app.DocCategoryRepository.GetDocumentCategoryByID(r.Context(), categoryID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document category", err)
+		return d, false
+	}
+	if category == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Category not found")
+		return d, false
+	}
+	if !app.canSee(app.viewer(r), *category) {
+		app.clientError(w, r, http.StatusForbidden, "Only HR can file confidential documents")
+		return d, false
+	}
+	d = Document{CategoryID: category.ID, Title: strings.TrimSpace(r.FormValue("title"))}
+	if s := r.FormValue("expires_on"); s != "" {
+		if d.ExpiresOn, err = time.Parse("2006-01-02", s); err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+			return d, false
+		}
+	}
+	return d, true
+}
+
+// saveDocumentFile stores the file of a new document version. A file
+// is required. The error response has been written when ok is false.
+func (app *App) saveDocumentFile(w http.ResponseWriter, r *http.Request) (v DocumentVersion, ok bool) {
+	key, name, ok := app.saveUpload(w, r, "file")
+	if !ok {
+		return v, false
+	}
+	if key == "" {
+		app.clientError(w, r, http.StatusBadRequest, "File is required")
+		return v, false
+	}
+	var size int64
+	if f, err := app.Files.Open(key); err == nil {
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+		f.Close()
+	}
+	return DocumentVersion{Version: 1, FileKey: key, FileName: name, Size: size, UploadedBy: app.viewer(r).User}, true
+}
+
+// handleUploadDocument adds a document for an employee with its first
+// version.
+func (app *App) handleUploadDocument(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	if !app.parseUploadForm(w, r) {
+		return
+	}
+	document, ok := app.documentForm(w, r)
+	if !ok {
+		return
+	}
+	version, ok := app.saveDocumentFile(w, r)
+	if !ok {
+		return
+	}
+	document.EmployeeID = employee.ID
+	if document.Title == "" {
+		document.Title = version.FileName
+	}
+
+	if err := app.DocumentRepository.CreateDocument(r.Context(), &document); err != nil {
+		app.Files.Remove(version.FileKey)
+		app.serverError(w, r, "Failed to add document", err)
+		return
+	}
+	version.DocumentID = document.ID
+	if err := app.DocVersionRepository.CreateDocumentVersion(r.Context(), &version); err != nil {
+		app.Files.Remove(version.FileKey)
+		app.DocumentRepository.DeleteDocument(r.Context(), document.ID)
+		app.serverError(w, r, "Failed to add document", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/documents/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// document fetches the document named by the request's id path value.
+// Documents the viewer may not see are reported as not found.
+func (app *App) document(w http.ResponseWriter, r *http.Request) (*Document, *DocumentCategory, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, nil, false
+	}
+	return app.visibleDocument(w, r, id)
+}
+
+func (app *App) visibleDocument(w http.ResponseWriter, r *http.Request, id int) (*Document, *DocumentCategory, bool) {
+	document, err := app.DocumentRepository.GetDocumentByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document", err)
+		return nil, nil, false
+	}
+	var category *DocumentCategory
+	if document != nil {
+		if category, err = app.DocCategoryRepository.GetDocumentCategoryByID(r.Context(), document.CategoryID); err != nil {
+			app.serverError(w, r, "Failed to fetch document category", err)
+			return nil, nil, false
+		}
+	}
+	if document == nil || category == nil || !app.canSee(app.viewer(r), *category) {
+		app.clientError(w, r, http.StatusNotFound, "Document not found")
+		return nil, nil, false
+	}
+	return document, category, true
+}
+
+// handleDocument shows a document with its versions and, to HR, who
+// downloaded it.
+func (app *App) handleDocument(w http.ResponseWriter, r *http.Request) {
+	document, category, ok := app.document(w, r)
+	if !ok {
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), document.EmployeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	versions, err := app.DocVersionRepository.GetDocumentVersions(r.Context(), document.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document versions", err)
+		return
+	}
+	viewer := app.viewer(r)
+	var downloads []DocumentDownload
+	if app.isHR(viewer) {
+		if downloads, err = app.DownloadRepository.GetDocumentDownloads(r.Context(), document.ID); err != nil {
+			app.serverError(w, r, "Failed to fetch downloads", err)
+			return
+		}
+	}
+	categories, err := app.visibleCategories(r.Context(), viewer)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document categories", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage": "documents",
+		"Document":   document,
+		"Category":   category,
+		"Status":     expiryStatus(document.ExpiresOn, today(), documentReminderDays),
+		"Employee":   employee,
+		"Versions":   versions,
+		"Downloads":  downloads,
+		"Categories": categories,
+		"HR":         app.isHR(viewer),
+	}
+	app.render(w, r, "document.html", "", data)
+}
+
+// handleUpdateDocument changes the title, category or expiry date of a
+// document.
+func (app *App) handleUpdateDocument(w http.ResponseWriter, r *http.Request) {
+	document, _, ok := app.document(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated, ok := app.documentForm(w, r)
+	if !ok {
+		return
+	}
+	if updated.Title == "" {
+		app.clientError(w, r, http.StatusBadRequest, "Title is required")
+		return
+	}
+	updated.ID, updated.EmployeeID = document.ID, document.EmployeeID
+	if err := app.DocumentRepository.UpdateDocument(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update document", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/documents/files/%d", document.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleUploadDocumentVersion adds a new version of a document. Earlier
+// versions are kept.
+func (app *App) handleUploadDocumentVersion(w http.ResponseWriter, r *http.Request) {
+	document, _, ok := app.document(w, r)
+	if !ok {
+		return
+	}
+	if !app.parseUploadForm(w, r) {
+		return
+	}
+	versions, err := app.DocVersionRepository.GetDocumentVersions(r.Context(), document.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document versions", err)
+		return
+	}
+	version, ok := app.saveDocumentFile(w, r)
+	if !ok {
+		return
+	}
+	version.DocumentID = document.ID
+	if len(versions) > 0 {
+		version.Version = versions[0].Version + 1
+	}
+	if err := app.DocVersionRepository.CreateDocumentVersion(r.Context(), &version); err != nil {
+		app.Files.Remove(version.FileKey)
+		app.serverError(w, r, "Failed to add document version", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/documents/files/%d", document.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDownloadDocument sends the latest version of a document, or the
+// one named by the version query parameter, and records the download.
+func (app *App) handleDownloadDocument(w http.ResponseWriter, r *http.Request) {
+	document, _, ok := app.document(w, r)
+	if !ok {
+		return
+	}
+	versions, err := app.DocVersionRepository.GetDocumentVersions(r.Context(), document.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document versions", err)
+		return
+	}
+	want, _ := strconv.Atoi(r.URL.Query().Get("version"))
+	i := slices.IndexFunc(versions, func(v DocumentVersion) bool { return want == 0 || v.Version == want })
+	if i < 0 {
+		app.clientError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	version := versions[i]
+
+	download := DocumentDownload{
+		DocumentID: document.ID,
+		EmployeeID: document.EmployeeID,
+		Title:      document.Title,
+		Version:    version.Version,
+		User:       app.viewer(r).User,
+	}
+	if err := app.DownloadRepository.CreateDocumentDownload(r.Context(), &download); err != nil {
+		app.serverError(w, r, "Failed to record download", err)
+		return
+	}
+	app.serveUpload(w, r, version.FileKey, version.FileName)
+}
+
+// handleDeleteDocument deletes a document with all its versions. The
+// record of its downloads is kept.
+func (app *App) handleDeleteDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	document, _, ok := app.visibleDocument(w, r, id)
+	if !ok {
+		return
+	}
+	versions, err := app.DocVersionRepository.GetDocumentVersions(r.Context(), document.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch document versions", err)
+		return
+	}
+	if err := app.DocVersionRepository.DeleteDocumentVersions(r.Context(), document.ID); err != nil {
+		app.serverError(w, r, "Failed to delete document", err)
+		return
+	}
+	if err := app.DocumentRepository.DeleteDocument(r.Context(), document.ID); err != nil {
+		app.serverError(w, r, "Failed to delete document", err)
+		return
+	}
+	var errs []error
+	for _, v := range versions {
+		errs = append(errs, app.Files.Remove(v.FileKey))
+	}
+	if err := errors.Join(errs...); err != nil {
+		app.serverError(w, r, "Failed to delete file", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/documents/employees/%d", document.EmployeeID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDocumentDownloads lists who downloaded which document, newest
+// first.
+func (app *App) handleDocumentDownloads(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	downloads, err := app.DownloadRepository.GetDocumentDownloads(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch downloads", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "documents",
+		"Downloads":  downloads,
+		"Employees":  employees,
+		"HR":         true,
+	}
+	app.render(w, r, "document_downloads.html", "", data)
+}
+
+func (app *App) handleExportDocumentDownloads(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	downloads, err := app.DownloadRepository.GetDocumentDownloads(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch downloads", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	headers := []string{"Downloaded At", "User", "Employee ID", "Employee", "Document", "Version"}
+	mapper := func(d DocumentDownload) []string {
+		e := employees[d.EmployeeID]
+		return []string{
+			d.CreatedAt.Format("2006-01-02 15:04:05"),
+			d.User,
+			fmt.Sprintf("%d", d.EmployeeID),
+			e.FirstName + " " + e.LastName,
+			d.Title,
+			fmt.Sprintf("%d", d.Version),
+		}
+	}
+
+	writeExport(w, r, "Document Downloads", downloads, headers, mapper)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocuments(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &omar); err != nil {
+		t.Fatal(err)
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+	employee := strconv.Itoa(employees[0].ID)
+	hr := map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "staff, hr"}
+	staff := map[string]string{"X-Forwarded-User": "sam", "X-Forwarded-Groups": "staff"}
+
+	if w := send(h, "POST", "/documents/categories/add", url.Values{"name": {"Contracts"}, "confidential": {"1"}}, staff); w.Code != http.StatusForbidden {
+		t.Errorf("add category as staff = %d, want 403", w.Code)
+	}
+	for _, form := range []url.Values{{"name": {"Contracts"}, "confidential": {"1"}}, {"name": {"Policies"}}} {
+		if w := send(h, "POST", "/documents/categories/add", form, hr); w.Code != http.StatusSeeOther {
+			t.Fatalf("add category = %d %s", w.Code, w.Body)
+		}
+	}
+	if w := send(h, "POST", "/documents/categories/add", url.Values{"name": {"policies"}}, hr); w.Code != http.StatusConflict {
+		t.Errorf("add duplicate category = %d, want 409", w.Code)
+	}
+	categories, _ := repos.DocCategories.GetDocumentCategories(ctx)
+	contracts, policies := strconv.Itoa(categories[0].ID), strconv.Itoa(categories[1].ID)
+
+	upload := "/documents/employees/" + employee
+	contract := url.Values{"category_id": {contracts}, "expires_on": {today().AddDate(0, 0, 10).Format("2006-01-02")}}
+	if w := sendFile(h, upload, contract, "file", "contract.pdf", "v1", staff); w.Code != http.StatusForbidden {
+		t.Errorf("file confidential document as staff = %d, want 403", w.Code)
+	}
+	if w := sendFile(h, upload, contract, "file", "contract.pdf", "v1", hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("upload contract = %d %s", w.Code, w.Body)
+	}
+	policy := url.Values{"category_id": {policies}, "title": {"Code of conduct"}}
+	if w := sendFile(h, upload, policy, "file", "conduct.pdf", "signed", hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("upload policy = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", upload, policy, hr); w.Code != http.StatusBadRequest {
+		t.Errorf("upload without a file = %d, want 400", w.Code)
+	}
+
+	documents, _ := repos.Documents.GetDocuments(ctx, employees[0].ID)
+	if len(documents) != 2 || documents[0].Title != "contract.pdf" {
+		t.Fatalf("documents = %+v, want the contract titled by its file", documents)
+	}
+	doc := "/documents/files/" + strconv.Itoa(documents[0].ID)
+	if w := sendFile(h, doc+"/versions", nil, "file", "contract-signed.pdf", "v2", hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("upload version = %d %s", w.Code, w.Body)
+	}
+
+	// Staff see their own documents under /me, never an employee's file.
+	for _, page := range []string{upload, doc, doc + "/download"} {
+		if w := send(h, "GET", page, nil, staff); w.Code != http.StatusForbidden {
+			t.Errorf("GET %s as staff = %d, want 403", page, w.Code)
+		}
+	}
+
+	if w := send(h, "GET", doc+"/download", nil, hr); w.Code != http.StatusOK || w.Body.String() != "v2" {
+		t.Errorf("download latest = %d %q, want v2", w.Code, w.Body)
+	}
+	if w := send(h, "GET", doc+"/download?version=1", nil, hr); w.Code != http.StatusOK || w.Body.String() != "v1" {
+		t.Errorf("download version 1 = %d %q, want v1", w.Code, w.Body)
+	}
+	downloads, _ := repos.Downloads.GetDocumentDownloads(ctx, 0)
+	if len(downloads) != 2 || downloads[0].User != "hana" || downloads[0].Version != 1 {
+		t.Fatalf("downloads = %+v, want two by hana, newest first", downloads)
+	}
+	if w := send(h, "GET", "/documents/downloads", nil, staff); w.Code != http.StatusForbidden {
+		t.Errorf("download log as staff = %d, want 403", w.Code)
+	}
+	if w := send(h, "GET", "/documents", nil, hr); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "contract.pdf") {
+		t.Errorf("expiring documents = %d, want the contract", w.Code)
+	}
+
+	if w := send(h, "DELETE", "/documents/categories/delete?id="+contracts, nil, hr); w.Code != http.StatusConflict {
+		t.Errorf("delete category in use = %d, want 409", w.Code)
+	}
+	if w := send(h, "DELETE", "/documents/delete?id="+strconv.Itoa(documents[0].ID), nil, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("delete document = %d %s", w.Code, w.Body)
+	}
+	if versions, _ := repos.DocVersions.GetDocumentVersions(ctx, documents[0].ID); len(versions) != 0 {
+		t.Errorf("versions after delete = %+v, want none", versions)
+	}
+	if w := send(h, "GET", "/documents/downloads", nil, hr); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "contract.pdf") {
+		t.Errorf("download log after delete = %d, want the contract kept", w.Code)
+	}
+}
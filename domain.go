@@ -22,6 +22,8 @@ type Employee struct {
 	Salary       float64
 	Status       string
 	DepartmentID int
+	ManagerID    int    // the employee they report to, 0 for none
+	CardNumber   string // access badge, used to match badge-reader swipes
 	CreatedAt    time.Time
 }
 
@@ -54,6 +56,492 @@ type Leave struct {
 	CreatedAt  time.Time
 }
 
+// AttendanceEvent is one clock-in or clock-out of an employee.
+type AttendanceEvent struct {
+	ID         int
+	EmployeeID int
+	Kind       string // in or out
+	OccurredAt time.Time
+	Source     string // e.g., web, correction
+	CreatedAt  time.Time
+}
+
+// WorkSchedule sets the hours employees are expected at work. A schedule
+// with a DepartmentID applies to that department; one without applies to
+// everyone else.
+type WorkSchedule struct {
+	ID           int
+	Name         string
+	DepartmentID int
+	StartTime    string // HH:MM
+	EndTime      string // HH:MM
+	Workdays     string // e.g., mon,tue,wed,thu,fri
+	GraceMinutes int
+	CreatedAt    time.Time
+}
+
+// AttendanceCorrection asks to replace the clock events of one day with
+// the given times. Approving it rewrites that day's events.
+type AttendanceCorrection struct {
+	ID         int
+	EmployeeID int
+	Date       time.Time
+	ClockIn    time.Time
+	ClockOut   time.Time
+	Reason     string
+	Status     string // e.g., pending, approved, rejected
+	CreatedAt  time.Time
+}
+
+// BadgeImport records one badge-reader file fed into attendance and what
+// came of its swipes.
+type BadgeImport struct {
+	ID        int
+	FileName  string
+	Source    string // upload or watch
+	Report    BadgeReport
+	CreatedAt time.Time
+}
+
+// BadgeReport sums up a badge import.
+type BadgeReport struct {
+	Rows       int // data rows in the file
+	Swipes     int // rows with a card and a valid time
+	Duplicates int // swipes repeating an earlier one
+	Events     int // attendance events added
+	Days       int // employee days whose events were updated
+	Unmatched  []UnmatchedCard
+	Errors     []LineError
+}
+
+// UnmatchedCard is a card number in a badge file that belongs to no
+// employee.
+type UnmatchedCard struct {
+	CardNumber string
+	Swipes     int
+	First      time.Time
+	Last       time.Time
+}
+
+// LineError is a row of a badge file that was skipped.
+type LineError struct {
+	Line int
+	Err  string
+}
+
+// ReviewCycle is one round of performance reviews, for a department or,
+// when DepartmentID is 0, for every employee.
+type ReviewCycle struct {
+	ID           int
+	Name         string
+	DepartmentID int
+	SelfDue      time.Time
+	ManagerDue   time.Time
+	PeerDue      time.Time
+	Status       string // draft, open or closed
+	CreatedAt    time.Time
+}
+
+// ReviewQuestion is one rated question of a cycle's questionnaire.
+type ReviewQuestion struct {
+	ID        int
+	CycleID   int
+	Text      string
+	Scale     int // answers are rated from 1 to Scale
+	CreatedAt time.Time
+}
+
+// ReviewAssessment is one form of a review cycle: an employee assessing
+// themselves, or their manager or a peer assessing them.
+type ReviewAssessment struct {
+	ID          int
+	CycleID     int
+	EmployeeID  int // the employee under review
+	ReviewerID  int
+	Kind        string // self, manager or peer
+	DueDate     time.Time
+	Status      string      // pending or submitted
+	Ratings     map[int]int // question ID to rating
+	Comment     string
+	SubmittedAt time.Time
+	CreatedAt   time.Time
+}
+
+// EmployeeRating is the final rating an employee got in a review cycle,
+// settled during calibration. An employee's ratings are their review
+// history.
+type EmployeeRating struct {
+	ID         int
+	EmployeeID int
+	CycleID    int
+	Rating     int // 1 to 5
+	Comment    string
+	CreatedAt  time.Time
+}
+
+// Objective is a goal for a quarter, owned by an employee or, when
+// EmployeeID is 0, by a department. An objective can align with a
+// department objective it contributes to, which is how goals cascade.
+type Objective struct {
+	ID           int
+	Title        string
+	Description  string
+	Quarter      string // e.g. 2025-Q1
+	EmployeeID   int
+	DepartmentID int
+	ParentID     int // the department objective this one aligns with, or 0
+	CreatedAt    time.Time
+}
+
+// KeyResult is a measurable result of an objective, moving from
+// StartValue towards TargetValue.
+type KeyResult struct {
+	ID           int
+	ObjectiveID  int
+	Title        string
+	Unit         string // e.g. %, tickets, USD
+	StartValue   float64
+	TargetValue  float64
+	CurrentValue float64
+	CreatedAt    time.Time
+}
+
+// CheckIn records the value of a key result at some point in the quarter,
+// with the owner's comment on it.
+type CheckIn struct {
+	ID          int
+	KeyResultID int
+	Value       float64
+	Comment     string
+	CreatedAt   time.Time
+}
+
+// Course is an entry of the training catalog. Completing a course that
+// grants a certification records it for the employee.
+type Course struct {
+	ID              int
+	Title           string
+	Provider        string
+	Description     string
+	Hours           float64
+	CertificationID int // the certification the course grants, or 0
+	CreatedAt       time.Time
+}
+
+// Enrollment is an employee taking a course.
+type Enrollment struct {
+	ID          int
+	CourseID    int
+	EmployeeID  int
+	Status      string // enrolled or completed
+	EnrolledOn  time.Time
+	CompletedOn time.Time
+	CreatedAt   time.Time
+}
+
+// Certification is a kind of certificate staff can hold, such as first
+// aid or forklift operation, and the departments whose staff must hold it.
+type Certification struct {
+	ID          int
+	Name        string
+	Description string
+	// ValidityMonths is how long a certificate is valid from its issue
+	// date; 0 means it never expires.
+	ValidityMonths int
+	// ReminderDays is how many days before expiry a certificate shows up
+	// in the reminders.
+	ReminderDays int
+	Departments  []int // the departments that require it
+	CreatedAt    time.Time
+}
+
+// EmployeeCertification is a certificate an employee holds, with the
+// uploaded proof of it.
+type EmployeeCertification struct {
+	ID              int
+	EmployeeID      int
+	CertificationID int
+	IssuedOn        time.Time
+	ExpiresOn       time.Time // zero when it never expires
+	ProofKey        string    // the proof's key in the file store, or empty
+	ProofName       string
+	CreatedAt       time.Time
+}
+
+// DocumentCategory groups employee documents, such as contracts or IDs.
+// Documents in confidential categories are only shown to HR.
+type DocumentCategory struct {
+	ID           int
+	Name         string
+	Confidential bool
+	SelfService  bool // employees download their own documents of it, e.g. payslips
+	CreatedAt    time.Time
+}
+
+// Document is a file kept for an employee. Uploading it again adds a
+// version; the latest version is the one downloaded by default.
+type Document struct {
+	ID         int
+	EmployeeID int
+	CategoryID int
+	Title      string
+	ExpiresOn  time.Time // zero when it never expires
+	CreatedAt  time.Time
+}
+
+// DocumentVersion is one upload of a document.
+type DocumentVersion struct {
+	ID         int
+	DocumentID int
+	Version    int // 1 for the first upload
+	FileKey    string
+	FileName   string
+	Size       int64
+	UploadedBy string // the user who uploaded it, empty if unknown
+	CreatedAt  time.Time
+}
+
+// DocumentDownload records who downloaded a document. The title and
+// employee are copied so the record outlives the document.
+type DocumentDownload struct {
+	ID         int
+	DocumentID int
+	EmployeeID int
+	Title      string
+	Version    int
+	User       string // empty if unknown
+	CreatedAt  time.Time
+}
+
+// OnboardingTemplate is a reusable checklist for new hires or, for the
+// offboarding kind, for leavers. It applies to employees of its
+// department and position; an empty department or position matches any.
+type OnboardingTemplate struct {
+	ID           int
+	Kind         string // onboarding or offboarding
+	Name         string
+	DepartmentID int    // 0 for every department
+	Position     string // matched against Employee.JobTitle, empty for any
+	CreatedAt    time.Time
+}
+
+// OnboardingTemplateTask is a task of an onboarding template. It is due
+// DueDays after the hire date, or after the last day for offboarding;
+// negative days are before it.
+type OnboardingTemplateTask struct {
+	ID         int
+	TemplateID int
+	Title      string
+	Assignee   string // who does it, such as IT or the manager
+	DueDays    int
+	CreatedAt  time.Time
+}
+
+// OnboardingTask is a task of a new hire's checklist, generated from a
+// template task when the employee is created, or of a leaver's checklist
+// generated on termination.
+type OnboardingTask struct {
+	ID         int
+	EmployeeID int
+	Kind       string
+	TemplateID int // the template it came from; kept if it is deleted
+	Title      string
+	Assignee   string
+	DueOn      time.Time
+	DoneOn     time.Time // zero while open
+	DoneBy     string    // the user who ticked it off, empty if unknown
+	CreatedAt  time.Time
+}
+
+// Termination records that an employee left. Besides the reason it
+// keeps the inputs of their final pay that the employee record lacks.
+type Termination struct {
+	ID              int
+	EmployeeID      int
+	LastDay         time.Time
+	Reason          string // one of terminationReasons
+	Notes           string
+	RehireEligible  bool
+	UnusedLeaveDays float64 // paid out with the final pay
+	Severance       float64
+	Deductions      float64 // such as unreturned equipment or advances
+	ProcessedBy     string  // the user who recorded it, empty if unknown
+	CreatedAt       time.Time
+}
+
+// Equipment is an item of company property lent to employees, such as a
+// laptop, phone or access badge.
+type Equipment struct {
+	ID           int
+	Category     string // one of equipmentCategories
+	Name         string
+	SerialNumber string // unique, empty if the item has none
+	Condition    string // the latest entry of the condition log
+	Notes        string
+	CreatedAt    time.Time
+}
+
+// EquipmentAssignment is a period an employee held an item.
+type EquipmentAssignment struct {
+	ID          int
+	EquipmentID int
+	EmployeeID  int
+	AssignedOn  time.Time
+	ReturnedOn  time.Time // zero while the employee holds the item
+	AssignedBy  string
+	ReturnedBy  string
+	CreatedAt   time.Time
+}
+
+// EquipmentCondition is an entry of the condition log of an item.
+type EquipmentCondition struct {
+	ID          int
+	EquipmentID int
+	Condition   string // one of equipmentConditions
+	Notes       string
+	RecordedBy  string
+	CreatedAt   time.Time
+}
+
+// ExpenseClaim is money an employee spent for work and wants paid back.
+// It is approved by their manager first, then by finance.
+type ExpenseClaim struct {
+	ID                int
+	EmployeeID        int
+	ManagerID         int // the employee who approves it first
+	Title             string
+	Currency          string // ISO 4217 code of all of its items
+	Status            string // one of expenseStatuses
+	SubmittedAt       time.Time
+	ManagerApprovedBy string
+	ManagerApprovedAt time.Time
+	ApprovedBy        string    // the finance approver
+	ApprovedAt        time.Time // decides the payroll period it is paid in
+	ReimbursedOn      time.Time
+	RejectedBy        string
+	RejectionReason   string
+	CreatedAt         time.Time
+}
+
+// ExpenseItem is a line of an expense claim.
+type ExpenseItem struct {
+	ID          int
+	ClaimID     int
+	SpentOn     time.Time
+	Category    string // one of expenseCategories
+	Description string
+	Amount      float64 // in the currency of the claim
+	ReceiptKey  string  // the receipt in the file store, empty if none
+	ReceiptName string
+	CreatedAt   time.Time
+}
+
+// BenefitPlan is a benefit employees can enroll in, such as health
+// insurance or a pension. Employees join or change their coverage during
+// its open-enrollment window.
+type BenefitPlan struct {
+	ID               int
+	Name             string
+	Kind             string // one of benefitKinds
+	Description      string
+	Statuses         string // eligible employee statuses, e.g. active,suspended; empty for any
+	DepartmentID     int    // 0 for every department
+	MinTenureMonths  int    // months since the hire date before one is eligible
+	CoversDependents bool
+	OpensOn          time.Time
+	ClosesOn         time.Time
+	CreatedAt        time.Time
+}
+
+// Dependent is a family member of an employee who can be covered by
+// their benefits.
+type Dependent struct {
+	ID           int
+	EmployeeID   int
+	FirstName    string
+	LastName     string
+	Relationship string // one of dependentRelationships
+	BirthDate    time.Time
+	CreatedAt    time.Time
+}
+
+// BenefitEnrollment is a period an employee was covered by a plan.
+// Changing the coverage ends the enrollment and starts a new one, so the
+// enrollments of an employee are their history.
+type BenefitEnrollment struct {
+	ID            int
+	PlanID        int
+	EmployeeID    int
+	DependentIDs  []int // the covered dependents
+	EffectiveFrom time.Time
+	EffectiveTo   time.Time // the last covered day, zero while current
+	EnrolledBy    string
+	CreatedAt     time.Time
+}
+
+// ShiftTemplate is a shift that can be put on the rota, such as Early
+// 06:00-14:00. A template with a DepartmentID is offered to that
+// department only.
+type ShiftTemplate struct {
+	ID           int
+	Name         string
+	DepartmentID int
+	StartTime    string // HH:MM
+	EndTime      string // HH:MM, before StartTime when the shift runs past midnight
+	BreakMinutes int
+	CreatedAt    time.Time
+}
+
+// Shift is an employee's shift on a day of a department's rota. The
+// times are copied from the template, so editing a template leaves the
+// rotas already planned alone.
+type Shift struct {
+	ID           int
+	DepartmentID int
+	EmployeeID   int
+	Name         string
+	Date         time.Time
+	StartTime    string // HH:MM
+	EndTime      string // HH:MM
+	BreakMinutes int
+	PublishedAt  time.Time // zero while a draft only HR sees
+	CreatedAt    time.Time
+}
+
+// OvertimeRequest asks for hours worked beyond contract on a day to be
+// paid or credited as time off in lieu. The kind of day and its
+// multiplier are fixed when the request is made.
+type OvertimeRequest struct {
+	ID           int
+	EmployeeID   int
+	Date         time.Time
+	Hours        float64
+	DayKind      string // weekday, weekend or holiday
+	Multiplier   float64
+	Compensation string // pay or toil
+	Reason       string
+	Status       string // pending, approved or rejected
+	DecidedBy    string
+	DecidedAt    time.Time
+	CreatedAt    time.Time
+}
+
+// ContactChange is an update of an employee's contact details made from
+// self-service. It takes effect once HR approves it: the latest approved
+// change holds the current details.
+type ContactChange struct {
+	ID             int
+	EmployeeID     int
+	Phone          string
+	Address        string
+	EmergencyName  string
+	EmergencyPhone string
+	Status         string // pending, approved or rejected
+	DecidedBy      string
+	DecidedAt      time.Time
+	CreatedAt      time.Time
+}
+
 type DepartmentRepository interface {
 	GetDepartments(ctx context.Context, q string) ([]Department, error)
 	GetDepartmentByID(ctx context.Context, id int) (*Department, error)
@@ -73,6 +561,8 @@ type PositionRepository interface {
 type EmployeeRepository interface {
 	GetEmployees(ctx context.Context, q string) ([]Employee, error)
 	GetEmployeeByID(ctx context.Context, id int) (*Employee, error)
+	// GetEmployeeByEmail matches email regardless of case.
+	GetEmployeeByEmail(ctx context.Context, email string) (*Employee, error)
 	DeleteEmployee(ctx context.Context, id int) error
 	CreateEmployee(ctx context.Context, employee *Employee) error
 	UpdateEmployee(ctx context.Context, employee *Employee) error
@@ -93,3 +583,377 @@ type LeaveRepository interface {
 	CreateLeave(ctx context.Context, leave *Leave) error
 	UpdateLeave(ctx context.Context, leave *Leave) error
 }
+
+type AttendanceRepository interface {
+	// GetAttendanceEvents returns the events in [from, to) ordered by time,
+	// of one employee or, when employeeID is 0, of everyone.
+	GetAttendanceEvents(ctx context.Context, employeeID int, from, to time.Time) ([]AttendanceEvent, error)
+	CreateAttendanceEvent(ctx context.Context, event *AttendanceEvent) error
+	DeleteAttendanceEvent(ctx context.Context, id int) error
+	// ReplaceAttendanceEvents atomically swaps the employee's events in
+	// [from, to) for events.
+	ReplaceAttendanceEvents(ctx context.Context, employeeID int, from, to time.Time, events []AttendanceEvent) error
+}
+
+type WorkScheduleRepository interface {
+	GetWorkSchedules(ctx context.Context) ([]WorkSchedule, error)
+	GetWorkScheduleByID(ctx context.Context, id int) (*WorkSchedule, error)
+	DeleteWorkSchedule(ctx context.Context, id int) error
+	CreateWorkSchedule(ctx context.Context, schedule *WorkSchedule) error
+	UpdateWorkSchedule(ctx context.Context, schedule *WorkSchedule) error
+}
+
+type AttendanceCorrectionRepository interface {
+	// GetAttendanceCorrections returns the corrections with status, or all
+	// of them when status is empty, newest first.
+	GetAttendanceCorrections(ctx context.Context, status string) ([]AttendanceCorrection, error)
+	GetAttendanceCorrectionByID(ctx context.Context, id int) (*AttendanceCorrection, error)
+	CreateAttendanceCorrection(ctx context.Context, correction *AttendanceCorrection) error
+	UpdateAttendanceCorrection(ctx context.Context, correction *AttendanceCorrection) error
+}
+
+type BadgeImportRepository interface {
+	// GetBadgeImports returns every import, newest first.
+	GetBadgeImports(ctx context.Context) ([]BadgeImport, error)
+	GetBadgeImportByID(ctx context.Context, id int) (*BadgeImport, error)
+	CreateBadgeImport(ctx context.Context, badgeImport *BadgeImport) error
+}
+
+type ReviewCycleRepository interface {
+	// GetReviewCycles returns every cycle, newest first.
+	GetReviewCycles(ctx context.Context) ([]ReviewCycle, error)
+	GetReviewCycleByID(ctx context.Context, id int) (*ReviewCycle, error)
+	DeleteReviewCycle(ctx context.Context, id int) error
+	CreateReviewCycle(ctx context.Context, cycle *ReviewCycle) error
+	UpdateReviewCycle(ctx context.Context, cycle *ReviewCycle) error
+}
+
+type ReviewQuestionRepository interface {
+	// GetReviewQuestions returns the questionnaire of a cycle in the order
+	// the questions were added.
+	GetReviewQuestions(ctx context.Context, cycleID int) ([]ReviewQuestion, error)
+	DeleteReviewQuestion(ctx context.Context, id int) error
+	CreateReviewQuestion(ctx context.Context, question *ReviewQuestion) error
+}
+
+type ReviewAssessmentRepository interface {
+	// GetReviewAssessments returns the assessments of a cycle, of one
+	// employee under review or, when employeeID is 0, of everyone.
+	GetReviewAssessments(ctx context.Context, cycleID, employeeID int) ([]ReviewAssessment, error)
+	GetReviewAssessmentByID(ctx context.Context, id int) (*ReviewAssessment, error)
+	CreateReviewAssessment(ctx context.Context, assessment *ReviewAssessment) error
+	UpdateReviewAssessment(ctx context.Context, assessment *ReviewAssessment) error
+}
+
+type EmployeeRatingRepository interface {
+	// GetEmployeeRatings returns the final ratings of a cycle, or of every
+	// cycle when cycleID is 0, and of one employee or, when employeeID is 0,
+	// of everyone.
+	GetEmployeeRatings(ctx context.Context, cycleID, employeeID int) ([]EmployeeRating, error)
+	// SaveEmployeeRating stores the rating, replacing the one the employee
+	// already has for the cycle.
+	SaveEmployeeRating(ctx context.Context, rating *EmployeeRating) error
+}
+
+type ObjectiveRepository interface {
+	// GetObjectives returns the objectives of a quarter or, when quarter
+	// is empty, of every quarter.
+	GetObjectives(ctx context.Context, quarter string) ([]Objective, error)
+	GetObjectiveByID(ctx context.Context, id int) (*Objective, error)
+	DeleteObjective(ctx context.Context, id int) error
+	CreateObjective(ctx context.Context, objective *Objective) error
+	UpdateObjective(ctx context.Context, objective *Objective) error
+}
+
+type KeyResultRepository interface {
+	// GetKeyResults returns the key results of an objective or, when
+	// objectiveID is 0, of every objective.
+	GetKeyResults(ctx context.Context, objectiveID int) ([]KeyResult, error)
+	GetKeyResultByID(ctx context.Context, id int) (*KeyResult, error)
+	DeleteKeyResult(ctx context.Context, id int) error
+	CreateKeyResult(ctx context.Context, kr *KeyResult) error
+	UpdateKeyResult(ctx context.Context, kr *KeyResult) error
+}
+
+type CheckInRepository interface {
+	// GetCheckIns returns the check-ins of a key result, newest first.
+	GetCheckIns(ctx context.Context, keyResultID int) ([]CheckIn, error)
+	CreateCheckIn(ctx context.Context, checkIn *CheckIn) error
+	// DeleteCheckIns deletes every check-in of a key result.
+	DeleteCheckIns(ctx context.Context, keyResultID int) error
+}
+
+type CourseRepository interface {
+	GetCourses(ctx context.Context) ([]Course, error)
+	GetCourseByID(ctx context.Context, id int) (*Course, error)
+	DeleteCourse(ctx context.Context, id int) error
+	CreateCourse(ctx context.Context, course *Course) error
+	UpdateCourse(ctx context.Context, course *Course) error
+}
+
+type EnrollmentRepository interface {
+	// GetEnrollments returns the enrollments of a course and of an
+	// employee; 0 matches every course or employee.
+	GetEnrollments(ctx context.Context, courseID, employeeID int) ([]Enrollment, error)
+	GetEnrollmentByID(ctx context.Context, id int) (*Enrollment, error)
+	DeleteEnrollment(ctx context.Context, id int) error
+	// CreateEnrollment fails if the employee is already enrolled in the
+	// course.
+	CreateEnrollment(ctx context.Context, enrollment *Enrollment) error
+	UpdateEnrollment(ctx context.Context, enrollment *Enrollment) error
+}
+
+type CertificationRepository interface {
+	GetCertifications(ctx context.Context) ([]Certification, error)
+	GetCertificationByID(ctx context.Context, id int) (*Certification, error)
+	DeleteCertification(ctx context.Context, id int) error
+	CreateCertification(ctx context.Context, certification *Certification) error
+	UpdateCertification(ctx context.Context, certification *Certification) error
+}
+
+type EmployeeCertificationRepository interface {
+	// GetEmployeeCertifications returns the certificates of a
+	// certification and of an employee, 0 matching any, newest issue
+	// first.
+	GetEmployeeCertifications(ctx context.Context, certificationID, employeeID int) ([]EmployeeCertification, error)
+	GetEmployeeCertificationByID(ctx context.Context, id int) (*EmployeeCertification, error)
+	DeleteEmployeeCertification(ctx context.Context, id int) error
+	CreateEmployeeCertification(ctx context.Context, certificate *EmployeeCertification) error
+}
+
+type DocumentCategoryRepository interface {
+	GetDocumentCategories(ctx context.Context) ([]DocumentCategory, error)
+	GetDocumentCategoryByID(ctx context.Context, id int) (*DocumentCategory, error)
+	DeleteDocumentCategory(ctx context.Context, id int) error
+	CreateDocumentCategory(ctx context.Context, category *DocumentCategory) error
+	UpdateDocumentCategory(ctx context.Context, category *DocumentCategory) error
+}
+
+type DocumentRepository interface {
+	// GetDocuments returns the documents of an employee, or of everyone
+	// for 0.
+	GetDocuments(ctx context.Context, employeeID int) ([]Document, error)
+	GetDocumentByID(ctx context.Context, id int) (*Document, error)
+	DeleteDocument(ctx context.Context, id int) error
+	CreateDocument(ctx context.Context, document *Document) error
+	UpdateDocument(ctx context.Context, document *Document) error
+}
+
+type DocumentVersionRepository interface {
+	// GetDocumentVersions returns the versions of a document, or of every
+	// document for 0, newest first.
+	GetDocumentVersions(ctx context.Context, documentID int) ([]DocumentVersion, error)
+	// CreateDocumentVersion fails if the document already has a version
+	// with the same number.
+	CreateDocumentVersion(ctx context.Context, version *DocumentVersion) error
+	// DeleteDocumentVersions deletes every version of a document.
+	DeleteDocumentVersions(ctx context.Context, documentID int) error
+}
+
+type DocumentDownloadRepository interface {
+	// GetDocumentDownloads returns the downloads of a document, or of
+	// every document for 0, newest first.
+	GetDocumentDownloads(ctx context.Context, documentID int) ([]DocumentDownload, error)
+	CreateDocumentDownload(ctx context.Context, download *DocumentDownload) error
+}
+
+type OnboardingTemplateRepository interface {
+	GetOnboardingTemplates(ctx context.Context) ([]OnboardingTemplate, error)
+	GetOnboardingTemplateByID(ctx context.Context, id int) (*OnboardingTemplate, error)
+	DeleteOnboardingTemplate(ctx context.Context, id int) error
+	CreateOnboardingTemplate(ctx context.Context, template *OnboardingTemplate) error
+	UpdateOnboardingTemplate(ctx context.Context, template *OnboardingTemplate) error
+}
+
+type OnboardingTemplateTaskRepository interface {
+	// GetOnboardingTemplateTasks returns the tasks of a template, or of
+	// every template for 0, in due order.
+	GetOnboardingTemplateTasks(ctx context.Context, templateID int) ([]OnboardingTemplateTask, error)
+	DeleteOnboardingTemplateTask(ctx context.Context, id int) error
+	CreateOnboardingTemplateTask(ctx context.Context, task *OnboardingTemplateTask) error
+}
+
+type OnboardingTaskRepository interface {
+	// GetOnboardingTasks returns the onboarding tasks of an employee, or
+	// of everyone for 0, in due order.
+	GetOnboardingTasks(ctx context.Context, employeeID int) ([]OnboardingTask, error)
+	GetOnboardingTaskByID(ctx context.Context, id int) (*OnboardingTask, error)
+	DeleteOnboardingTask(ctx context.Context, id int) error
+	CreateOnboardingTask(ctx context.Context, task *OnboardingTask) error
+	UpdateOnboardingTask(ctx context.Context, task *OnboardingTask) error
+}
+
+type TerminationRepository interface {
+	GetTerminations(ctx context.Context) ([]Termination, error)
+	// GetTermination returns the termination of an employee, or nil if
+	// they were not terminated.
+	GetTermination(ctx context.Context, employeeID int) (*Termination, error)
+	// CreateTermination fails if the employee was already terminated.
+	CreateTermination(ctx context.Context, termination *Termination) error
+	UpdateTermination(ctx context.Context, termination *Termination) error
+}
+
+type EquipmentRepository interface {
+	// GetEquipment returns the items whose name or serial number contains
+	// q, by category and name.
+	GetEquipment(ctx context.Context, q string) ([]Equipment, error)
+	GetEquipmentByID(ctx context.Context, id int) (*Equipment, error)
+	DeleteEquipment(ctx context.Context, id int) error
+	// CreateEquipment fails if another item has the same serial number.
+	CreateEquipment(ctx context.Context, equipment *Equipment) error
+	UpdateEquipment(ctx context.Context, equipment *Equipment) error
+}
+
+type EquipmentAssignmentRepository interface {
+	// GetEquipmentAssignments returns the assignments of an item and of an
+	// employee, newest first; 0 matches every item or employee.
+	GetEquipmentAssignments(ctx context.Context, equipmentID, employeeID int) ([]EquipmentAssignment, error)
+	CreateEquipmentAssignment(ctx context.Context, assignment *EquipmentAssignment) error
+	UpdateEquipmentAssignment(ctx context.Context, assignment *EquipmentAssignment) error
+	// DeleteEquipmentAssignments deletes every assignment of an item.
+	DeleteEquipmentAssignments(ctx context.Context, equipmentID int) error
+}
+
+type EquipmentConditionRepository interface {
+	// GetEquipmentConditions returns the condition log of an item, newest
+	// first.
+	GetEquipmentConditions(ctx context.Context, equipmentID int) ([]EquipmentCondition, error)
+	CreateEquipmentCondition(ctx context.Context, condition *EquipmentCondition) error
+	// DeleteEquipmentConditions deletes the condition log of an item.
+	DeleteEquipmentConditions(ctx context.Context, equipmentID int) error
+}
+
+type ExpenseClaimRepository interface {
+	// GetExpenseClaims returns the claims of an employee, or of everyone
+	// for 0, with status, or any status when it is empty; newest first.
+	GetExpenseClaims(ctx context.Context, employeeID int, status string) ([]ExpenseClaim, error)
+	GetExpenseClaimByID(ctx context.Context, id int) (*ExpenseClaim, error)
+	DeleteExpenseClaim(ctx context.Context, id int) error
+	CreateExpenseClaim(ctx context.Context, claim *ExpenseClaim) error
+	UpdateExpenseClaim(ctx context.Context, claim *ExpenseClaim) error
+}
+
+type ExpenseItemRepository interface {
+	// GetExpenseItems returns the items of a claim, or of every claim for
+	// 0, by date.
+	GetExpenseItems(ctx context.Context, claimID int) ([]ExpenseItem, error)
+	GetExpenseItemByID(ctx context.Context, id int) (*ExpenseItem, error)
+	CreateExpenseItem(ctx context.Context, item *ExpenseItem) error
+	DeleteExpenseItem(ctx context.Context, id int) error
+	// DeleteExpenseItems deletes every item of a claim.
+	DeleteExpenseItems(ctx context.Context, claimID int) error
+}
+
+type BenefitPlanRepository interface {
+	GetBenefitPlans(ctx context.Context) ([]BenefitPlan, error)
+	GetBenefitPlanByID(ctx context.Context, id int) (*BenefitPlan, error)
+	DeleteBenefitPlan(ctx context.Context, id int) error
+	CreateBenefitPlan(ctx context.Context, plan *BenefitPlan) error
+	UpdateBenefitPlan(ctx context.Context, plan *BenefitPlan) error
+}
+
+type DependentRepository interface {
+	// GetDependents returns the dependents of an employee, or of everyone
+	// for 0.
+	GetDependents(ctx context.Context, employeeID int) ([]Dependent, error)
+	GetDependentByID(ctx context.Context, id int) (*Dependent, error)
+	CreateDependent(ctx context.Context, dependent *Dependent) error
+	DeleteDependent(ctx context.Context, id int) error
+}
+
+type BenefitEnrollmentRepository interface {
+	// GetBenefitEnrollments returns the enrollments in a plan and of an
+	// employee, newest first; 0 matches every plan or employee.
+	GetBenefitEnrollments(ctx context.Context, planID, employeeID int) ([]BenefitEnrollment, error)
+	GetBenefitEnrollmentByID(ctx context.Context, id int) (*BenefitEnrollment, error)
+	CreateBenefitEnrollment(ctx context.Context, enrollment *BenefitEnrollment) error
+	UpdateBenefitEnrollment(ctx context.Context, enrollment *BenefitEnrollment) error
+}
+
+type ShiftTemplateRepository interface {
+	GetShiftTemplates(ctx context.Context) ([]ShiftTemplate, error)
+	GetShiftTemplateByID(ctx context.Context, id int) (*ShiftTemplate, error)
+	DeleteShiftTemplate(ctx context.Context, id int) error
+	CreateShiftTemplate(ctx context.Context, template *ShiftTemplate) error
+	UpdateShiftTemplate(ctx context.Context, template *ShiftTemplate) error
+}
+
+type ShiftRepository interface {
+	// GetShifts returns the shifts dated in [from, to) of a department and
+	// an employee, 0 matching every one, ordered by date and start time.
+	GetShifts(ctx context.Context, departmentID, employeeID int, from, to time.Time) ([]Shift, error)
+	GetShiftByID(ctx context.Context, id int) (*Shift, error)
+	CreateShift(ctx context.Context, shift *Shift) error
+	// UpdateShift moves a shift to another employee or day and records
+	// when it was published.
+	UpdateShift(ctx context.Context, shift *Shift) error
+	DeleteShift(ctx context.Context, id int) error
+}
+
+type OvertimeRequestRepository interface {
+	// GetOvertimeRequests returns the requests of an employee, 0 matching
+	// every one, with status, or all of them when status is empty. The
+	// latest worked come first.
+	GetOvertimeRequests(ctx context.Context, employeeID int, status string) ([]OvertimeRequest, error)
+	GetOvertimeRequestByID(ctx context.Context, id int) (*OvertimeRequest, error)
+	CreateOvertimeRequest(ctx context.Context, request *OvertimeRequest) error
+	// UpdateOvertimeRequest records the decision on a request.
+	UpdateOvertimeRequest(ctx context.Context, request *OvertimeRequest) error
+	DeleteOvertimeRequest(ctx context.Context, id int) error
+}
+
+type ContactChangeRepository interface {
+	// GetContactChanges returns the changes of an employee, 0 matching
+	// every one, with status, or all of them when status is empty, newest
+	// first.
+	GetContactChanges(ctx context.Context, employeeID int, status string) ([]ContactChange, error)
+	GetContactChangeByID(ctx context.Context, id int) (*ContactChange, error)
+	CreateContactChange(ctx context.Context, change *ContactChange) error
+	// UpdateContactChange records the decision on a change.
+	UpdateContactChange(ctx context.Context, change *ContactChange) error
+}
+
+// Repositories holds one implementation of every repository interface,
+// all backed by the same store.
+type Repositories struct {
+	Departments         DepartmentRepository
+	Positions           PositionRepository
+	Employees           EmployeeRepository
+	Applications        ApplicationRepository
+	Leaves              LeaveRepository
+	Attendance          AttendanceRepository
+	Schedules           WorkScheduleRepository
+	Corrections         AttendanceCorrectionRepository
+	BadgeImports        BadgeImportRepository
+	ReviewCycles        ReviewCycleRepository
+	Questions           ReviewQuestionRepository
+	Assessments         ReviewAssessmentRepository
+	Ratings             EmployeeRatingRepository
+	Objectives          ObjectiveRepository
+	KeyResults          KeyResultRepository
+	CheckIns            CheckInRepository
+	Courses             CourseRepository
+	Enrollments         EnrollmentRepository
+	Certifications      CertificationRepository
+	Certificates        EmployeeCertificationRepository
+	DocCategories       DocumentCategoryRepository
+	Documents           DocumentRepository
+	DocVersions         DocumentVersionRepository
+	Downloads           DocumentDownloadRepository
+	OnboardingTemplates OnboardingTemplateRepository
+	TemplateTasks       OnboardingTemplateTaskRepository
+	OnboardingTasks     OnboardingTaskRepository
+	Terminations        TerminationRepository
+	Equipment           EquipmentRepository
+	Assignments         EquipmentAssignmentRepository
+	Conditions          EquipmentConditionRepository
+	ExpenseClaims       ExpenseClaimRepository
+	ExpenseItems        ExpenseItemRepository
+	BenefitPlans        BenefitPlanRepository
+	Dependents          DependentRepository
+	BenefitEnrollments  BenefitEnrollmentRepository
+	ShiftTemplates      ShiftTemplateRepository
+	Shifts              ShiftRepository
+	Overtime            OvertimeRequestRepository
+	ContactChanges      ContactChangeRepository
+}
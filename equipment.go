@@ -0,0 +1,538 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// equipmentCategories are the kinds of equipment, in the order they are
+// offered.
+var equipmentCategories = []string{"laptop", "phone", "tablet", "monitor", "badge", "vehicle", "other"}
+
+// equipmentConditions are the conditions an item can be logged in, from
+// best to worst.
+var equipmentConditions = []string{"new", "good", "fair", "damaged", "lost"}
+
+func (e *Equipment) validate() error {
+	if e.Name == "" {
+		return errors.New("Name is required")
+	}
+	if !slices.Contains(equipmentCategories, e.Category) {
+		return errors.New("Invalid equipment category")
+	}
+	if !slices.Contains(equipmentConditions, e.Condition) {
+		return errors.New("Invalid equipment condition")
+	}
+	return nil
+}
+
+// Returned reports whether the item was handed back.
+func (a EquipmentAssignment) Returned() bool {
+	return !a.ReturnedOn.IsZero()
+}
+
+// EquipmentView is an item with the employee holding it, if anyone.
+type EquipmentView struct {
+	Equipment
+	Assignment EquipmentAssignment // zero when the item is in stock
+	Holder     Employee
+}
+
+// Outstanding reports whether the item is held by someone who no longer
+// works for the company.
+func (v EquipmentView) Outstanding() bool {
+	return v.Assignment.ID != 0 && !isActive(v.Holder)
+}
+
+// equipmentViews joins every item with its open assignment and holder.
+func equipmentViews(equipment []Equipment, assignments []EquipmentAssignment, employees map[int]Employee) []EquipmentView {
+	held := make(map[int]EquipmentAssignment)
+	for _, a := range assignments {
+		if !a.Returned() {
+			held[a.EquipmentID] = a
+		}
+	}
+	views := make([]EquipmentView, len(equipment))
+	for i, e := range equipment {
+		views[i].Equipment = e
+		if a, ok := held[e.ID]; ok {
+			views[i].Assignment, views[i].Holder = a, employees[a.EmployeeID]
+		}
+	}
+	return views
+}
+
+// heldEquipment loads the items an employee holds, or every item held by
+// someone for 0.
+func (app *App) heldEquipment(ctx context.Context, employeeID int) ([]EquipmentView, error) {
+	equipment, err := app.EquipmentRepository.GetEquipment(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := app.AssignmentRepository.GetEquipmentAssignments(ctx, 0, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	_, employees, err := app.employeesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := equipmentViews(equipment, assignments, employees)
+	return slices.DeleteFunc(views, func(v EquipmentView) bool { return v.Assignment.ID == 0 }), nil
+}
+
+func (app *App) handleEquipment(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	category := r.URL.Query().Get("category")
+	equipment, err := app.EquipmentRepository.GetEquipment(r.Context(), q)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return
+	}
+	assignments, err := app.AssignmentRepository.GetEquipmentAssignments(r.Context(), 0, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment assignments", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	if category != "" {
+		equipment = slices.DeleteFunc(equipment, func(e Equipment) bool { return e.Category != category })
+	}
+
+	data := map[string]any{
+		"ActivePage": "equipment",
+		"Equipment":  equipmentViews(equipment, assignments, employees),
+		"Categories": equipmentCategories,
+		"Category":   category,
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		app.render(w, r, "equipment.html", "equipment_partial", data)
+		return
+	}
+	app.render(w, r, "equipment.html", "", data)
+}
+
+// handleOutstandingEquipment lists the items still held by employees who
+// were terminated or otherwise left.
+func (app *App) handleOutstandingEquipment(w http.ResponseWriter, r *http.Request) {
+	held, err := app.heldEquipment(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "equipment",
+		"Equipment":  slices.DeleteFunc(held, func(v EquipmentView) bool { return !v.Outstanding() }),
+	}
+	app.render(w, r, "outstanding_equipment.html", "", data)
+}
+
+// equipmentForm reads the fields of the add and update equipment forms.
+func equipmentForm(r *http.Request) Equipment {
+	return Equipment{
+		Category:     r.FormValue("category"),
+		Name:         strings.TrimSpace(r.FormValue("name")),
+		SerialNumber: strings.TrimSpace(r.FormValue("serial_number")),
+		Condition:    r.FormValue("condition"),
+		Notes:        strings.TrimSpace(r.FormValue("notes")),
+	}
+}
+
+// checkSerialNumber writes the error response when the serial number of
+// e is taken by another item.
+func (app *App) checkSerialNumber(w http.ResponseWriter, r *http.Request, e Equipment) bool {
+	if e.SerialNumber == "" {
+		return true
+	}
+	equipment, err := app.EquipmentRepository.GetEquipment(r.Context(), e.SerialNumber)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return false
+	}
+	for _, other := range equipment {
+		if other.ID != e.ID && other.SerialNumber == e.SerialNumber {
+			app.clientError(w, r, http.StatusConflict, "An item with this serial number already exists")
+			return false
+		}
+	}
+	return true
+}
+
+func (app *App) handleAddEquipment(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		data := map[string]any{
+			"ActivePage": "equipment",
+			"Equipment":  Equipment{Condition: "new"},
+			"Categories": equipmentCategories,
+			"Conditions": equipmentConditions,
+		}
+		app.render(w, r, "add_equipment.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	equipment := equipmentForm(r)
+	if err := equipment.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !app.checkSerialNumber(w, r, equipment) {
+		return
+	}
+	if err := app.EquipmentRepository.CreateEquipment(r.Context(), &equipment); err != nil {
+		app.serverError(w, r, "Failed to add equipment", err)
+		return
+	}
+	condition := EquipmentCondition{EquipmentID: equipment.ID, Condition: equipment.Condition, RecordedBy: app.viewer(r).User}
+	if err := app.ConditionRepository.CreateEquipmentCondition(r.Context(), &condition); err != nil {
+		app.serverError(w, r, "Failed to log condition", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/equipment")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleUpdateEquipment edits an item. Its condition is changed through
+// the condition log, so the form leaves it alone.
+func (app *App) handleUpdateEquipment(w http.ResponseWriter, r *http.Request) {
+	equipment, ok := app.equipmentItem(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		data := map[string]any{
+			"ActivePage": "equipment",
+			"Equipment":  equipment,
+			"Categories": equipmentCategories,
+		}
+		app.render(w, r, "update_equipment.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated := equipmentForm(r)
+	updated.ID, updated.Condition = equipment.ID, equipment.Condition
+	if err := updated.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !app.checkSerialNumber(w, r, updated) {
+		return
+	}
+	if err := app.EquipmentRepository.UpdateEquipment(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update equipment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/equipment/items/%d", equipment.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteEquipment deletes an item with its history. Items someone
+// holds must be returned first.
+func (app *App) handleDeleteEquipment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	_, open, err := app.equipmentHolder(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment assignments", err)
+		return
+	}
+	if open {
+		app.clientError(w, r, http.StatusConflict, "Equipment is still assigned")
+		return
+	}
+	if err := app.AssignmentRepository.DeleteEquipmentAssignments(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete equipment assignments", err)
+		return
+	}
+	if err := app.ConditionRepository.DeleteEquipmentConditions(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete equipment conditions", err)
+		return
+	}
+	if err := app.EquipmentRepository.DeleteEquipment(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete equipment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/equipment")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// equipmentItem fetches the item named by the request's id path value
+// and writes the error response when there is none.
+func (app *App) equipmentItem(w http.ResponseWriter, r *http.Request) (*Equipment, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	equipment, err := app.EquipmentRepository.GetEquipmentByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return nil, false
+	}
+	if equipment == nil {
+		app.clientError(w, r, http.StatusNotFound, "Equipment not found")
+		return nil, false
+	}
+	return equipment, true
+}
+
+// equipmentHolder returns the open assignment of an item and whether it
+// has one.
+func (app *App) equipmentHolder(ctx context.Context, equipmentID int) (EquipmentAssignment, bool, error) {
+	assignments, err := app.AssignmentRepository.GetEquipmentAssignments(ctx, equipmentID, 0)
+	if err != nil {
+		return EquipmentAssignment{}, false, err
+	}
+	for _, a := range assignments {
+		if !a.Returned() {
+			return a, true, nil
+		}
+	}
+	return EquipmentAssignment{}, false, nil
+}
+
+// handleEquipmentItem shows an item with its assignment history and
+// condition log.
+func (app *App) handleEquipmentItem(w http.ResponseWriter, r *http.Request) {
+	equipment, ok := app.equipmentItem(w, r)
+	if !ok {
+		return
+	}
+	assignments, err := app.AssignmentRepository.GetEquipmentAssignments(r.Context(), equipment.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment assignments", err)
+		return
+	}
+	conditions, err := app.ConditionRepository.GetEquipmentConditions(r.Context(), equipment.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment conditions", err)
+		return
+	}
+	employees, byID, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	view := equipmentViews([]Equipment{*equipment}, assignments, byID)[0]
+	data := map[string]any{
+		"ActivePage":  "equipment",
+		"Equipment":   view,
+		"Assignments": assignments,
+		"Conditions":  conditions,
+		"States":      equipmentConditions,
+		"Employees":   byID,
+		"Candidates":  slices.DeleteFunc(employees, func(e Employee) bool { return !isActive(e) }),
+		"Today":       today(),
+	}
+	app.render(w, r, "equipment_item.html", "", data)
+}
+
+// formDate parses the date form field name, defaulting to today when it
+// is empty.
+func formDate(r *http.Request, name string) (time.Time, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return today(), nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+// handleAssignEquipment hands an item in stock to an active employee.
+func (app *App) handleAssignEquipment(w http.ResponseWriter, r *http.Request) {
+	equipment, ok := app.equipmentItem(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	if employee == nil || !isActive(*employee) {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	assignedOn, err := formDate(r, "assigned_on")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	if equipment.Condition == "lost" {
+		app.clientError(w, r, http.StatusConflict, "Lost equipment can't be assigned")
+		return
+	}
+	_, open, err := app.equipmentHolder(r.Context(), equipment.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment assignments", err)
+		return
+	}
+	if open {
+		app.clientError(w, r, http.StatusConflict, "Equipment is already assigned")
+		return
+	}
+
+	assignment := EquipmentAssignment{EquipmentID: equipment.ID, EmployeeID: employee.ID, AssignedOn: assignedOn, AssignedBy: app.viewer(r).User}
+	if err := app.AssignmentRepository.CreateEquipmentAssignment(r.Context(), &assignment); err != nil {
+		app.serverError(w, r, "Failed to assign equipment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/equipment/items/%d", equipment.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleReturnEquipment closes the open assignment of an item. The
+// condition it came back in, if given, goes into the condition log.
+func (app *App) handleReturnEquipment(w http.ResponseWriter, r *http.Request) {
+	equipment, ok := app.equipmentItem(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	returnedOn, err := formDate(r, "returned_on")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	assignment, open, err := app.equipmentHolder(r.Context(), equipment.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment assignments", err)
+		return
+	}
+	if !open {
+		app.clientError(w, r, http.StatusConflict, "Equipment is not assigned")
+		return
+	}
+	if returnedOn.Before(assignment.AssignedOn) {
+		app.clientError(w, r, http.StatusBadRequest, "The return date must not be before the assignment date")
+		return
+	}
+
+	assignment.ReturnedOn, assignment.ReturnedBy = returnedOn, app.viewer(r).User
+	if err := app.AssignmentRepository.UpdateEquipmentAssignment(r.Context(), &assignment); err != nil {
+		app.serverError(w, r, "Failed to return equipment", err)
+		return
+	}
+	if r.FormValue("condition") != "" {
+		if !app.logCondition(w, r, equipment) {
+			return
+		}
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/equipment/items/%d", equipment.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// logCondition adds the condition and notes of the form to the log of
+// an item and makes it the item's condition. It writes the error
+// response when that fails.
+func (app *App) logCondition(w http.ResponseWriter, r *http.Request, equipment *Equipment) bool {
+	condition := EquipmentCondition{
+		EquipmentID: equipment.ID,
+		Condition:   r.FormValue("condition"),
+		Notes:       strings.TrimSpace(r.FormValue("notes")),
+		RecordedBy:  app.viewer(r).User,
+	}
+	if !slices.Contains(equipmentConditions, condition.Condition) {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid equipment condition")
+		return false
+	}
+	if err := app.ConditionRepository.CreateEquipmentCondition(r.Context(), &condition); err != nil {
+		app.serverError(w, r, "Failed to log condition", err)
+		return false
+	}
+	equipment.Condition = condition.Condition
+	if err := app.EquipmentRepository.UpdateEquipment(r.Context(), equipment); err != nil {
+		app.serverError(w, r, "Failed to update equipment", err)
+		return false
+	}
+	return true
+}
+
+func (app *App) handleLogCondition(w http.ResponseWriter, r *http.Request) {
+	equipment, ok := app.equipmentItem(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	if !app.logCondition(w, r, equipment) {
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/equipment/items/%d", equipment.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleEmployeeEquipment shows the items an employee holds and every
+// item they held before.
+func (app *App) handleEmployeeEquipment(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	assignments, err := app.AssignmentRepository.GetEquipmentAssignments(r.Context(), 0, employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment assignments", err)
+		return
+	}
+	equipment, err := app.EquipmentRepository.GetEquipment(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return
+	}
+	byID := make(map[int]Equipment, len(equipment))
+	for _, e := range equipment {
+		byID[e.ID] = e
+	}
+	data := map[string]any{
+		"ActivePage":  "equipment",
+		"Employee":    employee,
+		"Assignments": assignments,
+		"Equipment":   byID,
+		"Active":      isActive(*employee),
+	}
+	app.render(w, r, "employee_equipment.html", "", data)
+}
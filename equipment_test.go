@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEquipmentViews(t *testing.T) {
+	employees := map[int]Employee{
+		1: {ID: 1, FirstName: "Omar", Status: "active"},
+		2: {ID: 2, FirstName: "Lea", Status: employeeTerminated},
+	}
+	equipment := []Equipment{{ID: 10, Name: "Laptop"}, {ID: 11, Name: "Phone"}, {ID: 12, Name: "Badge"}}
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	assignments := []EquipmentAssignment{
+		{ID: 3, EquipmentID: 10, EmployeeID: 1, AssignedOn: day},
+		{ID: 2, EquipmentID: 11, EmployeeID: 1, AssignedOn: day, ReturnedOn: day.AddDate(0, 1, 0)},
+		{ID: 1, EquipmentID: 12, EmployeeID: 2, AssignedOn: day},
+	}
+	views := equipmentViews(equipment, assignments, employees)
+	if views[0].Assignment.ID != 3 || views[0].Holder.FirstName != "Omar" || views[0].Outstanding() {
+		t.Errorf("laptop = %+v, want held by Omar", views[0])
+	}
+	if views[1].Assignment.ID != 0 || views[1].Outstanding() {
+		t.Errorf("phone = %+v, want in stock", views[1])
+	}
+	if !views[2].Outstanding() {
+		t.Errorf("badge = %+v, want outstanding with the terminated Lea", views[2])
+	}
+}
+
+func TestEquipment(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	hr := map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "staff, hr"}
+
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &omar); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := send(h, "POST", "/equipment/add", url.Values{"category": {"spaceship"}, "name": {"X"}, "condition": {"new"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add with an unknown category = %d, want 400", w.Code)
+	}
+	form := url.Values{"category": {"laptop"}, "name": {"ThinkPad T14"}, "serial_number": {"PF-123"}, "condition": {"new"}}
+	if w := send(h, "POST", "/equipment/add", form, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("add = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", "/equipment/add", form, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("add with a taken serial number = %d, want 409", w.Code)
+	}
+	items, _ := repos.Equipment.GetEquipment(ctx, "")
+	item := "/equipment/items/" + strconv.Itoa(items[0].ID)
+	if log, _ := repos.Conditions.GetEquipmentConditions(ctx, items[0].ID); len(log) != 1 || log[0].Condition != "new" || log[0].RecordedBy != "hana" {
+		t.Errorf("condition log = %+v, want the initial condition", log)
+	}
+
+	assign := url.Values{"employee_id": {strconv.Itoa(omar.ID)}, "assigned_on": {"2025-01-06"}}
+	if w := send(h, "POST", item+"/assign", assign, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("assign = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", item+"/assign", assign, hr); w.Code != http.StatusConflict {
+		t.Errorf("assign again = %d, want 409", w.Code)
+	}
+	if w := send(h, "DELETE", "/equipment/delete?id="+strconv.Itoa(items[0].ID), nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete while assigned = %d, want 409", w.Code)
+	}
+
+	// Terminating Omar lists the laptop as outstanding.
+	terminate := url.Values{"last_day": {"2025-03-31"}, "reason": {"resignation"}}
+	if w := send(h, "POST", "/offboarding/employees/"+strconv.Itoa(omar.ID), terminate, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("terminate = %d %s", w.Code, w.Body)
+	}
+	w := send(h, "GET", "/equipment/outstanding", nil, hrHeaders)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "PF-123") {
+		t.Errorf("outstanding = %d, want the laptop", w.Code)
+	}
+	w = send(h, "GET", "/offboarding/employees/"+strconv.Itoa(omar.ID), nil, hr)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "ThinkPad T14") {
+		t.Errorf("offboarding page = %d, want the laptop listed", w.Code)
+	}
+
+	if w := send(h, "POST", item+"/return", url.Values{"returned_on": {"2024-12-31"}}, hr); w.Code != http.StatusBadRequest {
+		t.Errorf("return before assignment = %d, want 400", w.Code)
+	}
+	ret := url.Values{"returned_on": {"2025-03-31"}, "condition": {"damaged"}, "notes": {"Cracked hinge"}}
+	if w := send(h, "POST", item+"/return", ret, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("return = %d %s", w.Code, w.Body)
+	}
+	if got, _ := repos.Equipment.GetEquipmentByID(ctx, items[0].ID); got == nil || got.Condition != "damaged" {
+		t.Errorf("item = %+v, want damaged", got)
+	}
+	if history, _ := repos.Assignments.GetEquipmentAssignments(ctx, items[0].ID, 0); len(history) != 1 || !history[0].Returned() || history[0].ReturnedBy != "hana" {
+		t.Errorf("history = %+v, want returned by hana", history)
+	}
+	w = send(h, "GET", "/equipment/outstanding", nil, hrHeaders)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "PF-123") {
+		t.Errorf("outstanding after return = %d, want the laptop gone", w.Code)
+	}
+	if w := send(h, "POST", item+"/return", ret, hr); w.Code != http.StatusConflict {
+		t.Errorf("return again = %d, want 409", w.Code)
+	}
+
+	if w := send(h, "POST", item+"/conditions", url.Values{"condition": {"lost"}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("log condition = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "GET", item, nil, hrHeaders); w.Code != http.StatusOK || strings.Count(w.Body.String(), "Cracked hinge") != 1 {
+		t.Errorf("item page = %d, want the condition log", w.Code)
+	}
+	if w := send(h, "GET", "/equipment?q=pf&category=laptop", nil, map[string]string{"HX-Request": "true", "X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"}); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "ThinkPad T14") {
+		t.Errorf("search = %d, want the laptop", w.Code)
+	}
+	if w := send(h, "GET", "/equipment/employees/"+strconv.Itoa(omar.ID), nil, hrHeaders); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "ThinkPad T14") {
+		t.Errorf("employee equipment = %d, want the laptop in the history", w.Code)
+	}
+	if w := send(h, "DELETE", "/equipment/delete?id="+strconv.Itoa(items[0].ID), nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("delete = %d %s", w.Code, w.Body)
+	}
+}
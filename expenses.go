@@ -0,0 +1,667 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
+// expenseCategories are the kinds of expenses, in the order they are
+// offered.
+var expenseCategories = []string{"travel", "lodging", "meals", "transport", "mileage", "supplies", "training", "other"}
+
+// expenseStatuses are the states of a claim. A draft is submitted to the
+// employee's manager, whose approval sends it to finance; finance
+// approves it for payroll and marks it reimbursed once paid. Either
+// approver can reject it instead.
+var expenseStatuses = []string{"draft", "submitted", "manager_approved", "approved", "rejected", "reimbursed"}
+
+func (c *ExpenseClaim) validate() error {
+	if c.Title == "" {
+		return errors.New("Title is required")
+	}
+	unit, err := currency.ParseISO(c.Currency)
+	if err != nil {
+		return errors.New("Invalid currency")
+	}
+	c.Currency = unit.String()
+	if c.ManagerID == c.EmployeeID {
+		return errors.New("An employee can't approve their own claim")
+	}
+	return nil
+}
+
+func (i *ExpenseItem) validate() error {
+	if !slices.Contains(expenseCategories, i.Category) {
+		return errors.New("Invalid expense category")
+	}
+	if !(i.Amount > 0) || math.IsInf(i.Amount, 1) {
+		return errors.New("Amount must be positive")
+	}
+	if i.SpentOn.After(today()) {
+		return errors.New("The expense date can't be in the future")
+	}
+	return nil
+}
+
+// Paid reports whether finance approved the claim for payroll, whether
+// or not it was reimbursed yet.
+func (c ExpenseClaim) Paid() bool {
+	return c.Status == "approved" || c.Status == "reimbursed"
+}
+
+// payrollPeriod returns the month, as YYYY-MM, an approved claim is paid
+// in: the month finance approved it.
+func payrollPeriod(c ExpenseClaim) string {
+	return c.ApprovedAt.Local().Format("2006-01")
+}
+
+// ExpenseClaimView is a claim with its employee, manager and total.
+type ExpenseClaimView struct {
+	ExpenseClaim
+	Employee Employee
+	Manager  Employee
+	Total    float64
+	Items    int
+}
+
+// expenseClaimViews joins the claims with their people and sums the items
+// of each.
+func expenseClaimViews(claims []ExpenseClaim, items []ExpenseItem, employees map[int]Employee) []ExpenseClaimView {
+	totals := make(map[int]float64)
+	counts := make(map[int]int)
+	for _, i := range items {
+		totals[i.ClaimID] += i.Amount
+		counts[i.ClaimID]++
+	}
+	views := make([]ExpenseClaimView, len(claims))
+	for n, c := range claims {
+		views[n] = ExpenseClaimView{
+			ExpenseClaim: c,
+			Employee:     employees[c.EmployeeID],
+			Manager:      employees[c.ManagerID],
+			Total:        totals[c.ID],
+			Items:        counts[c.ID],
+		}
+	}
+	return views
+}
+
+// mayViewClaim reports whether v may see a claim: the employee who filed
+// it, their manager, HR and finance.
+func (app *App) mayViewClaim(v Viewer, c ExpenseClaimView) bool {
+	if app.isHR(v) || app.isFinance(v) {
+		return true
+	}
+	return v.User != "" && (strings.EqualFold(v.User, c.Employee.Email) || strings.EqualFold(v.User, c.Manager.Email))
+}
+
+// mayApprove reports whether v may give the approval a claim waits for.
+// The manager the employee reported to when filing it approves a
+// submitted claim, HR standing in for them; finance gives the final
+// approval. Nobody approves their own claim, nor both steps of one.
+func (app *App) mayApprove(v Viewer, c ExpenseClaimView) bool {
+	if v.User == "" || strings.EqualFold(v.User, c.Employee.Email) {
+		return false
+	}
+	switch c.Status {
+	case "submitted":
+		return strings.EqualFold(v.User, c.Manager.Email) || app.isHR(v)
+	case "manager_approved":
+		return app.isFinance(v) && !strings.EqualFold(v.User, c.ManagerApprovedBy)
+	}
+	return false
+}
+
+func (app *App) handleExpenses(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	claims, err := app.ExpenseClaimRepository.GetExpenseClaims(r.Context(), 0, status)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense claims", err)
+		return
+	}
+	items, err := app.ExpenseItemRepository.GetExpenseItems(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense items", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	viewer := app.viewer(r)
+	views := slices.DeleteFunc(expenseClaimViews(claims, items, employees), func(c ExpenseClaimView) bool {
+		return !app.mayViewClaim(viewer, c)
+	})
+
+	data := map[string]any{
+		"ActivePage": "expenses",
+		"Claims":     views,
+		"Statuses":   expenseStatuses,
+		"Status":     status,
+		"Period":     time.Now().Format("2006-01"),
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		app.render(w, r, "expenses.html", "expenses_partial", data)
+		return
+	}
+	app.render(w, r, "expenses.html", "", data)
+}
+
+// claimManager returns the manager an active employee reports to, who
+// gives the first approval of their claims. The error response has been
+// written when it returns false.
+func (app *App) claimManager(w http.ResponseWriter, r *http.Request, employee *Employee) (*Employee, bool) {
+	if !isActive(*employee) {
+		app.clientError(w, r, http.StatusConflict, "Only active employees can file claims")
+		return nil, false
+	}
+	var manager *Employee
+	if employee.ManagerID != 0 {
+		var err error
+		if manager, err = app.EmployeeRepository.GetEmployeeByID(r.Context(), employee.ManagerID); err != nil {
+			app.serverError(w, r, "Failed to fetch employee", err)
+			return nil, false
+		}
+	}
+	if manager == nil || !isActive(*manager) {
+		app.clientError(w, r, http.StatusConflict, "Ask HR to record who you report to before filing a claim")
+		return nil, false
+	}
+	return manager, true
+}
+
+// requireClaimant writes a 403 response unless the viewer is the employee
+// who filed claim. Only they change a draft.
+func (app *App) requireClaimant(w http.ResponseWriter, r *http.Request, claim *ExpenseClaim) bool {
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), claim.EmployeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return false
+	}
+	if v := app.viewer(r); employee == nil || v.User == "" || !strings.EqualFold(v.User, employee.Email) {
+		app.clientError(w, r, http.StatusForbidden, "Only the employee who filed the claim can change it")
+		return false
+	}
+	return true
+}
+
+// handleAddExpenseClaim starts a draft claim of the viewer. Its items are
+// added on the claim page before it is submitted. The manager who gives
+// the first approval is the one HR recorded the viewer reports to.
+func (app *App) handleAddExpenseClaim(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	manager, ok := app.claimManager(w, r, employee)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		data := map[string]any{
+			"ActivePage": "expenses",
+			"Employee":   employee,
+			"Manager":    manager,
+			"Currency":   app.Config.I18n.Currency,
+		}
+		app.render(w, r, "add_expense_claim.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	claim := ExpenseClaim{
+		EmployeeID: employee.ID,
+		ManagerID:  manager.ID,
+		Title:      strings.TrimSpace(r.FormValue("title")),
+		Currency:   strings.ToUpper(strings.TrimSpace(r.FormValue("currency"))),
+		Status:     "draft",
+	}
+	if err := claim.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := app.ExpenseClaimRepository.CreateExpenseClaim(r.Context(), &claim); err != nil {
+		app.serverError(w, r, "Failed to add expense claim", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/expenses/claims/%d", claim.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteExpenseClaim deletes a draft or rejected claim with its
+// items and receipts.
+func (app *App) handleDeleteExpenseClaim(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	claim, err := app.ExpenseClaimRepository.GetExpenseClaimByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense claim", err)
+		return
+	}
+	if claim == nil {
+		app.clientError(w, r, http.StatusNotFound, "Expense claim not found")
+		return
+	}
+	if !app.requireClaimant(w, r, claim) {
+		return
+	}
+	if claim.Status != "draft" && claim.Status != "rejected" {
+		app.clientError(w, r, http.StatusConflict, "Only draft or rejected claims can be deleted")
+		return
+	}
+	items, err := app.ExpenseItemRepository.GetExpenseItems(r.Context(), claim.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense items", err)
+		return
+	}
+	if err := app.ExpenseItemRepository.DeleteExpenseItems(r.Context(), claim.ID); err != nil {
+		app.serverError(w, r, "Failed to delete expense items", err)
+		return
+	}
+	if err := app.ExpenseClaimRepository.DeleteExpenseClaim(r.Context(), claim.ID); err != nil {
+		app.serverError(w, r, "Failed to delete expense claim", err)
+		return
+	}
+	for _, i := range items {
+		if i.ReceiptKey != "" {
+			app.Files.Remove(i.ReceiptKey)
+		}
+	}
+	w.Header().Set("HX-Redirect", "/expenses")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// expenseClaim fetches the claim named by the request's id path value
+// and writes the error response when there is none.
+func (app *App) expenseClaim(w http.ResponseWriter, r *http.Request) (*ExpenseClaim, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	claim, err := app.ExpenseClaimRepository.GetExpenseClaimByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense claim", err)
+		return nil, false
+	}
+	if claim == nil {
+		app.clientError(w, r, http.StatusNotFound, "Expense claim not found")
+		return nil, false
+	}
+	return claim, true
+}
+
+// expenseClaimView loads the items and people of a claim. The error
+// response has been written when it returns false.
+func (app *App) expenseClaimView(w http.ResponseWriter, r *http.Request, claim *ExpenseClaim) (ExpenseClaimView, []ExpenseItem, bool) {
+	items, err := app.ExpenseItemRepository.GetExpenseItems(r.Context(), claim.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense items", err)
+		return ExpenseClaimView{}, nil, false
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return ExpenseClaimView{}, nil, false
+	}
+	return expenseClaimViews([]ExpenseClaim{*claim}, items, employees)[0], items, true
+}
+
+// handleExpenseClaim shows a claim with its items and the action it
+// waits for.
+func (app *App) handleExpenseClaim(w http.ResponseWriter, r *http.Request) {
+	claim, ok := app.expenseClaim(w, r)
+	if !ok {
+		return
+	}
+	view, items, ok := app.expenseClaimView(w, r, claim)
+	if !ok {
+		return
+	}
+	viewer := app.viewer(r)
+	if !app.mayViewClaim(viewer, view) {
+		app.clientError(w, r, http.StatusNotFound, "Expense claim not found")
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "expenses",
+		"Claim":      view,
+		"Items":      items,
+		"Categories": expenseCategories,
+		"Editable":   view.Status == "draft" && strings.EqualFold(viewer.User, view.Employee.Email),
+		"MayApprove": app.mayApprove(viewer, view),
+		"IsFinance":  app.isFinance(viewer),
+		"Today":      today(),
+	}
+	app.render(w, r, "expense_claim.html", "", data)
+}
+
+func (app *App) handleAddExpenseItem(w http.ResponseWriter, r *http.Request) {
+	claim, ok := app.expenseClaim(w, r)
+	if !ok || !app.requireClaimant(w, r, claim) {
+		return
+	}
+	if claim.Status != "draft" {
+		app.clientError(w, r, http.StatusConflict, "Only draft claims can be changed")
+		return
+	}
+	if !app.parseUploadForm(w, r) {
+		return
+	}
+	spentOn, err := time.Parse("2006-01-02", r.FormValue("spent_on"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	amount, err := strconv.ParseFloat(r.FormValue("amount"), 64)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid amount")
+		return
+	}
+	item := ExpenseItem{
+		ClaimID:     claim.ID,
+		SpentOn:     spentOn,
+		Category:    r.FormValue("category"),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		Amount:      amount,
+	}
+	if err := item.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, name, ok := app.saveUpload(w, r, "receipt")
+	if !ok {
+		return
+	}
+	item.ReceiptKey, item.ReceiptName = key, name
+	if err := app.ExpenseItemRepository.CreateExpenseItem(r.Context(), &item); err != nil {
+		if key != "" {
+			app.Files.Remove(key)
+		}
+		app.serverError(w, r, "Failed to add expense item", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/expenses/claims/%d", claim.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteExpenseItem(w http.ResponseWriter, r *http.Request) {
+	claim, ok := app.expenseClaim(w, r)
+	if !ok || !app.requireClaimant(w, r, claim) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	item, err := app.ExpenseItemRepository.GetExpenseItemByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense item", err)
+		return
+	}
+	if item == nil || item.ClaimID != claim.ID {
+		app.clientError(w, r, http.StatusNotFound, "Expense item not found")
+		return
+	}
+	if claim.Status != "draft" {
+		app.clientError(w, r, http.StatusConflict, "Only draft claims can be changed")
+		return
+	}
+	if err := app.ExpenseItemRepository.DeleteExpenseItem(r.Context(), item.ID); err != nil {
+		app.serverError(w, r, "Failed to delete expense item", err)
+		return
+	}
+	if item.ReceiptKey != "" {
+		app.Files.Remove(item.ReceiptKey)
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/expenses/claims/%d", claim.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleSubmitExpenseClaim sends a draft to the employee's manager.
+func (app *App) handleSubmitExpenseClaim(w http.ResponseWriter, r *http.Request) {
+	claim, ok := app.expenseClaim(w, r)
+	if !ok || !app.requireClaimant(w, r, claim) {
+		return
+	}
+	if claim.Status != "draft" {
+		app.clientError(w, r, http.StatusConflict, "Claim was already submitted")
+		return
+	}
+	items, err := app.ExpenseItemRepository.GetExpenseItems(r.Context(), claim.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense items", err)
+		return
+	}
+	if len(items) == 0 {
+		app.clientError(w, r, http.StatusBadRequest, "Add at least one item before submitting")
+		return
+	}
+
+	claim.Status, claim.SubmittedAt = "submitted", time.Now()
+	if err := app.ExpenseClaimRepository.UpdateExpenseClaim(r.Context(), claim); err != nil {
+		app.serverError(w, r, "Failed to update expense claim", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/expenses/claims/%d", claim.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleReviewExpenseClaim gives or refuses the approval a claim waits
+// for: the manager's first, then finance's.
+func (app *App) handleReviewExpenseClaim(w http.ResponseWriter, r *http.Request) {
+	decision := r.PathValue("decision")
+	if decision != "approve" && decision != "reject" {
+		http.NotFound(w, r)
+		return
+	}
+	claim, ok := app.expenseClaim(w, r)
+	if !ok {
+		return
+	}
+	if claim.Status != "submitted" && claim.Status != "manager_approved" {
+		app.clientError(w, r, http.StatusConflict, "Claim is not awaiting approval")
+		return
+	}
+	view, _, ok := app.expenseClaimView(w, r, claim)
+	if !ok {
+		return
+	}
+	viewer := app.viewer(r)
+	if !app.mayApprove(viewer, view) {
+		app.clientError(w, r, http.StatusForbidden, "You can't approve this claim")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case decision == "reject":
+		reason := strings.TrimSpace(r.FormValue("reason"))
+		if reason == "" {
+			app.clientError(w, r, http.StatusBadRequest, "A reason is required to reject a claim")
+			return
+		}
+		claim.Status, claim.RejectedBy, claim.RejectionReason = "rejected", viewer.User, reason
+	case claim.Status == "submitted":
+		claim.Status, claim.ManagerApprovedBy, claim.ManagerApprovedAt = "manager_approved", viewer.User, now
+	default:
+		claim.Status, claim.ApprovedBy, claim.ApprovedAt = "approved", viewer.User, now
+	}
+	if err := app.ExpenseClaimRepository.UpdateExpenseClaim(r.Context(), claim); err != nil {
+		app.serverError(w, r, "Failed to update expense claim", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/expenses/claims/%d", claim.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleReimburseExpenseClaim records that finance paid an approved claim
+// back.
+func (app *App) handleReimburseExpenseClaim(w http.ResponseWriter, r *http.Request) {
+	claim, ok := app.expenseClaim(w, r)
+	if !ok {
+		return
+	}
+	if !app.isFinance(app.viewer(r)) {
+		app.clientError(w, r, http.StatusForbidden, "Only finance can do this")
+		return
+	}
+	if claim.Status != "approved" {
+		app.clientError(w, r, http.StatusConflict, "Only approved claims can be reimbursed")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	reimbursedOn, err := formDate(r, "reimbursed_on")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	if reimbursedOn.Before(civilDate(claim.ApprovedAt.Local())) {
+		app.clientError(w, r, http.StatusBadRequest, "The reimbursement date must not be before the approval date")
+		return
+	}
+
+	claim.Status, claim.ReimbursedOn = "reimbursed", reimbursedOn
+	if err := app.ExpenseClaimRepository.UpdateExpenseClaim(r.Context(), claim); err != nil {
+		app.serverError(w, r, "Failed to update expense claim", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/expenses/claims/%d", claim.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleExpenseReceipt(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	item, err := app.ExpenseItemRepository.GetExpenseItemByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense item", err)
+		return
+	}
+	if item == nil || item.ReceiptKey == "" {
+		app.clientError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	claim, err := app.ExpenseClaimRepository.GetExpenseClaimByID(r.Context(), item.ClaimID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense claim", err)
+		return
+	}
+	if claim == nil {
+		app.clientError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	view, _, ok := app.expenseClaimView(w, r, claim)
+	if !ok {
+		return
+	}
+	if !app.mayViewClaim(app.viewer(r), view) {
+		app.clientError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	app.serveUpload(w, r, item.ReceiptKey, item.ReceiptName)
+}
+
+// handleExportExpenses exports the claims finance approved in a payroll
+// period, given as YYYY-MM and defaulting to the current month, for
+// payroll to pay back.
+func (app *App) handleExportExpenses(w http.ResponseWriter, r *http.Request) {
+	if v := app.viewer(r); !app.isFinance(v) && !app.isHR(v) {
+		app.clientError(w, r, http.StatusForbidden, "Only finance or HR can do this")
+		return
+	}
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = time.Now().Format("2006-01")
+	}
+	if _, err := time.Parse("2006-01", period); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid period")
+		return
+	}
+	claims, err := app.ExpenseClaimRepository.GetExpenseClaims(r.Context(), 0, "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense claims", err)
+		return
+	}
+	claims = slices.DeleteFunc(claims, func(c ExpenseClaim) bool { return !c.Paid() || payrollPeriod(c) != period })
+	items, err := app.ExpenseItemRepository.GetExpenseItems(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch expense items", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	headers := []string{"Claim ID", "Employee ID", "Employee", "Department", "Title", "Currency", "Total", "Approved On", "Approved By", "Status", "Reimbursed On"}
+	mapper := func(c ExpenseClaimView) []string {
+		reimbursedOn := ""
+		if !c.ReimbursedOn.IsZero() {
+			reimbursedOn = c.ReimbursedOn.Format("2006-01-02")
+		}
+		return []string{
+			fmt.Sprintf("%d", c.ID),
+			fmt.Sprintf("%d", c.Employee.ID),
+			c.Employee.FirstName + " " + c.Employee.LastName,
+			departments[c.Employee.DepartmentID].Name,
+			c.Title,
+			c.Currency,
+			fmt.Sprintf("%.2f", c.Total),
+			c.ApprovedAt.Local().Format("2006-01-02"),
+			c.ApprovedBy,
+			c.Status,
+			reimbursedOn,
+		}
+	}
+
+	writeExport(w, r, "Expenses", expenseClaimViews(claims, items, employees), headers, mapper)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestMayApprove(t *testing.T) {
+	cfg := defaultConfig()
+	app := &App{Config: &cfg}
+	claim := ExpenseClaimView{
+		ExpenseClaim: ExpenseClaim{Status: "submitted"},
+		Employee:     Employee{Email: "omar@example.com"},
+		Manager:      Employee{Email: "lea@example.com"},
+	}
+	tests := []struct {
+		name   string
+		status string
+		viewer Viewer
+		want   bool
+	}{
+		{"manager", "submitted", Viewer{User: "Lea@example.com"}, true},
+		{"hr stands in", "submitted", Viewer{User: "hana", Groups: []string{"hr"}}, true},
+		{"finance before the manager", "submitted", Viewer{User: "fatima", Groups: []string{"finance"}}, false},
+		{"own claim", "submitted", Viewer{User: "omar@example.com", Groups: []string{"hr"}}, false},
+		{"anonymous", "submitted", Viewer{Groups: []string{"hr"}}, false},
+		{"finance", "manager_approved", Viewer{User: "fatima", Groups: []string{"finance"}}, true},
+		{"manager again", "manager_approved", Viewer{User: "lea@example.com", Groups: []string{"finance"}}, false},
+		{"manager again in other case", "manager_approved", Viewer{User: "LEA@example.com", Groups: []string{"finance"}}, false},
+		{"approved", "approved", Viewer{User: "fatima", Groups: []string{"finance"}}, false},
+	}
+	for _, tt := range tests {
+		c := claim
+		c.Status = tt.status
+		if c.Status == "manager_approved" {
+			c.ManagerApprovedBy = "lea@example.com"
+		}
+		if got := app.mayApprove(tt.viewer, c); got != tt.want {
+			t.Errorf("%s: mayApprove() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpenses(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	me := map[string]string{"X-Forwarded-User": "Omar@Example.com"}
+	lea := map[string]string{"X-Forwarded-User": "lea@example.com"}
+	sami := map[string]string{"X-Forwarded-User": "sami@example.com"}
+	finance := map[string]string{"X-Forwarded-User": "fatima", "X-Forwarded-Groups": "finance"}
+
+	manager := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &manager); err != nil {
+		t.Fatal(err)
+	}
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active", ManagerID: manager.ID}
+	colleague := Employee{FirstName: "Sami", LastName: "Nasr", Email: "sami@example.com", Status: "active"}
+	for _, e := range []*Employee{&omar, &colleague} {
+		if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The claimant is the viewer and the approver whom they report to,
+	// whatever the form says.
+	form := url.Values{"employee_id": {strconv.Itoa(colleague.ID)}, "manager_id": {strconv.Itoa(colleague.ID)}, "title": {"Client visit"}, "currency": {"eur"}}
+	if w := send(h, "POST", "/expenses/add", form, map[string]string{"X-Forwarded-User": "nobody@example.com"}); w.Code != http.StatusForbidden {
+		t.Errorf("add as someone who isn't an employee = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", "/expenses/add", form, sami); w.Code != http.StatusConflict {
+		t.Errorf("add without a manager on file = %d, want 409", w.Code)
+	}
+	if w := send(h, "POST", "/expenses/add", url.Values{"title": {"X"}, "currency": {"EURO"}}, me); w.Code != http.StatusBadRequest {
+		t.Errorf("add with an invalid currency = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", "/expenses/add", form, me); w.Code != http.StatusSeeOther {
+		t.Fatalf("add = %d %s", w.Code, w.Body)
+	}
+	claims, _ := repos.ExpenseClaims.GetExpenseClaims(ctx, omar.ID, "")
+	if len(claims) != 1 || claims[0].Currency != "EUR" || claims[0].Status != "draft" || claims[0].ManagerID != manager.ID {
+		t.Fatalf("claims = %+v, want a EUR draft for Lea to approve", claims)
+	}
+	claim := "/expenses/claims/" + strconv.Itoa(claims[0].ID)
+	if w := send(h, "GET", claim, nil, sami); w.Code != http.StatusNotFound {
+		t.Errorf("GET someone else's claim = %d, want 404", w.Code)
+	}
+	if w := send(h, "POST", claim+"/items", url.Values{"spent_on": {"2025-03-03"}, "category": {"meals"}, "amount": {"10"}}, lea); w.Code != http.StatusForbidden {
+		t.Errorf("add an item to someone else's claim = %d, want 403", w.Code)
+	}
+
+	if w := send(h, "POST", claim+"/submit", nil, me); w.Code != http.StatusBadRequest {
+		t.Errorf("submit without items = %d, want 400", w.Code)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	if w := send(h, "POST", claim+"/items", url.Values{"spent_on": {tomorrow}, "category": {"meals"}, "amount": {"10"}}, me); w.Code != http.StatusBadRequest {
+		t.Errorf("add an item in the future = %d, want 400", w.Code)
+	}
+	for _, amount := range []string{"-5", "NaN", "Inf"} {
+		if w := send(h, "POST", claim+"/items", url.Values{"spent_on": {"2025-03-03"}, "category": {"meals"}, "amount": {amount}}, me); w.Code != http.StatusBadRequest {
+			t.Errorf("add an item of %s = %d, want 400", amount, w.Code)
+		}
+	}
+	hotel := url.Values{"spent_on": {"2025-03-04"}, "category": {"lodging"}, "description": {"Hotel"}, "amount": {"240.50"}}
+	if w := sendFile(h, claim+"/items", hotel, "receipt", "hotel.pdf", "%PDF-1.4", me); w.Code != http.StatusSeeOther {
+		t.Fatalf("add item = %d %s", w.Code, w.Body)
+	}
+	taxi := url.Values{"spent_on": {"2025-03-03"}, "category": {"transport"}, "amount": {"35"}}
+	if w := send(h, "POST", claim+"/items", taxi, me); w.Code != http.StatusSeeOther {
+		t.Fatalf("add item = %d %s", w.Code, w.Body)
+	}
+	items, _ := repos.ExpenseItems.GetExpenseItems(ctx, claims[0].ID)
+	if len(items) != 2 || items[1].ReceiptName != "hotel.pdf" {
+		t.Fatalf("items = %+v, want the taxi and the hotel with its receipt", items)
+	}
+	if w := send(h, "GET", "/expenses/receipts/"+strconv.Itoa(items[1].ID), nil, me); w.Code != http.StatusOK || w.Body.String() != "%PDF-1.4" {
+		t.Errorf("receipt = %d %q", w.Code, w.Body)
+	}
+	if w := send(h, "GET", "/expenses/receipts/"+strconv.Itoa(items[1].ID), nil, sami); w.Code != http.StatusNotFound {
+		t.Errorf("receipt of someone else's claim = %d, want 404", w.Code)
+	}
+	if w := send(h, "DELETE", claim+"/items?id="+strconv.Itoa(items[0].ID), nil, me); w.Code != http.StatusSeeOther {
+		t.Fatalf("delete item = %d %s", w.Code, w.Body)
+	}
+
+	if w := send(h, "POST", claim+"/submit", nil, me); w.Code != http.StatusSeeOther {
+		t.Fatalf("submit = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", claim+"/items", taxi, me); w.Code != http.StatusConflict {
+		t.Errorf("add item after submitting = %d, want 409", w.Code)
+	}
+	if w := send(h, "DELETE", "/expenses/delete?id="+strconv.Itoa(claims[0].ID), nil, me); w.Code != http.StatusConflict {
+		t.Errorf("delete a submitted claim = %d, want 409", w.Code)
+	}
+
+	// Finance can't approve before the manager, nor can the employee.
+	if w := send(h, "POST", claim+"/approve", nil, finance); w.Code != http.StatusForbidden {
+		t.Errorf("finance approval first = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", claim+"/approve", nil, map[string]string{"X-Forwarded-User": "omar@example.com"}); w.Code != http.StatusForbidden {
+		t.Errorf("approving one's own claim = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", claim+"/reject", nil, lea); w.Code != http.StatusBadRequest {
+		t.Errorf("reject without a reason = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", claim+"/approve", nil, lea); w.Code != http.StatusSeeOther {
+		t.Fatalf("manager approval = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", claim+"/approve", nil, lea); w.Code != http.StatusForbidden {
+		t.Errorf("manager approving twice = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", claim+"/reimburse", nil, finance); w.Code != http.StatusConflict {
+		t.Errorf("reimburse before the finance approval = %d, want 409", w.Code)
+	}
+	if w := send(h, "POST", claim+"/approve", nil, finance); w.Code != http.StatusSeeOther {
+		t.Fatalf("finance approval = %d %s", w.Code, w.Body)
+	}
+	if got, _ := repos.ExpenseClaims.GetExpenseClaimByID(ctx, claims[0].ID); got == nil || got.Status != "approved" || got.ManagerApprovedBy != "lea@example.com" || got.ApprovedBy != "fatima" {
+		t.Fatalf("claim = %+v, want approved by lea then fatima", got)
+	}
+	if w := send(h, "POST", claim+"/approve", nil, finance); w.Code != http.StatusConflict {
+		t.Errorf("approve again = %d, want 409", w.Code)
+	}
+
+	if w := send(h, "GET", "/expenses/export", nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("export without finance = %d, want 403", w.Code)
+	}
+	w := send(h, "GET", "/expenses/export?period="+time.Now().Format("2006-01"), nil, finance)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export = %d %s", w.Code, w.Body)
+	}
+	f, err := excelize.OpenReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := f.GetRows("Sheet1")
+	if len(rows) != 2 || rows[1][2] != "Omar Khalil" || rows[1][5] != "EUR" || rows[1][6] != "240.50" || rows[1][8] != "fatima" {
+		t.Errorf("export rows = %v", rows)
+	}
+	w = send(h, "GET", "/expenses/export?period=2001-01", nil, finance)
+	if f, err = excelize.OpenReader(w.Body); err != nil {
+		t.Fatal(err)
+	}
+	if rows, _ := f.GetRows("Sheet1"); len(rows) != 1 {
+		t.Errorf("export of another period = %v, want only the headers", rows)
+	}
+
+	if w := send(h, "POST", claim+"/reimburse", url.Values{"reimbursed_on": {"2001-01-01"}}, finance); w.Code != http.StatusBadRequest {
+		t.Errorf("reimburse before the approval = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", claim+"/reimburse", nil, lea); w.Code != http.StatusForbidden {
+		t.Errorf("reimburse without finance = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", claim+"/reimburse", nil, finance); w.Code != http.StatusSeeOther {
+		t.Fatalf("reimburse = %d %s", w.Code, w.Body)
+	}
+
+	for _, viewer := range []map[string]string{me, lea, finance} {
+		if w := send(h, "GET", "/expenses?status=reimbursed", nil, viewer); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Client visit") {
+			t.Errorf("list as %v = %d, want the claim", viewer, w.Code)
+		}
+	}
+	if w := send(h, "GET", "/expenses", nil, sami); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Client visit") {
+		t.Errorf("list as a colleague = %d, want no claim of others", w.Code)
+	}
+	for _, page := range []string{"/expenses", "/expenses/add", claim} {
+		if w := send(h, "GET", page, nil, me); w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", page, w.Code)
+		}
+	}
+}
+
+func TestEmployeeFormReportingLine(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &lea); err != nil {
+		t.Fatal(err)
+	}
+	update := func(managerID int) int {
+		form := url.Values{"first_name": {"Lea"}, "last_name": {"Haddad"}, "email": {"lea@example.com"}, "status": {"active"}, "manager_id": {strconv.Itoa(managerID)}}
+		return send(h, "PUT", "/employees/update/"+strconv.Itoa(lea.ID), form, hrHeaders).Code
+	}
+	if code := update(lea.ID); code != http.StatusBadRequest {
+		t.Errorf("report to themselves = %d, want 400", code)
+	}
+	if code := update(9999); code != http.StatusBadRequest {
+		t.Errorf("report to an unknown employee = %d, want 400", code)
+	}
+	form := url.Values{"first_name": {"Omar"}, "last_name": {"Khalil"}, "email": {"omar@example.com"}, "hire_date": {"2025-01-06"}, "status": {"active"}, "manager_id": {strconv.Itoa(lea.ID)}}
+	if w := send(h, "POST", "/employees/add", form, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add = %d %s", w.Code, w.Body)
+	}
+	if omar, _ := repos.Employees.GetEmployeeByEmail(ctx, "omar@example.com"); omar == nil || omar.ManagerID != lea.ID {
+		t.Errorf("employee = %+v, want reporting to Lea", omar)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/xuri/excelize/v2"
@@ -15,7 +16,7 @@ func ExportToExcel[T any](w io.Writer, data []T, headers []string, mapper func(T
 	f := excelize.NewFile()
 	defer func() {
 		if err := f.Close(); err != nil {
-			fmt.Printf("Error closing excel file: %v\n", err)
+			slog.Error("closing excel file", "err", err)
 		}
 	}()
 
@@ -0,0 +1,704 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var quarterPattern = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+
+// quarterOf returns the quarter t falls in, e.g. 2025-Q1.
+func quarterOf(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// parseQuarter returns the first day of a quarter such as 2025-Q1, in the
+// local zone.
+func parseQuarter(s string) (time.Time, error) {
+	m := quarterPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, errors.New("Invalid quarter")
+	}
+	year, _ := strconv.Atoi(m[1])
+	q, _ := strconv.Atoi(m[2])
+	return time.Date(year, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, time.Local), nil
+}
+
+// quarterElapsed returns how much of the quarter starting at start has
+// passed at now, from 0 to 100.
+func quarterElapsed(start, now time.Time) float64 {
+	end := start.AddDate(0, 3, 0)
+	switch {
+	case now.Before(start):
+		return 0
+	case !now.Before(end):
+		return 100
+	}
+	return math.Round(100 * float64(now.Sub(start)) / float64(end.Sub(start)))
+}
+
+// Progress returns how far the key result has moved from its start value
+// to its target, from 0 to 100. Targets below the start value, such as
+// fewer open tickets, count down.
+func (kr KeyResult) Progress() float64 {
+	if kr.TargetValue == kr.StartValue {
+		if kr.CurrentValue == kr.TargetValue {
+			return 100
+		}
+		return 0
+	}
+	p := 100 * (kr.CurrentValue - kr.StartValue) / (kr.TargetValue - kr.StartValue)
+	return math.Round(min(max(p, 0), 100))
+}
+
+func (kr *KeyResult) validate() error {
+	if strings.TrimSpace(kr.Title) == "" {
+		return errors.New("Title is required")
+	}
+	if kr.TargetValue == kr.StartValue {
+		return errors.New("Target must differ from the start value")
+	}
+	return nil
+}
+
+// objectiveProgress averages the progress of an objective's key results.
+func objectiveProgress(results []KeyResult) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, kr := range results {
+		sum += kr.Progress()
+	}
+	return math.Round(sum / float64(len(results)))
+}
+
+func (o *Objective) validate() error {
+	if strings.TrimSpace(o.Title) == "" {
+		return errors.New("Title is required")
+	}
+	if _, err := parseQuarter(o.Quarter); err != nil {
+		return err
+	}
+	if (o.EmployeeID == 0) == (o.DepartmentID == 0) {
+		return errors.New("Pick an owner")
+	}
+	return nil
+}
+
+// checkAlignment reports whether o may align with its parent: only
+// department objectives of the same quarter can be aligned with, and
+// never in a circle.
+func checkAlignment(o Objective, objectives map[int]Objective) error {
+	if o.ParentID == 0 {
+		return nil
+	}
+	parent, ok := objectives[o.ParentID]
+	if !ok {
+		return errors.New("Aligned objective not found")
+	}
+	if parent.DepartmentID == 0 {
+		return errors.New("Objectives can only align with department objectives")
+	}
+	if parent.Quarter != o.Quarter {
+		return errors.New("Aligned objective must be in the same quarter")
+	}
+	for id, seen := o.ParentID, 0; id != 0 && seen <= len(objectives); id, seen = objectives[id].ParentID, seen+1 {
+		if id == o.ID {
+			return errors.New("Objectives can't align in a circle")
+		}
+	}
+	return nil
+}
+
+// ObjectiveView is an objective on the goals pages, with its owner's name,
+// its progress and the objectives aligned with it.
+type ObjectiveView struct {
+	Objective
+	Owner      string
+	Progress   float64
+	KeyResults int
+	Children   []ObjectiveView
+}
+
+// goalOwners names the owners of objectives.
+type goalOwners struct {
+	employees   map[int]Employee
+	departments map[int]Department
+}
+
+func (g goalOwners) name(o Objective) string {
+	if o.EmployeeID != 0 {
+		e := g.employees[o.EmployeeID]
+		return e.FirstName + " " + e.LastName
+	}
+	return g.departments[o.DepartmentID].Name
+}
+
+// objectiveTree arranges objectives under the objectives they align with.
+// Objectives whose parent is not in the list become roots.
+func objectiveTree(objectives []Objective, results []KeyResult, owners goalOwners) []ObjectiveView {
+	byObjective := make(map[int][]KeyResult)
+	for _, kr := range results {
+		byObjective[kr.ObjectiveID] = append(byObjective[kr.ObjectiveID], kr)
+	}
+	present := make(map[int]bool, len(objectives))
+	for _, o := range objectives {
+		present[o.ID] = true
+	}
+	var build func(parent int, depth int) []ObjectiveView
+	build = func(parent int, depth int) []ObjectiveView {
+		var views []ObjectiveView
+		for _, o := range objectives {
+			isRoot := o.ParentID == 0 || !present[o.ParentID]
+			if (parent == 0 && !isRoot) || (parent != 0 && o.ParentID != parent) {
+				continue
+			}
+			v := ObjectiveView{
+				Objective:  o,
+				Owner:      owners.name(o),
+				Progress:   objectiveProgress(byObjective[o.ID]),
+				KeyResults: len(byObjective[o.ID]),
+			}
+			if depth < len(objectives) {
+				v.Children = build(o.ID, depth+1)
+			}
+			views = append(views, v)
+		}
+		return views
+	}
+	return build(0, 0)
+}
+
+// DepartmentRollup sums up one department's objectives of a quarter: its
+// own and those of its employees.
+type DepartmentRollup struct {
+	Department         Department
+	Objectives         []ObjectiveView // the department's own objectives
+	EmployeeObjectives int
+	Progress           float64 // average over all of them
+	Completed          int
+	Behind             int // more than 25 points behind the time elapsed
+}
+
+// behindBy is how far an objective's progress can trail the elapsed part
+// of the quarter before the roll-up counts it as behind.
+const behindBy = 25
+
+// rollupGoals groups a quarter's objectives by department. Employees'
+// objectives count towards the department they are in; departments with
+// no objectives are left out.
+func rollupGoals(departments []Department, employees map[int]Employee, objectives []Objective, results []KeyResult, owners goalOwners, elapsed float64) []DepartmentRollup {
+	byObjective := make(map[int][]KeyResult)
+	for _, kr := range results {
+		byObjective[kr.ObjectiveID] = append(byObjective[kr.ObjectiveID], kr)
+	}
+	var rollups []DepartmentRollup
+	for _, d := range departments {
+		r := DepartmentRollup{Department: d}
+		var sum float64
+		var n int
+		for _, o := range objectives {
+			deptID := o.DepartmentID
+			if o.EmployeeID != 0 {
+				deptID = employees[o.EmployeeID].DepartmentID
+			}
+			if deptID != d.ID {
+				continue
+			}
+			p := objectiveProgress(byObjective[o.ID])
+			if o.EmployeeID != 0 {
+				r.EmployeeObjectives++
+			} else {
+				r.Objectives = append(r.Objectives, ObjectiveView{Objective: o, Owner: owners.name(o), Progress: p, KeyResults: len(byObjective[o.ID])})
+			}
+			sum += p
+			n++
+			if p >= 100 {
+				r.Completed++
+			} else if p+behindBy < elapsed {
+				r.Behind++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		r.Progress = math.Round(sum / float64(n))
+		rollups = append(rollups, r)
+	}
+	return rollups
+}
+
+// goalQuarter reads the quarter query parameter, defaulting to the current
+// quarter.
+func goalQuarter(r *http.Request) (string, time.Time, error) {
+	q := r.URL.Query().Get("quarter")
+	if q == "" {
+		q = quarterOf(time.Now())
+	}
+	start, err := parseQuarter(q)
+	return q, start, err
+}
+
+func (app *App) goalOwners(ctx context.Context) (goalOwners, []Employee, error) {
+	employees, byID, err := app.employeesByID(ctx)
+	if err != nil {
+		return goalOwners{}, nil, err
+	}
+	departments, err := app.departmentsByID(ctx)
+	if err != nil {
+		return goalOwners{}, nil, err
+	}
+	return goalOwners{employees: byID, departments: departments}, employees, nil
+}
+
+func (app *App) handleGoals(w http.ResponseWriter, r *http.Request) {
+	quarter, start, err := goalQuarter(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	objectives, err := app.ObjectiveRepository.GetObjectives(r.Context(), quarter)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch objectives", err)
+		return
+	}
+	results, err := app.KeyResultRepository.GetKeyResults(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch key results", err)
+		return
+	}
+	owners, _, err := app.goalOwners(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch owners", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage": "goals",
+		"Quarter":    quarter,
+		"Previous":   quarterOf(start.AddDate(0, -3, 0)),
+		"Next":       quarterOf(start.AddDate(0, 3, 0)),
+		"Objectives": objectiveTree(objectives, results, owners),
+	}
+	app.render(w, r, "goals.html", "", data)
+}
+
+func (app *App) handleGoalsRollup(w http.ResponseWriter, r *http.Request) {
+	quarter, start, err := goalQuarter(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	objectives, err := app.ObjectiveRepository.GetObjectives(r.Context(), quarter)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch objectives", err)
+		return
+	}
+	results, err := app.KeyResultRepository.GetKeyResults(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch key results", err)
+		return
+	}
+	owners, _, err := app.goalOwners(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch owners", err)
+		return
+	}
+	departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	elapsed := quarterElapsed(start, time.Now())
+	data := map[string]any{
+		"ActivePage": "goals",
+		"Quarter":    quarter,
+		"Previous":   quarterOf(start.AddDate(0, -3, 0)),
+		"Next":       quarterOf(start.AddDate(0, 3, 0)),
+		"Elapsed":    elapsed,
+		"Rollups":    rollupGoals(departments, owners.employees, objectives, results, owners, elapsed),
+	}
+	app.render(w, r, "goals_rollup.html", "", data)
+}
+
+// objectiveForm reads the fields of the add and update objective forms.
+// The owner field holds "employee:ID" or "department:ID".
+func objectiveForm(r *http.Request) Objective {
+	o := Objective{
+		Title:       strings.TrimSpace(r.FormValue("title")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		Quarter:     strings.TrimSpace(r.FormValue("quarter")),
+	}
+	o.ParentID, _ = strconv.Atoi(r.FormValue("parent_id"))
+	kind, id, _ := strings.Cut(r.FormValue("owner"), ":")
+	switch n, _ := strconv.Atoi(id); kind {
+	case "employee":
+		o.EmployeeID = n
+	case "department":
+		o.DepartmentID = n
+	}
+	return o
+}
+
+// objectiveFormData returns the template data shared by the add and
+// update objective pages: the possible owners and the department
+// objectives of the quarter to align with.
+func (app *App) objectiveFormData(ctx context.Context, o Objective) (map[string]any, error) {
+	employees, err := app.EmployeeRepository.GetEmployees(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	departments, err := app.DepartmentRepository.GetDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	objectives, err := app.ObjectiveRepository.GetObjectives(ctx, o.Quarter)
+	if err != nil {
+		return nil, err
+	}
+	var parents []Objective
+	for _, p := range objectives {
+		if p.DepartmentID != 0 && p.ID != o.ID {
+			parents = append(parents, p)
+		}
+	}
+	return map[string]any{
+		"ActivePage":  "goals",
+		"Objective":   o,
+		"Employees":   employees,
+		"Departments": departments,
+		"Parents":     parents,
+	}, nil
+}
+
+// validObjective validates o, including its alignment, and writes the
+// client error when it is not valid.
+func (app *App) validObjective(w http.ResponseWriter, r *http.Request, o Objective) bool {
+	if err := o.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return false
+	}
+	objectives, err := app.ObjectiveRepository.GetObjectives(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch objectives", err)
+		return false
+	}
+	byID := make(map[int]Objective, len(objectives))
+	for _, obj := range objectives {
+		byID[obj.ID] = obj
+	}
+	if err := checkAlignment(o, byID); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func (app *App) handleAddObjective(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		o := Objective{Quarter: r.URL.Query().Get("quarter")}
+		if _, err := parseQuarter(o.Quarter); err != nil {
+			o.Quarter = quarterOf(time.Now())
+		}
+		o.ParentID, _ = strconv.Atoi(r.URL.Query().Get("parent"))
+		data, err := app.objectiveFormData(r.Context(), o)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch objectives", err)
+			return
+		}
+		app.render(w, r, "add_objective.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	objective := objectiveForm(r)
+	if !app.validObjective(w, r, objective) {
+		return
+	}
+	if err := app.ObjectiveRepository.CreateObjective(r.Context(), &objective); err != nil {
+		app.serverError(w, r, "Failed to add objective", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/goals/objectives/%d", objective.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleUpdateObjective(w http.ResponseWriter, r *http.Request) {
+	objective, ok := app.objective(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		data, err := app.objectiveFormData(r.Context(), *objective)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch objectives", err)
+			return
+		}
+		app.render(w, r, "update_objective.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated := objectiveForm(r)
+	updated.ID = objective.ID
+	if !app.validObjective(w, r, updated) {
+		return
+	}
+	if err := app.ObjectiveRepository.UpdateObjective(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update objective", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/goals/objectives/%d", updated.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteObjective deletes an objective with its key results and
+// their check-ins. Objectives others align with are kept until those are
+// moved or deleted.
+func (app *App) handleDeleteObjective(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	objectives, err := app.ObjectiveRepository.GetObjectives(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch objectives", err)
+		return
+	}
+	for _, o := range objectives {
+		if o.ParentID == id {
+			app.clientError(w, r, http.StatusConflict, "Other objectives align with this one")
+			return
+		}
+	}
+
+	results, err := app.KeyResultRepository.GetKeyResults(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch key results", err)
+		return
+	}
+	for _, kr := range results {
+		if !app.deleteKeyResult(w, r, kr.ID) {
+			return
+		}
+	}
+	if err := app.ObjectiveRepository.DeleteObjective(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete objective", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/goals")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) deleteKeyResult(w http.ResponseWriter, r *http.Request, id int) bool {
+	if err := app.CheckInRepository.DeleteCheckIns(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete check-ins", err)
+		return false
+	}
+	if err := app.KeyResultRepository.DeleteKeyResult(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete key result", err)
+		return false
+	}
+	return true
+}
+
+// objective fetches the objective named by the request's id path value
+// and writes the error response when there is none.
+func (app *App) objective(w http.ResponseWriter, r *http.Request) (*Objective, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	objective, err := app.ObjectiveRepository.GetObjectiveByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch objective", err)
+		return nil, false
+	}
+	if objective == nil {
+		app.clientError(w, r, http.StatusNotFound, "Objective not found")
+		return nil, false
+	}
+	return objective, true
+}
+
+// KeyResultView is a key result on the objective page with its history
+// of check-ins, newest first.
+type KeyResultView struct {
+	KeyResult
+	CheckIns []CheckIn
+}
+
+func (app *App) handleObjective(w http.ResponseWriter, r *http.Request) {
+	objective, ok := app.objective(w, r)
+	if !ok {
+		return
+	}
+	results, err := app.KeyResultRepository.GetKeyResults(r.Context(), objective.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch key results", err)
+		return
+	}
+	views := make([]KeyResultView, len(results))
+	for i, kr := range results {
+		checkIns, err := app.CheckInRepository.GetCheckIns(r.Context(), kr.ID)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch check-ins", err)
+			return
+		}
+		views[i] = KeyResultView{KeyResult: kr, CheckIns: checkIns}
+	}
+	owners, _, err := app.goalOwners(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch owners", err)
+		return
+	}
+	quarter, err := app.ObjectiveRepository.GetObjectives(r.Context(), objective.Quarter)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch objectives", err)
+		return
+	}
+	var parent *Objective
+	var aligned []Objective
+	for _, o := range quarter {
+		if o.ID == objective.ParentID {
+			parent = &o
+		}
+		if o.ParentID == objective.ID {
+			aligned = append(aligned, o)
+		}
+	}
+
+	data := map[string]any{
+		"ActivePage": "goals",
+		"Objective":  objective,
+		"Owner":      owners.name(*objective),
+		"Progress":   objectiveProgress(results),
+		"KeyResults": views,
+		"Parent":     parent,
+		"Aligned":    aligned,
+	}
+	app.render(w, r, "objective.html", "", data)
+}
+
+func (app *App) handleAddKeyResult(w http.ResponseWriter, r *http.Request) {
+	objective, ok := app.objective(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	start, err1 := strconv.ParseFloat(r.FormValue("start_value"), 64)
+	target, err2 := strconv.ParseFloat(r.FormValue("target_value"), 64)
+	if err1 != nil || err2 != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid value")
+		return
+	}
+	kr := KeyResult{
+		ObjectiveID:  objective.ID,
+		Title:        strings.TrimSpace(r.FormValue("title")),
+		Unit:         strings.TrimSpace(r.FormValue("unit")),
+		StartValue:   start,
+		TargetValue:  target,
+		CurrentValue: start,
+	}
+	if err := kr.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.KeyResultRepository.CreateKeyResult(r.Context(), &kr); err != nil {
+		app.serverError(w, r, "Failed to add key result", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/goals/objectives/%d", objective.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteKeyResult(w http.ResponseWriter, r *http.Request) {
+	objective, ok := app.objective(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	if !app.deleteKeyResult(w, r, id) {
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/goals/objectives/%d", objective.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleCheckIn records the current value of a key result with a comment.
+func (app *App) handleCheckIn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	value, err := strconv.ParseFloat(r.FormValue("value"), 64)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid value")
+		return
+	}
+	kr, err := app.KeyResultRepository.GetKeyResultByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch key result", err)
+		return
+	}
+	if kr == nil {
+		app.clientError(w, r, http.StatusNotFound, "Key result not found")
+		return
+	}
+
+	checkIn := CheckIn{KeyResultID: kr.ID, Value: value, Comment: strings.TrimSpace(r.FormValue("comment"))}
+	if err := app.CheckInRepository.CreateCheckIn(r.Context(), &checkIn); err != nil {
+		app.serverError(w, r, "Failed to add check-in", err)
+		return
+	}
+	kr.CurrentValue = value
+	if err := app.KeyResultRepository.UpdateKeyResult(r.Context(), kr); err != nil {
+		app.serverError(w, r, "Failed to update key result", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/goals/objectives/%d", kr.ObjectiveID))
+	w.WriteHeader(http.StatusSeeOther)
+}
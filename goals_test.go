@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyResultProgress(t *testing.T) {
+	tests := []struct {
+		kr   KeyResult
+		want float64
+	}{
+		{KeyResult{StartValue: 0, TargetValue: 10, CurrentValue: 5}, 50},
+		{KeyResult{StartValue: 24, TargetValue: 4, CurrentValue: 14}, 50},
+		{KeyResult{StartValue: 24, TargetValue: 4, CurrentValue: 30}, 0},
+		{KeyResult{StartValue: 0, TargetValue: 10, CurrentValue: 12}, 100},
+		{KeyResult{StartValue: 5, TargetValue: 5, CurrentValue: 5}, 100},
+	}
+	for _, tt := range tests {
+		if got := tt.kr.Progress(); got != tt.want {
+			t.Errorf("%+v.Progress() = %v, want %v", tt.kr, got, tt.want)
+		}
+	}
+	if got := objectiveProgress([]KeyResult{tests[0].kr, tests[3].kr}); got != 75 {
+		t.Errorf("objectiveProgress() = %v, want 75", got)
+	}
+
+	start, err := parseQuarter("2025-Q2")
+	if err != nil || !start.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("parseQuarter(2025-Q2) = %v, %v", start, err)
+	}
+	if _, err := parseQuarter("2025-Q5"); err == nil {
+		t.Error("parseQuarter(2025-Q5) succeeded")
+	}
+	if got := quarterOf(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)); got != "2025-Q4" {
+		t.Errorf("quarterOf(Dec 31) = %s", got)
+	}
+}
+
+func TestCheckAlignment(t *testing.T) {
+	objectives := map[int]Objective{
+		1: {ID: 1, Quarter: "2025-Q1", DepartmentID: 1},
+		2: {ID: 2, Quarter: "2025-Q1", DepartmentID: 2, ParentID: 1},
+		3: {ID: 3, Quarter: "2025-Q1", EmployeeID: 1, ParentID: 2},
+		4: {ID: 4, Quarter: "2025-Q2", DepartmentID: 1},
+	}
+	tests := []struct {
+		name string
+		o    Objective
+		ok   bool
+	}{
+		{"department parent", Objective{ID: 5, Quarter: "2025-Q1", ParentID: 2}, true},
+		{"employee parent", Objective{ID: 5, Quarter: "2025-Q1", ParentID: 3}, false},
+		{"other quarter", Objective{ID: 5, Quarter: "2025-Q1", ParentID: 4}, false},
+		{"missing parent", Objective{ID: 5, Quarter: "2025-Q1", ParentID: 9}, false},
+		{"circle", Objective{ID: 1, Quarter: "2025-Q1", DepartmentID: 1, ParentID: 2}, false},
+	}
+	for _, tt := range tests {
+		if err := checkAlignment(tt.o, objectives); (err == nil) != tt.ok {
+			t.Errorf("%s: checkAlignment() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestGoals(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	support := Department{Name: "Support"}
+	if err := repos.Departments.CreateDepartment(ctx, &support); err != nil {
+		t.Fatal(err)
+	}
+	departments, _ := repos.Departments.GetDepartments(ctx, "")
+	dept := strconv.Itoa(departments[0].ID)
+	lina := Employee{FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com", HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Status: "active", DepartmentID: departments[0].ID}
+	if err := repos.Employees.CreateEmployee(ctx, &lina); err != nil {
+		t.Fatal(err)
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+
+	form := url.Values{"title": {"Faster support"}, "quarter": {"2025-Q1"}, "owner": {"department:" + dept}}
+	w := send(h, "POST", "/goals/add", form, hrHeaders)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("add objective = %d %s", w.Code, w.Body)
+	}
+	parent := w.Header().Get("HX-Redirect")
+	parentID := strings.TrimPrefix(parent, "/goals/objectives/")
+
+	aligned := url.Values{"title": {"Clear the backlog"}, "quarter": {"2025-Q1"}, "owner": {"employee:" + strconv.Itoa(employees[0].ID)}, "parent_id": {parentID}}
+	if w := send(h, "POST", "/goals/add", aligned, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add aligned objective = %d %s", w.Code, w.Body)
+	}
+	aligned.Set("quarter", "2025-Q2")
+	if w := send(h, "POST", "/goals/add", aligned, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("align across quarters = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", "/goals/add", url.Values{"title": {"Ownerless"}, "quarter": {"2025-Q1"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add without owner = %d, want 400", w.Code)
+	}
+
+	kr := url.Values{"title": {"First reply in hours"}, "unit": {"hours"}, "start_value": {"24"}, "target_value": {"4"}}
+	if w := send(h, "POST", parent+"/key-results", kr, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add key result = %d %s", w.Code, w.Body)
+	}
+	kr.Set("target_value", "24")
+	if w := send(h, "POST", parent+"/key-results", kr, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("target equal to start = %d, want 400", w.Code)
+	}
+	id, _ := strconv.Atoi(parentID)
+	krs, _ := repos.KeyResults.GetKeyResults(ctx, id)
+	if len(krs) != 1 || krs[0].CurrentValue != 24 {
+		t.Fatalf("key results = %+v, want one starting at 24", krs)
+	}
+	checkIn := url.Values{"value": {"14"}, "comment": {"New macros"}}
+	if w := send(h, "POST", "/goals/key-results/"+strconv.Itoa(krs[0].ID)+"/check-ins", checkIn, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("check in = %d %s", w.Code, w.Body)
+	}
+	if got, _ := repos.KeyResults.GetKeyResultByID(ctx, krs[0].ID); got.Progress() != 50 {
+		t.Errorf("progress after check-in = %v, want 50", got.Progress())
+	}
+
+	w = send(h, "GET", "/goals?quarter=2025-Q1", nil, hrHeaders)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Clear the backlog") {
+		t.Errorf("goals page = %d, want the aligned objective", w.Code)
+	}
+	w = send(h, "GET", "/goals/rollup?quarter=2025-Q1", nil, hrHeaders)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Support") || !strings.Contains(w.Body.String(), "25%") {
+		t.Errorf("roll-up = %d, want Support at 25%%", w.Code)
+	}
+	if w := send(h, "GET", parent, nil, hrHeaders); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "New macros") {
+		t.Errorf("objective page = %d, want the check-in", w.Code)
+	}
+
+	if w := send(h, "DELETE", "/goals/delete?id="+parentID, nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete with aligned objectives = %d, want 409", w.Code)
+	}
+}
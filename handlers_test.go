@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// newTestApp returns the routes of an App over empty in-memory
+// repositories, together with the repositories for seeding and checks.
+func newTestApp(t *testing.T) (http.Handler, Repositories) {
+	t.Helper()
+	cfg := defaultConfig()
+	cfg.Server.DevMode = false
+	cfg.Uploads.Dir = t.TempDir()
+	repos := NewMemoryRepositories()
+	return NewApp(&cfg, repos, testTemplates(t)).routes(), repos
+}
+
+// hrHeaders are sent by requests made as a member of HR, who may use
+// every page outside self-service.
+var hrHeaders = map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"}
+
+// send issues a request against h. A non-nil form is sent url-encoded.
+func send(h http.Handler, method, target string, form url.Values, header map[string]string) *httptest.ResponseRecorder {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	r := httptest.NewRequest(method, target, body)
+	if form != nil {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// sendFile posts form and a file in the given field to h as a multipart
+// form.
+func sendFile(h http.Handler, target string, form url.Values, field, name, content string, header map[string]string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, vs := range form {
+		for _, v := range vs {
+			mw.WriteField(k, v)
+		}
+	}
+	fw, _ := mw.CreateFormFile(field, name)
+	io.WriteString(fw, content)
+	mw.Close()
+	r := httptest.NewRequest("POST", target, &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// entityCase describes one CRUD page for TestEntityHandlers.
+type entityCase struct {
+	path string
+	// seed stores one record and returns its id and a text it is listed with.
+	seed func(t *testing.T, repos Repositories) (id int, text string)
+	// field reads back the value that the update form changes.
+	field func(repos Repositories, id int) (string, bool)
+
+	addForm    url.Values
+	addText    string
+	updateForm url.Values
+	updated    string
+}
+
+func TestEntityHandlers(t *testing.T) {
+	ctx := context.Background()
+	hired := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	cases := []entityCase{
+		{
+			path: "departments",
+			seed: func(t *testing.T, repos Repositories) (int, string) {
+				if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Engineering", Description: "Builds things"}); err != nil {
+					t.Fatal(err)
+				}
+				d, _ := repos.Departments.GetDepartments(ctx, "Engineering")
+				return d[0].ID, "Engineering"
+			},
+			field: func(repos Repositories, id int) (string, bool) {
+				d, _ := repos.Departments.GetDepartmentByID(ctx, id)
+				if d == nil {
+					return "", false
+				}
+				return d.Name, true
+			},
+			addForm:    url.Values{"name": {"Marketing"}, "description": {"Sells things"}},
+			addText:    "Marketing",
+			updateForm: url.Values{"name": {"Platform"}, "description": {"Runs things"}},
+			updated:    "Platform",
+		},
+		{
+			path: "positions",
+			seed: func(t *testing.T, repos Repositories) (int, string) {
+				if err := repos.Positions.CreatePosition(ctx, &Position{Name: "Backend Engineer"}); err != nil {
+					t.Fatal(err)
+				}
+				p, _ := repos.Positions.GetPositions(ctx, "Backend")
+				return p[0].ID, "Backend Engineer"
+			},
+			field: func(repos Repositories, id int) (string, bool) {
+				p, _ := repos.Positions.GetPositionByID(ctx, id)
+				if p == nil {
+					return "", false
+				}
+				return p.Name, true
+			},
+			addForm:    url.Values{"name": {"UI Designer"}},
+			addText:    "UI Designer",
+			updateForm: url.Values{"name": {"Staff Engineer"}},
+			updated:    "Staff Engineer",
+		},
+		{
+			path: "employees",
+			seed: func(t *testing.T, repos Repositories) (int, string) {
+				e := Employee{FirstName: "Jane", LastName: "Smith", Email: "jane@example.com", HireDate: hired, Status: "active"}
+				if err := repos.Employees.CreateEmployee(ctx, &e); err != nil {
+					t.Fatal(err)
+				}
+				found, _ := repos.Employees.GetEmployees(ctx, "jane")
+				return found[0].ID, "jane@example.com"
+			},
+			field: func(repos Repositories, id int) (string, bool) {
+				e, _ := repos.Employees.GetEmployeeByID(ctx, id)
+				if e == nil {
+					return "", false
+				}
+				return e.Email, true
+			},
+			addForm: url.Values{
+				"first_name": {"John"}, "last_name": {"Doe"}, "email": {"john@example.com"},
+				"hire_date": {"2023-06-01"}, "salary": {"50000"}, "status": {"active"},
+			},
+			addText: "john@example.com",
+			updateForm: url.Values{
+				"first_name": {"Jane"}, "last_name": {"Smith"}, "email": {"jane.smith@example.com"},
+				"hire_date": {"2023-01-15"}, "salary": {"90000"}, "status": {"active"},
+			},
+			updated: "jane.smith@example.com",
+		},
+		{
+			path: "applications",
+			seed: func(t *testing.T, repos Repositories) (int, string) {
+				a := Application{Name: "Alice Walker", Email: "alice@example.com", Status: "pending"}
+				if err := repos.Applications.CreateApplication(ctx, &a); err != nil {
+					t.Fatal(err)
+				}
+				found, _ := repos.Applications.GetApplications(ctx, "alice")
+				return found[0].ID, "Alice Walker"
+			},
+			field: func(repos Repositories, id int) (string, bool) {
+				a, _ := repos.Applications.GetApplicationByID(ctx, id)
+				if a == nil {
+					return "", false
+				}
+				return a.Status, true
+			},
+			addForm:    url.Values{"name": {"Bob Ross"}, "email": {"bob@example.com"}, "status": {"pending"}},
+			addText:    "Bob Ross",
+			updateForm: url.Values{"name": {"Alice Walker"}, "email": {"alice@example.com"}, "status": {"interviewing"}},
+			updated:    "interviewing",
+		},
+		{
+			path: "leaves",
+			seed: func(t *testing.T, repos Repositories) (int, string) {
+				l := Leave{EmployeeID: 1, LeaveType: "vacation", StartDate: hired, EndDate: hired.AddDate(0, 0, 6), Status: "pending", Reason: "Annual leave"}
+				if err := repos.Leaves.CreateLeave(ctx, &l); err != nil {
+					t.Fatal(err)
+				}
+				found, _ := repos.Leaves.GetLeaves(ctx, "annual")
+				return found[0].ID, "Annual leave"
+			},
+			field: func(repos Repositories, id int) (string, bool) {
+				l, _ := repos.Leaves.GetLeaveByID(ctx, id)
+				if l == nil {
+					return "", false
+				}
+				return l.Status, true
+			},
+			addForm: url.Values{
+				"employee_id": {"1"}, "leave_type": {"sick"}, "start_date": {"2024-02-15"},
+				"end_date": {"2024-02-16"}, "status": {"pending"}, "reason": {"Flu"},
+			},
+			addText: "Flu",
+			updateForm: url.Values{
+				"employee_id": {"1"}, "leave_type": {"vacation"}, "start_date": {"2023-01-15"},
+				"end_date": {"2023-01-21"}, "status": {"approved"}, "reason": {"Annual leave"},
+			},
+			updated: "approved",
+		},
+	}
+
+	for _, tc := range cases {
+		base := "/" + tc.path
+
+		t.Run(tc.path+"/list", func(t *testing.T) {
+			h, repos := newTestApp(t)
+			_, text := tc.seed(t, repos)
+
+			w := send(h, http.MethodGet, base, nil, hrHeaders)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, "<html") || !strings.Contains(body, text) {
+				t.Errorf("full page does not list %q", text)
+			}
+		})
+
+		t.Run(tc.path+"/partial", func(t *testing.T) {
+			h, repos := newTestApp(t)
+			_, text := tc.seed(t, repos)
+			htmx := map[string]string{"HX-Request": "true", "X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"}
+
+			w := send(h, http.MethodGet, base+"?q="+url.QueryEscape(strings.ToUpper(text)), nil, htmx)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			body := w.Body.String()
+			if strings.Contains(body, "<html") {
+				t.Error("HTMX request got the full page, want only the table partial")
+			}
+			if !strings.Contains(body, text) {
+				t.Errorf("partial does not list %q", text)
+			}
+
+			w = send(h, http.MethodGet, base+"?q=no-such-record", nil, htmx)
+			if strings.Contains(w.Body.String(), text) {
+				t.Errorf("search for a missing record still lists %q", text)
+			}
+		})
+
+		t.Run(tc.path+"/add", func(t *testing.T) {
+			h, _ := newTestApp(t)
+
+			if w := send(h, http.MethodGet, base+"/add", nil, hrHeaders); w.Code != http.StatusOK {
+				t.Fatalf("GET form: status = %d, want %d", w.Code, http.StatusOK)
+			}
+			w := send(h, http.MethodPost, base+"/add", tc.addForm, hrHeaders)
+			if w.Code != http.StatusSeeOther || w.Header().Get("HX-Redirect") != base {
+				t.Fatalf("POST: status = %d, HX-Redirect = %q, want %d to %s", w.Code, w.Header().Get("HX-Redirect"), http.StatusSeeOther, base)
+			}
+			if body := send(h, http.MethodGet, base, nil, hrHeaders).Body.String(); !strings.Contains(body, tc.addText) {
+				t.Errorf("list does not show added record %q", tc.addText)
+			}
+		})
+
+		t.Run(tc.path+"/update", func(t *testing.T) {
+			h, repos := newTestApp(t)
+			id, _ := tc.seed(t, repos)
+			target := base + "/update/" + strconv.Itoa(id)
+
+			if w := send(h, http.MethodGet, target, nil, hrHeaders); w.Code != http.StatusOK {
+				t.Fatalf("GET form: status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if w := send(h, http.MethodGet, base+"/update/999", nil, hrHeaders); w.Code != http.StatusNotFound {
+				t.Errorf("GET missing record: status = %d, want %d", w.Code, http.StatusNotFound)
+			}
+			if w := send(h, http.MethodGet, base+"/update/abc", nil, hrHeaders); w.Code != http.StatusBadRequest {
+				t.Errorf("GET bad id: status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+
+			w := send(h, http.MethodPost, target, tc.updateForm, hrHeaders)
+			if w.Code != http.StatusSeeOther {
+				t.Fatalf("POST: status = %d, want %d", w.Code, http.StatusSeeOther)
+			}
+			if got, _ := tc.field(repos, id); got != tc.updated {
+				t.Errorf("after update field = %q, want %q", got, tc.updated)
+			}
+		})
+
+		t.Run(tc.path+"/delete", func(t *testing.T) {
+			h, repos := newTestApp(t)
+			id, _ := tc.seed(t, repos)
+			// htmx sends the hx-vals of a DELETE in the query string.
+			target := base + "/delete?id=" + strconv.Itoa(id)
+
+			if w := send(h, http.MethodPost, target, nil, hrHeaders); w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("POST: status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+			}
+			if w := send(h, http.MethodDelete, base+"/delete?id=x", nil, hrHeaders); w.Code != http.StatusBadRequest {
+				t.Errorf("DELETE bad id: status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			w := send(h, http.MethodDelete, target, nil, hrHeaders)
+			if w.Code != http.StatusSeeOther {
+				t.Fatalf("DELETE: status = %d, want %d", w.Code, http.StatusSeeOther)
+			}
+			if _, ok := tc.field(repos, id); ok {
+				t.Error("record still exists after delete")
+			}
+		})
+
+		t.Run(tc.path+"/export", func(t *testing.T) {
+			h, repos := newTestApp(t)
+			id, _ := tc.seed(t, repos)
+
+			w := send(h, http.MethodGet, base+"/export", nil, hrHeaders)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "spreadsheetml") {
+				t.Errorf("Content-Type = %q, want an xlsx type", ct)
+			}
+			f, err := excelize.OpenReader(w.Body)
+			if err != nil {
+				t.Fatalf("export is not a valid workbook: %v", err)
+			}
+			defer f.Close()
+			cell, _ := f.GetCellValue("Sheet1", "A2")
+			if cell != strconv.Itoa(id) {
+				t.Errorf("first exported row id = %q, want %d", cell, id)
+			}
+		})
+	}
+}
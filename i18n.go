@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// localeCookie remembers the language a user picked in the switcher.
+const localeCookie = "lang"
+
+// Locale is one UI language, loaded from locales/<tag>.json. Messages maps
+// the English text used in templates and handlers to its translation;
+// missing entries fall back to the English text.
+type Locale struct {
+	Tag            string            `json:"-"`
+	Name           string            `json:"name"`
+	Dir            string            `json:"dir"`
+	DateFormat     string            `json:"date_format"`
+	DateTimeFormat string            `json:"datetime_format"`
+	Months         []string          `json:"months"`
+	Messages       map[string]string `json:"messages"`
+
+	printer  *message.Printer
+	digits   *strings.Replacer
+	months   *strings.Replacer
+	currency currency.Unit
+}
+
+// T translates key and, when args are given, formats it like fmt.Sprintf
+// with the locale's digits and separators.
+func (l *Locale) T(key string, args ...any) string {
+	msg, ok := l.Messages[key]
+	if !ok || msg == "" {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return l.printer.Sprintf(msg, args...)
+}
+
+// FormatDate formats t as a date without time of day, e.g. HireDate.
+func (l *Locale) FormatDate(t time.Time) string {
+	return l.formatTime(t, l.DateFormat)
+}
+
+// FormatDateTime formats t with the time of day.
+func (l *Locale) FormatDateTime(t time.Time) string {
+	return l.formatTime(t, l.DateTimeFormat)
+}
+
+func (l *Locale) formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	s := t.Format(layout)
+	if l.months != nil {
+		s = l.months.Replace(s)
+	}
+	return l.digits.Replace(s)
+}
+
+// FormatClock formats the time of day of the instant t in the local zone,
+// e.g. a clock-in.
+func (l *Locale) FormatClock(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return l.digits.Replace(t.Local().Format("15:04"))
+}
+
+// FormatDuration formats d in hours and minutes, e.g. time worked.
+func (l *Locale) FormatDuration(d time.Duration) string {
+	m := int(d.Round(time.Minute).Minutes())
+	return l.T("%dh %02dm", m/60, m%60)
+}
+
+// FormatNumber formats n, an integer or a float64, with the locale's
+// digits and separators and at most two decimals.
+func (l *Locale) FormatNumber(n any) string {
+	if f, ok := n.(float64); ok {
+		if f == float64(int64(f)) {
+			return l.printer.Sprintf("%d", int64(f))
+		}
+		return l.printer.Sprintf("%.2f", f)
+	}
+	return l.printer.Sprintf("%d", n)
+}
+
+// FormatMoney formats amount in the configured currency, e.g. a Salary.
+func (l *Locale) FormatMoney(amount float64) string {
+	return l.printer.Sprint(currency.Symbol(l.currency.Amount(amount)))
+}
+
+// FormatSize formats a file size given in bytes, e.g. 1.5 MB.
+func (l *Locale) FormatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return l.T("%s MB", l.printer.Sprintf("%.1f", float64(n)/(1<<20)))
+	case n >= 1<<10:
+		return l.T("%s KB", l.printer.Sprintf("%.1f", float64(n)/(1<<10)))
+	}
+	return l.T("%s bytes", l.FormatNumber(n))
+}
+
+// FormatPeriod formats an inclusive date range such as a leave, together
+// with its length in days.
+func (l *Locale) FormatPeriod(start, end time.Time) string {
+	days := int(end.Sub(start).Hours()/24) + 1
+	length := l.T("%d days", days)
+	if days == 1 {
+		length = l.T("1 day")
+	}
+	return l.T("%s – %s (%s)", l.FormatDate(start), l.FormatDate(end), length)
+}
+
+// funcs returns the template functions that format for this locale.
+func (l *Locale) funcs(c *Catalog) template.FuncMap {
+	return template.FuncMap{
+		"t":        l.T,
+		"date":     l.FormatDate,
+		"datetime": l.FormatDateTime,
+		"clock":    l.FormatClock,
+		"duration": l.FormatDuration,
+		"number":   l.FormatNumber,
+		"money":    l.FormatMoney,
+		"period":   l.FormatPeriod,
+		"size":     l.FormatSize,
+		"lang":     func() string { return l.Tag },
+		"dir":      func() string { return l.Dir },
+		"locales":  c.Locales,
+	}
+}
+
+// Catalog holds every available Locale and picks one per request.
+type Catalog struct {
+	locales  []*Locale
+	byTag    map[string]*Locale
+	fallback *Locale
+	matcher  language.Matcher
+	// matched lists the locales in the order of the matcher's tags.
+	matched []*Locale
+}
+
+// loadCatalog reads every locales/*.json file in fsys. cfg.Locale names
+// the locale used when a request asks for none we have.
+func loadCatalog(fsys fs.FS, cfg I18nConfig) (*Catalog, error) {
+	unit, err := currency.ParseISO(cfg.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("parsing currency %q: %w", cfg.Currency, err)
+	}
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("globbing locales: %w", err)
+	}
+
+	c := &Catalog{byTag: make(map[string]*Locale)}
+	for _, file := range files {
+		l, err := loadLocale(fsys, file, unit)
+		if err != nil {
+			return nil, err
+		}
+		c.locales = append(c.locales, l)
+		c.byTag[l.Tag] = l
+	}
+
+	c.fallback = c.byTag[cfg.Locale]
+	if c.fallback == nil {
+		return nil, fmt.Errorf("no catalog for default locale %q in locales/", cfg.Locale)
+	}
+	// The matcher falls back to its first tag.
+	c.matched = []*Locale{c.fallback}
+	for _, l := range c.locales {
+		if l != c.fallback {
+			c.matched = append(c.matched, l)
+		}
+	}
+	var tags []language.Tag
+	for _, l := range c.matched {
+		tags = append(tags, language.Make(l.Tag))
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+func loadLocale(fsys fs.FS, file string, unit currency.Unit) (*Locale, error) {
+	b, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("reading locale: %w", err)
+	}
+	l := &Locale{Tag: strings.TrimSuffix(path.Base(file), ".json"), Dir: "ltr"}
+	if err := json.Unmarshal(b, l); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	tag, err := language.Parse(l.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("locale file %s: %w", file, err)
+	}
+	if l.Dir != "ltr" && l.Dir != "rtl" {
+		return nil, fmt.Errorf("locale file %s: dir must be ltr or rtl", file)
+	}
+	if l.DateFormat == "" {
+		l.DateFormat = "Jan 02, 2006"
+	}
+	if l.DateTimeFormat == "" {
+		l.DateTimeFormat = l.DateFormat + " 15:04"
+	}
+
+	l.printer = message.NewPrinter(tag)
+	l.currency = unit
+	var digits []string
+	for d := range 10 {
+		digits = append(digits, fmt.Sprint(d), l.printer.Sprintf("%d", d))
+	}
+	l.digits = strings.NewReplacer(digits...)
+	if len(l.Months) > 0 {
+		if len(l.Months) != 12 {
+			return nil, fmt.Errorf("locale file %s: months must list 12 names", file)
+		}
+		var months []string
+		for m := time.January; m <= time.December; m++ {
+			months = append(months, m.String(), l.Months[m-1])
+		}
+		for m := time.January; m <= time.December; m++ {
+			months = append(months, m.String()[:3], l.Months[m-1])
+		}
+		l.months = strings.NewReplacer(months...)
+	}
+	return l, nil
+}
+
+// Locales returns the available locales in file name order.
+func (c *Catalog) Locales() []*Locale {
+	return c.locales
+}
+
+// Lookup returns the locale with tag, or nil.
+func (c *Catalog) Lookup(tag string) *Locale {
+	return c.byTag[tag]
+}
+
+// ForRequest picks the locale for r: the one chosen in the language
+// switcher, else the best match for Accept-Language, else the default.
+func (c *Catalog) ForRequest(r *http.Request) *Locale {
+	if cookie, err := r.Cookie(localeCookie); err == nil {
+		if l := c.byTag[cookie.Value]; l != nil {
+			return l
+		}
+	}
+	if accept := r.Header.Get("Accept-Language"); accept != "" {
+		if tags, _, err := language.ParseAcceptLanguage(accept); err == nil && len(tags) > 0 {
+			if _, i, conf := c.matcher.Match(tags...); conf != language.No {
+				return c.matched[i]
+			}
+		}
+	}
+	return c.fallback
+}
+
+// locale returns the locale the response to r is written in.
+func (app *App) locale(r *http.Request) *Locale {
+	return app.Templates.Catalog().ForRequest(r)
+}
+
+// handleLocale stores the language picked in the switcher and sends the
+// user back to the page they were on.
+func (app *App) handleLocale(w http.ResponseWriter, r *http.Request) {
+	tag := r.URL.Query().Get("lang")
+	if app.Templates.Catalog().Lookup(tag) == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Unknown language")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     localeCookie,
+		Value:    tag,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	back := "/"
+	if ref, err := url.Parse(r.Referer()); err == nil && ref.Host == r.Host && ref.Path != "" {
+		back = ref.RequestURI()
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
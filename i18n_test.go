@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	return testTemplates(t).Catalog()
+}
+
+func TestCatalogForRequest(t *testing.T) {
+	c := testCatalog(t)
+	tests := []struct {
+		name   string
+		cookie string
+		accept string
+		want   string
+	}{
+		{name: "default", want: "en"},
+		{name: "accept-language", accept: "ar-SY,ar;q=0.9,en;q=0.5", want: "ar"},
+		{name: "unsupported language", accept: "fr-FR", want: "en"},
+		{name: "cookie wins", cookie: "ar", accept: "en-US", want: "ar"},
+		{name: "unknown cookie", cookie: "xx", accept: "ar", want: "ar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: localeCookie, Value: tt.cookie})
+			}
+			if tt.accept != "" {
+				r.Header.Set("Accept-Language", tt.accept)
+			}
+			if got := c.ForRequest(r).Tag; got != tt.want {
+				t.Errorf("ForRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocaleFormatting(t *testing.T) {
+	c := testCatalog(t)
+	en, ar := c.Lookup("en"), c.Lookup("ar")
+	start := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"en date", en.FormatDate(start), "Mar 05, 2024"},
+		{"ar date", ar.FormatDate(start), "٥ مارس ٢٠٢٤"},
+		{"en money", en.FormatMoney(75000), "$ 75,000.00"},
+		{"en period", en.FormatPeriod(start, end), "Mar 05, 2024 – Mar 07, 2024 (3 days)"},
+		{"en single day", en.FormatPeriod(start, start), "Mar 05, 2024 – Mar 05, 2024 (1 day)"},
+		{"ar number", ar.FormatNumber(1234), "١٬٢٣٤"},
+		{"en size", en.FormatSize(1536 << 10), "1.5 MB"},
+		{"en small size", en.FormatSize(512), "512 bytes"},
+		{"ar message", ar.T("Employees"), "الموظفون"},
+		{"missing message", ar.T("Not in the catalog"), "Not in the catalog"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestPagesFollowRequestLocale(t *testing.T) {
+	h, repos := newTestApp(t)
+	err := repos.Employees.CreateEmployee(context.Background(), &Employee{
+		FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com",
+		HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Salary: 75000, Status: "active",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := send(h, "GET", "/employees", nil, map[string]string{"Accept-Language": "ar", "X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"})
+	body := w.Body.String()
+	for _, want := range []string{`lang="ar"`, `dir="rtl"`, "الموظفون", "١٥ يناير ٢٠٢٣"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Arabic employees page is missing %q", want)
+		}
+	}
+
+	w = send(h, "GET", "/employees", nil, hrHeaders)
+	body = w.Body.String()
+	for _, want := range []string{`lang="en"`, `dir="ltr"`, "Jan 15, 2023", "75,000.00"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("English employees page is missing %q", want)
+		}
+	}
+}
+
+func TestHandleLocale(t *testing.T) {
+	h, _ := newTestApp(t)
+
+	w := send(h, "GET", "/locale?lang=ar", nil, map[string]string{"Referer": "http://example.com/leaves?q=x"})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	if loc := w.Header().Get("Location"); loc != "/leaves?q=x" {
+		t.Errorf("Location = %q, want /leaves?q=x", loc)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != localeCookie || cookies[0].Value != "ar" {
+		t.Errorf("cookies = %v, want %s=ar", cookies, localeCookie)
+	}
+
+	w = send(h, "GET", "/locale?lang=ar", nil, map[string]string{"Referer": "http://evil.test/phish"})
+	if loc := w.Header().Get("Location"); loc != "/" {
+		t.Errorf("Location for foreign referer = %q, want /", loc)
+	}
+
+	w = send(h, "GET", "/locale?lang=xx", nil, map[string]string{"Accept-Language": "ar", "X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "لغة غير معروفة") {
+		t.Errorf("unknown language = %d %q, want 400 with a translated message", w.Code, w.Body.String())
+	}
+}
+
+// TestArabicCatalogCoversTemplates catches UI text added to a template
+// without a translation.
+func TestArabicCatalogCoversTemplates(t *testing.T) {
+	ar := testCatalog(t).Lookup("ar")
+	key := regexp.MustCompile(`\{\{t "([^"]+)"`)
+	fsys := os.DirFS("templates")
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		for _, m := range key.FindAllStringSubmatch(string(b), -1) {
+			if _, ok := ar.Messages[m[1]]; !ok {
+				t.Errorf("%s: %q has no Arabic translation", name, m[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
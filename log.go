@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	tenantIDKey
+)
+
+// newLogger builds the process logger. Every record logged with a request
+// context carries that request's ID.
+func newLogger(w io.Writer, cfg LogConfig) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch cfg.Format {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q (want json or text)", cfg.Format)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+// initLogging installs the configured logger as the slog default.
+func initLogging(cfg *Config) error {
+	logger, err := newLogger(os.Stderr, cfg.Log)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// contextHandler adds the request and tenant IDs found in the context to
+// each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id := TenantID(ctx); id != "" {
+		r.AddAttrs(slog.String("tenant", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// RequestID returns the ID assigned to the request ctx belongs to, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withRequestID reuses a sane X-Request-ID from the client or proxy, or
+// generates one, and echoes it back in the response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 64 || strings.ContainsAny(id, " \t\r\n") {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// statusRecorder captures what a handler wrote for the access log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Flush keeps the /dev-reload event stream working behind the recorder.
+func (rec *statusRecorder) Flush() {
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// logRequests writes one access log record per request.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		slog.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+			slog.Bool("htmx", r.Header.Get("HX-Request") == "true"),
+		)
+	})
+}
+
+// serverError logs err and answers with a generic 500 carrying msg.
+func (app *App) serverError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	slog.ErrorContext(r.Context(), msg, "err", err)
+	http.Error(w, app.locale(r).T(msg), http.StatusInternalServerError)
+}
+
+// clientError answers with status and msg; the request was at fault so
+// it's only logged at debug level.
+func (app *App) clientError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	slog.DebugContext(r.Context(), "client error", "status", status, "msg", msg)
+	http.Error(w, app.locale(r).T(msg), status)
+}
+
+func (app *App) methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	app.clientError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// render executes the named page template, or only its partial block when
+// partial is not empty. Headers are already sent by the time execution can
+// fail, so errors are logged only.
+func (app *App) render(w http.ResponseWriter, r *http.Request, page, partial string, data any) {
+	tmpl, ok := app.Templates.Lookup(app.locale(r).Tag, page)
+	if !ok {
+		app.serverError(w, r, "Page not found", fmt.Errorf("template %s is not loaded", page))
+		return
+	}
+
+	var err error
+	if partial != "" {
+		err = tmpl.ExecuteTemplate(w, partial, data)
+	} else {
+		err = tmpl.Execute(w, data)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "template execution failed", "template", page, "err", err)
+	}
+}
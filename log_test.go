@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// captureLogs makes the default logger write JSON records to the returned
+// buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, LogConfig{Level: "debug", Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+// logRecords decodes the JSON records written to buf.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+var generatedID = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+func TestWithRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string // "" for a generated ID
+	}{
+		{name: "passed through", header: "edge-4f2a-9c", want: "edge-4f2a-9c"},
+		{name: "missing", header: ""},
+		{name: "too long", header: strings.Repeat("a", 65)},
+		{name: "with spaces", header: "two words"},
+		{name: "with a newline", header: "abc\r\nSet-Cookie: x=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestID(r.Context())
+			}))
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Request-ID", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			got := w.Header().Get("X-Request-ID")
+			if got != seen {
+				t.Errorf("response ID %q, handler saw %q", got, seen)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("ID = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && !generatedID.MatchString(got) {
+				t.Errorf("ID = %q, want a generated one", got)
+			}
+		})
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		header     map[string]string
+		wantStatus float64
+		wantBytes  float64
+		wantHTMX   bool
+	}{
+		{
+			name:       "implicit 200",
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("hello")) },
+			wantStatus: 200, wantBytes: 5,
+		},
+		{
+			name:       "explicit status",
+			handler:    func(w http.ResponseWriter, r *http.Request) { http.Error(w, "nope", http.StatusForbidden) },
+			header:     map[string]string{"HX-Request": "true"},
+			wantStatus: 403, wantBytes: 5, wantHTMX: true,
+		},
+		{
+			name:       "nothing written",
+			handler:    func(w http.ResponseWriter, r *http.Request) {},
+			wantStatus: 200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t)
+			r := httptest.NewRequest("POST", "/departments/add", nil)
+			r.Header.Set("X-Request-ID", "req-1")
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			withRequestID(logRequests(tt.handler)).ServeHTTP(httptest.NewRecorder(), r)
+
+			records := logRecords(t, buf)
+			if len(records) != 1 {
+				t.Fatalf("records = %v, want one", records)
+			}
+			rec := records[0]
+			if rec["msg"] != "request" || rec["method"] != "POST" || rec["path"] != "/departments/add" {
+				t.Errorf("record = %v, want the request line", rec)
+			}
+			if rec["status"] != tt.wantStatus || rec["bytes"] != tt.wantBytes || rec["htmx"] != tt.wantHTMX {
+				t.Errorf("status, bytes, htmx = %v, %v, %v, want %v, %v, %v", rec["status"], rec["bytes"], rec["htmx"], tt.wantStatus, tt.wantBytes, tt.wantHTMX)
+			}
+			if rec["request_id"] != "req-1" {
+				t.Errorf("request_id = %v, want req-1", rec["request_id"])
+			}
+		})
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requestID any
+		tenant    any
+	}{
+		{name: "no request", ctx: context.Background()},
+		{name: "request", ctx: context.WithValue(context.Background(), requestIDKey, "req-2"), requestID: "req-2"},
+		{
+			name:      "request of a tenant",
+			ctx:       context.WithValue(context.WithValue(context.Background(), requestIDKey, "req-3"), tenantIDKey, "acme"),
+			requestID: "req-3", tenant: "acme",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t)
+			// Derived loggers must keep adding the IDs too.
+			slog.Default().With("component", "test").WithGroup("g").InfoContext(tt.ctx, "hello", "k", "v")
+
+			records := logRecords(t, buf)
+			if len(records) != 1 {
+				t.Fatalf("records = %v, want one", records)
+			}
+			rec := records[0]
+			if rec["component"] != "test" || rec["g"] == nil {
+				t.Errorf("record = %v, want the attributes of the derived logger", rec)
+			}
+			// The IDs are added to the record, so they land in the open group.
+			g, _ := rec["g"].(map[string]any)
+			if g["request_id"] != tt.requestID || g["tenant"] != tt.tenant {
+				t.Errorf("request_id, tenant = %v, %v, want %v, %v", g["request_id"], g["tenant"], tt.requestID, tt.tenant)
+			}
+		})
+	}
+}
+
+func TestRepoErrorCarriesRequestID(t *testing.T) {
+	cause := errors.New("database is locked")
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requestID string
+		message   string
+	}{
+		{name: "no request", ctx: context.Background(), message: "CreateDepartment: database is locked"},
+		{
+			name:      "request",
+			ctx:       context.WithValue(context.Background(), requestIDKey, "req-4"),
+			requestID: "req-4", message: "CreateDepartment (request req-4): database is locked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repoError(tt.ctx, "CreateDepartment", cause)
+			var repoErr *RepositoryError
+			if !errors.As(err, &repoErr) || repoErr.RequestID != tt.requestID {
+				t.Errorf("error = %#v, want request ID %q", err, tt.requestID)
+			}
+			if !errors.Is(err, cause) {
+				t.Errorf("error = %v, want it to wrap the cause", err)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestRequestIDFollowsFailures(t *testing.T) {
+	buf := captureLogs(t)
+	h := withRequestID(logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := repoError(r.Context(), "GetDepartments", errors.New("disk I/O error"))
+		slog.ErrorContext(r.Context(), "Failed to fetch departments", "err", err)
+		http.Error(w, "Failed to fetch departments", http.StatusInternalServerError)
+	})))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/departments", nil))
+
+	id := w.Header().Get("X-Request-ID")
+	records := logRecords(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("records = %v, want the failure and the request", records)
+	}
+	for _, rec := range records {
+		if rec["request_id"] != id {
+			t.Errorf("record %v, want request_id %q", rec, id)
+		}
+	}
+	if msg, _ := records[0]["err"].(string); !strings.Contains(msg, "(request "+id+")") {
+		t.Errorf("err = %q, want the request ID in it", msg)
+	}
+}
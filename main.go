@@ -2,242 +2,495 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"html/template"
-	"io/fs"
-	"log"
+	"log/slog"
 	"net/http"
-	"path/filepath"
+	"os"
+	"os/signal"
+	"slices"
 	"strconv"
-	"sync"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var (
-	devMode = true // Set to true for development
-	clients = make(map[chan bool]bool)
-	mu      sync.Mutex
-)
-
-type Reloader struct {
-	watcher *fsnotify.Watcher
+type App struct {
+	DepartmentRepository    DepartmentRepository
+	PositionRepository      PositionRepository
+	EmployeeRepository      EmployeeRepository
+	ApplicationRepository   ApplicationRepository
+	LeaveRepository         LeaveRepository
+	AttendanceRepository    AttendanceRepository
+	ScheduleRepository      WorkScheduleRepository
+	CorrectionRepository    AttendanceCorrectionRepository
+	BadgeImportRepository   BadgeImportRepository
+	ReviewCycleRepository   ReviewCycleRepository
+	QuestionRepository      ReviewQuestionRepository
+	AssessmentRepository    ReviewAssessmentRepository
+	RatingRepository        EmployeeRatingRepository
+	ObjectiveRepository     ObjectiveRepository
+	KeyResultRepository     KeyResultRepository
+	CheckInRepository       CheckInRepository
+	CourseRepository        CourseRepository
+	EnrollmentRepository    EnrollmentRepository
+	CertificationRepository CertificationRepository
+	CertificateRepository   EmployeeCertificationRepository
+	DocCategoryRepository   DocumentCategoryRepository
+	DocumentRepository      DocumentRepository
+	DocVersionRepository    DocumentVersionRepository
+	DownloadRepository      DocumentDownloadRepository
+	OnboardingRepository    OnboardingTemplateRepository
+	TemplateTaskRepository  OnboardingTemplateTaskRepository
+	ChecklistRepository     OnboardingTaskRepository
+	TerminationRepository   TerminationRepository
+	EquipmentRepository     EquipmentRepository
+	AssignmentRepository    EquipmentAssignmentRepository
+	ConditionRepository     EquipmentConditionRepository
+	ExpenseClaimRepository  ExpenseClaimRepository
+	ExpenseItemRepository   ExpenseItemRepository
+	BenefitPlanRepository   BenefitPlanRepository
+	DependentRepository     DependentRepository
+	CoverageRepository      BenefitEnrollmentRepository
+	ShiftTemplateRepository ShiftTemplateRepository
+	ShiftRepository         ShiftRepository
+	OvertimeRepository      OvertimeRequestRepository
+	ContactChangeRepository ContactChangeRepository
+	Files                   *FileStore
+	Badges                  *BadgeImporter
+	reloader                *Reloader
+	Config                  *Config
+	Templates               *TemplateManager
+	Backups                 *BackupManager
 }
 
-func (r *Reloader) Close() error {
-	return r.watcher.Close()
+// NewApp returns an App serving the data in repos with the given page
+// templates. Backups and the dev reloader are optional and left unset.
+func NewApp(cfg *Config, repos Repositories, templates *TemplateManager) *App {
+	return &App{
+		DepartmentRepository:    repos.Departments,
+		PositionRepository:      repos.Positions,
+		EmployeeRepository:      repos.Employees,
+		ApplicationRepository:   repos.Applications,
+		LeaveRepository:         repos.Leaves,
+		AttendanceRepository:    repos.Attendance,
+		ScheduleRepository:      repos.Schedules,
+		CorrectionRepository:    repos.Corrections,
+		BadgeImportRepository:   repos.BadgeImports,
+		ReviewCycleRepository:   repos.ReviewCycles,
+		QuestionRepository:      repos.Questions,
+		AssessmentRepository:    repos.Assessments,
+		RatingRepository:        repos.Ratings,
+		ObjectiveRepository:     repos.Objectives,
+		KeyResultRepository:     repos.KeyResults,
+		CheckInRepository:       repos.CheckIns,
+		CourseRepository:        repos.Courses,
+		EnrollmentRepository:    repos.Enrollments,
+		CertificationRepository: repos.Certifications,
+		CertificateRepository:   repos.Certificates,
+		DocCategoryRepository:   repos.DocCategories,
+		DocumentRepository:      repos.Documents,
+		DocVersionRepository:    repos.DocVersions,
+		DownloadRepository:      repos.Downloads,
+		OnboardingRepository:    repos.OnboardingTemplates,
+		TemplateTaskRepository:  repos.TemplateTasks,
+		ChecklistRepository:     repos.OnboardingTasks,
+		TerminationRepository:   repos.Terminations,
+		EquipmentRepository:     repos.Equipment,
+		AssignmentRepository:    repos.Assignments,
+		ConditionRepository:     repos.Conditions,
+		ExpenseClaimRepository:  repos.ExpenseClaims,
+		ExpenseItemRepository:   repos.ExpenseItems,
+		BenefitPlanRepository:   repos.BenefitPlans,
+		DependentRepository:     repos.Dependents,
+		CoverageRepository:      repos.BenefitEnrollments,
+		ShiftTemplateRepository: repos.ShiftTemplates,
+		ShiftRepository:         repos.Shifts,
+		OvertimeRepository:      repos.Overtime,
+		ContactChangeRepository: repos.ContactChanges,
+		Files:                   NewFileStore(cfg.Uploads),
+		Badges:                  NewBadgeImporter(repos, cfg.Badge),
+		Config:                  cfg,
+		Templates:               templates,
+	}
 }
 
-func NewReloader() *Reloader {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Fatal(err)
+func main() {
+	args := os.Args[1:]
+	cmd := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(args)
+	case "config":
+		err = runConfig(args)
+	case "backup":
+		err = runBackup(args)
+	case "verify":
+		err = runVerify(args)
+	case "restore":
+		err = runRestore(args)
+	default:
+		err = fmt.Errorf("unknown command %q (want serve, config, backup, verify or restore)", cmd)
 	}
-	return &Reloader{
-		watcher: watcher,
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
-type App struct {
-	DepartmentRepository  DepartmentRepository
-	PositionRepository    PositionRepository
-	EmployeeRepository    EmployeeRepository
-	ApplicationRepository ApplicationRepository
-	LeaveRepository       LeaveRepository
-	reloader              Reloader
-	Templates             map[string]*template.Template
-}
-
-func (app *App) AddPath(path string) error {
-	return app.reloader.watcher.Add(path)
-}
+// runServe implements the default "serve" command.
+func runServe(args []string) error {
+	cfg, err := loadConfig("serve", args, os.Stderr)
+	if err != nil {
+		return err
+	}
 
-var app *App
+	if err := initLogging(cfg); err != nil {
+		return err
+	}
 
-func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.StartupTimeout))
 	defer cancel()
 
-	reloader := NewReloader()
+	reloader, err := NewReloader()
+	if err != nil {
+		return err
+	}
 	defer reloader.Close()
 
-	db, err := connectToDB(ctx)
+	assets, err := NewAssets(cfg.Server.DevMode)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-	defer db.Close()
-
-	app = &App{
-		DepartmentRepository:  NewDepartmentRepository(db),
-		PositionRepository:    NewPositionRepository(db),
-		EmployeeRepository:    NewEmployeeRepository(db),
-		ApplicationRepository: NewApplicationRepository(db),
-		LeaveRepository:       NewLeaveRepository(db),
-		reloader:              *reloader,
-		Templates:             loadTemplates(),
-	}
-
-	if devMode {
-		app.AddPath("templates/dashboard")
-		app.AddPath("templates/partials")
-		app.AddPath("static/css")
-
-		go func() {
-			for {
-				select {
-				case event, ok := <-app.reloader.watcher.Events:
-					if !ok {
-						return
-					}
-					if event.Op&fsnotify.Write == fsnotify.Write {
-						fmt.Printf("📝 File modified: %s. Notifying clients... 🔊\n", event.Name)
-						mu.Lock()
-						for client := range clients {
-							client <- true
-						}
-						mu.Unlock()
-					}
-				case err, ok := <-app.reloader.watcher.Errors:
-					if !ok {
-						return
-					}
-					log.Println("Watcher error:", err)
-				}
-			}
-		}()
-	}
-
-	fs := http.FileServer(http.Dir("static"))
-	http.Handle("/static/", http.StripPrefix("/static/", fs))
-
-	http.HandleFunc("/", app.handleIndex)
-	http.HandleFunc("/dev-reload", app.handleDevReload)
-	http.HandleFunc("/departments", app.handleDepartments)
-	http.HandleFunc("/departments/export", app.handleExportDepartments)
-	http.HandleFunc("/departments/add", app.handleAddDepartments)
-	http.HandleFunc("/departments/delete", app.handleDeleteDepartment)
-	http.HandleFunc("/departments/update/{id}", app.handleUpdateDepartment)
-	http.HandleFunc("/positions", app.handlePositions)
-	http.HandleFunc("/positions/export", app.handleExportPositions)
-	http.HandleFunc("/positions/add", app.handleAddPositions)
-	http.HandleFunc("/positions/delete", app.handleDeletePosition)
-	http.HandleFunc("/positions/update/{id}", app.handleUpdatePosition)
-	http.HandleFunc("/employees", app.handleEmployees)
-	http.HandleFunc("/employees/export", app.handleExportEmployees)
-	http.HandleFunc("/employees/add", app.handleAddEmployees)
-	http.HandleFunc("/employees/update/{id}", app.handleUpdateEmployee)
-	http.HandleFunc("/employees/delete", app.handleDeleteEmployee)
-	http.HandleFunc("/applications", app.handleApplications)
-	http.HandleFunc("/applications/export", app.handleExportApplications)
-	http.HandleFunc("/applications/add", app.handleAddApplications)
-	http.HandleFunc("/applications/update/{id}", app.handleUpdateApplication)
-	http.HandleFunc("/applications/delete", app.handleDeleteApplication)
-	http.HandleFunc("/leaves", app.handleLeaves)
-	http.HandleFunc("/leaves/export", app.handleExportLeaves)
-	http.HandleFunc("/leaves/add", app.handleAddLeaves)
-	http.HandleFunc("/leaves/update/{id}", app.handleUpdateLeave)
-	http.HandleFunc("/leaves/delete", app.handleDeleteLeave)
-
-	fmt.Println("🚀 Server starting on :8080... 🌐")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		fmt.Printf("Error starting server: %v\n", err)
+		return err
 	}
-}
-
-func loadTemplates() map[string]*template.Template {
-	tmpls := make(map[string]*template.Template)
-	baseFile := "templates/dashboard/base.html"
-	files := []string{}
-
-	partials, err := filepath.Glob("templates/partials/*.html")
+	templates, err := NewTemplateManager(assets, cfg.I18n)
+	if err != nil {
+		return err
+	}
+	tenants, err := openTenants(ctx, cfg, func(tc TenantConfig, db *DB) *App {
+		app := NewApp(cfg, NewSQLRepositories(db), templates)
+		app.reloader = reloader
+		app.Backups = NewBackupManager(db, cfg.tenantBackup(tc), cfg.tenantUploads(tc))
+		app.Files = NewFileStore(cfg.tenantUploads(tc))
+		return app
+	})
 	if err != nil {
-		log.Fatalf("Error globbing partials: %v", err)
+		return err
 	}
+	router := NewTenantRouter(tenants, cfg.defaultTenantID())
+	defer router.Close()
 
-	err = filepath.WalkDir("templates/dashboard/", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			// Handle the error here to prevent panic and continue the walk (or stop by returning the error)
-			fmt.Printf("preventing panic by handling failure accessing a path %q: %v\n", path, err)
-			return err
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	if cfg.Backup.Interval > 0 {
+		for _, t := range tenants {
+			go t.App.Backups.Schedule(bgCtx, time.Duration(cfg.Backup.Interval))
 		}
-
-		if d.IsDir() {
-			return nil
+	}
+	if cfg.Badge.ImportDir != "" {
+		for _, t := range tenants {
+			watcher := NewBadgeWatcher(cfg.tenantBadge(t.Config).ImportDir, t.App.Badges)
+			go func() {
+				if err := watcher.Run(bgCtx); err != nil {
+					slog.Error("badge file watcher stopped", "tenant", t.Config.ID, "err", err)
+				}
+			}()
 		}
+	}
 
-		files = append(files, path)
-		return nil
-	})
-	for _, file := range files {
-		name := filepath.Base(file)
-		if name == "base.html" {
-			continue
+	if cfg.Server.DevMode {
+		dirs, err := assets.templateDirs()
+		if err != nil {
+			return fmt.Errorf("listing template directories: %w", err)
 		}
-		allFiles := append([]string{baseFile, file}, partials...)
-		tmpls[name] = template.Must(template.ParseFiles(allFiles...))
+		for _, dir := range append(dirs, "static/css") {
+			if err := reloader.Add(dir); err != nil {
+				slog.Warn("cannot watch directory", "dir", dir, "err", err)
+			}
+		}
+		reloader.OnChange(func(name string) error {
+			if strings.HasSuffix(name, ".html") || strings.HasSuffix(name, ".json") {
+				return templates.Reload()
+			}
+			return nil
+		})
+		go reloader.Watch()
 	}
-	return tmpls
-}
 
-func (app *App) handleDevReload(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static/", assets.StaticHandler()))
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	mux.HandleFunc("GET /readyz", router.handleReadyz)
+	mux.Handle("GET /metrics", promhttp.Handler())
+	mux.Handle("/", router)
 
-	messageChan := make(chan bool)
-	mu.Lock()
-	clients[messageChan] = true
-	mu.Unlock()
+	srv := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      withRequestID(logRequests(mux)),
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout),
+	}
+	srv.RegisterOnShutdown(router.Drain)
+	srv.RegisterOnShutdown(reloader.CloseClients)
 
-	defer func() {
-		mu.Lock()
-		delete(clients, messageChan)
-		mu.Unlock()
+	return serve(srv, cfg.Server)
+}
+
+// serve runs srv until it fails or the process receives SIGINT/SIGTERM, in
+// which case in-flight requests get cfg.ShutdownTimeout to complete.
+func serve(srv *http.Server, cfg ServerConfig) error {
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		if cfg.TLSCertFile != "" {
+			slog.Info("server starting", "addr", srv.Addr, "tls", true)
+			errCh <- srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+			return
+		}
+		slog.Info("server starting", "addr", srv.Addr, "tls", false)
+		errCh <- srv.ListenAndServe()
 	}()
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
-		return
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("starting server: %w", err)
+	case <-sigCtx.Done():
 	}
+	stop()
 
-	for {
-		select {
-		case <-messageChan:
-			fmt.Fprintf(w, "data: reload\n\n")
-			flusher.Flush()
-		case <-r.Context().Done():
-			return
-		}
+	slog.Info("shutting down, waiting for in-flight requests")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutting down server: %w", err)
 	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+func (app *App) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", app.handleIndex)
+	mux.HandleFunc("/dev-reload", app.handleDevReload)
+	mux.HandleFunc("GET /locale", app.handleLocale)
+
+	// Everything else is HR's own. Self-service, expense claims and the
+	// overtime summary are open to all and check the viewer themselves.
+	admin := http.NewServeMux()
+	mux.Handle("/", app.hrOnly(admin))
+	admin.HandleFunc("/departments", app.handleDepartments)
+	admin.HandleFunc("/departments/export", app.handleExportDepartments)
+	admin.HandleFunc("/departments/add", app.handleAddDepartments)
+	admin.HandleFunc("/departments/delete", app.handleDeleteDepartment)
+	admin.HandleFunc("/departments/update/{id}", app.handleUpdateDepartment)
+	admin.HandleFunc("/positions", app.handlePositions)
+	admin.HandleFunc("/positions/export", app.handleExportPositions)
+	admin.HandleFunc("/positions/add", app.handleAddPositions)
+	admin.HandleFunc("/positions/delete", app.handleDeletePosition)
+	admin.HandleFunc("/positions/update/{id}", app.handleUpdatePosition)
+	admin.HandleFunc("/employees", app.handleEmployees)
+	admin.HandleFunc("/employees/export", app.handleExportEmployees)
+	admin.HandleFunc("/employees/add", app.handleAddEmployees)
+	admin.HandleFunc("/employees/update/{id}", app.handleUpdateEmployee)
+	admin.HandleFunc("/employees/delete", app.handleDeleteEmployee)
+	admin.HandleFunc("/applications", app.handleApplications)
+	admin.HandleFunc("/applications/export", app.handleExportApplications)
+	admin.HandleFunc("/applications/add", app.handleAddApplications)
+	admin.HandleFunc("/applications/update/{id}", app.handleUpdateApplication)
+	admin.HandleFunc("/applications/delete", app.handleDeleteApplication)
+	admin.HandleFunc("/leaves", app.handleLeaves)
+	admin.HandleFunc("/leaves/export", app.handleExportLeaves)
+	admin.HandleFunc("/leaves/add", app.handleAddLeaves)
+	admin.HandleFunc("/leaves/update/{id}", app.handleUpdateLeave)
+	admin.HandleFunc("/leaves/delete", app.handleDeleteLeave)
+	admin.HandleFunc("GET /attendance", app.handleAttendance)
+	admin.HandleFunc("GET /attendance/export", app.handleExportAttendance)
+	admin.HandleFunc("POST /attendance/clock", app.handleClock)
+	admin.HandleFunc("GET /attendance/corrections", app.handleCorrections)
+	admin.HandleFunc("/attendance/corrections/add", app.handleAddCorrection)
+	admin.HandleFunc("POST /attendance/corrections/{id}/{decision}", app.handleReviewCorrection)
+	admin.HandleFunc("GET /attendance/schedules", app.handleSchedules)
+	admin.HandleFunc("/attendance/schedules/add", app.handleAddSchedule)
+	admin.HandleFunc("/attendance/schedules/update/{id}", app.handleUpdateSchedule)
+	admin.HandleFunc("/attendance/schedules/delete", app.handleDeleteSchedule)
+	admin.HandleFunc("GET /attendance/imports", app.handleBadgeImports)
+	admin.HandleFunc("POST /attendance/imports", app.handleUploadBadgeFile)
+	admin.HandleFunc("GET /attendance/imports/{id}", app.handleBadgeImport)
+	admin.HandleFunc("GET /reviews", app.handleReviewCycles)
+	admin.HandleFunc("GET /reviews/export", app.handleExportRatings)
+	admin.HandleFunc("/reviews/add", app.handleAddReviewCycle)
+	admin.HandleFunc("/reviews/update/{id}", app.handleUpdateReviewCycle)
+	admin.HandleFunc("/reviews/delete", app.handleDeleteReviewCycle)
+	admin.HandleFunc("GET /reviews/cycles/{id}", app.handleReviewCycle)
+	admin.HandleFunc("POST /reviews/cycles/{id}/questions", app.handleAddReviewQuestion)
+	admin.HandleFunc("DELETE /reviews/cycles/{id}/questions", app.handleDeleteReviewQuestion)
+	admin.HandleFunc("POST /reviews/cycles/{id}/launch", app.handleLaunchReviewCycle)
+	admin.HandleFunc("POST /reviews/cycles/{id}/close", app.handleCloseReviewCycle)
+	admin.HandleFunc("POST /reviews/cycles/{id}/assessments", app.handleAssignReviewer)
+	admin.HandleFunc("GET /reviews/cycles/{id}/calibration", app.handleCalibration)
+	admin.HandleFunc("POST /reviews/cycles/{id}/ratings", app.handleSaveRating)
+	admin.HandleFunc("/reviews/assessments/{id}", app.handleReviewAssessment)
+	admin.HandleFunc("GET /reviews/history/{id}", app.handleEmployeeReviews)
+	admin.HandleFunc("GET /goals", app.handleGoals)
+	admin.HandleFunc("GET /goals/rollup", app.handleGoalsRollup)
+	admin.HandleFunc("/goals/add", app.handleAddObjective)
+	admin.HandleFunc("/goals/update/{id}", app.handleUpdateObjective)
+	admin.HandleFunc("/goals/delete", app.handleDeleteObjective)
+	admin.HandleFunc("GET /goals/objectives/{id}", app.handleObjective)
+	admin.HandleFunc("POST /goals/objectives/{id}/key-results", app.handleAddKeyResult)
+	admin.HandleFunc("DELETE /goals/objectives/{id}/key-results", app.handleDeleteKeyResult)
+	admin.HandleFunc("POST /goals/key-results/{id}/check-ins", app.handleCheckIn)
+	admin.HandleFunc("GET /training", app.handleTraining)
+	admin.HandleFunc("/training/add", app.handleAddCourse)
+	admin.HandleFunc("/training/update/{id}", app.handleUpdateCourse)
+	admin.HandleFunc("/training/delete", app.handleDeleteCourse)
+	admin.HandleFunc("GET /training/courses/{id}", app.handleCourse)
+	admin.HandleFunc("POST /training/courses/{id}/enrollments", app.handleEnroll)
+	admin.HandleFunc("DELETE /training/courses/{id}/enrollments", app.handleDeleteEnrollment)
+	admin.HandleFunc("POST /training/enrollments/{id}/complete", app.handleCompleteEnrollment)
+	admin.HandleFunc("GET /training/employees/{id}", app.handleEmployeeTraining)
+	admin.HandleFunc("POST /training/employees/{id}/certificates", app.handleAddCertificate)
+	admin.HandleFunc("DELETE /training/employees/{id}/certificates", app.handleDeleteCertificate)
+	admin.HandleFunc("GET /training/certificates/{id}/proof", app.handleCertificateProof)
+	admin.HandleFunc("GET /certifications", app.handleCertifications)
+	admin.HandleFunc("/certifications/add", app.handleAddCertification)
+	admin.HandleFunc("/certifications/update/{id}", app.handleUpdateCertification)
+	admin.HandleFunc("/certifications/delete", app.handleDeleteCertification)
+	admin.HandleFunc("GET /certifications/compliance", app.handleCompliance)
+	admin.HandleFunc("GET /certifications/reminders", app.handleCertificateReminders)
+	admin.HandleFunc("GET /certifications/reminders/export", app.handleExportCertificateReminders)
+	admin.HandleFunc("GET /documents", app.handleDocuments)
+	admin.HandleFunc("GET /documents/categories", app.handleDocumentCategories)
+	admin.HandleFunc("/documents/categories/add", app.handleAddDocumentCategory)
+	admin.HandleFunc("/documents/categories/update/{id}", app.handleUpdateDocumentCategory)
+	admin.HandleFunc("/documents/categories/delete", app.handleDeleteDocumentCategory)
+	admin.HandleFunc("GET /documents/employees/{id}", app.handleEmployeeDocuments)
+	admin.HandleFunc("POST /documents/employees/{id}", app.handleUploadDocument)
+	admin.HandleFunc("GET /documents/files/{id}", app.handleDocument)
+	admin.HandleFunc("PUT /documents/files/{id}", app.handleUpdateDocument)
+	admin.HandleFunc("POST /documents/files/{id}/versions", app.handleUploadDocumentVersion)
+	admin.HandleFunc("GET /documents/files/{id}/download", app.handleDownloadDocument)
+	admin.HandleFunc("/documents/delete", app.handleDeleteDocument)
+	admin.HandleFunc("GET /documents/downloads", app.handleDocumentDownloads)
+	admin.HandleFunc("GET /documents/downloads/export", app.handleExportDocumentDownloads)
+	admin.HandleFunc("GET /onboarding", app.handleOnboarding)
+	admin.HandleFunc("GET /onboarding/templates", app.handleOnboardingTemplates)
+	admin.HandleFunc("/onboarding/templates/add", app.handleAddOnboardingTemplate)
+	admin.HandleFunc("/onboarding/templates/update/{id}", app.handleUpdateOnboardingTemplate)
+	admin.HandleFunc("/onboarding/templates/delete", app.handleDeleteOnboardingTemplate)
+	admin.HandleFunc("POST /onboarding/templates/tasks", app.handleAddTemplateTask)
+	admin.HandleFunc("/onboarding/templates/tasks/delete", app.handleDeleteTemplateTask)
+	admin.HandleFunc("GET /onboarding/employees/{id}", app.handleEmployeeOnboarding)
+	admin.HandleFunc("POST /onboarding/employees/{id}", app.handleStartOnboarding)
+	admin.HandleFunc("POST /onboarding/tasks/{id}/done", app.handleToggleOnboardingTask)
+	admin.HandleFunc("/onboarding/tasks/delete", app.handleDeleteOnboardingTask)
+	admin.HandleFunc("GET /offboarding", app.handleOffboarding)
+	admin.HandleFunc("GET /offboarding/employees/{id}", app.handleEmployeeOffboarding)
+	admin.HandleFunc("POST /offboarding/employees/{id}", app.handleTerminate)
+	admin.HandleFunc("PUT /offboarding/employees/{id}", app.handleUpdateTermination)
+	admin.HandleFunc("GET /equipment", app.handleEquipment)
+	admin.HandleFunc("GET /equipment/outstanding", app.handleOutstandingEquipment)
+	admin.HandleFunc("/equipment/add", app.handleAddEquipment)
+	admin.HandleFunc("/equipment/update/{id}", app.handleUpdateEquipment)
+	admin.HandleFunc("/equipment/delete", app.handleDeleteEquipment)
+	admin.HandleFunc("GET /equipment/items/{id}", app.handleEquipmentItem)
+	admin.HandleFunc("POST /equipment/items/{id}/assign", app.handleAssignEquipment)
+	admin.HandleFunc("POST /equipment/items/{id}/return", app.handleReturnEquipment)
+	admin.HandleFunc("POST /equipment/items/{id}/conditions", app.handleLogCondition)
+	admin.HandleFunc("GET /equipment/employees/{id}", app.handleEmployeeEquipment)
+	admin.HandleFunc("GET /benefits", app.handleBenefits)
+	admin.HandleFunc("/benefits/add", app.handleAddBenefitPlan)
+	admin.HandleFunc("/benefits/update/{id}", app.handleUpdateBenefitPlan)
+	admin.HandleFunc("/benefits/delete", app.handleDeleteBenefitPlan)
+	admin.HandleFunc("GET /benefits/plans/{id}", app.handleBenefitPlan)
+	admin.HandleFunc("GET /benefits/plans/{id}/export", app.handleExportBenefitPlan)
+	admin.HandleFunc("GET /benefits/employees/{id}", app.handleEmployeeBenefits)
+	admin.HandleFunc("POST /benefits/employees/{id}/dependents", app.handleAddDependent)
+	admin.HandleFunc("DELETE /benefits/employees/{id}/dependents", app.handleDeleteDependent)
+	admin.HandleFunc("POST /benefits/employees/{id}/enrollments", app.handleEnrollBenefit)
+	admin.HandleFunc("POST /benefits/enrollments/{id}/end", app.handleEndBenefitEnrollment)
+	admin.HandleFunc("GET /rota", app.handleRota)
+	admin.HandleFunc("POST /rota/publish", app.handlePublishRota)
+	admin.HandleFunc("GET /rota/templates", app.handleShiftTemplates)
+	admin.HandleFunc("/rota/templates/add", app.handleAddShiftTemplate)
+	admin.HandleFunc("/rota/templates/update/{id}", app.handleUpdateShiftTemplate)
+	admin.HandleFunc("/rota/templates/delete", app.handleDeleteShiftTemplate)
+	admin.HandleFunc("POST /rota/shifts", app.handleAddShift)
+	admin.HandleFunc("DELETE /rota/shifts", app.handleDeleteShift)
+	admin.HandleFunc("POST /rota/shifts/{id}/move", app.handleMoveShift)
+	admin.HandleFunc("GET /rota/employees/{id}", app.handleEmployeeShifts)
+	admin.HandleFunc("GET /rota/employees/{id}/shifts.ics", app.handleShiftFeed)
+	admin.HandleFunc("GET /overtime", app.handleOvertime)
+	admin.HandleFunc("/overtime/add", app.handleAddOvertime)
+	admin.HandleFunc("/overtime/delete", app.handleDeleteOvertime)
+	admin.HandleFunc("GET /overtime/employees/{id}", app.handleEmployeeOvertime)
+	admin.HandleFunc("POST /overtime/{id}/{decision}", app.handleReviewOvertime)
+	admin.HandleFunc("GET /contact-changes", app.handleContactChanges)
+	admin.HandleFunc("POST /contact-changes/{id}/{decision}", app.handleReviewContactChange)
+	admin.HandleFunc("GET /admin/backups", app.handleBackups)
+	admin.HandleFunc("POST /admin/backups", app.handleCreateBackup)
+	admin.HandleFunc("GET /admin/backups/{name}", app.handleDownloadBackup)
+
+	mux.HandleFunc("GET /expenses", app.handleExpenses)
+	mux.HandleFunc("GET /expenses/export", app.handleExportExpenses)
+	mux.HandleFunc("/expenses/add", app.handleAddExpenseClaim)
+	mux.HandleFunc("/expenses/delete", app.handleDeleteExpenseClaim)
+	mux.HandleFunc("GET /expenses/claims/{id}", app.handleExpenseClaim)
+	mux.HandleFunc("POST /expenses/claims/{id}/items", app.handleAddExpenseItem)
+	mux.HandleFunc("DELETE /expenses/claims/{id}/items", app.handleDeleteExpenseItem)
+	mux.HandleFunc("POST /expenses/claims/{id}/submit", app.handleSubmitExpenseClaim)
+	mux.HandleFunc("POST /expenses/claims/{id}/reimburse", app.handleReimburseExpenseClaim)
+	mux.HandleFunc("POST /expenses/claims/{id}/{decision}", app.handleReviewExpenseClaim)
+	mux.HandleFunc("GET /expenses/receipts/{id}", app.handleExpenseReceipt)
+	mux.HandleFunc("GET /overtime/summary", app.handleOvertimeSummary)
+	mux.HandleFunc("GET /overtime/summary/export", app.handleExportOvertime)
+
+	mux.HandleFunc("GET /me", app.handleSelfProfile)
+	mux.HandleFunc("POST /me/contact", app.handleSelfContact)
+	mux.HandleFunc("GET /me/leaves", app.handleSelfLeaves)
+	mux.HandleFunc("POST /me/leaves", app.handleSelfRequestLeave)
+	mux.HandleFunc("POST /me/leaves/{id}/cancel", app.handleSelfCancelLeave)
+	mux.HandleFunc("GET /me/documents", app.handleSelfDocuments)
+	mux.HandleFunc("GET /me/documents/{id}/download", app.handleSelfDownloadDocument)
+	return mux
 }
 
+// handleIndex shows HR the dashboard and sends everyone else to their own
+// page.
 func (app *App) handleIndex(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+	if !app.isHR(app.viewer(r)) {
+		http.Redirect(w, r, "/me", http.StatusSeeOther)
 		return
 	}
 
-	if devMode {
-		app.Templates = loadTemplates()
-	}
-
 	data := map[string]any{
 		"ActivePage": "dashboard",
 	}
 
-	if err := app.Templates["index.html"].Execute(w, data); err != nil {
-		log.Printf("Template execution error: %v", err)
-	}
+	app.render(w, r, "index.html", "", data)
 }
 
 func (app *App) handleDepartments(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	departments, err := app.DepartmentRepository.GetDepartments(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching departments: %v", err)
-		http.Error(w, "Failed to fetch departments", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch departments", err)
 		return
 	}
 
@@ -246,28 +499,19 @@ func (app *App) handleDepartments(w http.ResponseWriter, r *http.Request) {
 		"Departments": departments,
 	}
 
-	if devMode {
-		app.Templates = loadTemplates()
-	}
-
 	if r.Header.Get("HX-Request") == "true" {
-		if err := app.Templates["departments.html"].ExecuteTemplate(w, "departments_partial", data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "departments.html", "departments_partial", data)
 		return
 	}
 
-	if err := app.Templates["departments.html"].Execute(w, data); err != nil {
-		log.Printf("Template execution error: %v", err)
-	}
+	app.render(w, r, "departments.html", "", data)
 }
 
 func (app *App) handlePositions(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	positions, err := app.PositionRepository.GetPositions(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching positions: %v", err)
-		http.Error(w, "Failed to fetch positions", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch positions", err)
 		return
 	}
 
@@ -276,28 +520,19 @@ func (app *App) handlePositions(w http.ResponseWriter, r *http.Request) {
 		"Positions":  positions,
 	}
 
-	if devMode {
-		app.Templates = loadTemplates()
-	}
-
 	if r.Header.Get("HX-Request") == "true" {
-		if err := app.Templates["positions.html"].ExecuteTemplate(w, "positions_partial", data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "positions.html", "positions_partial", data)
 		return
 	}
 
-	if err := app.Templates["positions.html"].Execute(w, data); err != nil {
-		log.Printf("Template execution error: %v", err)
-	}
+	app.render(w, r, "positions.html", "", data)
 }
 
 func (app *App) handleEmployees(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	employees, err := app.EmployeeRepository.GetEmployees(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching employees: %v", err)
-		http.Error(w, "Failed to fetch employees", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch employees", err)
 		return
 	}
 
@@ -306,28 +541,19 @@ func (app *App) handleEmployees(w http.ResponseWriter, r *http.Request) {
 		"Employees":  employees,
 	}
 
-	if devMode {
-		app.Templates = loadTemplates()
-	}
-
 	if r.Header.Get("HX-Request") == "true" {
-		if err := app.Templates["employees.html"].ExecuteTemplate(w, "employees_partial", data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "employees.html", "employees_partial", data)
 		return
 	}
 
-	if err := app.Templates["employees.html"].Execute(w, data); err != nil {
-		log.Printf("Template execution error: %v", err)
-	}
+	app.render(w, r, "employees.html", "", data)
 }
 
 func (app *App) handleApplications(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	applications, err := app.ApplicationRepository.GetApplications(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching applications: %v", err)
-		http.Error(w, "Failed to fetch applications", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch applications", err)
 		return
 	}
 
@@ -336,28 +562,19 @@ func (app *App) handleApplications(w http.ResponseWriter, r *http.Request) {
 		"Applications": applications,
 	}
 
-	if devMode {
-		app.Templates = loadTemplates()
-	}
-
 	if r.Header.Get("HX-Request") == "true" {
-		if err := app.Templates["applications.html"].ExecuteTemplate(w, "applications_partial", data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "applications.html", "applications_partial", data)
 		return
 	}
 
-	if err := app.Templates["applications.html"].Execute(w, data); err != nil {
-		log.Printf("Template execution error: %v", err)
-	}
+	app.render(w, r, "applications.html", "", data)
 }
 
 func (app *App) handleLeaves(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	leaves, err := app.LeaveRepository.GetLeaves(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching leaves: %v", err)
-		http.Error(w, "Failed to fetch leaves", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch leaves", err)
 		return
 	}
 
@@ -367,28 +584,22 @@ func (app *App) handleLeaves(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Header.Get("HX-Request") == "true" {
-		if err := app.Templates["leaves.html"].ExecuteTemplate(w, "leaves_partial", data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "leaves.html", "leaves_partial", data)
 		return
 	}
 
-	if err := app.Templates["leaves.html"].Execute(w, data); err != nil {
-		log.Printf("Template execution error: %v", err)
-	}
+	app.render(w, r, "leaves.html", "", data)
 }
 
 func (app *App) handleAddDepartments(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
-		if err := app.Templates["add_department.html"].Execute(w, nil); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "add_department.html", "", nil)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -398,7 +609,7 @@ func (app *App) handleAddDepartments(w http.ResponseWriter, r *http.Request) {
 	department := Department{Name: name, Description: description}
 	err = app.DepartmentRepository.CreateDepartment(r.Context(), &department)
 	if err != nil {
-		log.Printf("Error adding department : %v", err)
+		app.serverError(w, r, "Failed to add department", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/departments")
@@ -410,15 +621,19 @@ func (app *App) handleUpdateDepartment(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id") // Works in Go 1.22+
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	// 2. Handle GET: Show the form with existing data
 	if r.Method == http.MethodGet {
 		dept, err := app.DepartmentRepository.GetDepartmentByID(r.Context(), id)
-		if err != nil || dept == nil {
-			http.Error(w, "Department not found", http.StatusNotFound)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch department", err)
+			return
+		}
+		if dept == nil {
+			app.clientError(w, r, http.StatusNotFound, "Department not found")
 			return
 		}
 
@@ -426,15 +641,13 @@ func (app *App) handleUpdateDepartment(w http.ResponseWriter, r *http.Request) {
 			"Department": dept,
 		}
 
-		if err := app.Templates["update_department.html"].Execute(w, data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "update_department.html", "", data)
 		return
 	}
 
 	// 3. Handle POST/PUT: Save the changes
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -445,8 +658,7 @@ func (app *App) handleUpdateDepartment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := app.DepartmentRepository.UpdateDepartment(r.Context(), &department); err != nil {
-		log.Printf("Error updating department: %v", err)
-		http.Error(w, "Failed to update", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to update", err)
 		return
 	}
 
@@ -456,23 +668,23 @@ func (app *App) handleUpdateDepartment(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) handleDeleteDepartment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		fmt.Println("method is not delete")
+		app.methodNotAllowed(w, r, http.MethodDelete)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 	id, err := strconv.Atoi(r.FormValue("id"))
 	if err != nil {
-		http.Error(w, "can't parse id", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
 		return
 	}
 	err = app.DepartmentRepository.DeleteDepartment(r.Context(), id)
 	if err != nil {
-		http.Error(w, "can't delete department", http.StatusInternalServerError)
+		app.serverError(w, r, "can't delete department", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/departments")
@@ -483,8 +695,7 @@ func (app *App) handleExportDepartments(w http.ResponseWriter, r *http.Request)
 	q := r.URL.Query().Get("q")
 	departments, err := app.DepartmentRepository.GetDepartments(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching departments for export: %v", err)
-		http.Error(w, "Failed to fetch departments", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch departments", err)
 		return
 	}
 
@@ -498,18 +709,14 @@ func (app *App) handleExportDepartments(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	SetExcelHeaders(w, "Departments")
-	if err := ExportToExcel(w, departments, headers, mapper); err != nil {
-		log.Printf("Error exporting departments: %v", err)
-	}
+	writeExport(w, r, "Departments", departments, headers, mapper)
 }
 
 func (app *App) handleExportPositions(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	positions, err := app.PositionRepository.GetPositions(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching positions for export: %v", err)
-		http.Error(w, "Failed to fetch positions", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch positions", err)
 		return
 	}
 
@@ -523,23 +730,18 @@ func (app *App) handleExportPositions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	SetExcelHeaders(w, "Positions")
-	if err := ExportToExcel(w, positions, headers, mapper); err != nil {
-		log.Printf("Error exporting positions: %v", err)
-	}
+	writeExport(w, r, "Positions", positions, headers, mapper)
 }
 
 func (app *App) handleAddPositions(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
-		if err := app.Templates["add_position.html"].Execute(w, nil); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "add_position.html", "", nil)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -549,7 +751,7 @@ func (app *App) handleAddPositions(w http.ResponseWriter, r *http.Request) {
 	position := Position{Name: name, Description: description}
 	err = app.PositionRepository.CreatePosition(r.Context(), &position)
 	if err != nil {
-		log.Printf("Error adding position : %v", err)
+		app.serverError(w, r, "Failed to add position", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/positions")
@@ -560,15 +762,19 @@ func (app *App) handleUpdatePosition(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	// 2. Handle GET: Show the form with existing data
 	if r.Method == http.MethodGet {
 		pos, err := app.PositionRepository.GetPositionByID(r.Context(), id)
-		if err != nil || pos == nil {
-			http.Error(w, "Position not found", http.StatusNotFound)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch position", err)
+			return
+		}
+		if pos == nil {
+			app.clientError(w, r, http.StatusNotFound, "Position not found")
 			return
 		}
 
@@ -576,15 +782,13 @@ func (app *App) handleUpdatePosition(w http.ResponseWriter, r *http.Request) {
 			"Position": pos,
 		}
 
-		if err := app.Templates["update_position.html"].Execute(w, data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "update_position.html", "", data)
 		return
 	}
 
 	// 3. Handle POST/PUT: Save the changes
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -595,8 +799,7 @@ func (app *App) handleUpdatePosition(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := app.PositionRepository.UpdatePosition(r.Context(), &position); err != nil {
-		log.Printf("Error updating position: %v", err)
-		http.Error(w, "Failed to update", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to update", err)
 		return
 	}
 
@@ -606,34 +809,39 @@ func (app *App) handleUpdatePosition(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) handleDeletePosition(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
 	id, err := strconv.Atoi(r.FormValue("id"))
 	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	err = app.PositionRepository.DeletePosition(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to delete position", err)
 		return
 	}
-	app.PositionRepository.DeletePosition(r.Context(), id)
 	w.Header().Set("HX-Redirect", "/positions")
 	w.WriteHeader(http.StatusSeeOther)
-
 }
 
 func (app *App) handleExportEmployees(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	employees, err := app.EmployeeRepository.GetEmployees(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching employees for export: %v", err)
-		http.Error(w, "Failed to fetch employees", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch employees", err)
 		return
 	}
 
-	headers := []string{"ID", "First Name", "Last Name", "Email", "Job Title", "Hire Date", "Salary", "Status", "Department ID", "Created At"}
+	headers := []string{"ID", "First Name", "Last Name", "Email", "Job Title", "Hire Date", "Salary", "Status", "Department ID", "Card Number", "Created At"}
 	mapper := func(e Employee) []string {
 		return []string{
 			fmt.Sprintf("%d", e.ID),
@@ -645,37 +853,91 @@ func (app *App) handleExportEmployees(w http.ResponseWriter, r *http.Request) {
 			fmt.Sprintf("%.2f", e.Salary),
 			e.Status,
 			fmt.Sprintf("%d", e.DepartmentID),
+			e.CardNumber,
 			e.CreatedAt.Format("2006-01-02 15:04:05"),
 		}
 	}
 
-	SetExcelHeaders(w, "Employees")
-	if err := ExportToExcel(w, employees, headers, mapper); err != nil {
-		log.Printf("Error exporting employees: %v", err)
+	writeExport(w, r, "Employees", employees, headers, mapper)
+}
+
+// checkManager writes the error response and returns false unless the
+// employee reports to nobody or to another employee on file.
+func (app *App) checkManager(w http.ResponseWriter, r *http.Request, e *Employee) bool {
+	if e.ManagerID == 0 {
+		return true
 	}
+	if e.ManagerID == e.ID {
+		app.clientError(w, r, http.StatusBadRequest, "An employee can't report to themselves")
+		return false
+	}
+	manager, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), e.ManagerID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return false
+	}
+	if manager == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid manager")
+		return false
+	}
+	return true
 }
 
 func (app *App) handleAddEmployees(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
-		if err := app.Templates["add_employee.html"].Execute(w, nil); err != nil {
-			log.Printf("Template execution error: %v", err)
+		employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
 		}
+		data := map[string]any{
+			"Employees": slices.DeleteFunc(employees, func(e Employee) bool { return !isActive(e) }),
+		}
+		app.render(w, r, "add_employee.html", "", data)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
-	firstName := r.FormValue("first_name")
-	lastName := r.FormValue("last_name")
+	hireDate, err := time.Parse("2006-01-02", r.FormValue("hire_date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid hire date")
+		return
+	}
+	salary, _ := strconv.ParseFloat(r.FormValue("salary"), 64)
+	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	managerID, _ := strconv.Atoi(r.FormValue("manager_id"))
 
-	employee := Employee{FirstName: firstName, LastName: lastName}
+	employee := Employee{
+		FirstName:    r.FormValue("first_name"),
+		LastName:     r.FormValue("last_name"),
+		Email:        r.FormValue("email"),
+		JobTitle:     r.FormValue("job_title"),
+		HireDate:     hireDate,
+		Salary:       salary,
+		Status:       r.FormValue("status"),
+		DepartmentID: deptID,
+		ManagerID:    managerID,
+		CardNumber:   strings.TrimSpace(r.FormValue("card_number")),
+	}
+	if employee.Status == employeeTerminated {
+		app.clientError(w, r, http.StatusBadRequest, "Employees are terminated from offboarding")
+		return
+	}
+	if !app.checkManager(w, r, &employee) {
+		return
+	}
 	err = app.EmployeeRepository.CreateEmployee(r.Context(), &employee)
 	if err != nil {
-		log.Printf("Error adding employee : %v", err)
+		app.serverError(w, r, "Failed to add employee", err)
+		return
+	}
+	if _, err := app.startOnboarding(r.Context(), &employee); err != nil {
+		app.serverError(w, r, "Failed to create onboarding tasks", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/employees")
@@ -686,33 +948,42 @@ func (app *App) handleUpdateEmployee(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	if r.Method == http.MethodGet {
 		emp, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), id)
-		if err != nil || emp == nil {
-			http.Error(w, "Employee not found", http.StatusNotFound)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employee", err)
+			return
+		}
+		if emp == nil {
+			app.clientError(w, r, http.StatusNotFound, "Employee not found")
 			return
 		}
 
-		data := map[string]any{
-			"Emp": emp,
+		employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
 		}
-		if err := app.Templates["update_employee.html"].Execute(w, data); err != nil {
-			log.Printf("Template execution error: %v", err)
+		data := map[string]any{
+			"Employee":  emp,
+			"Employees": slices.DeleteFunc(employees, func(e Employee) bool { return !isActive(e) && e.ID != emp.ManagerID }),
 		}
+		app.render(w, r, "update_employee.html", "", data)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
 	salary, _ := strconv.ParseFloat(r.FormValue("salary"), 64)
 	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	managerID, _ := strconv.Atoi(r.FormValue("manager_id"))
 	hireDate, _ := time.Parse("2006-01-02", r.FormValue("hire_date"))
 
 	employee := Employee{
@@ -724,13 +995,34 @@ func (app *App) handleUpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		Salary:       salary,
 		Status:       r.FormValue("status"),
 		DepartmentID: deptID,
+		ManagerID:    managerID,
 		HireDate:     hireDate,
+		CardNumber:   strings.TrimSpace(r.FormValue("card_number")),
+	}
+	if !app.checkManager(w, r, &employee) {
+		return
+	}
+
+	// Terminating an employee, and undoing it, goes through offboarding,
+	// which keeps the termination record, their leaves and checklist in
+	// step with the status.
+	current, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	if current == nil {
+		app.clientError(w, r, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if (current.Status == employeeTerminated) != (employee.Status == employeeTerminated) {
+		app.clientError(w, r, http.StatusConflict, "Employees are terminated from offboarding")
+		return
 	}
 
 	err = app.EmployeeRepository.UpdateEmployee(r.Context(), &employee)
 	if err != nil {
-		log.Printf("Error updating employee : %v", err)
-		http.Error(w, "Failed to update employee", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to update employee", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/employees")
@@ -739,30 +1031,35 @@ func (app *App) handleUpdateEmployee(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) handleDeleteEmployee(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
 	id, err := strconv.Atoi(r.FormValue("id"))
 	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	err = app.EmployeeRepository.DeleteEmployee(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to delete employee", err)
 		return
 	}
-	app.EmployeeRepository.DeleteEmployee(r.Context(), id)
 	w.Header().Set("HX-Redirect", "/employees")
 	w.WriteHeader(http.StatusSeeOther)
-
 }
 
 func (app *App) handleExportApplications(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	applications, err := app.ApplicationRepository.GetApplications(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching applications for export: %v", err)
-		http.Error(w, "Failed to fetch applications", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch applications", err)
 		return
 	}
 
@@ -780,23 +1077,18 @@ func (app *App) handleExportApplications(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	SetExcelHeaders(w, "Applications")
-	if err := ExportToExcel(w, applications, headers, mapper); err != nil {
-		log.Printf("Error exporting applications: %v", err)
-	}
+	writeExport(w, r, "Applications", applications, headers, mapper)
 }
 
 func (app *App) handleAddApplications(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
-		if err := app.Templates["add_application.html"].Execute(w, nil); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "add_application.html", "", nil)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -810,7 +1102,7 @@ func (app *App) handleAddApplications(w http.ResponseWriter, r *http.Request) {
 	application := Application{Name: name, Email: email, Phone: phone, AppliedFor: appliedFor, ResumeURL: resumeURL, Status: status}
 	err = app.ApplicationRepository.CreateApplication(r.Context(), &application)
 	if err != nil {
-		log.Printf("Error adding application : %v", err)
+		app.serverError(w, r, "Failed to add application", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/applications")
@@ -819,20 +1111,25 @@ func (app *App) handleAddApplications(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) handleDeleteApplication(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
 	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
 
 	err = app.ApplicationRepository.DeleteApplication(r.Context(), id)
 	if err != nil {
-		log.Printf("Error deleting application : %v", err)
+		app.serverError(w, r, "Failed to delete application", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/applications")
@@ -843,28 +1140,30 @@ func (app *App) handleUpdateApplication(w http.ResponseWriter, r *http.Request)
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	if r.Method == http.MethodGet {
 		appData, err := app.ApplicationRepository.GetApplicationByID(r.Context(), id)
-		if err != nil || appData == nil {
-			http.Error(w, "Application not found", http.StatusNotFound)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch application", err)
+			return
+		}
+		if appData == nil {
+			app.clientError(w, r, http.StatusNotFound, "Application not found")
 			return
 		}
 
 		data := map[string]any{
 			"Application": appData,
 		}
-		if err := app.Templates["update_application.html"].Execute(w, data); err != nil {
-			log.Printf("Template execution error: %v", err)
-		}
+		app.render(w, r, "update_application.html", "", data)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -880,8 +1179,7 @@ func (app *App) handleUpdateApplication(w http.ResponseWriter, r *http.Request)
 
 	err = app.ApplicationRepository.UpdateApplication(r.Context(), &application)
 	if err != nil {
-		log.Printf("Error updating application : %v", err)
-		http.Error(w, "Failed to update application", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to update application", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/applications")
@@ -892,8 +1190,7 @@ func (app *App) handleExportLeaves(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	leaves, err := app.LeaveRepository.GetLeaves(r.Context(), q)
 	if err != nil {
-		log.Printf("Error fetching leaves for export: %v", err)
-		http.Error(w, "Failed to fetch leaves", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to fetch leaves", err)
 		return
 	}
 
@@ -911,37 +1208,56 @@ func (app *App) handleExportLeaves(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	SetExcelHeaders(w, "Leaves")
-	if err := ExportToExcel(w, leaves, headers, mapper); err != nil {
-		log.Printf("Error exporting leaves: %v", err)
-	}
+	writeExport(w, r, "Leaves", leaves, headers, mapper)
 }
 
 func (app *App) handleAddLeaves(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
-		if err := app.Templates["add_leave.html"].Execute(w, nil); err != nil {
-			log.Printf("Template execution error: %v", err)
+		employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
 		}
+		data := map[string]any{
+			"ActivePage": "leaves",
+			"Employees":  employees,
+		}
+		app.render(w, r, "add_leave.html", "", data)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
 	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
-	leaveType := r.FormValue("leave_type")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
 	startDate, err := time.Parse("2006-01-02", r.FormValue("start_date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid start date")
+		return
+	}
 	endDate, err := time.Parse("2006-01-02", r.FormValue("end_date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid end date")
+		return
+	}
+	leaveType := r.FormValue("leave_type")
 	status := r.FormValue("status")
 	reason := r.FormValue("reason")
 
 	leave := Leave{EmployeeID: employeeID, LeaveType: leaveType, StartDate: startDate, EndDate: endDate, Status: status, Reason: reason}
+	if !app.checkToilLeave(w, r, leave) {
+		return
+	}
 	err = app.LeaveRepository.CreateLeave(r.Context(), &leave)
 	if err != nil {
-		log.Printf("Error adding leave : %v", err)
+		app.serverError(w, r, "Failed to add leave", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/leaves")
@@ -950,20 +1266,25 @@ func (app *App) handleAddLeaves(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) handleDeleteLeave(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
 		return
 	}
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
 	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
 
 	err = app.LeaveRepository.DeleteLeave(r.Context(), id)
 	if err != nil {
-		log.Printf("Error deleting leave : %v", err)
+		app.serverError(w, r, "Failed to delete leave", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/leaves")
@@ -974,28 +1295,38 @@ func (app *App) handleUpdateLeave(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	if r.Method == http.MethodGet {
 		leave, err := app.LeaveRepository.GetLeaveByID(r.Context(), id)
-		if err != nil || leave == nil {
-			http.Error(w, "Leave not found", http.StatusNotFound)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch leave", err)
+			return
+		}
+		if leave == nil {
+			app.clientError(w, r, http.StatusNotFound, "Leave not found")
 			return
 		}
 
-		data := map[string]any{
-			"Leave": leave,
+		employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
 		}
-		if err := app.Templates["update_leave.html"].Execute(w, data); err != nil {
-			log.Printf("Template execution error: %v", err)
+
+		data := map[string]any{
+			"ActivePage": "leaves",
+			"Leave":      leave,
+			"Employees":  employees,
 		}
+		app.render(w, r, "update_leave.html", "", data)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "can't parse form", http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
 		return
 	}
 
@@ -1012,11 +1343,13 @@ func (app *App) handleUpdateLeave(w http.ResponseWriter, r *http.Request) {
 		Status:     r.FormValue("status"),
 		Reason:     r.FormValue("reason"),
 	}
+	if !app.checkToilLeave(w, r, leave) {
+		return
+	}
 
 	err = app.LeaveRepository.UpdateLeave(r.Context(), &leave)
 	if err != nil {
-		log.Printf("Error updating leave : %v", err)
-		http.Error(w, "Failed to update leave", http.StatusInternalServerError)
+		app.serverError(w, r, "Failed to update leave", err)
 		return
 	}
 	w.Header().Set("HX-Redirect", "/leaves")
@@ -0,0 +1,1791 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errDuplicate is returned by the in-memory repositories where the SQL
+// schema has a UNIQUE constraint.
+var errDuplicate = errors.New("duplicate value violates unique constraint")
+
+// memoryTable is a goroutine-safe table of rows ordered by an
+// auto-incrementing id. It is the in-memory counterpart of one SQL table.
+type memoryTable[T any] struct {
+	mu     sync.RWMutex
+	rows   []T
+	nextID int
+
+	// id returns a pointer to the id field of a row.
+	id func(*T) *int
+	// created is called on new rows to set their creation time.
+	created func(*T, time.Time)
+	// conflicts reports whether two rows break a unique constraint.
+	conflicts func(a, b *T) bool
+}
+
+func newMemoryTable[T any](id func(*T) *int, created func(*T, time.Time), conflicts func(a, b *T) bool) *memoryTable[T] {
+	return &memoryTable[T]{nextID: 1, id: id, created: created, conflicts: conflicts}
+}
+
+func (t *memoryTable[T]) list(match func(*T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var rows []T
+	for i := range t.rows {
+		if match(&t.rows[i]) {
+			rows = append(rows, t.rows[i])
+		}
+	}
+	return rows
+}
+
+func (t *memoryTable[T]) get(id int) *T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if i := t.index(id); i >= 0 {
+		row := t.rows[i]
+		return &row
+	}
+	return nil
+}
+
+func (t *memoryTable[T]) insert(row *T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conflict(row, -1) {
+		return errDuplicate
+	}
+	*t.id(row) = t.nextID
+	t.nextID++
+	t.created(row, time.Now().UTC())
+	t.rows = append(t.rows, *row)
+	return nil
+}
+
+// update replaces the stored row with the same id, keeping its creation
+// time. Like an SQL UPDATE, it does nothing when there is no such row.
+func (t *memoryTable[T]) update(row *T, keep func(dst, src *T)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := t.index(*t.id(row))
+	if i < 0 {
+		return nil
+	}
+	if t.conflict(row, i) {
+		return errDuplicate
+	}
+	updated := *row
+	keep(&updated, &t.rows[i])
+	t.rows[i] = updated
+	return nil
+}
+
+func (t *memoryTable[T]) delete(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if i := t.index(id); i >= 0 {
+		t.rows = slices.Delete(t.rows, i, i+1)
+	}
+}
+
+// replace deletes the rows matching match and inserts rows in their place
+// as one step.
+func (t *memoryTable[T]) replace(match func(*T) bool, rows []T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows = slices.DeleteFunc(t.rows, func(row T) bool { return match(&row) })
+	for _, row := range rows {
+		*t.id(&row) = t.nextID
+		t.nextID++
+		t.created(&row, time.Now().UTC())
+		t.rows = append(t.rows, row)
+	}
+}
+
+func (t *memoryTable[T]) index(id int) int {
+	return slices.IndexFunc(t.rows, func(row T) bool { return *t.id(&row) == id })
+}
+
+func (t *memoryTable[T]) conflict(row *T, skip int) bool {
+	if t.conflicts == nil {
+		return false
+	}
+	for i := range t.rows {
+		if i != skip && t.conflicts(row, &t.rows[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether any of fields contains q, ignoring case,
+// which is what the SQL repositories' LOWER(...) LIKE LOWER('%q%') does.
+func containsFold(q string, fields ...string) bool {
+	q = strings.ToLower(q)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), q) {
+			return true
+		}
+	}
+	return false
+}
+
+type MemoryDepartmentRepository struct {
+	table *memoryTable[Department]
+}
+
+type MemoryPositionRepository struct {
+	table *memoryTable[Position]
+}
+
+type MemoryEmployeeRepository struct {
+	table *memoryTable[Employee]
+}
+
+type MemoryApplicationRepository struct {
+	table *memoryTable[Application]
+}
+
+type MemoryLeaveRepository struct {
+	table *memoryTable[Leave]
+}
+
+type MemoryAttendanceRepository struct {
+	table *memoryTable[AttendanceEvent]
+}
+
+type MemoryWorkScheduleRepository struct {
+	table *memoryTable[WorkSchedule]
+}
+
+type MemoryAttendanceCorrectionRepository struct {
+	table *memoryTable[AttendanceCorrection]
+}
+
+type MemoryBadgeImportRepository struct {
+	table *memoryTable[BadgeImport]
+}
+
+type MemoryReviewCycleRepository struct {
+	table *memoryTable[ReviewCycle]
+}
+
+type MemoryReviewQuestionRepository struct {
+	table *memoryTable[ReviewQuestion]
+}
+
+type MemoryReviewAssessmentRepository struct {
+	table *memoryTable[ReviewAssessment]
+}
+
+type MemoryObjectiveRepository struct {
+	table *memoryTable[Objective]
+}
+
+type MemoryKeyResultRepository struct {
+	table *memoryTable[KeyResult]
+}
+
+type MemoryCheckInRepository struct {
+	table *memoryTable[CheckIn]
+}
+
+type MemoryCourseRepository struct {
+	table *memoryTable[Course]
+}
+
+type MemoryEnrollmentRepository struct {
+	table *memoryTable[Enrollment]
+}
+
+type MemoryCertificationRepository struct {
+	table *memoryTable[Certification]
+}
+
+type MemoryEmployeeCertificationRepository struct {
+	table *memoryTable[EmployeeCertification]
+}
+
+type MemoryDocumentCategoryRepository struct {
+	table *memoryTable[DocumentCategory]
+}
+
+type MemoryDocumentRepository struct {
+	table *memoryTable[Document]
+}
+
+type MemoryDocumentVersionRepository struct {
+	table *memoryTable[DocumentVersion]
+}
+
+type MemoryDocumentDownloadRepository struct {
+	table *memoryTable[DocumentDownload]
+}
+
+type MemoryOnboardingTemplateRepository struct {
+	table *memoryTable[OnboardingTemplate]
+}
+
+type MemoryOnboardingTemplateTaskRepository struct {
+	table *memoryTable[OnboardingTemplateTask]
+}
+
+type MemoryOnboardingTaskRepository struct {
+	table *memoryTable[OnboardingTask]
+}
+
+type MemoryTerminationRepository struct {
+	table *memoryTable[Termination]
+}
+
+type MemoryEquipmentRepository struct {
+	table *memoryTable[Equipment]
+}
+
+type MemoryEquipmentAssignmentRepository struct {
+	table *memoryTable[EquipmentAssignment]
+}
+
+type MemoryEquipmentConditionRepository struct {
+	table *memoryTable[EquipmentCondition]
+}
+
+type MemoryExpenseClaimRepository struct {
+	table *memoryTable[ExpenseClaim]
+}
+
+type MemoryExpenseItemRepository struct {
+	table *memoryTable[ExpenseItem]
+}
+
+type MemoryBenefitPlanRepository struct {
+	table *memoryTable[BenefitPlan]
+}
+
+type MemoryDependentRepository struct {
+	table *memoryTable[Dependent]
+}
+
+type MemoryBenefitEnrollmentRepository struct {
+	table *memoryTable[BenefitEnrollment]
+}
+
+type MemoryShiftTemplateRepository struct {
+	table *memoryTable[ShiftTemplate]
+}
+
+type MemoryShiftRepository struct {
+	table *memoryTable[Shift]
+}
+
+type MemoryOvertimeRequestRepository struct {
+	table *memoryTable[OvertimeRequest]
+}
+
+type MemoryContactChangeRepository struct {
+	table *memoryTable[ContactChange]
+}
+
+type MemoryEmployeeRatingRepository struct {
+	mu    sync.Mutex // serializes SaveEmployeeRating's lookup and write
+	table *memoryTable[EmployeeRating]
+}
+
+func NewMemoryDepartmentRepository() *MemoryDepartmentRepository {
+	return &MemoryDepartmentRepository{table: newMemoryTable(
+		func(d *Department) *int { return &d.ID },
+		func(d *Department, t time.Time) { d.CreatedAt = t },
+		func(a, b *Department) bool { return a.Name == b.Name },
+	)}
+}
+
+func NewMemoryPositionRepository() *MemoryPositionRepository {
+	return &MemoryPositionRepository{table: newMemoryTable(
+		func(p *Position) *int { return &p.ID },
+		func(p *Position, t time.Time) { p.CreatedAt = t },
+		func(a, b *Position) bool { return a.Name == b.Name },
+	)}
+}
+
+func NewMemoryEmployeeRepository() *MemoryEmployeeRepository {
+	return &MemoryEmployeeRepository{table: newMemoryTable(
+		func(e *Employee) *int { return &e.ID },
+		func(e *Employee, t time.Time) { e.CreatedAt = t },
+		func(a, b *Employee) bool {
+			return a.Email == b.Email || a.CardNumber != "" && a.CardNumber == b.CardNumber
+		},
+	)}
+}
+
+func NewMemoryApplicationRepository() *MemoryApplicationRepository {
+	return &MemoryApplicationRepository{table: newMemoryTable(
+		func(a *Application) *int { return &a.ID },
+		func(a *Application, t time.Time) { a.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryLeaveRepository() *MemoryLeaveRepository {
+	return &MemoryLeaveRepository{table: newMemoryTable(
+		func(l *Leave) *int { return &l.ID },
+		func(l *Leave, t time.Time) { l.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryAttendanceRepository() *MemoryAttendanceRepository {
+	return &MemoryAttendanceRepository{table: newMemoryTable(
+		func(e *AttendanceEvent) *int { return &e.ID },
+		func(e *AttendanceEvent, t time.Time) { e.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryWorkScheduleRepository() *MemoryWorkScheduleRepository {
+	return &MemoryWorkScheduleRepository{table: newMemoryTable(
+		func(ws *WorkSchedule) *int { return &ws.ID },
+		func(ws *WorkSchedule, t time.Time) { ws.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryAttendanceCorrectionRepository() *MemoryAttendanceCorrectionRepository {
+	return &MemoryAttendanceCorrectionRepository{table: newMemoryTable(
+		func(c *AttendanceCorrection) *int { return &c.ID },
+		func(c *AttendanceCorrection, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryBadgeImportRepository() *MemoryBadgeImportRepository {
+	return &MemoryBadgeImportRepository{table: newMemoryTable(
+		func(bi *BadgeImport) *int { return &bi.ID },
+		func(bi *BadgeImport, t time.Time) { bi.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryReviewCycleRepository() *MemoryReviewCycleRepository {
+	return &MemoryReviewCycleRepository{table: newMemoryTable(
+		func(c *ReviewCycle) *int { return &c.ID },
+		func(c *ReviewCycle, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryReviewQuestionRepository() *MemoryReviewQuestionRepository {
+	return &MemoryReviewQuestionRepository{table: newMemoryTable(
+		func(q *ReviewQuestion) *int { return &q.ID },
+		func(q *ReviewQuestion, t time.Time) { q.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryReviewAssessmentRepository() *MemoryReviewAssessmentRepository {
+	return &MemoryReviewAssessmentRepository{table: newMemoryTable(
+		func(a *ReviewAssessment) *int { return &a.ID },
+		func(a *ReviewAssessment, t time.Time) { a.CreatedAt = t },
+		func(a, b *ReviewAssessment) bool {
+			return a.CycleID == b.CycleID && a.EmployeeID == b.EmployeeID && a.ReviewerID == b.ReviewerID && a.Kind == b.Kind
+		},
+	)}
+}
+
+func NewMemoryEmployeeRatingRepository() *MemoryEmployeeRatingRepository {
+	return &MemoryEmployeeRatingRepository{table: newMemoryTable(
+		func(er *EmployeeRating) *int { return &er.ID },
+		func(er *EmployeeRating, t time.Time) { er.CreatedAt = t },
+		func(a, b *EmployeeRating) bool { return a.EmployeeID == b.EmployeeID && a.CycleID == b.CycleID },
+	)}
+}
+
+func NewMemoryObjectiveRepository() *MemoryObjectiveRepository {
+	return &MemoryObjectiveRepository{table: newMemoryTable(
+		func(o *Objective) *int { return &o.ID },
+		func(o *Objective, t time.Time) { o.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryKeyResultRepository() *MemoryKeyResultRepository {
+	return &MemoryKeyResultRepository{table: newMemoryTable(
+		func(kr *KeyResult) *int { return &kr.ID },
+		func(kr *KeyResult, t time.Time) { kr.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryCheckInRepository() *MemoryCheckInRepository {
+	return &MemoryCheckInRepository{table: newMemoryTable(
+		func(c *CheckIn) *int { return &c.ID },
+		func(c *CheckIn, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryCourseRepository() *MemoryCourseRepository {
+	return &MemoryCourseRepository{table: newMemoryTable(
+		func(c *Course) *int { return &c.ID },
+		func(c *Course, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryEnrollmentRepository() *MemoryEnrollmentRepository {
+	return &MemoryEnrollmentRepository{table: newMemoryTable(
+		func(e *Enrollment) *int { return &e.ID },
+		func(e *Enrollment, t time.Time) { e.CreatedAt = t },
+		func(a, b *Enrollment) bool { return a.CourseID == b.CourseID && a.EmployeeID == b.EmployeeID },
+	)}
+}
+
+func NewMemoryCertificationRepository() *MemoryCertificationRepository {
+	return &MemoryCertificationRepository{table: newMemoryTable(
+		func(c *Certification) *int { return &c.ID },
+		func(c *Certification, t time.Time) { c.CreatedAt = t },
+		func(a, b *Certification) bool { return a.Name == b.Name },
+	)}
+}
+
+func NewMemoryEmployeeCertificationRepository() *MemoryEmployeeCertificationRepository {
+	return &MemoryEmployeeCertificationRepository{table: newMemoryTable(
+		func(ec *EmployeeCertification) *int { return &ec.ID },
+		func(ec *EmployeeCertification, t time.Time) { ec.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryDocumentCategoryRepository() *MemoryDocumentCategoryRepository {
+	return &MemoryDocumentCategoryRepository{table: newMemoryTable(
+		func(c *DocumentCategory) *int { return &c.ID },
+		func(c *DocumentCategory, t time.Time) { c.CreatedAt = t },
+		func(a, b *DocumentCategory) bool { return a.Name == b.Name },
+	)}
+}
+
+func NewMemoryDocumentRepository() *MemoryDocumentRepository {
+	return &MemoryDocumentRepository{table: newMemoryTable(
+		func(d *Document) *int { return &d.ID },
+		func(d *Document, t time.Time) { d.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryDocumentVersionRepository() *MemoryDocumentVersionRepository {
+	return &MemoryDocumentVersionRepository{table: newMemoryTable(
+		func(v *DocumentVersion) *int { return &v.ID },
+		func(v *DocumentVersion, t time.Time) { v.CreatedAt = t },
+		func(a, b *DocumentVersion) bool { return a.DocumentID == b.DocumentID && a.Version == b.Version },
+	)}
+}
+
+func NewMemoryDocumentDownloadRepository() *MemoryDocumentDownloadRepository {
+	return &MemoryDocumentDownloadRepository{table: newMemoryTable(
+		func(d *DocumentDownload) *int { return &d.ID },
+		func(d *DocumentDownload, t time.Time) { d.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryOnboardingTemplateRepository() *MemoryOnboardingTemplateRepository {
+	return &MemoryOnboardingTemplateRepository{table: newMemoryTable(
+		func(t *OnboardingTemplate) *int { return &t.ID },
+		func(t *OnboardingTemplate, at time.Time) { t.CreatedAt = at },
+		nil,
+	)}
+}
+
+func NewMemoryOnboardingTemplateTaskRepository() *MemoryOnboardingTemplateTaskRepository {
+	return &MemoryOnboardingTemplateTaskRepository{table: newMemoryTable(
+		func(t *OnboardingTemplateTask) *int { return &t.ID },
+		func(t *OnboardingTemplateTask, at time.Time) { t.CreatedAt = at },
+		nil,
+	)}
+}
+
+func NewMemoryOnboardingTaskRepository() *MemoryOnboardingTaskRepository {
+	return &MemoryOnboardingTaskRepository{table: newMemoryTable(
+		func(t *OnboardingTask) *int { return &t.ID },
+		func(t *OnboardingTask, at time.Time) { t.CreatedAt = at },
+		nil,
+	)}
+}
+
+func NewMemoryTerminationRepository() *MemoryTerminationRepository {
+	return &MemoryTerminationRepository{table: newMemoryTable(
+		func(t *Termination) *int { return &t.ID },
+		func(t *Termination, at time.Time) { t.CreatedAt = at },
+		func(a, b *Termination) bool { return a.EmployeeID == b.EmployeeID },
+	)}
+}
+
+func NewMemoryEquipmentRepository() *MemoryEquipmentRepository {
+	return &MemoryEquipmentRepository{table: newMemoryTable(
+		func(e *Equipment) *int { return &e.ID },
+		func(e *Equipment, t time.Time) { e.CreatedAt = t },
+		func(a, b *Equipment) bool { return a.SerialNumber != "" && a.SerialNumber == b.SerialNumber },
+	)}
+}
+
+func NewMemoryEquipmentAssignmentRepository() *MemoryEquipmentAssignmentRepository {
+	return &MemoryEquipmentAssignmentRepository{table: newMemoryTable(
+		func(a *EquipmentAssignment) *int { return &a.ID },
+		func(a *EquipmentAssignment, t time.Time) { a.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryEquipmentConditionRepository() *MemoryEquipmentConditionRepository {
+	return &MemoryEquipmentConditionRepository{table: newMemoryTable(
+		func(c *EquipmentCondition) *int { return &c.ID },
+		func(c *EquipmentCondition, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryExpenseClaimRepository() *MemoryExpenseClaimRepository {
+	return &MemoryExpenseClaimRepository{table: newMemoryTable(
+		func(c *ExpenseClaim) *int { return &c.ID },
+		func(c *ExpenseClaim, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryExpenseItemRepository() *MemoryExpenseItemRepository {
+	return &MemoryExpenseItemRepository{table: newMemoryTable(
+		func(i *ExpenseItem) *int { return &i.ID },
+		func(i *ExpenseItem, t time.Time) { i.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryBenefitPlanRepository() *MemoryBenefitPlanRepository {
+	return &MemoryBenefitPlanRepository{table: newMemoryTable(
+		func(p *BenefitPlan) *int { return &p.ID },
+		func(p *BenefitPlan, t time.Time) { p.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryDependentRepository() *MemoryDependentRepository {
+	return &MemoryDependentRepository{table: newMemoryTable(
+		func(d *Dependent) *int { return &d.ID },
+		func(d *Dependent, t time.Time) { d.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryBenefitEnrollmentRepository() *MemoryBenefitEnrollmentRepository {
+	return &MemoryBenefitEnrollmentRepository{table: newMemoryTable(
+		func(e *BenefitEnrollment) *int { return &e.ID },
+		func(e *BenefitEnrollment, t time.Time) { e.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryShiftTemplateRepository() *MemoryShiftTemplateRepository {
+	return &MemoryShiftTemplateRepository{table: newMemoryTable(
+		func(st *ShiftTemplate) *int { return &st.ID },
+		func(st *ShiftTemplate, t time.Time) { st.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryShiftRepository() *MemoryShiftRepository {
+	return &MemoryShiftRepository{table: newMemoryTable(
+		func(s *Shift) *int { return &s.ID },
+		func(s *Shift, t time.Time) { s.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryOvertimeRequestRepository() *MemoryOvertimeRequestRepository {
+	return &MemoryOvertimeRequestRepository{table: newMemoryTable(
+		func(o *OvertimeRequest) *int { return &o.ID },
+		func(o *OvertimeRequest, t time.Time) { o.CreatedAt = t },
+		nil,
+	)}
+}
+
+func NewMemoryContactChangeRepository() *MemoryContactChangeRepository {
+	return &MemoryContactChangeRepository{table: newMemoryTable(
+		func(c *ContactChange) *int { return &c.ID },
+		func(c *ContactChange, t time.Time) { c.CreatedAt = t },
+		nil,
+	)}
+}
+
+// NewMemoryRepositories returns empty in-memory repositories. Nothing is
+// persisted, so they are meant for tests.
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		Departments:         NewMemoryDepartmentRepository(),
+		Positions:           NewMemoryPositionRepository(),
+		Employees:           NewMemoryEmployeeRepository(),
+		Applications:        NewMemoryApplicationRepository(),
+		Leaves:              NewMemoryLeaveRepository(),
+		Attendance:          NewMemoryAttendanceRepository(),
+		Schedules:           NewMemoryWorkScheduleRepository(),
+		Corrections:         NewMemoryAttendanceCorrectionRepository(),
+		BadgeImports:        NewMemoryBadgeImportRepository(),
+		ReviewCycles:        NewMemoryReviewCycleRepository(),
+		Questions:           NewMemoryReviewQuestionRepository(),
+		Assessments:         NewMemoryReviewAssessmentRepository(),
+		Ratings:             NewMemoryEmployeeRatingRepository(),
+		Objectives:          NewMemoryObjectiveRepository(),
+		KeyResults:          NewMemoryKeyResultRepository(),
+		CheckIns:            NewMemoryCheckInRepository(),
+		Courses:             NewMemoryCourseRepository(),
+		Enrollments:         NewMemoryEnrollmentRepository(),
+		Certifications:      NewMemoryCertificationRepository(),
+		Certificates:        NewMemoryEmployeeCertificationRepository(),
+		DocCategories:       NewMemoryDocumentCategoryRepository(),
+		Documents:           NewMemoryDocumentRepository(),
+		DocVersions:         NewMemoryDocumentVersionRepository(),
+		Downloads:           NewMemoryDocumentDownloadRepository(),
+		OnboardingTemplates: NewMemoryOnboardingTemplateRepository(),
+		TemplateTasks:       NewMemoryOnboardingTemplateTaskRepository(),
+		OnboardingTasks:     NewMemoryOnboardingTaskRepository(),
+		Terminations:        NewMemoryTerminationRepository(),
+		Equipment:           NewMemoryEquipmentRepository(),
+		Assignments:         NewMemoryEquipmentAssignmentRepository(),
+		Conditions:          NewMemoryEquipmentConditionRepository(),
+		ExpenseClaims:       NewMemoryExpenseClaimRepository(),
+		ExpenseItems:        NewMemoryExpenseItemRepository(),
+		BenefitPlans:        NewMemoryBenefitPlanRepository(),
+		Dependents:          NewMemoryDependentRepository(),
+		BenefitEnrollments:  NewMemoryBenefitEnrollmentRepository(),
+		ShiftTemplates:      NewMemoryShiftTemplateRepository(),
+		Shifts:              NewMemoryShiftRepository(),
+		Overtime:            NewMemoryOvertimeRequestRepository(),
+		ContactChanges:      NewMemoryContactChangeRepository(),
+	}
+}
+
+func (r *MemoryDepartmentRepository) GetDepartments(ctx context.Context, q string) ([]Department, error) {
+	return r.table.list(func(d *Department) bool { return containsFold(q, d.Name) }), nil
+}
+
+func (r *MemoryDepartmentRepository) GetDepartmentByID(ctx context.Context, id int) (*Department, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryDepartmentRepository) CreateDepartment(ctx context.Context, department *Department) error {
+	if err := r.table.insert(department); err != nil {
+		return repoError(ctx, "creating department", err)
+	}
+	return nil
+}
+
+func (r *MemoryDepartmentRepository) UpdateDepartment(ctx context.Context, department *Department) error {
+	err := r.table.update(department, func(dst, src *Department) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating department", err)
+	}
+	return nil
+}
+
+func (r *MemoryDepartmentRepository) DeleteDepartment(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryPositionRepository) GetPositions(ctx context.Context, q string) ([]Position, error) {
+	return r.table.list(func(p *Position) bool { return containsFold(q, p.Name) }), nil
+}
+
+func (r *MemoryPositionRepository) GetPositionByID(ctx context.Context, id int) (*Position, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryPositionRepository) CreatePosition(ctx context.Context, position *Position) error {
+	if err := r.table.insert(position); err != nil {
+		return repoError(ctx, "creating position", err)
+	}
+	return nil
+}
+
+func (r *MemoryPositionRepository) UpdatePosition(ctx context.Context, position *Position) error {
+	err := r.table.update(position, func(dst, src *Position) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating position", err)
+	}
+	return nil
+}
+
+func (r *MemoryPositionRepository) DeletePosition(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryEmployeeRepository) GetEmployees(ctx context.Context, q string) ([]Employee, error) {
+	return r.table.list(func(e *Employee) bool { return containsFold(q, e.FirstName, e.LastName, e.Email) }), nil
+}
+
+func (r *MemoryEmployeeRepository) GetEmployeeByID(ctx context.Context, id int) (*Employee, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryEmployeeRepository) GetEmployeeByEmail(ctx context.Context, email string) (*Employee, error) {
+	rows := r.table.list(func(e *Employee) bool { return strings.EqualFold(e.Email, email) })
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+func (r *MemoryEmployeeRepository) CreateEmployee(ctx context.Context, employee *Employee) error {
+	if err := r.table.insert(employee); err != nil {
+		return repoError(ctx, "creating employee", err)
+	}
+	return nil
+}
+
+func (r *MemoryEmployeeRepository) UpdateEmployee(ctx context.Context, employee *Employee) error {
+	err := r.table.update(employee, func(dst, src *Employee) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating employee", err)
+	}
+	return nil
+}
+
+func (r *MemoryEmployeeRepository) DeleteEmployee(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryApplicationRepository) GetApplications(ctx context.Context, q string) ([]Application, error) {
+	return r.table.list(func(a *Application) bool { return containsFold(q, a.Name, a.Email) }), nil
+}
+
+func (r *MemoryApplicationRepository) GetApplicationByID(ctx context.Context, id int) (*Application, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryApplicationRepository) CreateApplication(ctx context.Context, application *Application) error {
+	if err := r.table.insert(application); err != nil {
+		return repoError(ctx, "creating application", err)
+	}
+	return nil
+}
+
+func (r *MemoryApplicationRepository) UpdateApplication(ctx context.Context, application *Application) error {
+	err := r.table.update(application, func(dst, src *Application) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating application", err)
+	}
+	return nil
+}
+
+func (r *MemoryApplicationRepository) DeleteApplication(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryLeaveRepository) GetLeaves(ctx context.Context, q string) ([]Leave, error) {
+	return r.table.list(func(l *Leave) bool { return containsFold(q, l.LeaveType, l.Status, l.Reason) }), nil
+}
+
+func (r *MemoryLeaveRepository) GetLeaveByID(ctx context.Context, id int) (*Leave, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryLeaveRepository) CreateLeave(ctx context.Context, leave *Leave) error {
+	if err := r.table.insert(leave); err != nil {
+		return repoError(ctx, "creating leave", err)
+	}
+	return nil
+}
+
+func (r *MemoryLeaveRepository) UpdateLeave(ctx context.Context, leave *Leave) error {
+	err := r.table.update(leave, func(dst, src *Leave) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating leave", err)
+	}
+	return nil
+}
+
+func (r *MemoryLeaveRepository) DeleteLeave(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+// inRange reports whether t lies in [from, to).
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+func (r *MemoryAttendanceRepository) GetAttendanceEvents(ctx context.Context, employeeID int, from, to time.Time) ([]AttendanceEvent, error) {
+	events := r.table.list(func(e *AttendanceEvent) bool {
+		return (employeeID == 0 || e.EmployeeID == employeeID) && inRange(e.OccurredAt, from, to)
+	})
+	slices.SortStableFunc(events, func(a, b AttendanceEvent) int { return a.OccurredAt.Compare(b.OccurredAt) })
+	return events, nil
+}
+
+func (r *MemoryAttendanceRepository) CreateAttendanceEvent(ctx context.Context, event *AttendanceEvent) error {
+	if err := r.table.insert(event); err != nil {
+		return repoError(ctx, "creating attendance event", err)
+	}
+	return nil
+}
+
+func (r *MemoryAttendanceRepository) DeleteAttendanceEvent(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryAttendanceRepository) ReplaceAttendanceEvents(ctx context.Context, employeeID int, from, to time.Time, events []AttendanceEvent) error {
+	rows := slices.Clone(events)
+	for i := range rows {
+		rows[i].EmployeeID = employeeID
+	}
+	r.table.replace(func(e *AttendanceEvent) bool {
+		return e.EmployeeID == employeeID && inRange(e.OccurredAt, from, to)
+	}, rows)
+	return nil
+}
+
+func (r *MemoryWorkScheduleRepository) GetWorkSchedules(ctx context.Context) ([]WorkSchedule, error) {
+	return r.table.list(func(*WorkSchedule) bool { return true }), nil
+}
+
+func (r *MemoryWorkScheduleRepository) GetWorkScheduleByID(ctx context.Context, id int) (*WorkSchedule, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryWorkScheduleRepository) CreateWorkSchedule(ctx context.Context, schedule *WorkSchedule) error {
+	if err := r.table.insert(schedule); err != nil {
+		return repoError(ctx, "creating work schedule", err)
+	}
+	return nil
+}
+
+func (r *MemoryWorkScheduleRepository) UpdateWorkSchedule(ctx context.Context, schedule *WorkSchedule) error {
+	err := r.table.update(schedule, func(dst, src *WorkSchedule) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating work schedule", err)
+	}
+	return nil
+}
+
+func (r *MemoryWorkScheduleRepository) DeleteWorkSchedule(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryAttendanceCorrectionRepository) GetAttendanceCorrections(ctx context.Context, status string) ([]AttendanceCorrection, error) {
+	corrections := r.table.list(func(c *AttendanceCorrection) bool { return status == "" || c.Status == status })
+	slices.Reverse(corrections)
+	return corrections, nil
+}
+
+func (r *MemoryAttendanceCorrectionRepository) GetAttendanceCorrectionByID(ctx context.Context, id int) (*AttendanceCorrection, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryAttendanceCorrectionRepository) CreateAttendanceCorrection(ctx context.Context, correction *AttendanceCorrection) error {
+	if err := r.table.insert(correction); err != nil {
+		return repoError(ctx, "creating attendance correction", err)
+	}
+	return nil
+}
+
+func (r *MemoryAttendanceCorrectionRepository) UpdateAttendanceCorrection(ctx context.Context, correction *AttendanceCorrection) error {
+	err := r.table.update(correction, func(dst, src *AttendanceCorrection) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating attendance correction", err)
+	}
+	return nil
+}
+
+func (r *MemoryBadgeImportRepository) GetBadgeImports(ctx context.Context) ([]BadgeImport, error) {
+	imports := r.table.list(func(*BadgeImport) bool { return true })
+	slices.Reverse(imports)
+	return imports, nil
+}
+
+func (r *MemoryBadgeImportRepository) GetBadgeImportByID(ctx context.Context, id int) (*BadgeImport, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryBadgeImportRepository) CreateBadgeImport(ctx context.Context, badgeImport *BadgeImport) error {
+	if err := r.table.insert(badgeImport); err != nil {
+		return repoError(ctx, "creating badge import", err)
+	}
+	return nil
+}
+
+func (r *MemoryReviewCycleRepository) GetReviewCycles(ctx context.Context) ([]ReviewCycle, error) {
+	cycles := r.table.list(func(*ReviewCycle) bool { return true })
+	slices.Reverse(cycles)
+	return cycles, nil
+}
+
+func (r *MemoryReviewCycleRepository) GetReviewCycleByID(ctx context.Context, id int) (*ReviewCycle, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryReviewCycleRepository) CreateReviewCycle(ctx context.Context, cycle *ReviewCycle) error {
+	if err := r.table.insert(cycle); err != nil {
+		return repoError(ctx, "creating review cycle", err)
+	}
+	return nil
+}
+
+func (r *MemoryReviewCycleRepository) UpdateReviewCycle(ctx context.Context, cycle *ReviewCycle) error {
+	err := r.table.update(cycle, func(dst, src *ReviewCycle) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating review cycle", err)
+	}
+	return nil
+}
+
+func (r *MemoryReviewCycleRepository) DeleteReviewCycle(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryReviewQuestionRepository) GetReviewQuestions(ctx context.Context, cycleID int) ([]ReviewQuestion, error) {
+	return r.table.list(func(q *ReviewQuestion) bool { return q.CycleID == cycleID }), nil
+}
+
+func (r *MemoryReviewQuestionRepository) CreateReviewQuestion(ctx context.Context, question *ReviewQuestion) error {
+	if err := r.table.insert(question); err != nil {
+		return repoError(ctx, "creating review question", err)
+	}
+	return nil
+}
+
+func (r *MemoryReviewQuestionRepository) DeleteReviewQuestion(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryReviewAssessmentRepository) GetReviewAssessments(ctx context.Context, cycleID, employeeID int) ([]ReviewAssessment, error) {
+	return r.table.list(func(a *ReviewAssessment) bool {
+		return a.CycleID == cycleID && (employeeID == 0 || a.EmployeeID == employeeID)
+	}), nil
+}
+
+func (r *MemoryReviewAssessmentRepository) GetReviewAssessmentByID(ctx context.Context, id int) (*ReviewAssessment, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryReviewAssessmentRepository) CreateReviewAssessment(ctx context.Context, assessment *ReviewAssessment) error {
+	if err := r.table.insert(assessment); err != nil {
+		return repoError(ctx, "creating review assessment", err)
+	}
+	return nil
+}
+
+func (r *MemoryReviewAssessmentRepository) UpdateReviewAssessment(ctx context.Context, assessment *ReviewAssessment) error {
+	err := r.table.update(assessment, func(dst, src *ReviewAssessment) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating review assessment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEmployeeRatingRepository) GetEmployeeRatings(ctx context.Context, cycleID, employeeID int) ([]EmployeeRating, error) {
+	ratings := r.table.list(func(er *EmployeeRating) bool {
+		return (cycleID == 0 || er.CycleID == cycleID) && (employeeID == 0 || er.EmployeeID == employeeID)
+	})
+	slices.SortStableFunc(ratings, func(a, b EmployeeRating) int { return a.CycleID - b.CycleID })
+	return ratings, nil
+}
+
+func (r *MemoryEmployeeRatingRepository) SaveEmployeeRating(ctx context.Context, rating *EmployeeRating) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := r.table.list(func(er *EmployeeRating) bool {
+		return er.EmployeeID == rating.EmployeeID && er.CycleID == rating.CycleID
+	})
+	var err error
+	if len(existing) > 0 {
+		updated := *rating
+		updated.ID = existing[0].ID
+		err = r.table.update(&updated, func(dst, src *EmployeeRating) { dst.CreatedAt = src.CreatedAt })
+	} else {
+		err = r.table.insert(rating)
+	}
+	if err != nil {
+		return repoError(ctx, "saving employee rating", err)
+	}
+	return nil
+}
+
+func (r *MemoryObjectiveRepository) GetObjectives(ctx context.Context, quarter string) ([]Objective, error) {
+	return r.table.list(func(o *Objective) bool { return quarter == "" || o.Quarter == quarter }), nil
+}
+
+func (r *MemoryObjectiveRepository) GetObjectiveByID(ctx context.Context, id int) (*Objective, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryObjectiveRepository) CreateObjective(ctx context.Context, objective *Objective) error {
+	if err := r.table.insert(objective); err != nil {
+		return repoError(ctx, "creating objective", err)
+	}
+	return nil
+}
+
+func (r *MemoryObjectiveRepository) UpdateObjective(ctx context.Context, objective *Objective) error {
+	err := r.table.update(objective, func(dst, src *Objective) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating objective", err)
+	}
+	return nil
+}
+
+func (r *MemoryObjectiveRepository) DeleteObjective(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryKeyResultRepository) GetKeyResults(ctx context.Context, objectiveID int) ([]KeyResult, error) {
+	return r.table.list(func(kr *KeyResult) bool { return objectiveID == 0 || kr.ObjectiveID == objectiveID }), nil
+}
+
+func (r *MemoryKeyResultRepository) GetKeyResultByID(ctx context.Context, id int) (*KeyResult, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryKeyResultRepository) CreateKeyResult(ctx context.Context, kr *KeyResult) error {
+	if err := r.table.insert(kr); err != nil {
+		return repoError(ctx, "creating key result", err)
+	}
+	return nil
+}
+
+func (r *MemoryKeyResultRepository) UpdateKeyResult(ctx context.Context, kr *KeyResult) error {
+	err := r.table.update(kr, func(dst, src *KeyResult) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating key result", err)
+	}
+	return nil
+}
+
+func (r *MemoryKeyResultRepository) DeleteKeyResult(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryCheckInRepository) GetCheckIns(ctx context.Context, keyResultID int) ([]CheckIn, error) {
+	checkIns := r.table.list(func(c *CheckIn) bool { return c.KeyResultID == keyResultID })
+	slices.Reverse(checkIns)
+	return checkIns, nil
+}
+
+func (r *MemoryCheckInRepository) CreateCheckIn(ctx context.Context, checkIn *CheckIn) error {
+	if err := r.table.insert(checkIn); err != nil {
+		return repoError(ctx, "creating check-in", err)
+	}
+	return nil
+}
+
+func (r *MemoryCheckInRepository) DeleteCheckIns(ctx context.Context, keyResultID int) error {
+	r.table.replace(func(c *CheckIn) bool { return c.KeyResultID == keyResultID }, nil)
+	return nil
+}
+
+func (r *MemoryCourseRepository) GetCourses(ctx context.Context) ([]Course, error) {
+	return r.table.list(func(*Course) bool { return true }), nil
+}
+
+func (r *MemoryCourseRepository) GetCourseByID(ctx context.Context, id int) (*Course, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryCourseRepository) CreateCourse(ctx context.Context, course *Course) error {
+	if err := r.table.insert(course); err != nil {
+		return repoError(ctx, "creating course", err)
+	}
+	return nil
+}
+
+func (r *MemoryCourseRepository) UpdateCourse(ctx context.Context, course *Course) error {
+	err := r.table.update(course, func(dst, src *Course) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating course", err)
+	}
+	return nil
+}
+
+func (r *MemoryCourseRepository) DeleteCourse(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryEnrollmentRepository) GetEnrollments(ctx context.Context, courseID, employeeID int) ([]Enrollment, error) {
+	return r.table.list(func(e *Enrollment) bool {
+		return (courseID == 0 || e.CourseID == courseID) && (employeeID == 0 || e.EmployeeID == employeeID)
+	}), nil
+}
+
+func (r *MemoryEnrollmentRepository) GetEnrollmentByID(ctx context.Context, id int) (*Enrollment, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryEnrollmentRepository) CreateEnrollment(ctx context.Context, enrollment *Enrollment) error {
+	if err := r.table.insert(enrollment); err != nil {
+		return repoError(ctx, "creating enrollment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEnrollmentRepository) UpdateEnrollment(ctx context.Context, enrollment *Enrollment) error {
+	err := r.table.update(enrollment, func(dst, src *Enrollment) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating enrollment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEnrollmentRepository) DeleteEnrollment(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryCertificationRepository) GetCertifications(ctx context.Context) ([]Certification, error) {
+	return r.table.list(func(*Certification) bool { return true }), nil
+}
+
+func (r *MemoryCertificationRepository) GetCertificationByID(ctx context.Context, id int) (*Certification, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryCertificationRepository) CreateCertification(ctx context.Context, certification *Certification) error {
+	if err := r.table.insert(certification); err != nil {
+		return repoError(ctx, "creating certification", err)
+	}
+	return nil
+}
+
+func (r *MemoryCertificationRepository) UpdateCertification(ctx context.Context, certification *Certification) error {
+	err := r.table.update(certification, func(dst, src *Certification) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating certification", err)
+	}
+	return nil
+}
+
+func (r *MemoryCertificationRepository) DeleteCertification(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryEmployeeCertificationRepository) GetEmployeeCertifications(ctx context.Context, certificationID, employeeID int) ([]EmployeeCertification, error) {
+	certificates := r.table.list(func(ec *EmployeeCertification) bool {
+		return (certificationID == 0 || ec.CertificationID == certificationID) && (employeeID == 0 || ec.EmployeeID == employeeID)
+	})
+	slices.SortStableFunc(certificates, func(a, b EmployeeCertification) int {
+		if c := b.IssuedOn.Compare(a.IssuedOn); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+	return certificates, nil
+}
+
+func (r *MemoryEmployeeCertificationRepository) GetEmployeeCertificationByID(ctx context.Context, id int) (*EmployeeCertification, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryEmployeeCertificationRepository) CreateEmployeeCertification(ctx context.Context, certificate *EmployeeCertification) error {
+	if err := r.table.insert(certificate); err != nil {
+		return repoError(ctx, "creating employee certification", err)
+	}
+	return nil
+}
+
+func (r *MemoryEmployeeCertificationRepository) DeleteEmployeeCertification(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryDocumentCategoryRepository) GetDocumentCategories(ctx context.Context) ([]DocumentCategory, error) {
+	categories := r.table.list(func(*DocumentCategory) bool { return true })
+	slices.SortStableFunc(categories, func(a, b DocumentCategory) int { return strings.Compare(a.Name, b.Name) })
+	return categories, nil
+}
+
+func (r *MemoryDocumentCategoryRepository) GetDocumentCategoryByID(ctx context.Context, id int) (*DocumentCategory, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryDocumentCategoryRepository) CreateDocumentCategory(ctx context.Context, category *DocumentCategory) error {
+	if err := r.table.insert(category); err != nil {
+		return repoError(ctx, "creating document category", err)
+	}
+	return nil
+}
+
+func (r *MemoryDocumentCategoryRepository) UpdateDocumentCategory(ctx context.Context, category *DocumentCategory) error {
+	err := r.table.update(category, func(dst, src *DocumentCategory) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating document category", err)
+	}
+	return nil
+}
+
+func (r *MemoryDocumentCategoryRepository) DeleteDocumentCategory(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryDocumentRepository) GetDocuments(ctx context.Context, employeeID int) ([]Document, error) {
+	return r.table.list(func(d *Document) bool { return employeeID == 0 || d.EmployeeID == employeeID }), nil
+}
+
+func (r *MemoryDocumentRepository) GetDocumentByID(ctx context.Context, id int) (*Document, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryDocumentRepository) CreateDocument(ctx context.Context, document *Document) error {
+	if err := r.table.insert(document); err != nil {
+		return repoError(ctx, "creating document", err)
+	}
+	return nil
+}
+
+func (r *MemoryDocumentRepository) UpdateDocument(ctx context.Context, document *Document) error {
+	err := r.table.update(document, func(dst, src *Document) {
+		dst.EmployeeID, dst.CreatedAt = src.EmployeeID, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating document", err)
+	}
+	return nil
+}
+
+func (r *MemoryDocumentRepository) DeleteDocument(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryDocumentVersionRepository) GetDocumentVersions(ctx context.Context, documentID int) ([]DocumentVersion, error) {
+	versions := r.table.list(func(v *DocumentVersion) bool { return documentID == 0 || v.DocumentID == documentID })
+	slices.SortStableFunc(versions, func(a, b DocumentVersion) int {
+		if a.DocumentID != b.DocumentID {
+			return a.DocumentID - b.DocumentID
+		}
+		return b.Version - a.Version
+	})
+	return versions, nil
+}
+
+func (r *MemoryDocumentVersionRepository) CreateDocumentVersion(ctx context.Context, version *DocumentVersion) error {
+	if err := r.table.insert(version); err != nil {
+		return repoError(ctx, "creating document version", err)
+	}
+	return nil
+}
+
+func (r *MemoryDocumentVersionRepository) DeleteDocumentVersions(ctx context.Context, documentID int) error {
+	r.table.replace(func(v *DocumentVersion) bool { return v.DocumentID == documentID }, nil)
+	return nil
+}
+
+func (r *MemoryDocumentDownloadRepository) GetDocumentDownloads(ctx context.Context, documentID int) ([]DocumentDownload, error) {
+	downloads := r.table.list(func(d *DocumentDownload) bool { return documentID == 0 || d.DocumentID == documentID })
+	slices.Reverse(downloads)
+	return downloads, nil
+}
+
+func (r *MemoryDocumentDownloadRepository) CreateDocumentDownload(ctx context.Context, download *DocumentDownload) error {
+	if err := r.table.insert(download); err != nil {
+		return repoError(ctx, "creating document download", err)
+	}
+	return nil
+}
+
+func (r *MemoryOnboardingTemplateRepository) GetOnboardingTemplates(ctx context.Context) ([]OnboardingTemplate, error) {
+	templates := r.table.list(func(*OnboardingTemplate) bool { return true })
+	slices.SortStableFunc(templates, func(a, b OnboardingTemplate) int { return strings.Compare(a.Name, b.Name) })
+	return templates, nil
+}
+
+func (r *MemoryOnboardingTemplateRepository) GetOnboardingTemplateByID(ctx context.Context, id int) (*OnboardingTemplate, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryOnboardingTemplateRepository) CreateOnboardingTemplate(ctx context.Context, template *OnboardingTemplate) error {
+	if err := r.table.insert(template); err != nil {
+		return repoError(ctx, "creating onboarding template", err)
+	}
+	return nil
+}
+
+func (r *MemoryOnboardingTemplateRepository) UpdateOnboardingTemplate(ctx context.Context, template *OnboardingTemplate) error {
+	err := r.table.update(template, func(dst, src *OnboardingTemplate) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating onboarding template", err)
+	}
+	return nil
+}
+
+func (r *MemoryOnboardingTemplateRepository) DeleteOnboardingTemplate(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryOnboardingTemplateTaskRepository) GetOnboardingTemplateTasks(ctx context.Context, templateID int) ([]OnboardingTemplateTask, error) {
+	tasks := r.table.list(func(t *OnboardingTemplateTask) bool { return templateID == 0 || t.TemplateID == templateID })
+	slices.SortStableFunc(tasks, func(a, b OnboardingTemplateTask) int {
+		if a.TemplateID != b.TemplateID {
+			return a.TemplateID - b.TemplateID
+		}
+		return a.DueDays - b.DueDays
+	})
+	return tasks, nil
+}
+
+func (r *MemoryOnboardingTemplateTaskRepository) CreateOnboardingTemplateTask(ctx context.Context, task *OnboardingTemplateTask) error {
+	if err := r.table.insert(task); err != nil {
+		return repoError(ctx, "creating onboarding template task", err)
+	}
+	return nil
+}
+
+func (r *MemoryOnboardingTemplateTaskRepository) DeleteOnboardingTemplateTask(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryOnboardingTaskRepository) GetOnboardingTasks(ctx context.Context, employeeID int) ([]OnboardingTask, error) {
+	tasks := r.table.list(func(t *OnboardingTask) bool { return employeeID == 0 || t.EmployeeID == employeeID })
+	slices.SortStableFunc(tasks, func(a, b OnboardingTask) int { return a.DueOn.Compare(b.DueOn) })
+	return tasks, nil
+}
+
+func (r *MemoryOnboardingTaskRepository) GetOnboardingTaskByID(ctx context.Context, id int) (*OnboardingTask, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryOnboardingTaskRepository) CreateOnboardingTask(ctx context.Context, task *OnboardingTask) error {
+	if err := r.table.insert(task); err != nil {
+		return repoError(ctx, "creating onboarding task", err)
+	}
+	return nil
+}
+
+func (r *MemoryOnboardingTaskRepository) UpdateOnboardingTask(ctx context.Context, task *OnboardingTask) error {
+	err := r.table.update(task, func(dst, src *OnboardingTask) {
+		dst.EmployeeID, dst.Kind, dst.TemplateID, dst.CreatedAt = src.EmployeeID, src.Kind, src.TemplateID, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating onboarding task", err)
+	}
+	return nil
+}
+
+func (r *MemoryOnboardingTaskRepository) DeleteOnboardingTask(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryTerminationRepository) GetTerminations(ctx context.Context) ([]Termination, error) {
+	terminations := r.table.list(func(*Termination) bool { return true })
+	slices.Reverse(terminations)
+	slices.SortStableFunc(terminations, func(a, b Termination) int { return b.LastDay.Compare(a.LastDay) })
+	return terminations, nil
+}
+
+func (r *MemoryTerminationRepository) GetTermination(ctx context.Context, employeeID int) (*Termination, error) {
+	terminations := r.table.list(func(t *Termination) bool { return t.EmployeeID == employeeID })
+	if len(terminations) == 0 {
+		return nil, nil
+	}
+	return &terminations[0], nil
+}
+
+func (r *MemoryTerminationRepository) CreateTermination(ctx context.Context, termination *Termination) error {
+	if err := r.table.insert(termination); err != nil {
+		return repoError(ctx, "creating termination", err)
+	}
+	return nil
+}
+
+func (r *MemoryTerminationRepository) UpdateTermination(ctx context.Context, termination *Termination) error {
+	existing, _ := r.GetTermination(ctx, termination.EmployeeID)
+	if existing == nil {
+		return nil
+	}
+	updated := *termination
+	updated.ID = existing.ID
+	err := r.table.update(&updated, func(dst, src *Termination) { dst.ProcessedBy, dst.CreatedAt = src.ProcessedBy, src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating termination", err)
+	}
+	return nil
+}
+
+func (r *MemoryEquipmentRepository) GetEquipment(ctx context.Context, q string) ([]Equipment, error) {
+	equipment := r.table.list(func(e *Equipment) bool { return containsFold(q, e.Name, e.SerialNumber) })
+	slices.SortStableFunc(equipment, func(a, b Equipment) int {
+		if c := strings.Compare(a.Category, b.Category); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return equipment, nil
+}
+
+func (r *MemoryEquipmentRepository) GetEquipmentByID(ctx context.Context, id int) (*Equipment, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryEquipmentRepository) CreateEquipment(ctx context.Context, equipment *Equipment) error {
+	if err := r.table.insert(equipment); err != nil {
+		return repoError(ctx, "creating equipment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEquipmentRepository) UpdateEquipment(ctx context.Context, equipment *Equipment) error {
+	err := r.table.update(equipment, func(dst, src *Equipment) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating equipment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEquipmentRepository) DeleteEquipment(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryEquipmentAssignmentRepository) GetEquipmentAssignments(ctx context.Context, equipmentID, employeeID int) ([]EquipmentAssignment, error) {
+	assignments := r.table.list(func(a *EquipmentAssignment) bool {
+		return (equipmentID == 0 || a.EquipmentID == equipmentID) && (employeeID == 0 || a.EmployeeID == employeeID)
+	})
+	slices.Reverse(assignments)
+	slices.SortStableFunc(assignments, func(a, b EquipmentAssignment) int { return b.AssignedOn.Compare(a.AssignedOn) })
+	return assignments, nil
+}
+
+func (r *MemoryEquipmentAssignmentRepository) CreateEquipmentAssignment(ctx context.Context, assignment *EquipmentAssignment) error {
+	if err := r.table.insert(assignment); err != nil {
+		return repoError(ctx, "creating equipment assignment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEquipmentAssignmentRepository) UpdateEquipmentAssignment(ctx context.Context, assignment *EquipmentAssignment) error {
+	err := r.table.update(assignment, func(dst, src *EquipmentAssignment) {
+		dst.EquipmentID, dst.EmployeeID, dst.AssignedBy, dst.CreatedAt = src.EquipmentID, src.EmployeeID, src.AssignedBy, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating equipment assignment", err)
+	}
+	return nil
+}
+
+func (r *MemoryEquipmentAssignmentRepository) DeleteEquipmentAssignments(ctx context.Context, equipmentID int) error {
+	r.table.replace(func(a *EquipmentAssignment) bool { return a.EquipmentID == equipmentID }, nil)
+	return nil
+}
+
+func (r *MemoryEquipmentConditionRepository) GetEquipmentConditions(ctx context.Context, equipmentID int) ([]EquipmentCondition, error) {
+	conditions := r.table.list(func(c *EquipmentCondition) bool { return c.EquipmentID == equipmentID })
+	slices.Reverse(conditions)
+	return conditions, nil
+}
+
+func (r *MemoryEquipmentConditionRepository) CreateEquipmentCondition(ctx context.Context, condition *EquipmentCondition) error {
+	if err := r.table.insert(condition); err != nil {
+		return repoError(ctx, "creating equipment condition", err)
+	}
+	return nil
+}
+
+func (r *MemoryEquipmentConditionRepository) DeleteEquipmentConditions(ctx context.Context, equipmentID int) error {
+	r.table.replace(func(c *EquipmentCondition) bool { return c.EquipmentID == equipmentID }, nil)
+	return nil
+}
+
+func (r *MemoryExpenseClaimRepository) GetExpenseClaims(ctx context.Context, employeeID int, status string) ([]ExpenseClaim, error) {
+	claims := r.table.list(func(c *ExpenseClaim) bool {
+		return (employeeID == 0 || c.EmployeeID == employeeID) && (status == "" || c.Status == status)
+	})
+	slices.Reverse(claims)
+	return claims, nil
+}
+
+func (r *MemoryExpenseClaimRepository) GetExpenseClaimByID(ctx context.Context, id int) (*ExpenseClaim, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryExpenseClaimRepository) CreateExpenseClaim(ctx context.Context, claim *ExpenseClaim) error {
+	if err := r.table.insert(claim); err != nil {
+		return repoError(ctx, "creating expense claim", err)
+	}
+	return nil
+}
+
+func (r *MemoryExpenseClaimRepository) UpdateExpenseClaim(ctx context.Context, claim *ExpenseClaim) error {
+	err := r.table.update(claim, func(dst, src *ExpenseClaim) { dst.EmployeeID, dst.CreatedAt = src.EmployeeID, src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating expense claim", err)
+	}
+	return nil
+}
+
+func (r *MemoryExpenseClaimRepository) DeleteExpenseClaim(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryExpenseItemRepository) GetExpenseItems(ctx context.Context, claimID int) ([]ExpenseItem, error) {
+	items := r.table.list(func(i *ExpenseItem) bool { return claimID == 0 || i.ClaimID == claimID })
+	slices.SortStableFunc(items, func(a, b ExpenseItem) int { return a.SpentOn.Compare(b.SpentOn) })
+	return items, nil
+}
+
+func (r *MemoryExpenseItemRepository) GetExpenseItemByID(ctx context.Context, id int) (*ExpenseItem, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryExpenseItemRepository) CreateExpenseItem(ctx context.Context, item *ExpenseItem) error {
+	if err := r.table.insert(item); err != nil {
+		return repoError(ctx, "creating expense item", err)
+	}
+	return nil
+}
+
+func (r *MemoryExpenseItemRepository) DeleteExpenseItem(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryExpenseItemRepository) DeleteExpenseItems(ctx context.Context, claimID int) error {
+	r.table.replace(func(i *ExpenseItem) bool { return i.ClaimID == claimID }, nil)
+	return nil
+}
+
+func (r *MemoryBenefitPlanRepository) GetBenefitPlans(ctx context.Context) ([]BenefitPlan, error) {
+	plans := r.table.list(func(*BenefitPlan) bool { return true })
+	slices.SortStableFunc(plans, func(a, b BenefitPlan) int {
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return plans, nil
+}
+
+func (r *MemoryBenefitPlanRepository) GetBenefitPlanByID(ctx context.Context, id int) (*BenefitPlan, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryBenefitPlanRepository) CreateBenefitPlan(ctx context.Context, plan *BenefitPlan) error {
+	if err := r.table.insert(plan); err != nil {
+		return repoError(ctx, "creating benefit plan", err)
+	}
+	return nil
+}
+
+func (r *MemoryBenefitPlanRepository) UpdateBenefitPlan(ctx context.Context, plan *BenefitPlan) error {
+	err := r.table.update(plan, func(dst, src *BenefitPlan) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating benefit plan", err)
+	}
+	return nil
+}
+
+func (r *MemoryBenefitPlanRepository) DeleteBenefitPlan(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryDependentRepository) GetDependents(ctx context.Context, employeeID int) ([]Dependent, error) {
+	return r.table.list(func(d *Dependent) bool { return employeeID == 0 || d.EmployeeID == employeeID }), nil
+}
+
+func (r *MemoryDependentRepository) GetDependentByID(ctx context.Context, id int) (*Dependent, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryDependentRepository) CreateDependent(ctx context.Context, dependent *Dependent) error {
+	if err := r.table.insert(dependent); err != nil {
+		return repoError(ctx, "creating dependent", err)
+	}
+	return nil
+}
+
+func (r *MemoryDependentRepository) DeleteDependent(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryBenefitEnrollmentRepository) GetBenefitEnrollments(ctx context.Context, planID, employeeID int) ([]BenefitEnrollment, error) {
+	enrollments := r.table.list(func(e *BenefitEnrollment) bool {
+		return (planID == 0 || e.PlanID == planID) && (employeeID == 0 || e.EmployeeID == employeeID)
+	})
+	slices.Reverse(enrollments)
+	slices.SortStableFunc(enrollments, func(a, b BenefitEnrollment) int { return b.EffectiveFrom.Compare(a.EffectiveFrom) })
+	return enrollments, nil
+}
+
+func (r *MemoryBenefitEnrollmentRepository) GetBenefitEnrollmentByID(ctx context.Context, id int) (*BenefitEnrollment, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryBenefitEnrollmentRepository) CreateBenefitEnrollment(ctx context.Context, enrollment *BenefitEnrollment) error {
+	if err := r.table.insert(enrollment); err != nil {
+		return repoError(ctx, "creating benefit enrollment", err)
+	}
+	return nil
+}
+
+func (r *MemoryBenefitEnrollmentRepository) UpdateBenefitEnrollment(ctx context.Context, enrollment *BenefitEnrollment) error {
+	err := r.table.update(enrollment, func(dst, src *BenefitEnrollment) {
+		dst.PlanID, dst.EmployeeID, dst.EnrolledBy, dst.CreatedAt = src.PlanID, src.EmployeeID, src.EnrolledBy, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating benefit enrollment", err)
+	}
+	return nil
+}
+
+func (r *MemoryShiftTemplateRepository) GetShiftTemplates(ctx context.Context) ([]ShiftTemplate, error) {
+	templates := r.table.list(func(*ShiftTemplate) bool { return true })
+	slices.SortStableFunc(templates, func(a, b ShiftTemplate) int {
+		return cmp.Or(cmp.Compare(a.StartTime, b.StartTime), cmp.Compare(a.Name, b.Name))
+	})
+	return templates, nil
+}
+
+func (r *MemoryShiftTemplateRepository) GetShiftTemplateByID(ctx context.Context, id int) (*ShiftTemplate, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryShiftTemplateRepository) DeleteShiftTemplate(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryShiftTemplateRepository) CreateShiftTemplate(ctx context.Context, template *ShiftTemplate) error {
+	if err := r.table.insert(template); err != nil {
+		return repoError(ctx, "creating shift template", err)
+	}
+	return nil
+}
+
+func (r *MemoryShiftTemplateRepository) UpdateShiftTemplate(ctx context.Context, template *ShiftTemplate) error {
+	err := r.table.update(template, func(dst, src *ShiftTemplate) { dst.CreatedAt = src.CreatedAt })
+	if err != nil {
+		return repoError(ctx, "updating shift template", err)
+	}
+	return nil
+}
+
+func (r *MemoryShiftRepository) GetShifts(ctx context.Context, departmentID, employeeID int, from, to time.Time) ([]Shift, error) {
+	shifts := r.table.list(func(s *Shift) bool {
+		return (departmentID == 0 || s.DepartmentID == departmentID) && (employeeID == 0 || s.EmployeeID == employeeID) &&
+			!s.Date.Before(from) && s.Date.Before(to)
+	})
+	slices.SortStableFunc(shifts, func(a, b Shift) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.StartTime, b.StartTime))
+	})
+	return shifts, nil
+}
+
+func (r *MemoryShiftRepository) GetShiftByID(ctx context.Context, id int) (*Shift, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryShiftRepository) CreateShift(ctx context.Context, shift *Shift) error {
+	if err := r.table.insert(shift); err != nil {
+		return repoError(ctx, "creating shift", err)
+	}
+	return nil
+}
+
+func (r *MemoryShiftRepository) UpdateShift(ctx context.Context, shift *Shift) error {
+	err := r.table.update(shift, func(dst, src *Shift) {
+		dst.DepartmentID, dst.Name, dst.StartTime, dst.EndTime, dst.BreakMinutes, dst.CreatedAt = src.DepartmentID, src.Name, src.StartTime, src.EndTime, src.BreakMinutes, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating shift", err)
+	}
+	return nil
+}
+
+func (r *MemoryShiftRepository) DeleteShift(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryOvertimeRequestRepository) GetOvertimeRequests(ctx context.Context, employeeID int, status string) ([]OvertimeRequest, error) {
+	requests := r.table.list(func(o *OvertimeRequest) bool {
+		return (employeeID == 0 || o.EmployeeID == employeeID) && (status == "" || o.Status == status)
+	})
+	slices.SortStableFunc(requests, func(a, b OvertimeRequest) int {
+		return cmp.Or(b.Date.Compare(a.Date), cmp.Compare(b.ID, a.ID))
+	})
+	return requests, nil
+}
+
+func (r *MemoryOvertimeRequestRepository) GetOvertimeRequestByID(ctx context.Context, id int) (*OvertimeRequest, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryOvertimeRequestRepository) CreateOvertimeRequest(ctx context.Context, request *OvertimeRequest) error {
+	if err := r.table.insert(request); err != nil {
+		return repoError(ctx, "creating overtime request", err)
+	}
+	return nil
+}
+
+func (r *MemoryOvertimeRequestRepository) UpdateOvertimeRequest(ctx context.Context, request *OvertimeRequest) error {
+	err := r.table.update(request, func(dst, src *OvertimeRequest) {
+		dst.EmployeeID, dst.Date, dst.Hours, dst.DayKind, dst.Multiplier = src.EmployeeID, src.Date, src.Hours, src.DayKind, src.Multiplier
+		dst.Compensation, dst.Reason, dst.CreatedAt = src.Compensation, src.Reason, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating overtime request", err)
+	}
+	return nil
+}
+
+func (r *MemoryOvertimeRequestRepository) DeleteOvertimeRequest(ctx context.Context, id int) error {
+	r.table.delete(id)
+	return nil
+}
+
+func (r *MemoryContactChangeRepository) GetContactChanges(ctx context.Context, employeeID int, status string) ([]ContactChange, error) {
+	changes := r.table.list(func(c *ContactChange) bool {
+		return (employeeID == 0 || c.EmployeeID == employeeID) && (status == "" || c.Status == status)
+	})
+	slices.Reverse(changes)
+	return changes, nil
+}
+
+func (r *MemoryContactChangeRepository) GetContactChangeByID(ctx context.Context, id int) (*ContactChange, error) {
+	return r.table.get(id), nil
+}
+
+func (r *MemoryContactChangeRepository) CreateContactChange(ctx context.Context, change *ContactChange) error {
+	if err := r.table.insert(change); err != nil {
+		return repoError(ctx, "creating contact change", err)
+	}
+	return nil
+}
+
+func (r *MemoryContactChangeRepository) UpdateContactChange(ctx context.Context, change *ContactChange) error {
+	err := r.table.update(change, func(dst, src *ContactChange) {
+		dst.EmployeeID, dst.Phone, dst.Address, dst.EmergencyName, dst.EmergencyPhone, dst.CreatedAt = src.EmployeeID, src.Phone, src.Address, src.EmergencyName, src.EmergencyPhone, src.CreatedAt
+	})
+	if err != nil {
+		return repoError(ctx, "updating contact change", err)
+	}
+	return nil
+}
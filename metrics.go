@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "hr_http_requests_total",
+		Help: "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hr_http_request_duration_seconds",
+		Help:    "HTTP request latency by route pattern and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hr_db_query_duration_seconds",
+		Help:    "Duration of repository calls by method.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"method"})
+
+	exportBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hr_export_size_bytes",
+		Help:    "Size of generated Excel exports by entity.",
+		Buckets: prometheus.ExponentialBuckets(4096, 4, 8),
+	}, []string{"entity"})
+
+	exportRows = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hr_export_rows",
+		Help:    "Number of rows in generated Excel exports by entity.",
+		Buckets: prometheus.ExponentialBuckets(10, 4, 7),
+	}, []string{"entity"})
+
+	sseClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "hr_sse_clients",
+		Help: "Browsers currently connected to the /dev-reload stream.",
+	})
+)
+
+// observeQuery records how long a repository method took. Call it as
+// defer observeQuery("GetDepartments", time.Now()).
+func observeQuery(method string, start time.Time) {
+	dbQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// instrument records request counts and latencies. It must wrap the mux
+// directly so the matched route pattern is visible after ServeHTTP.
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+type countingWriter struct {
+	io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.Writer.Write(b)
+	c.n += n
+	return n, err
+}
+
+// writeExport streams data as an Excel download named after entity and
+// records its size.
+func writeExport[T any](w http.ResponseWriter, r *http.Request, entity string, data []T, headers []string, mapper func(T) []string) {
+	SetExcelHeaders(w, entity)
+	cw := &countingWriter{Writer: w}
+	if err := ExportToExcel(cw, data, headers, mapper); err != nil {
+		slog.ErrorContext(r.Context(), "export failed", "entity", entity, "err", err)
+		return
+	}
+	exportBytes.WithLabelValues(entity).Observe(float64(cw.n))
+	exportRows.WithLabelValues(entity).Observe(float64(len(data)))
+}
+
+// handleHealthz reports that the process is up and serving.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "0" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("item"))
+	})
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusSeeOther)
+	})
+	mux.HandleFunc("GET /empty", func(w http.ResponseWriter, r *http.Request) {})
+	h := instrument(mux)
+
+	tests := []struct {
+		method, target string
+		route, status  string
+	}{
+		{"GET", "/items/7", "GET /items/{id}", "200"},
+		{"GET", "/items/0", "GET /items/{id}", "404"},
+		{"POST", "/items", "POST /items", "303"},
+		{"GET", "/empty", "GET /empty", "200"},
+		{"GET", "/nowhere", "unmatched", "404"},
+		{"DELETE", "/items", "unmatched", "405"},
+	}
+	for _, tt := range tests {
+		counter := httpRequests.WithLabelValues(tt.route, tt.method, tt.status)
+		before := testutil.ToFloat64(counter)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.target, nil))
+		if got := testutil.ToFloat64(counter) - before; got != 1 {
+			t.Errorf("%s %s counted %v times under %s %s, want once", tt.method, tt.target, got, tt.route, tt.status)
+		}
+	}
+}
+
+func TestInstrumentSeesNestedRoutes(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Server.DevMode = false
+	h := instrument(NewApp(&cfg, NewMemoryRepositories(), testTemplates(t)).routes())
+
+	// Admin pages live on a mux of their own behind the HR check; once
+	// past it the label names the page, not the catch-all it is mounted on.
+	tests := []struct {
+		header        map[string]string
+		route, status string
+	}{
+		{hrHeaders, "/departments", "200"},
+		{nil, "/", "403"},
+	}
+	for _, tt := range tests {
+		counter := httpRequests.WithLabelValues(tt.route, "GET", tt.status)
+		before := testutil.ToFloat64(counter)
+		send(h, "GET", "/departments", nil, tt.header)
+		if got := testutil.ToFloat64(counter) - before; got != 1 {
+			t.Errorf("GET /departments with %v counted %v times under %s %s, want once", tt.header, got, tt.route, tt.status)
+		}
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleHealthz(w, httptest.NewRequest("GET", "/healthz", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok\n" {
+		t.Errorf("healthz = %d %q, want 200 ok", w.Code, w.Body)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	readyz := func(router *TenantRouter) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.handleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+		return w
+	}
+
+	router := newTestTenants(t, "acme", "globex")
+	if w := readyz(router); w.Code != http.StatusOK || w.Body.String() != "ready\n" {
+		t.Errorf("readyz = %d %q, want 200 ready", w.Code, w.Body)
+	}
+
+	router.byID["globex"].db.Close()
+	w := readyz(router)
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "database of tenant globex unreachable") {
+		t.Errorf("readyz with a closed database = %d %q, want 503 naming globex", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "acme") {
+		t.Errorf("readyz = %q, want acme left out", w.Body)
+	}
+
+	draining := newTestTenants(t, "acme")
+	draining.Drain()
+	if w := readyz(draining); w.Code != http.StatusServiceUnavailable || w.Body.String() != "shutting down\n" {
+		t.Errorf("readyz while draining = %d %q, want 503 shutting down", w.Code, w.Body)
+	}
+}
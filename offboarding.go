@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// employeeTerminated is the status of employees who left.
+const employeeTerminated = "terminated"
+
+// terminationReasons are the reasons an employee leaves, in the order
+// they are offered.
+var terminationReasons = []string{"resignation", "dismissal", "redundancy", "retirement", "contract_end", "other"}
+
+// defaultOffboardingTasks make up the checklist of leavers no offboarding
+// template applies to.
+var defaultOffboardingTasks = []OnboardingTemplateTask{
+	{Title: "Hold exit interview", Assignee: "HR", DueDays: -1},
+	{Title: "Collect company assets", Assignee: "HR", DueDays: 0},
+	{Title: "Revoke system access", Assignee: "IT", DueDays: 0},
+}
+
+// workingDaysPerYear converts the annual salary into the day rate unused
+// leave is paid out at.
+const workingDaysPerYear = 260
+
+func (t *Termination) validate() error {
+	if t.LastDay.IsZero() {
+		return errors.New("Last day is required")
+	}
+	if !slices.Contains(terminationReasons, t.Reason) {
+		return errors.New("Invalid termination reason")
+	}
+	if !(t.UnusedLeaveDays >= 0 && t.Severance >= 0 && t.Deductions >= 0) {
+		return errors.New("Final pay amounts must not be negative")
+	}
+	return nil
+}
+
+// FinalPay estimates what a leaver is owed from their annual salary and
+// the inputs recorded on their termination.
+type FinalPay struct {
+	DaysWorked  int // days of the last month up to the last day
+	DaysInMonth int
+	Salary      float64 // the last month's salary, prorated
+	DayRate     float64
+	LeavePayout float64
+	Severance   float64
+	Deductions  float64
+	Total       float64
+}
+
+func finalPay(e Employee, t Termination) FinalPay {
+	last := civilDate(t.LastDay)
+	p := FinalPay{
+		DaysWorked:  last.Day(),
+		DaysInMonth: time.Date(last.Year(), last.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day(),
+		DayRate:     e.Salary / workingDaysPerYear,
+		Severance:   t.Severance,
+		Deductions:  t.Deductions,
+	}
+	p.Salary = e.Salary / 12 * float64(p.DaysWorked) / float64(p.DaysInMonth)
+	p.LeavePayout = p.DayRate * t.UnusedLeaveDays
+	p.Total = p.Salary + p.LeavePayout + p.Severance - p.Deductions
+	return p
+}
+
+// leavesAfter returns the pending and approved leaves of an employee that
+// extend past their last day, changed so that they don't: leaves that
+// start later are cancelled and leaves that run over are cut short.
+func leavesAfter(leaves []Leave, employeeID int, lastDay time.Time) []Leave {
+	lastDay = civilDate(lastDay)
+	var changed []Leave
+	for _, l := range leaves {
+		if l.EmployeeID != employeeID || (l.Status != "pending" && l.Status != "approved") {
+			continue
+		}
+		switch {
+		case civilDate(l.StartDate).After(lastDay):
+			l.Status = "cancelled"
+		case civilDate(l.EndDate).After(lastDay):
+			l.EndDate = lastDay
+		default:
+			continue
+		}
+		changed = append(changed, l)
+	}
+	return changed
+}
+
+// endLeaves cancels or cuts short the leaves of an employee after their
+// last day. It returns the number of leaves changed.
+func (app *App) endLeaves(ctx context.Context, employeeID int, lastDay time.Time) (int, error) {
+	leaves, err := app.LeaveRepository.GetLeaves(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	changed := leavesAfter(leaves, employeeID, lastDay)
+	for i := range changed {
+		if err := app.LeaveRepository.UpdateLeave(ctx, &changed[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(changed), nil
+}
+
+// startOffboarding creates the offboarding checklist of a leaver from the
+// templates that apply to them, or from defaultOffboardingTasks when none
+// does.
+func (app *App) startOffboarding(ctx context.Context, e *Employee, lastDay time.Time) error {
+	n, err := app.startChecklist(ctx, checklistOffboarding, e, lastDay)
+	if err != nil || n > 0 {
+		return err
+	}
+	tasks, err := app.ChecklistRepository.GetOnboardingTasks(ctx, e.ID)
+	if err != nil {
+		return err
+	}
+	created := make(map[string]bool)
+	for _, t := range ofKind(tasks, checklistOffboarding) {
+		if t.TemplateID != 0 {
+			return nil // the checklist came from templates
+		}
+		created[t.Title] = true
+	}
+	for _, d := range defaultOffboardingTasks {
+		if created[d.Title] {
+			continue // left from an earlier, interrupted start
+		}
+		task := OnboardingTask{
+			EmployeeID: e.ID,
+			Kind:       checklistOffboarding,
+			Title:      d.Title,
+			Assignee:   d.Assignee,
+			DueOn:      civilDate(lastDay).AddDate(0, 0, d.DueDays),
+		}
+		if err := app.ChecklistRepository.CreateOnboardingTask(ctx, &task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TerminationView is a termination on the offboarding overview.
+type TerminationView struct {
+	Termination
+	Employee  Employee
+	Progress  OnboardingProgress
+	Equipment int // items the employee still holds
+}
+
+func (app *App) handleOffboarding(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	terminations, err := app.TerminationRepository.GetTerminations(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch terminations", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	tasks, err := app.ChecklistRepository.GetOnboardingTasks(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+		return
+	}
+	byEmployee := make(map[int][]OnboardingTask)
+	for _, t := range ofKind(tasks, checklistOffboarding) {
+		byEmployee[t.EmployeeID] = append(byEmployee[t.EmployeeID], t)
+	}
+	held, err := app.heldEquipment(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return
+	}
+	holding := make(map[int]int)
+	for _, v := range held {
+		holding[v.Holder.ID]++
+	}
+
+	now := today()
+	views := make([]TerminationView, 0, len(terminations))
+	for _, t := range terminations {
+		e, ok := employees[t.EmployeeID]
+		if !ok {
+			continue
+		}
+		views = append(views, TerminationView{Termination: t, Employee: e, Progress: onboardingProgress(byEmployee[e.ID], now), Equipment: holding[e.ID]})
+	}
+	data := map[string]any{
+		"ActivePage":   "offboarding",
+		"Terminations": views,
+	}
+	app.render(w, r, "offboarding.html", "", data)
+}
+
+// terminationForm reads the fields of the termination form.
+func terminationForm(r *http.Request) (Termination, error) {
+	lastDay, err := time.Parse("2006-01-02", r.FormValue("last_day"))
+	if err != nil {
+		return Termination{}, errors.New("Invalid date")
+	}
+	// Final pay inputs left blank are 0; anything else must be a number.
+	var amounts [3]float64
+	for i, name := range []string{"unused_leave_days", "severance", "deductions"} {
+		v := strings.TrimSpace(r.FormValue(name))
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return Termination{}, errors.New("Invalid final pay amount")
+		}
+		amounts[i] = f
+	}
+	t := Termination{
+		LastDay:         lastDay,
+		Reason:          r.FormValue("reason"),
+		Notes:           strings.TrimSpace(r.FormValue("notes")),
+		RehireEligible:  r.FormValue("rehire_eligible") != "",
+		UnusedLeaveDays: amounts[0],
+		Severance:       amounts[1],
+		Deductions:      amounts[2],
+	}
+	return t, t.validate()
+}
+
+// handleEmployeeOffboarding shows the termination form of an employee
+// or, once they were terminated, their termination, final pay and
+// offboarding checklist. Either way it lists the equipment they still
+// hold.
+func (app *App) handleEmployeeOffboarding(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	termination, err := app.TerminationRepository.GetTermination(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch termination", err)
+		return
+	}
+	held, err := app.heldEquipment(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch equipment", err)
+		return
+	}
+	now := today()
+	data := map[string]any{
+		"ActivePage":  "offboarding",
+		"Employee":    employee,
+		"Termination": termination,
+		"Reasons":     terminationReasons,
+		"Equipment":   held,
+		"Today":       now,
+	}
+	if termination != nil {
+		tasks, err := app.ChecklistRepository.GetOnboardingTasks(r.Context(), employee.ID)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+			return
+		}
+		tasks = ofKind(tasks, checklistOffboarding)
+		data["Tasks"] = tasks
+		data["Progress"] = onboardingProgress(tasks, now)
+		data["FinalPay"] = finalPay(*employee, *termination)
+	}
+	app.render(w, r, "employee_offboarding.html", "", data)
+}
+
+// handleTerminate terminates an employee: it records the termination,
+// marks them terminated, ends their leaves on their last day and creates
+// their offboarding checklist. The steps are separate writes, so a
+// termination that failed halfway is finished by terminating again: the
+// recorded termination is kept and the remaining steps are applied.
+func (app *App) handleTerminate(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	termination, err := app.TerminationRepository.GetTermination(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch termination", err)
+		return
+	}
+	if termination != nil {
+		// The checklist is created last: once it exists, so does
+		// everything else.
+		tasks, err := app.ChecklistRepository.GetOnboardingTasks(r.Context(), employee.ID)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+			return
+		}
+		if employee.Status == employeeTerminated && len(ofKind(tasks, checklistOffboarding)) > 0 {
+			app.clientError(w, r, http.StatusConflict, "Employee is already terminated")
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+			return
+		}
+		t, err := terminationForm(r)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		t.EmployeeID = employee.ID
+		t.ProcessedBy = app.viewer(r).User
+		if err := app.TerminationRepository.CreateTermination(r.Context(), &t); err != nil {
+			app.serverError(w, r, "Failed to terminate employee", err)
+			return
+		}
+		termination = &t
+	}
+
+	if employee.Status != employeeTerminated {
+		employee.Status = employeeTerminated
+		if err := app.EmployeeRepository.UpdateEmployee(r.Context(), employee); err != nil {
+			app.serverError(w, r, "Failed to update employee", err)
+			return
+		}
+	}
+	if _, err := app.endLeaves(r.Context(), employee.ID, termination.LastDay); err != nil {
+		app.serverError(w, r, "Failed to cancel leaves", err)
+		return
+	}
+	if err := app.startOffboarding(r.Context(), employee, termination.LastDay); err != nil {
+		app.serverError(w, r, "Failed to create offboarding tasks", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/offboarding/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleUpdateTermination corrects a termination. Moving the last day
+// earlier ends the leaves after the new date too.
+func (app *App) handleUpdateTermination(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	termination, err := app.TerminationRepository.GetTermination(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch termination", err)
+		return
+	}
+	if termination == nil {
+		app.clientError(w, r, http.StatusNotFound, "Termination not found")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated, err := terminationForm(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated.ID, updated.EmployeeID = termination.ID, employee.ID
+	if err := app.TerminationRepository.UpdateTermination(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update termination", err)
+		return
+	}
+	if _, err := app.endLeaves(r.Context(), employee.ID, updated.LastDay); err != nil {
+		app.serverError(w, r, "Failed to cancel leaves", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/offboarding/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFinalPay(t *testing.T) {
+	e := Employee{Salary: 52000}
+	p := finalPay(e, Termination{LastDay: time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), UnusedLeaveDays: 3, Severance: 1000, Deductions: 250})
+	if p.DaysWorked != 15 || p.DaysInMonth != 30 || p.DayRate != 200 || p.LeavePayout != 600 {
+		t.Errorf("finalPay() = %+v, want 15 of 30 days and 3 days at 200", p)
+	}
+	if want := 52000.0/12/2 + 600 + 1000 - 250; math.Abs(p.Total-want) > 1e-9 || math.Abs(p.Salary-52000.0/24) > 1e-9 {
+		t.Errorf("finalPay().Total = %v, want %v", p.Total, want)
+	}
+}
+
+func TestLeavesAfter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 4, d, 0, 0, 0, 0, time.UTC) }
+	leaves := []Leave{
+		{ID: 1, EmployeeID: 7, StartDate: day(1), EndDate: day(3), Status: "approved"},
+		{ID: 2, EmployeeID: 7, StartDate: day(14), EndDate: day(18), Status: "approved"},
+		{ID: 3, EmployeeID: 7, StartDate: day(20), EndDate: day(22), Status: "pending"},
+		{ID: 4, EmployeeID: 7, StartDate: day(25), EndDate: day(26), Status: "rejected"},
+		{ID: 5, EmployeeID: 8, StartDate: day(20), EndDate: day(22), Status: "approved"},
+	}
+	got := leavesAfter(leaves, 7, day(15))
+	if len(got) != 2 || got[0].ID != 2 || !got[0].EndDate.Equal(day(15)) || got[0].Status != "approved" || got[1].ID != 3 || got[1].Status != "cancelled" {
+		t.Errorf("leavesAfter() = %+v, want leave 2 cut short and 3 cancelled", got)
+	}
+	if leaves[1].EndDate != day(18) {
+		t.Error("leavesAfter() changed its input")
+	}
+}
+
+func TestOffboarding(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	hr := map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "staff, hr"}
+
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Salary: 52000, Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &omar); err != nil {
+		t.Fatal(err)
+	}
+	lastDay := today().AddDate(0, 0, 10)
+	for _, l := range []Leave{
+		{EmployeeID: omar.ID, LeaveType: "annual", StartDate: lastDay.AddDate(0, 0, -2), EndDate: lastDay.AddDate(0, 0, 3), Status: "approved"},
+		{EmployeeID: omar.ID, LeaveType: "annual", StartDate: lastDay.AddDate(0, 0, 5), EndDate: lastDay.AddDate(0, 0, 6), Status: "pending"},
+	} {
+		if err := repos.Leaves.CreateLeave(ctx, &l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page := "/offboarding/employees/" + strconv.Itoa(omar.ID)
+	form := url.Values{"last_day": {lastDay.Format("2006-01-02")}, "reason": {"resignation"}, "rehire_eligible": {"1"}, "unused_leave_days": {"2"}}
+	if w := send(h, "POST", page, form, nil); w.Code != http.StatusForbidden {
+		t.Errorf("terminate as staff = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", page, url.Values{"last_day": {lastDay.Format("2006-01-02")}, "reason": {"bored"}}, hr); w.Code != http.StatusBadRequest {
+		t.Errorf("terminate with an unknown reason = %d, want 400", w.Code)
+	}
+	for _, bad := range []url.Values{{"severance": {"1O00"}}, {"deductions": {"-5"}}, {"unused_leave_days": {"NaN"}}, {"severance": {"Inf"}}} {
+		bad.Set("last_day", lastDay.Format("2006-01-02"))
+		bad.Set("reason", "resignation")
+		if w := send(h, "POST", page, bad, hr); w.Code != http.StatusBadRequest {
+			t.Errorf("terminate with final pay %v = %d, want 400", bad, w.Code)
+		}
+	}
+	if w := send(h, "GET", page, nil, hr); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `name="last_day"`) {
+		t.Errorf("termination form = %d, want the form", w.Code)
+	}
+	if w := send(h, "POST", page, form, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("terminate = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", page, form, hr); w.Code != http.StatusConflict {
+		t.Errorf("terminate again = %d, want 409", w.Code)
+	}
+
+	if got, _ := repos.Employees.GetEmployeeByID(ctx, omar.ID); got == nil || got.Status != employeeTerminated {
+		t.Errorf("employee = %+v, want terminated", got)
+	}
+	if got, _ := repos.Terminations.GetTermination(ctx, omar.ID); got == nil || got.ProcessedBy != "hana" || !got.RehireEligible || got.UnusedLeaveDays != 2 {
+		t.Errorf("termination = %+v, want it processed by hana", got)
+	}
+	leaves, _ := repos.Leaves.GetLeaves(ctx, "")
+	for _, l := range leaves {
+		if l.Status == "approved" && !l.EndDate.Equal(lastDay) || l.Status == "pending" {
+			t.Errorf("leave = %+v, want it ending on the last day or cancelled", l)
+		}
+	}
+	tasks, _ := repos.OnboardingTasks.GetOnboardingTasks(ctx, omar.ID)
+	if len(tasks) != len(defaultOffboardingTasks) || tasks[0].Kind != checklistOffboarding || !tasks[0].DueOn.Equal(lastDay.AddDate(0, 0, -1)) {
+		t.Errorf("tasks = %+v, want the default offboarding checklist", tasks)
+	}
+
+	w := send(h, "GET", page, nil, hr)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Revoke system access") {
+		t.Errorf("offboarding page = %d, want the checklist", w.Code)
+	}
+	form.Set("reason", "dismissal")
+	form.Set("last_day", lastDay.AddDate(0, 0, -5).Format("2006-01-02"))
+	if w := send(h, "PUT", page, form, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("update termination = %d %s", w.Code, w.Body)
+	}
+	leaves, _ = repos.Leaves.GetLeaves(ctx, "")
+	for _, l := range leaves {
+		if l.Status != "cancelled" {
+			t.Errorf("leave after moving the last day earlier = %+v, want cancelled", l)
+		}
+	}
+	if w := send(h, "GET", "/offboarding", nil, hr); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Omar") {
+		t.Errorf("offboarding overview = %d, want Omar listed", w.Code)
+	}
+}
+
+func TestTerminateFinishesAnInterruptedTermination(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	hr := map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"}
+
+	lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &lea); err != nil {
+		t.Fatal(err)
+	}
+	lastDay := today().AddDate(0, 0, 10)
+	later := Leave{EmployeeID: lea.ID, LeaveType: "vacation", StartDate: lastDay.AddDate(0, 0, 5), EndDate: lastDay.AddDate(0, 0, 6), Status: "approved"}
+	if err := repos.Leaves.CreateLeave(ctx, &later); err != nil {
+		t.Fatal(err)
+	}
+	// The termination was recorded, then the next write failed.
+	recorded := Termination{EmployeeID: lea.ID, LastDay: lastDay, Reason: "resignation", ProcessedBy: "hana"}
+	if err := repos.Terminations.CreateTermination(ctx, &recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	page := "/offboarding/employees/" + strconv.Itoa(lea.ID)
+	if w := send(h, "POST", page, url.Values{"last_day": {lastDay.Format("2006-01-02")}, "reason": {"resignation"}}, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("terminate again = %d %s, want the termination finished", w.Code, w.Body)
+	}
+	if got, _ := repos.Employees.GetEmployeeByID(ctx, lea.ID); got.Status != employeeTerminated {
+		t.Errorf("status = %q, want terminated", got.Status)
+	}
+	if got, _ := repos.Leaves.GetLeaveByID(ctx, later.ID); got.Status != "cancelled" {
+		t.Errorf("leave after the last day = %q, want cancelled", got.Status)
+	}
+	if tasks, _ := repos.OnboardingTasks.GetOnboardingTasks(ctx, lea.ID); len(tasks) != len(defaultOffboardingTasks) {
+		t.Errorf("tasks = %+v, want the default offboarding checklist", tasks)
+	}
+	if w := send(h, "POST", page, url.Values{"last_day": {lastDay.Format("2006-01-02")}, "reason": {"resignation"}}, hr); w.Code != http.StatusConflict {
+		t.Errorf("terminate once finished = %d, want 409", w.Code)
+	}
+}
+
+func TestEmployeeFormLeavesTerminationToOffboarding(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	sam := Employee{FirstName: "Sam", LastName: "Nasr", Email: "sam@example.com", Status: "active"}
+	gone := Employee{FirstName: "Rami", LastName: "Aoun", Email: "rami@example.com", Status: employeeTerminated}
+	for _, e := range []*Employee{&sam, &gone} {
+		if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	form := func(e Employee, status string) url.Values {
+		return url.Values{"first_name": {e.FirstName}, "last_name": {e.LastName}, "email": {e.Email}, "status": {status}}
+	}
+	if w := send(h, "POST", "/employees/add", form(Employee{FirstName: "Nour", Email: "nour@example.com"}, employeeTerminated), hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add a terminated employee = %d, want 400", w.Code)
+	}
+	update := func(e Employee, status string) int {
+		return send(h, "PUT", "/employees/update/"+strconv.Itoa(e.ID), form(e, status), hrHeaders).Code
+	}
+	if code := update(sam, employeeTerminated); code != http.StatusConflict {
+		t.Errorf("terminate from the employee form = %d, want 409", code)
+	}
+	if code := update(gone, "active"); code != http.StatusConflict {
+		t.Errorf("reactivate from the employee form = %d, want 409", code)
+	}
+	if code := update(sam, "suspended"); code != http.StatusSeeOther {
+		t.Errorf("suspend = %d, want 303", code)
+	}
+	if code := update(gone, employeeTerminated); code != http.StatusSeeOther {
+		t.Errorf("edit a terminated employee = %d, want 303", code)
+	}
+	if w := send(h, "GET", "/employees/update/"+strconv.Itoa(gone.ID), nil, hrHeaders); w.Code != http.StatusOK || strings.Contains(w.Body.String(), `value="active"`) {
+		t.Errorf("form of a terminated employee = %d, want no way to reactivate", w.Code)
+	}
+}
@@ -0,0 +1,595 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of checklists.
+const (
+	checklistOnboarding  = "onboarding"
+	checklistOffboarding = "offboarding"
+)
+
+func (t *OnboardingTemplate) validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("Name is required")
+	}
+	if t.Kind != checklistOnboarding && t.Kind != checklistOffboarding {
+		return errors.New("Invalid checklist kind")
+	}
+	return nil
+}
+
+func (t *OnboardingTemplateTask) validate() error {
+	if strings.TrimSpace(t.Title) == "" {
+		return errors.New("Title is required")
+	}
+	return nil
+}
+
+// Matches reports whether the template applies to the employee.
+func (t OnboardingTemplate) Matches(e Employee) bool {
+	return (t.DepartmentID == 0 || t.DepartmentID == e.DepartmentID) &&
+		(t.Position == "" || strings.EqualFold(t.Position, strings.TrimSpace(e.JobTitle)))
+}
+
+func (t OnboardingTask) Done() bool {
+	return !t.DoneOn.IsZero()
+}
+
+// Overdue reports whether the task is still open after its due date.
+func (t OnboardingTask) Overdue(today time.Time) bool {
+	return !t.Done() && today.After(t.DueOn)
+}
+
+// checklistTasks returns a checklist of the given kind for an employee:
+// one task for each task of the templates of that kind that apply to
+// them, due relative to start. Templates in applied have been used for
+// them before and are skipped.
+func checklistTasks(kind string, e Employee, start time.Time, templates []OnboardingTemplate, tasks []OnboardingTemplateTask, applied map[int]bool) []OnboardingTask {
+	start = civilDate(start)
+	var checklist []OnboardingTask
+	for _, t := range templates {
+		if t.Kind != kind || applied[t.ID] || !t.Matches(e) {
+			continue
+		}
+		for _, task := range tasks {
+			if task.TemplateID != t.ID {
+				continue
+			}
+			checklist = append(checklist, OnboardingTask{
+				EmployeeID: e.ID,
+				Kind:       kind,
+				TemplateID: t.ID,
+				Title:      task.Title,
+				Assignee:   task.Assignee,
+				DueOn:      start.AddDate(0, 0, task.DueDays),
+			})
+		}
+	}
+	slices.SortStableFunc(checklist, func(a, b OnboardingTask) int { return a.DueOn.Compare(b.DueOn) })
+	return checklist
+}
+
+// OnboardingProgress counts the tasks of a checklist.
+type OnboardingProgress struct {
+	Total   int
+	Done    int
+	Overdue int
+}
+
+// Percent returns the share of the tasks that are done.
+func (p OnboardingProgress) Percent() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Done) * 100 / float64(p.Total)
+}
+
+func onboardingProgress(tasks []OnboardingTask, today time.Time) OnboardingProgress {
+	var p OnboardingProgress
+	for _, t := range tasks {
+		p.Total++
+		if t.Done() {
+			p.Done++
+		} else if t.Overdue(today) {
+			p.Overdue++
+		}
+	}
+	return p
+}
+
+// ofKind returns the tasks of a kind of checklist.
+func ofKind(tasks []OnboardingTask, kind string) []OnboardingTask {
+	return slices.DeleteFunc(tasks, func(t OnboardingTask) bool { return t.Kind != kind })
+}
+
+// startOnboarding creates the onboarding tasks of an employee from the
+// templates that apply to them and have not been used for them yet. It
+// returns the number of tasks created.
+func (app *App) startOnboarding(ctx context.Context, e *Employee) (int, error) {
+	return app.startChecklist(ctx, checklistOnboarding, e, e.HireDate)
+}
+
+// startChecklist creates the tasks of a kind of checklist for an
+// employee, due relative to start, from the templates that apply to them
+// and have not been used for them yet. It returns the number of tasks
+// created.
+func (app *App) startChecklist(ctx context.Context, kind string, e *Employee, start time.Time) (int, error) {
+	templates, err := app.OnboardingRepository.GetOnboardingTemplates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tasks, err := app.TemplateTaskRepository.GetOnboardingTemplateTasks(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	existing, err := app.ChecklistRepository.GetOnboardingTasks(ctx, e.ID)
+	if err != nil {
+		return 0, err
+	}
+	applied := make(map[int]bool, len(existing))
+	for _, t := range existing {
+		applied[t.TemplateID] = true
+	}
+	checklist := checklistTasks(kind, *e, start, templates, tasks, applied)
+	for i := range checklist {
+		if err := app.ChecklistRepository.CreateOnboardingTask(ctx, &checklist[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(checklist), nil
+}
+
+// OnboardingView is an employee on the onboarding overview.
+type OnboardingView struct {
+	Employee Employee
+	Progress OnboardingProgress
+}
+
+// OverdueTask is an overdue onboarding task with its employee.
+type OverdueTask struct {
+	OnboardingTask
+	Employee Employee
+}
+
+func (app *App) handleOnboarding(w http.ResponseWriter, r *http.Request) {
+	tasks, err := app.ChecklistRepository.GetOnboardingTasks(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+		return
+	}
+	tasks = ofKind(tasks, checklistOnboarding)
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	now := today()
+	byEmployee := make(map[int][]OnboardingTask)
+	var overdue []OverdueTask
+	for _, t := range tasks {
+		e, ok := employees[t.EmployeeID]
+		if !ok {
+			continue
+		}
+		byEmployee[e.ID] = append(byEmployee[e.ID], t)
+		if t.Overdue(now) {
+			overdue = append(overdue, OverdueTask{OnboardingTask: t, Employee: e})
+		}
+	}
+	views := make([]OnboardingView, 0, len(byEmployee))
+	for id, tasks := range byEmployee {
+		views = append(views, OnboardingView{Employee: employees[id], Progress: onboardingProgress(tasks, now)})
+	}
+	// Newest hires first.
+	slices.SortFunc(views, func(a, b OnboardingView) int {
+		if c := b.Employee.HireDate.Compare(a.Employee.HireDate); c != 0 {
+			return c
+		}
+		return a.Employee.ID - b.Employee.ID
+	})
+
+	data := map[string]any{
+		"ActivePage": "onboarding",
+		"Employees":  views,
+		"Overdue":    overdue,
+		"Today":      now,
+	}
+	app.render(w, r, "onboarding.html", "", data)
+}
+
+func (app *App) handleOnboardingTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := app.OnboardingRepository.GetOnboardingTemplates(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding templates", err)
+		return
+	}
+	tasks, err := app.TemplateTaskRepository.GetOnboardingTemplateTasks(r.Context(), 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+	counts := make(map[int]int, len(templates))
+	for _, t := range tasks {
+		counts[t.TemplateID]++
+	}
+
+	data := map[string]any{
+		"ActivePage":  "onboarding",
+		"Templates":   templates,
+		"TaskCounts":  counts,
+		"Departments": departments,
+	}
+	app.render(w, r, "onboarding_templates.html", "", data)
+}
+
+// onboardingTemplateForm reads the fields of the add and update template
+// forms.
+func onboardingTemplateForm(r *http.Request) OnboardingTemplate {
+	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	return OnboardingTemplate{
+		Kind:         r.FormValue("kind"),
+		Name:         strings.TrimSpace(r.FormValue("name")),
+		DepartmentID: deptID,
+		Position:     strings.TrimSpace(r.FormValue("position")),
+	}
+}
+
+// onboardingTemplateData returns the page data of the add and update
+// template forms.
+func (app *App) onboardingTemplateData(ctx context.Context, t *OnboardingTemplate) (map[string]any, error) {
+	departments, err := app.DepartmentRepository.GetDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	positions, err := app.PositionRepository.GetPositions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"ActivePage":  "onboarding",
+		"Template":    t,
+		"Departments": departments,
+		"Positions":   positions,
+	}, nil
+}
+
+func (app *App) handleAddOnboardingTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		data, err := app.onboardingTemplateData(r.Context(), &OnboardingTemplate{Kind: checklistOnboarding})
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		app.render(w, r, "add_onboarding_template.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	template := onboardingTemplateForm(r)
+	if err := template.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.OnboardingRepository.CreateOnboardingTemplate(r.Context(), &template); err != nil {
+		app.serverError(w, r, "Failed to add onboarding template", err)
+		return
+	}
+	// Tasks are added on the update page.
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/onboarding/templates/update/%d", template.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// onboardingTemplate fetches the template named by the request's id path
+// value and writes the error response when there is none.
+func (app *App) onboardingTemplate(w http.ResponseWriter, r *http.Request) (*OnboardingTemplate, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	template, err := app.OnboardingRepository.GetOnboardingTemplateByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding template", err)
+		return nil, false
+	}
+	if template == nil {
+		app.clientError(w, r, http.StatusNotFound, "Template not found")
+		return nil, false
+	}
+	return template, true
+}
+
+// handleUpdateOnboardingTemplate shows a template with its tasks and
+// saves changes to it.
+func (app *App) handleUpdateOnboardingTemplate(w http.ResponseWriter, r *http.Request) {
+	template, ok := app.onboardingTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		data, err := app.onboardingTemplateData(r.Context(), template)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		tasks, err := app.TemplateTaskRepository.GetOnboardingTemplateTasks(r.Context(), template.ID)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+			return
+		}
+		data["Tasks"] = tasks
+		app.render(w, r, "update_onboarding_template.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated := onboardingTemplateForm(r)
+	updated.ID = template.ID
+	if err := updated.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.OnboardingRepository.UpdateOnboardingTemplate(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update onboarding template", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/onboarding/templates")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteOnboardingTemplate deletes a template with its tasks. The
+// checklists generated from it are kept.
+func (app *App) handleDeleteOnboardingTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	tasks, err := app.TemplateTaskRepository.GetOnboardingTemplateTasks(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+		return
+	}
+	for _, t := range tasks {
+		if err := app.TemplateTaskRepository.DeleteOnboardingTemplateTask(r.Context(), t.ID); err != nil {
+			app.serverError(w, r, "Failed to delete onboarding task", err)
+			return
+		}
+	}
+	if err := app.OnboardingRepository.DeleteOnboardingTemplate(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete onboarding template", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/onboarding/templates")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleAddTemplateTask(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	templateID, _ := strconv.Atoi(r.FormValue("template_id"))
+	template, err := app.OnboardingRepository.GetOnboardingTemplateByID(r.Context(), templateID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding template", err)
+		return
+	}
+	if template == nil {
+		app.clientError(w, r, http.StatusNotFound, "Template not found")
+		return
+	}
+	dueDays, err := strconv.Atoi(r.FormValue("due_days"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid number of days")
+		return
+	}
+	task := OnboardingTemplateTask{
+		TemplateID: template.ID,
+		Title:      strings.TrimSpace(r.FormValue("title")),
+		Assignee:   strings.TrimSpace(r.FormValue("assignee")),
+		DueDays:    dueDays,
+	}
+	if err := task.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.TemplateTaskRepository.CreateOnboardingTemplateTask(r.Context(), &task); err != nil {
+		app.serverError(w, r, "Failed to add onboarding task", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/onboarding/templates/update/%d", template.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteTemplateTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	templateID, _ := strconv.Atoi(r.FormValue("template_id"))
+	if err := app.TemplateTaskRepository.DeleteOnboardingTemplateTask(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete onboarding task", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/onboarding/templates/update/%d", templateID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleEmployeeOnboarding(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	tasks, err := app.ChecklistRepository.GetOnboardingTasks(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding tasks", err)
+		return
+	}
+	tasks = ofKind(tasks, checklistOnboarding)
+	templates, err := app.OnboardingRepository.GetOnboardingTemplates(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding templates", err)
+		return
+	}
+	// Templates that apply to the employee but were added after they
+	// were hired.
+	applied := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		applied[t.TemplateID] = true
+	}
+	var pending []OnboardingTemplate
+	for _, t := range templates {
+		if t.Kind == checklistOnboarding && !applied[t.ID] && t.Matches(*employee) {
+			pending = append(pending, t)
+		}
+	}
+
+	now := today()
+	data := map[string]any{
+		"ActivePage": "onboarding",
+		"Employee":   employee,
+		"Tasks":      tasks,
+		"Progress":   onboardingProgress(tasks, now),
+		"Pending":    pending,
+		"Today":      now,
+	}
+	app.render(w, r, "employee_onboarding.html", "", data)
+}
+
+// handleStartOnboarding adds the tasks of templates that apply to the
+// employee but are not on their checklist yet, such as templates created
+// after they were hired.
+func (app *App) handleStartOnboarding(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	n, err := app.startOnboarding(r.Context(), employee)
+	if err != nil {
+		app.serverError(w, r, "Failed to create onboarding tasks", err)
+		return
+	}
+	if n == 0 {
+		app.clientError(w, r, http.StatusConflict, "No new onboarding tasks apply to this employee")
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/onboarding/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// checklistURL returns the page of the checklist the task is on.
+func checklistURL(t *OnboardingTask) string {
+	if t.Kind == checklistOffboarding {
+		return fmt.Sprintf("/offboarding/employees/%d", t.EmployeeID)
+	}
+	return fmt.Sprintf("/onboarding/employees/%d", t.EmployeeID)
+}
+
+// onboardingTask fetches the task named by id and writes the error
+// response when there is none.
+func (app *App) onboardingTask(w http.ResponseWriter, r *http.Request, id int) (*OnboardingTask, bool) {
+	task, err := app.ChecklistRepository.GetOnboardingTaskByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch onboarding task", err)
+		return nil, false
+	}
+	if task == nil {
+		app.clientError(w, r, http.StatusNotFound, "Task not found")
+		return nil, false
+	}
+	return task, true
+}
+
+// handleToggleOnboardingTask ticks a task off, or reopens it if it was
+// done.
+func (app *App) handleToggleOnboardingTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	task, ok := app.onboardingTask(w, r, id)
+	if !ok {
+		return
+	}
+	if task.Done() {
+		task.DoneOn, task.DoneBy = time.Time{}, ""
+	} else {
+		task.DoneOn, task.DoneBy = today(), app.viewer(r).User
+	}
+	if err := app.ChecklistRepository.UpdateOnboardingTask(r.Context(), task); err != nil {
+		app.serverError(w, r, "Failed to update onboarding task", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", checklistURL(task))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteOnboardingTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	task, ok := app.onboardingTask(w, r, id)
+	if !ok {
+		return
+	}
+	if err := app.ChecklistRepository.DeleteOnboardingTask(r.Context(), task.ID); err != nil {
+		app.serverError(w, r, "Failed to delete onboarding task", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", checklistURL(task))
+	w.WriteHeader(http.StatusSeeOther)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecklistTasks(t *testing.T) {
+	hired := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	e := Employee{ID: 7, JobTitle: "Engineer ", DepartmentID: 1, HireDate: hired}
+	templates := []OnboardingTemplate{
+		{ID: 1, Kind: checklistOnboarding, Name: "Everyone"},
+		{ID: 2, Kind: checklistOnboarding, Name: "Engineering", DepartmentID: 1, Position: "engineer"},
+		{ID: 3, Kind: checklistOnboarding, Name: "Sales", DepartmentID: 2},
+		{ID: 4, Kind: checklistOnboarding, Name: "Managers", Position: "Manager"},
+		{ID: 5, Kind: checklistOnboarding, Name: "Applied before"},
+		{ID: 6, Kind: checklistOffboarding, Name: "Leavers"},
+	}
+	tasks := []OnboardingTemplateTask{
+		{TemplateID: 1, Title: "Welcome lunch", DueDays: 0},
+		{TemplateID: 1, Title: "Email account", Assignee: "IT", DueDays: -2},
+		{TemplateID: 2, Title: "Repository access", Assignee: "IT", DueDays: 1},
+		{TemplateID: 3, Title: "CRM account"},
+		{TemplateID: 4, Title: "Budget training"},
+		{TemplateID: 5, Title: "Badge"},
+		{TemplateID: 6, Title: "Exit interview", DueDays: -1},
+	}
+
+	got := checklistTasks(checklistOnboarding, e, hired, templates, tasks, map[int]bool{5: true})
+	want := []string{"Email account", "Welcome lunch", "Repository access"}
+	if len(got) != len(want) {
+		t.Fatalf("checklistTasks() = %+v, want %v", got, want)
+	}
+	for i, task := range got {
+		if task.Title != want[i] || task.EmployeeID != 7 || task.Kind != checklistOnboarding {
+			t.Errorf("task %d = %+v, want %s for employee 7", i, task, want[i])
+		}
+	}
+	if !got[0].DueOn.Equal(hired.AddDate(0, 0, -2)) || got[0].Assignee != "IT" || got[0].TemplateID != 1 {
+		t.Errorf("email task = %+v, want IT two days before the hire date", got[0])
+	}
+	lastDay := time.Date(2026, 5, 29, 0, 0, 0, 0, time.UTC)
+	if leaving := checklistTasks(checklistOffboarding, e, lastDay, templates, tasks, nil); len(leaving) != 1 || !leaving[0].DueOn.Equal(lastDay.AddDate(0, 0, -1)) {
+		t.Errorf("offboarding checklistTasks() = %+v, want the exit interview the day before the last day", leaving)
+	}
+
+	got[0].DoneOn = hired
+	p := onboardingProgress(got, hired.AddDate(0, 0, 1))
+	if p != (OnboardingProgress{Total: 3, Done: 1, Overdue: 1}) || int(p.Percent()) != 33 {
+		t.Errorf("onboardingProgress() = %+v (%v%%), want 1 of 3 done and the lunch overdue", p, p.Percent())
+	}
+}
+
+func TestOnboarding(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	if w := send(h, "POST", "/onboarding/templates/add", url.Values{"kind": {"onboarding"}, "name": {" "}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add template without a name = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", "/onboarding/templates/add", url.Values{"kind": {"onboarding"}, "name": {"New hire"}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add template = %d %s", w.Code, w.Body)
+	}
+	templates, _ := repos.OnboardingTemplates.GetOnboardingTemplates(ctx)
+	template := strconv.Itoa(templates[0].ID)
+	for _, form := range []url.Values{
+		{"template_id": {template}, "title": {"Create email account"}, "assignee": {"IT"}, "due_days": {"-3"}},
+		{"template_id": {template}, "title": {"First-week plan"}, "assignee": {"Manager"}, "due_days": {"0"}},
+	} {
+		if w := send(h, "POST", "/onboarding/templates/tasks", form, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add template task = %d %s", w.Code, w.Body)
+		}
+	}
+	if w := send(h, "POST", "/onboarding/templates/tasks", url.Values{"template_id": {template}, "title": {"Badge"}, "due_days": {"soon"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add template task with bad days = %d, want 400", w.Code)
+	}
+
+	// A hire who started a week ago has the email task overdue.
+	hired := today().AddDate(0, 0, -7)
+	form := url.Values{"first_name": {"Omar"}, "last_name": {"Khalil"}, "email": {"omar@example.com"}, "hire_date": {hired.Format("2006-01-02")}, "status": {"active"}}
+	if w := send(h, "POST", "/employees/add", form, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add employee = %d %s", w.Code, w.Body)
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+	tasks, _ := repos.OnboardingTasks.GetOnboardingTasks(ctx, employees[0].ID)
+	if len(tasks) != 2 || tasks[0].Title != "Create email account" || !tasks[0].DueOn.Equal(hired.AddDate(0, 0, -3)) {
+		t.Fatalf("tasks on hire = %+v, want both, the email first", tasks)
+	}
+
+	page := "/onboarding/employees/" + strconv.Itoa(employees[0].ID)
+	w := send(h, "GET", page, nil, hrHeaders)
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), `class="row-overdue"`) != 2 {
+		t.Errorf("checklist = %d, want both tasks highlighted overdue", w.Code)
+	}
+	if w := send(h, "POST", page, nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("start onboarding again = %d, want 409", w.Code)
+	}
+
+	done := "/onboarding/tasks/" + strconv.Itoa(tasks[0].ID) + "/done"
+	if w := send(h, "POST", done, nil, map[string]string{"X-Forwarded-User": "it-desk", "X-Forwarded-Groups": "hr"}); w.Code != http.StatusSeeOther {
+		t.Fatalf("mark done = %d %s", w.Code, w.Body)
+	}
+	if got, _ := repos.OnboardingTasks.GetOnboardingTaskByID(ctx, tasks[0].ID); got == nil || !got.Done() || got.DoneBy != "it-desk" {
+		t.Errorf("task = %+v, want done by it-desk", got)
+	}
+	w = send(h, "GET", "/onboarding", nil, hrHeaders)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "First-week plan") || strings.Contains(w.Body.String(), "Create email account") {
+		t.Errorf("overview = %d, want only the open plan listed overdue", w.Code)
+	}
+	if w := send(h, "POST", done, nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("reopen = %d %s", w.Code, w.Body)
+	}
+	if got, _ := repos.OnboardingTasks.GetOnboardingTaskByID(ctx, tasks[0].ID); got == nil || got.Done() {
+		t.Errorf("task = %+v, want reopened", got)
+	}
+
+	if w := send(h, "DELETE", "/onboarding/templates/delete?id="+template, nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("delete template = %d %s", w.Code, w.Body)
+	}
+	if left, _ := repos.TemplateTasks.GetOnboardingTemplateTasks(ctx, 0); len(left) != 0 {
+		t.Errorf("template tasks after delete = %+v, want none", left)
+	}
+	if left, _ := repos.OnboardingTasks.GetOnboardingTasks(ctx, 0); len(left) != 2 {
+		t.Errorf("checklist after template delete = %+v, want it kept", left)
+	}
+	if w := send(h, "DELETE", "/onboarding/tasks/delete?id="+strconv.Itoa(tasks[1].ID), nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("delete task = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "GET", "/onboarding/templates", nil, hrHeaders); w.Code != http.StatusOK {
+		t.Errorf("templates = %d", w.Code)
+	}
+}
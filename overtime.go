@@ -0,0 +1,460 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// overtimeCompensations are the ways overtime is made up for: paid with
+// the next payroll, or credited as time off in lieu (toil).
+var overtimeCompensations = []string{"pay", "toil"}
+
+// rate returns the multiplier of overtime worked on a day of kind, as
+// returned by CalendarConfig.dayKind.
+func (c OvertimeConfig) rate(kind string) float64 {
+	switch kind {
+	case "holiday":
+		return c.HolidayRate
+	case "weekend":
+		return c.WeekendRate
+	}
+	return c.WeekdayRate
+}
+
+func (o *OvertimeRequest) validate() error {
+	// Written so that NaN, which compares false with everything, fails.
+	if !(o.Hours > 0) {
+		return errors.New("Hours must be positive")
+	}
+	if o.Hours > 24 {
+		return errors.New("Overtime can't be more than 24 hours a day")
+	}
+	if !slices.Contains(overtimeCompensations, o.Compensation) {
+		return errors.New("Invalid compensation")
+	}
+	return nil
+}
+
+// Credited returns the hours the request is worth once its multiplier is
+// applied: the hours paid, or the hours of time off in lieu.
+func (o OvertimeRequest) Credited() float64 {
+	return o.Hours * o.Multiplier
+}
+
+// ToilBalance is an employee's time off in lieu, in hours: approved toil
+// overtime credits it and approved toil leaves draw on it.
+type ToilBalance struct {
+	Credited float64
+	Taken    float64
+	DayHours float64
+}
+
+// Available returns the hours that can still be taken.
+func (b ToilBalance) Available() float64 {
+	return b.Credited - b.Taken
+}
+
+// Days returns the available hours as days of leave.
+func (b ToilBalance) Days() float64 {
+	return b.Available() / b.DayHours
+}
+
+// toilBalance sums the time off in lieu of an employee. The leave with
+// skipLeaveID is left out, so a leave being edited doesn't count against
+// itself.
+func toilBalance(requests []OvertimeRequest, leaves []Leave, employeeID, skipLeaveID int, cal CalendarConfig, cfg OvertimeConfig) ToilBalance {
+	b := ToilBalance{DayHours: cfg.DayHours}
+	for _, o := range requests {
+		if o.EmployeeID == employeeID && o.Compensation == "toil" && o.Status == "approved" {
+			b.Credited += o.Credited()
+		}
+	}
+	for _, l := range leaves {
+		if l.EmployeeID == employeeID && l.LeaveType == "toil" && l.Status == "approved" && l.ID != skipLeaveID {
+			b.Taken += float64(cal.leaveDays(l)) * cfg.DayHours
+		}
+	}
+	return b
+}
+
+func (app *App) toilBalance(ctx context.Context, employeeID, skipLeaveID int) (ToilBalance, error) {
+	requests, err := app.OvertimeRepository.GetOvertimeRequests(ctx, employeeID, "approved")
+	if err != nil {
+		return ToilBalance{}, err
+	}
+	leaves, err := app.LeaveRepository.GetLeaves(ctx, "")
+	if err != nil {
+		return ToilBalance{}, err
+	}
+	return toilBalance(requests, leaves, employeeID, skipLeaveID, app.Config.Calendar, app.Config.Overtime), nil
+}
+
+// checkToilLeave writes the error response and returns false when an
+// approved leave taken as time off in lieu is longer than the employee
+// has left.
+func (app *App) checkToilLeave(w http.ResponseWriter, r *http.Request, leave Leave) bool {
+	if leave.LeaveType != "toil" || leave.Status != "approved" {
+		return true
+	}
+	balance, err := app.toilBalance(r.Context(), leave.EmployeeID, leave.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return false
+	}
+	if float64(app.Config.Calendar.leaveDays(leave))*balance.DayHours > balance.Available() {
+		app.clientError(w, r, http.StatusConflict, "Not enough time off in lieu")
+		return false
+	}
+	return true
+}
+
+func (app *App) handleOvertime(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	requests, err := app.OvertimeRepository.GetOvertimeRequests(r.Context(), 0, status)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage": "overtime",
+		"Requests":   requests,
+		"Employees":  employees,
+		"Status":     status,
+		"Period":     time.Now().Format("2006-01"),
+		"IsHR":       app.isHR(app.viewer(r)),
+	}
+	app.render(w, r, "overtime.html", "", data)
+}
+
+func (app *App) handleAddOvertime(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":    "overtime",
+			"Employees":     slices.DeleteFunc(employees, func(e Employee) bool { return !isActive(e) }),
+			"EmployeeID":    r.URL.Query().Get("employee_id"),
+			"Compensations": overtimeCompensations,
+			"Rates":         app.Config.Overtime,
+		}
+		app.render(w, r, "add_overtime.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	day, err := formDate(r, "date")
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	hours, err := strconv.ParseFloat(r.FormValue("hours"), 64)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid hours")
+		return
+	}
+	request := OvertimeRequest{
+		EmployeeID:   employeeID,
+		Date:         day,
+		Hours:        hours,
+		Compensation: r.FormValue("compensation"),
+		Reason:       strings.TrimSpace(r.FormValue("reason")),
+		Status:       "pending",
+	}
+	if err := request.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	if employee == nil || !isActive(*employee) {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+
+	request.DayKind = app.Config.Calendar.dayKind(day)
+	request.Multiplier = app.Config.Overtime.rate(request.DayKind)
+	if err := app.OvertimeRepository.CreateOvertimeRequest(r.Context(), &request); err != nil {
+		app.serverError(w, r, "Failed to add overtime", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/overtime")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleReviewOvertime approves or rejects a pending request. HR decides,
+// but nobody decides on their own overtime. Approved toil is credited to
+// the employee's time off in lieu.
+func (app *App) handleReviewOvertime(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	decision := r.PathValue("decision")
+	if decision != "approve" && decision != "reject" {
+		http.NotFound(w, r)
+		return
+	}
+	if !app.requireHR(w, r) {
+		return
+	}
+
+	request, err := app.OvertimeRepository.GetOvertimeRequestByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return
+	}
+	if request == nil {
+		app.clientError(w, r, http.StatusNotFound, "Overtime request not found")
+		return
+	}
+	if request.Status != "pending" {
+		app.clientError(w, r, http.StatusConflict, "Overtime request was already reviewed")
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), request.EmployeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	viewer := app.viewer(r)
+	if employee != nil && strings.EqualFold(viewer.User, employee.Email) {
+		app.clientError(w, r, http.StatusForbidden, "You can't approve your own overtime")
+		return
+	}
+
+	request.Status = "rejected"
+	if decision == "approve" {
+		request.Status = "approved"
+	}
+	request.DecidedBy, request.DecidedAt = viewer.User, time.Now()
+	if err := app.OvertimeRepository.UpdateOvertimeRequest(r.Context(), request); err != nil {
+		app.serverError(w, r, "Failed to update overtime", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/overtime")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteOvertime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	request, err := app.OvertimeRepository.GetOvertimeRequestByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return
+	}
+	if request == nil {
+		app.clientError(w, r, http.StatusNotFound, "Overtime request not found")
+		return
+	}
+	if request.Status != "pending" {
+		app.clientError(w, r, http.StatusConflict, "Only pending requests can be deleted")
+		return
+	}
+	if err := app.OvertimeRepository.DeleteOvertimeRequest(r.Context(), request.ID); err != nil {
+		app.serverError(w, r, "Failed to delete overtime", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/overtime")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// OvertimeSummary is an employee's approved overtime in a payroll
+// period: the hours worked on each kind of day, and the hours owed once
+// the multipliers are applied, split by how they are made up for.
+type OvertimeSummary struct {
+	Employee     Employee
+	Department   string
+	WeekdayHours float64
+	WeekendHours float64
+	HolidayHours float64
+	PaidHours    float64
+	ToilHours    float64
+}
+
+// overtimeSummaries sums the approved overtime worked in period, a month
+// as YYYY-MM, by employee in name order.
+func overtimeSummaries(requests []OvertimeRequest, period string, employees map[int]Employee, departments map[int]Department) []OvertimeSummary {
+	byEmployee := make(map[int]*OvertimeSummary)
+	for _, o := range requests {
+		if o.Status != "approved" || o.Date.Format("2006-01") != period {
+			continue
+		}
+		s := byEmployee[o.EmployeeID]
+		if s == nil {
+			e := employees[o.EmployeeID]
+			s = &OvertimeSummary{Employee: e, Department: departments[e.DepartmentID].Name}
+			byEmployee[o.EmployeeID] = s
+		}
+		switch o.DayKind {
+		case "holiday":
+			s.HolidayHours += o.Hours
+		case "weekend":
+			s.WeekendHours += o.Hours
+		default:
+			s.WeekdayHours += o.Hours
+		}
+		if o.Compensation == "toil" {
+			s.ToilHours += o.Credited()
+		} else {
+			s.PaidHours += o.Credited()
+		}
+	}
+	summaries := make([]OvertimeSummary, 0, len(byEmployee))
+	for _, s := range byEmployee {
+		summaries = append(summaries, *s)
+	}
+	slices.SortFunc(summaries, func(a, b OvertimeSummary) int {
+		return strings.Compare(a.Employee.FirstName+" "+a.Employee.LastName, b.Employee.FirstName+" "+b.Employee.LastName)
+	})
+	return summaries
+}
+
+// overtimePeriod reads the period query value, a month as YYYY-MM that
+// defaults to the current one. Only HR and finance see the summaries;
+// the error response has been written when it returns false.
+func (app *App) overtimePeriod(w http.ResponseWriter, r *http.Request) ([]OvertimeSummary, string, bool) {
+	if v := app.viewer(r); !app.isFinance(v) && !app.isHR(v) {
+		app.clientError(w, r, http.StatusForbidden, "Only finance or HR can do this")
+		return nil, "", false
+	}
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = time.Now().Format("2006-01")
+	}
+	if _, err := time.Parse("2006-01", period); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid period")
+		return nil, "", false
+	}
+	requests, err := app.OvertimeRepository.GetOvertimeRequests(r.Context(), 0, "approved")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return nil, "", false
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return nil, "", false
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return nil, "", false
+	}
+	return overtimeSummaries(requests, period, employees, departments), period, true
+}
+
+// handleOvertimeSummary shows the approved overtime of a payroll period
+// per employee.
+func (app *App) handleOvertimeSummary(w http.ResponseWriter, r *http.Request) {
+	summaries, period, ok := app.overtimePeriod(w, r)
+	if !ok {
+		return
+	}
+	var total OvertimeSummary
+	for _, s := range summaries {
+		total.WeekdayHours += s.WeekdayHours
+		total.WeekendHours += s.WeekendHours
+		total.HolidayHours += s.HolidayHours
+		total.PaidHours += s.PaidHours
+		total.ToilHours += s.ToilHours
+	}
+	data := map[string]any{
+		"ActivePage": "overtime",
+		"Summaries":  summaries,
+		"Total":      total,
+		"Period":     period,
+		"Rates":      app.Config.Overtime,
+	}
+	app.render(w, r, "overtime_summary.html", "", data)
+}
+
+// handleExportOvertime exports the summary of a payroll period for
+// payroll to pay the overtime hours.
+func (app *App) handleExportOvertime(w http.ResponseWriter, r *http.Request) {
+	summaries, period, ok := app.overtimePeriod(w, r)
+	if !ok {
+		return
+	}
+	headers := []string{"Period", "Employee ID", "Employee", "Department", "Weekday Hours", "Weekend Hours", "Holiday Hours", "Paid Hours", "TOIL Hours"}
+	mapper := func(s OvertimeSummary) []string {
+		return []string{
+			period,
+			fmt.Sprintf("%d", s.Employee.ID),
+			s.Employee.FirstName + " " + s.Employee.LastName,
+			s.Department,
+			fmt.Sprintf("%.2f", s.WeekdayHours),
+			fmt.Sprintf("%.2f", s.WeekendHours),
+			fmt.Sprintf("%.2f", s.HolidayHours),
+			fmt.Sprintf("%.2f", s.PaidHours),
+			fmt.Sprintf("%.2f", s.ToilHours),
+		}
+	}
+
+	writeExport(w, r, "Overtime", summaries, headers, mapper)
+}
+
+// handleEmployeeOvertime lists an employee's overtime and their time off
+// in lieu.
+func (app *App) handleEmployeeOvertime(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	requests, err := app.OvertimeRepository.GetOvertimeRequests(r.Context(), employee.ID, "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return
+	}
+	balance, err := app.toilBalance(r.Context(), employee.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "overtime",
+		"Employee":   employee,
+		"Requests":   requests,
+		"Balance":    balance,
+	}
+	app.render(w, r, "employee_overtime.html", "", data)
+}
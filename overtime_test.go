@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestOvertimeDayKind(t *testing.T) {
+	cfg := defaultConfig().Overtime
+	cal := CalendarConfig{WeekendDays: []string{"fri", "sat"}, Holidays: []string{"2026-01-01", "2026-01-02"}}
+	tests := []struct {
+		day  string
+		kind string
+		rate float64
+	}{
+		{"2025-12-31", "weekday", 1.5},
+		{"2026-01-01", "holiday", 2.5},
+		{"2026-01-02", "holiday", 2.5}, // a Friday
+		{"2026-01-03", "weekend", 2},
+		{"2026-01-04", "weekday", 1.5}, // a Sunday
+	}
+	for _, tt := range tests {
+		day, _ := time.Parse("2006-01-02", tt.day)
+		if kind, rate := cal.dayKind(day), cfg.rate(cal.dayKind(day)); kind != tt.kind || rate != tt.rate {
+			t.Errorf("dayKind(%s) = %s, %v, want %s, %v", tt.day, kind, rate, tt.kind, tt.rate)
+		}
+	}
+
+	// Thursday to Sunday takes Thursday and Sunday; Friday is a holiday
+	// and Saturday a weekend day.
+	leave := Leave{StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)}
+	if n := cal.leaveDays(leave); n != 1 {
+		t.Errorf("leaveDays() = %d, want 1", n)
+	}
+	leave.StartDate = leave.StartDate.AddDate(0, 0, -1)
+	if n := cal.leaveDays(leave); n != 2 {
+		t.Errorf("leaveDays() = %d, want 2", n)
+	}
+}
+
+func TestToilBalance(t *testing.T) {
+	cfg := defaultConfig()
+	monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	requests := []OvertimeRequest{
+		{EmployeeID: 1, Hours: 4, Multiplier: 2, Compensation: "toil", Status: "approved"},
+		{EmployeeID: 1, Hours: 4, Multiplier: 1.5, Compensation: "toil", Status: "approved"},
+		{EmployeeID: 1, Hours: 8, Multiplier: 1.5, Compensation: "toil", Status: "pending"},
+		{EmployeeID: 1, Hours: 8, Multiplier: 1.5, Compensation: "pay", Status: "approved"},
+		{EmployeeID: 2, Hours: 8, Multiplier: 1.5, Compensation: "toil", Status: "approved"},
+	}
+	leaves := []Leave{
+		{ID: 1, EmployeeID: 1, LeaveType: "toil", Status: "approved", StartDate: monday, EndDate: monday},
+		{ID: 2, EmployeeID: 1, LeaveType: "toil", Status: "rejected", StartDate: monday, EndDate: monday},
+		{ID: 3, EmployeeID: 1, LeaveType: "vacation", Status: "approved", StartDate: monday, EndDate: monday},
+	}
+	b := toilBalance(requests, leaves, 1, 0, cfg.Calendar, cfg.Overtime)
+	if b.Credited != 14 || b.Taken != 8 || b.Available() != 6 || b.Days() != 0.75 {
+		t.Errorf("toilBalance() = %+v, want 14 credited and 8 taken", b)
+	}
+	if b := toilBalance(requests, leaves, 1, 1, cfg.Calendar, cfg.Overtime); b.Taken != 0 {
+		t.Errorf("toilBalance() skipping the leave = %+v, want none taken", b)
+	}
+}
+
+func TestOvertime(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Warehouse"}); err != nil {
+		t.Fatal(err)
+	}
+	departments, _ := repos.Departments.GetDepartments(ctx, "")
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active", DepartmentID: departments[0].ID}
+	if err := repos.Employees.CreateEmployee(ctx, &omar); err != nil {
+		t.Fatal(err)
+	}
+	id := strconv.Itoa(omar.ID)
+
+	for _, hours := range []string{"30", "0", "NaN", "Inf"} {
+		if w := send(h, "POST", "/overtime/add", url.Values{"employee_id": {id}, "date": {"2026-03-02"}, "hours": {hours}, "compensation": {"pay"}}, hrHeaders); w.Code != http.StatusBadRequest {
+			t.Errorf("add %s hours = %d, want 400", hours, w.Code)
+		}
+	}
+	// Monday and Saturday paid, Tuesday taken back as time off and April
+	// in the next period.
+	for _, form := range []url.Values{
+		{"employee_id": {id}, "date": {"2026-03-02"}, "hours": {"2"}, "compensation": {"pay"}},
+		{"employee_id": {id}, "date": {"2026-03-07"}, "hours": {"3"}, "compensation": {"pay"}},
+		{"employee_id": {id}, "date": {"2026-03-03"}, "hours": {"4"}, "compensation": {"toil"}},
+		{"employee_id": {id}, "date": {"2026-04-01"}, "hours": {"1"}, "compensation": {"pay"}},
+	} {
+		if w := send(h, "POST", "/overtime/add", form, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add = %d %s", w.Code, w.Body)
+		}
+	}
+	requests, _ := repos.Overtime.GetOvertimeRequests(ctx, omar.ID, "")
+	if len(requests) != 4 || requests[1].DayKind != "weekend" || requests[1].Multiplier != 2 || requests[3].DayKind != "weekday" || requests[3].Status != "pending" {
+		t.Fatalf("requests = %+v, want four pending, newest first", requests)
+	}
+
+	hr := map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"}
+	review := func(o OvertimeRequest, decision string, header map[string]string) int {
+		return send(h, "POST", "/overtime/"+strconv.Itoa(o.ID)+"/"+decision, nil, header).Code
+	}
+	if code := review(requests[0], "approve", nil); code != http.StatusForbidden {
+		t.Errorf("approve without HR = %d, want 403", code)
+	}
+	if code := review(requests[0], "approve", map[string]string{"X-Forwarded-User": "omar@example.com", "X-Forwarded-Groups": "hr"}); code != http.StatusForbidden {
+		t.Errorf("approve own overtime = %d, want 403", code)
+	}
+	for _, o := range requests[1:] {
+		if code := review(o, "approve", hr); code != http.StatusSeeOther {
+			t.Fatalf("approve = %d", code)
+		}
+	}
+	if code := review(requests[0], "reject", hr); code != http.StatusSeeOther {
+		t.Fatalf("reject = %d", code)
+	}
+	if code := review(requests[0], "approve", hr); code != http.StatusConflict {
+		t.Errorf("approve twice = %d, want 409", code)
+	}
+	if w := send(h, "DELETE", "/overtime/delete?id="+strconv.Itoa(requests[1].ID), nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete an approved request = %d, want 409", w.Code)
+	}
+
+	// The 4 toil hours at 1.5 are worth 6 hours: not enough for a day.
+	leave := url.Values{"employee_id": {id}, "leave_type": {"toil"}, "start_date": {"2026-03-10"}, "end_date": {"2026-03-10"}, "status": {"approved"}}
+	if w := send(h, "POST", "/leaves/add", leave, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("take a day of toil with 6 hours = %d, want 409", w.Code)
+	}
+	leave.Set("status", "pending")
+	if w := send(h, "POST", "/leaves/add", leave, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Errorf("request a day of toil = %d %s", w.Code, w.Body)
+	}
+
+	if w := send(h, "GET", "/overtime/summary?period=2026-03", nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("summary without HR or finance = %d, want 403", w.Code)
+	}
+	w := send(h, "GET", "/overtime/summary/export?period=2026-03", nil, map[string]string{"X-Forwarded-Groups": "finance"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("export = %d %s", w.Code, w.Body)
+	}
+	f, err := excelize.OpenReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := f.GetRows("Sheet1")
+	want := []string{"2026-03", id, "Omar Khalil", "Warehouse", "6.00", "3.00", "0.00", "9.00", "6.00"}
+	if len(rows) != 2 || len(rows[1]) != len(want) {
+		t.Fatalf("export rows = %v, want Omar's March", rows)
+	}
+	for i := range want {
+		if rows[1][i] != want[i] {
+			t.Errorf("export column %s = %q, want %q", rows[0][i], rows[1][i], want[i])
+		}
+	}
+
+	for _, page := range []string{"/overtime", "/overtime?status=pending", "/overtime/add", "/overtime/employees/" + id} {
+		if w := send(h, "GET", page, nil, hrHeaders); w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", page, w.Code)
+		}
+	}
+	if w := send(h, "GET", "/overtime/summary?period=2026-03", nil, hr); w.Code != http.StatusOK {
+		t.Errorf("summary = %d", w.Code)
+	}
+}
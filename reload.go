@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// devEvent is sent to browsers on /dev-reload. An empty Err asks them to
+// reload the page; otherwise they show Err in an overlay.
+type devEvent struct {
+	Err string
+}
+
+// Reloader watches template and asset directories in dev mode and pushes a
+// reload event to every browser connected to /dev-reload.
+type Reloader struct {
+	watcher  *fsnotify.Watcher
+	onChange func(name string) error
+
+	mu      sync.Mutex
+	clients map[chan devEvent]bool
+	done    chan struct{}
+	closed  bool
+}
+
+func NewReloader() (*Reloader, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+	return &Reloader{
+		watcher: watcher,
+		clients: make(map[chan devEvent]bool),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// CloseClients ends every open /dev-reload stream and refuses new ones. It
+// is registered as a shutdown hook so http.Server.Shutdown doesn't wait on
+// streams that never go idle.
+func (r *Reloader) CloseClients() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+}
+
+// Close stops the file watcher and disconnects all clients.
+func (r *Reloader) Close() error {
+	r.CloseClients()
+	return r.watcher.Close()
+}
+
+func (r *Reloader) Add(path string) error {
+	return r.watcher.Add(path)
+}
+
+// OnChange sets a function that is run for every changed file before
+// clients are told to reload. If it fails, clients are shown its error
+// instead. It must be set before Watch is started.
+func (r *Reloader) OnChange(fn func(name string) error) {
+	r.onChange = fn
+}
+
+// Watch forwards file changes to connected clients until the watcher is
+// closed.
+func (r *Reloader) Watch() {
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			slog.Debug("file modified, notifying dev reload clients", "file", event.Name)
+			var ev devEvent
+			if r.onChange != nil {
+				if err := r.onChange(event.Name); err != nil {
+					ev.Err = err.Error()
+				}
+			}
+			r.broadcast(ev)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("file watcher error", "err", err)
+		}
+	}
+}
+
+func (r *Reloader) broadcast(ev devEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for client := range r.clients {
+		// Clients have a one slot buffer and only the latest event matters,
+		// so a pending one is replaced.
+		select {
+		case client <- ev:
+		default:
+			select {
+			case <-client:
+			default:
+			}
+			client <- ev
+		}
+	}
+}
+
+func (r *Reloader) subscribe() (chan devEvent, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, false
+	}
+	ch := make(chan devEvent, 1)
+	r.clients[ch] = true
+	sseClients.Inc()
+	return ch, true
+}
+
+func (r *Reloader) unsubscribe(ch chan devEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, ch)
+	sseClients.Dec()
+}
+
+// writeDevEvent writes ev in the text/event-stream format. Errors go out as
+// "template-error" events, one data line per line of the message.
+func writeDevEvent(w http.ResponseWriter, ev devEvent) {
+	if ev.Err == "" {
+		fmt.Fprintf(w, "data: reload\n\n")
+		return
+	}
+	fmt.Fprintf(w, "event: template-error\n")
+	for _, line := range strings.Split(ev.Err, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func (app *App) handleDevReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+
+	messageChan, ok := app.reloader.subscribe()
+	if !ok {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer app.reloader.unsubscribe(messageChan)
+
+	// The stream outlives the server's write timeout by design.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "cannot clear write deadline for dev reload stream", "err", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// A page loaded while the templates are broken was rendered with the
+	// last good set, so tell it straight away.
+	if err := app.Templates.Err(); err != nil {
+		writeDevEvent(w, devEvent{Err: err.Error()})
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case ev := <-messageChan:
+			writeDevEvent(w, ev)
+			flusher.Flush()
+		case <-app.reloader.done:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -3,53 +3,408 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// RepositoryError is returned by repository methods when the database
+// fails. It carries the ID of the request that issued the query so the
+// failure can be matched with the access log.
+type RepositoryError struct {
+	Op        string
+	RequestID string
+	Err       error
+}
+
+func (e *RepositoryError) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s (request %s): %v", e.Op, e.RequestID, e.Err)
+}
+
+func (e *RepositoryError) Unwrap() error {
+	return e.Err
+}
+
+func repoError(ctx context.Context, op string, err error) error {
+	return &RepositoryError{Op: op, RequestID: RequestID(ctx), Err: err}
+}
+
 type SQLDepartmentRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 type SQLPositionRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 type SQLEmployeeRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 type SQLLeaveRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 type SQLApplicationRepository struct {
-	db *sql.DB
+	db *DB
+}
+
+type SQLAttendanceRepository struct {
+	db *DB
+}
+
+type SQLWorkScheduleRepository struct {
+	db *DB
+}
+
+type SQLAttendanceCorrectionRepository struct {
+	db *DB
+}
+
+type SQLBadgeImportRepository struct {
+	db *DB
+}
+
+type SQLReviewCycleRepository struct {
+	db *DB
+}
+
+type SQLReviewQuestionRepository struct {
+	db *DB
+}
+
+type SQLReviewAssessmentRepository struct {
+	db *DB
+}
+
+type SQLEmployeeRatingRepository struct {
+	db *DB
+}
+
+type SQLObjectiveRepository struct {
+	db *DB
+}
+
+type SQLKeyResultRepository struct {
+	db *DB
+}
+
+type SQLCheckInRepository struct {
+	db *DB
+}
+
+type SQLCourseRepository struct {
+	db *DB
+}
+
+type SQLEnrollmentRepository struct {
+	db *DB
+}
+
+type SQLCertificationRepository struct {
+	db *DB
+}
+
+type SQLEmployeeCertificationRepository struct {
+	db *DB
+}
+
+type SQLDocumentCategoryRepository struct {
+	db *DB
+}
+
+type SQLDocumentRepository struct {
+	db *DB
+}
+
+type SQLDocumentVersionRepository struct {
+	db *DB
+}
+
+type SQLDocumentDownloadRepository struct {
+	db *DB
+}
+
+type SQLOnboardingTemplateRepository struct {
+	db *DB
+}
+
+type SQLOnboardingTemplateTaskRepository struct {
+	db *DB
+}
+
+type SQLOnboardingTaskRepository struct {
+	db *DB
+}
+
+type SQLTerminationRepository struct {
+	db *DB
+}
+
+type SQLEquipmentRepository struct {
+	db *DB
+}
+
+type SQLEquipmentAssignmentRepository struct {
+	db *DB
+}
+
+type SQLEquipmentConditionRepository struct {
+	db *DB
+}
+
+type SQLExpenseClaimRepository struct {
+	db *DB
+}
+
+type SQLExpenseItemRepository struct {
+	db *DB
+}
+
+type SQLBenefitPlanRepository struct {
+	db *DB
+}
+
+type SQLDependentRepository struct {
+	db *DB
+}
+
+type SQLBenefitEnrollmentRepository struct {
+	db *DB
+}
+
+type SQLShiftTemplateRepository struct {
+	db *DB
+}
+
+type SQLShiftRepository struct {
+	db *DB
+}
+
+type SQLOvertimeRequestRepository struct {
+	db *DB
+}
+
+type SQLContactChangeRepository struct {
+	db *DB
 }
 
-func NewDepartmentRepository(db *sql.DB) *SQLDepartmentRepository {
+func NewDepartmentRepository(db *DB) *SQLDepartmentRepository {
 	return &SQLDepartmentRepository{db: db}
 }
 
-func NewPositionRepository(db *sql.DB) *SQLPositionRepository {
+func NewPositionRepository(db *DB) *SQLPositionRepository {
 	return &SQLPositionRepository{db: db}
 }
 
-func NewEmployeeRepository(db *sql.DB) *SQLEmployeeRepository {
+func NewEmployeeRepository(db *DB) *SQLEmployeeRepository {
 	return &SQLEmployeeRepository{db: db}
 }
 
-func NewLeaveRepository(db *sql.DB) *SQLLeaveRepository {
+func NewLeaveRepository(db *DB) *SQLLeaveRepository {
 	return &SQLLeaveRepository{db: db}
 }
 
-func NewApplicationRepository(db *sql.DB) *SQLApplicationRepository {
+func NewApplicationRepository(db *DB) *SQLApplicationRepository {
 	return &SQLApplicationRepository{db: db}
 }
 
+func NewAttendanceRepository(db *DB) *SQLAttendanceRepository {
+	return &SQLAttendanceRepository{db: db}
+}
+
+func NewWorkScheduleRepository(db *DB) *SQLWorkScheduleRepository {
+	return &SQLWorkScheduleRepository{db: db}
+}
+
+func NewAttendanceCorrectionRepository(db *DB) *SQLAttendanceCorrectionRepository {
+	return &SQLAttendanceCorrectionRepository{db: db}
+}
+
+func NewBadgeImportRepository(db *DB) *SQLBadgeImportRepository {
+	return &SQLBadgeImportRepository{db: db}
+}
+
+func NewReviewCycleRepository(db *DB) *SQLReviewCycleRepository {
+	return &SQLReviewCycleRepository{db: db}
+}
+
+func NewReviewQuestionRepository(db *DB) *SQLReviewQuestionRepository {
+	return &SQLReviewQuestionRepository{db: db}
+}
+
+func NewReviewAssessmentRepository(db *DB) *SQLReviewAssessmentRepository {
+	return &SQLReviewAssessmentRepository{db: db}
+}
+
+func NewEmployeeRatingRepository(db *DB) *SQLEmployeeRatingRepository {
+	return &SQLEmployeeRatingRepository{db: db}
+}
+
+func NewObjectiveRepository(db *DB) *SQLObjectiveRepository {
+	return &SQLObjectiveRepository{db: db}
+}
+
+func NewKeyResultRepository(db *DB) *SQLKeyResultRepository {
+	return &SQLKeyResultRepository{db: db}
+}
+
+func NewCheckInRepository(db *DB) *SQLCheckInRepository {
+	return &SQLCheckInRepository{db: db}
+}
+
+func NewCourseRepository(db *DB) *SQLCourseRepository {
+	return &SQLCourseRepository{db: db}
+}
+
+func NewEnrollmentRepository(db *DB) *SQLEnrollmentRepository {
+	return &SQLEnrollmentRepository{db: db}
+}
+
+func NewCertificationRepository(db *DB) *SQLCertificationRepository {
+	return &SQLCertificationRepository{db: db}
+}
+
+func NewEmployeeCertificationRepository(db *DB) *SQLEmployeeCertificationRepository {
+	return &SQLEmployeeCertificationRepository{db: db}
+}
+
+func NewDocumentCategoryRepository(db *DB) *SQLDocumentCategoryRepository {
+	return &SQLDocumentCategoryRepository{db: db}
+}
+
+func NewDocumentRepository(db *DB) *SQLDocumentRepository {
+	return &SQLDocumentRepository{db: db}
+}
+
+func NewDocumentVersionRepository(db *DB) *SQLDocumentVersionRepository {
+	return &SQLDocumentVersionRepository{db: db}
+}
+
+func NewDocumentDownloadRepository(db *DB) *SQLDocumentDownloadRepository {
+	return &SQLDocumentDownloadRepository{db: db}
+}
+
+func NewOnboardingTemplateRepository(db *DB) *SQLOnboardingTemplateRepository {
+	return &SQLOnboardingTemplateRepository{db: db}
+}
+
+func NewOnboardingTemplateTaskRepository(db *DB) *SQLOnboardingTemplateTaskRepository {
+	return &SQLOnboardingTemplateTaskRepository{db: db}
+}
+
+func NewOnboardingTaskRepository(db *DB) *SQLOnboardingTaskRepository {
+	return &SQLOnboardingTaskRepository{db: db}
+}
+
+func NewTerminationRepository(db *DB) *SQLTerminationRepository {
+	return &SQLTerminationRepository{db: db}
+}
+
+func NewEquipmentRepository(db *DB) *SQLEquipmentRepository {
+	return &SQLEquipmentRepository{db: db}
+}
+
+func NewEquipmentAssignmentRepository(db *DB) *SQLEquipmentAssignmentRepository {
+	return &SQLEquipmentAssignmentRepository{db: db}
+}
+
+func NewEquipmentConditionRepository(db *DB) *SQLEquipmentConditionRepository {
+	return &SQLEquipmentConditionRepository{db: db}
+}
+
+func NewExpenseClaimRepository(db *DB) *SQLExpenseClaimRepository {
+	return &SQLExpenseClaimRepository{db: db}
+}
+
+func NewExpenseItemRepository(db *DB) *SQLExpenseItemRepository {
+	return &SQLExpenseItemRepository{db: db}
+}
+
+func NewBenefitPlanRepository(db *DB) *SQLBenefitPlanRepository {
+	return &SQLBenefitPlanRepository{db: db}
+}
+
+func NewDependentRepository(db *DB) *SQLDependentRepository {
+	return &SQLDependentRepository{db: db}
+}
+
+func NewBenefitEnrollmentRepository(db *DB) *SQLBenefitEnrollmentRepository {
+	return &SQLBenefitEnrollmentRepository{db: db}
+}
+
+func NewShiftTemplateRepository(db *DB) *SQLShiftTemplateRepository {
+	return &SQLShiftTemplateRepository{db: db}
+}
+
+func NewShiftRepository(db *DB) *SQLShiftRepository {
+	return &SQLShiftRepository{db: db}
+}
+
+func NewOvertimeRequestRepository(db *DB) *SQLOvertimeRequestRepository {
+	return &SQLOvertimeRequestRepository{db: db}
+}
+
+func NewContactChangeRepository(db *DB) *SQLContactChangeRepository {
+	return &SQLContactChangeRepository{db: db}
+}
+
+// NewSQLRepositories returns the repositories backed by db.
+func NewSQLRepositories(db *DB) Repositories {
+	return Repositories{
+		Departments:         NewDepartmentRepository(db),
+		Positions:           NewPositionRepository(db),
+		Employees:           NewEmployeeRepository(db),
+		Applications:        NewApplicationRepository(db),
+		Leaves:              NewLeaveRepository(db),
+		Attendance:          NewAttendanceRepository(db),
+		Schedules:           NewWorkScheduleRepository(db),
+		Corrections:         NewAttendanceCorrectionRepository(db),
+		BadgeImports:        NewBadgeImportRepository(db),
+		ReviewCycles:        NewReviewCycleRepository(db),
+		Questions:           NewReviewQuestionRepository(db),
+		Assessments:         NewReviewAssessmentRepository(db),
+		Ratings:             NewEmployeeRatingRepository(db),
+		Objectives:          NewObjectiveRepository(db),
+		KeyResults:          NewKeyResultRepository(db),
+		CheckIns:            NewCheckInRepository(db),
+		Courses:             NewCourseRepository(db),
+		Enrollments:         NewEnrollmentRepository(db),
+		Certifications:      NewCertificationRepository(db),
+		Certificates:        NewEmployeeCertificationRepository(db),
+		DocCategories:       NewDocumentCategoryRepository(db),
+		Documents:           NewDocumentRepository(db),
+		DocVersions:         NewDocumentVersionRepository(db),
+		Downloads:           NewDocumentDownloadRepository(db),
+		OnboardingTemplates: NewOnboardingTemplateRepository(db),
+		TemplateTasks:       NewOnboardingTemplateTaskRepository(db),
+		OnboardingTasks:     NewOnboardingTaskRepository(db),
+		Terminations:        NewTerminationRepository(db),
+		Equipment:           NewEquipmentRepository(db),
+		Assignments:         NewEquipmentAssignmentRepository(db),
+		Conditions:          NewEquipmentConditionRepository(db),
+		ExpenseClaims:       NewExpenseClaimRepository(db),
+		ExpenseItems:        NewExpenseItemRepository(db),
+		BenefitPlans:        NewBenefitPlanRepository(db),
+		Dependents:          NewDependentRepository(db),
+		BenefitEnrollments:  NewBenefitEnrollmentRepository(db),
+		ShiftTemplates:      NewShiftTemplateRepository(db),
+		Shifts:              NewShiftRepository(db),
+		Overtime:            NewOvertimeRequestRepository(db),
+		ContactChanges:      NewContactChangeRepository(db),
+	}
+}
+
 func (r *SQLDepartmentRepository) GetDepartments(ctx context.Context, q string) ([]Department, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT * FROM departments WHERE name LIKE ?;", "%"+q+"%")
+	defer observeQuery("GetDepartments", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, description, created_at FROM departments WHERE LOWER(name) LIKE LOWER(?) ORDER BY id;", "%"+q+"%")
 	if err != nil {
-		return nil, fmt.Errorf("querying departments: %w", err)
+		return nil, repoError(ctx, "querying departments", err)
 	}
 	defer rows.Close()
 	var departments []Department
@@ -57,7 +412,7 @@ func (r *SQLDepartmentRepository) GetDepartments(ctx context.Context, q string)
 	for rows.Next() {
 		var department Department
 		if err := rows.Scan(&department.ID, &department.Name, &department.Description, &department.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning department: %w", err)
+			return nil, repoError(ctx, "scanning department", err)
 		}
 		departments = append(departments, department)
 	}
@@ -65,45 +420,50 @@ func (r *SQLDepartmentRepository) GetDepartments(ctx context.Context, q string)
 }
 
 func (r *SQLDepartmentRepository) GetDepartmentByID(ctx context.Context, id int) (*Department, error) {
+	defer observeQuery("GetDepartmentByID", time.Now())
 	var department Department
 	err := r.db.QueryRowContext(ctx, "SELECT id, name, description, created_at FROM departments WHERE id = ?;", id).Scan(&department.ID, &department.Name, &department.Description, &department.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("querying department by id: %w", err)
+		return nil, repoError(ctx, "querying department by id", err)
 	}
 	return &department, nil
 }
 
 func (r *SQLDepartmentRepository) CreateDepartment(ctx context.Context, department *Department) error {
+	defer observeQuery("CreateDepartment", time.Now())
 	_, err := r.db.ExecContext(ctx, "INSERT INTO departments (name, description) VALUES (?, ?);", department.Name, department.Description)
 	if err != nil {
-		return fmt.Errorf("creating department: %w", err)
+		return repoError(ctx, "creating department", err)
 	}
 	return nil
 }
 
 func (r *SQLDepartmentRepository) UpdateDepartment(ctx context.Context, department *Department) error {
+	defer observeQuery("UpdateDepartment", time.Now())
 	_, err := r.db.ExecContext(ctx, "UPDATE departments SET name = ?, description = ? WHERE id = ?;", department.Name, department.Description, department.ID)
 	if err != nil {
-		return fmt.Errorf("updating department: %w", err)
+		return repoError(ctx, "updating department", err)
 	}
 	return nil
 }
 
 func (r *SQLDepartmentRepository) DeleteDepartment(ctx context.Context, id int) error {
+	defer observeQuery("DeleteDepartment", time.Now())
 	_, err := r.db.ExecContext(ctx, "DELETE FROM departments WHERE id = ?;", id)
 	if err != nil {
-		return fmt.Errorf("deleting department: %w", err)
+		return repoError(ctx, "deleting department", err)
 	}
 	return nil
 }
 
 func (r *SQLPositionRepository) GetPositions(ctx context.Context, q string) ([]Position, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT * FROM positions WHERE name LIKE ?;", "%"+q+"%")
+	defer observeQuery("GetPositions", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, description, created_at FROM positions WHERE LOWER(name) LIKE LOWER(?) ORDER BY id;", "%"+q+"%")
 	if err != nil {
-		return nil, fmt.Errorf("querying positions: %w", err)
+		return nil, repoError(ctx, "querying positions", err)
 	}
 	defer rows.Close()
 	var positions []Position
@@ -111,7 +471,7 @@ func (r *SQLPositionRepository) GetPositions(ctx context.Context, q string) ([]P
 	for rows.Next() {
 		var position Position
 		if err := rows.Scan(&position.ID, &position.Name, &position.Description, &position.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning position: %w", err)
+			return nil, repoError(ctx, "scanning position", err)
 		}
 		positions = append(positions, position)
 	}
@@ -119,53 +479,58 @@ func (r *SQLPositionRepository) GetPositions(ctx context.Context, q string) ([]P
 }
 
 func (r *SQLPositionRepository) GetPositionByID(ctx context.Context, id int) (*Position, error) {
+	defer observeQuery("GetPositionByID", time.Now())
 	var position Position
 	err := r.db.QueryRowContext(ctx, "SELECT id, name, description, created_at FROM positions WHERE id = ?;", id).Scan(&position.ID, &position.Name, &position.Description, &position.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("querying position by id: %w", err)
+		return nil, repoError(ctx, "querying position by id", err)
 	}
 	return &position, nil
 }
 
 func (r *SQLPositionRepository) CreatePosition(ctx context.Context, position *Position) error {
+	defer observeQuery("CreatePosition", time.Now())
 	_, err := r.db.ExecContext(ctx, "INSERT INTO positions (name, description) VALUES (?, ?);", position.Name, position.Description)
 	if err != nil {
-		return fmt.Errorf("creating position: %w", err)
+		return repoError(ctx, "creating position", err)
 	}
 	return nil
 }
 
 func (r *SQLPositionRepository) UpdatePosition(ctx context.Context, position *Position) error {
+	defer observeQuery("UpdatePosition", time.Now())
 	_, err := r.db.ExecContext(ctx, "UPDATE positions SET name = ?, description = ? WHERE id = ?;", position.Name, position.Description, position.ID)
 	if err != nil {
-		return fmt.Errorf("updating position: %w", err)
+		return repoError(ctx, "updating position", err)
 	}
 	return nil
 }
 
 func (r *SQLPositionRepository) DeletePosition(ctx context.Context, id int) error {
+	defer observeQuery("DeletePosition", time.Now())
 	_, err := r.db.ExecContext(ctx, "DELETE FROM positions WHERE id = ?;", id)
 	if err != nil {
-		return fmt.Errorf("deleting position: %w", err)
+		return repoError(ctx, "deleting position", err)
 	}
 	return nil
 }
 
 func (r *SQLEmployeeRepository) GetEmployees(ctx context.Context, q string) ([]Employee, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, first_name, last_name, email, job_title, hire_date, salary, status, department_id, created_at FROM employees WHERE first_name LIKE ? OR last_name LIKE ? OR email LIKE ?;", "%"+q+"%", "%"+q+"%", "%"+q+"%")
+	defer observeQuery("GetEmployees", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, first_name, last_name, email, job_title, hire_date, salary, status, COALESCE(department_id, 0), COALESCE(manager_id, 0), COALESCE(card_number, ''), created_at FROM employees WHERE LOWER(first_name) LIKE LOWER(?) OR LOWER(last_name) LIKE LOWER(?) OR LOWER(email) LIKE LOWER(?) ORDER BY id;", "%"+q+"%", "%"+q+"%", "%"+q+"%")
 	if err != nil {
-		return nil, fmt.Errorf("querying employees: %w", err)
+		return nil, repoError(ctx, "querying employees", err)
 	}
 	defer rows.Close()
 	var employees []Employee
 
 	for rows.Next() {
 		var employee Employee
-		if err := rows.Scan(&employee.ID, &employee.FirstName, &employee.LastName, &employee.Email, &employee.JobTitle, &employee.HireDate, &employee.Salary, &employee.Status, &employee.DepartmentID, &employee.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning employee: %w", err)
+		if err := rows.Scan(&employee.ID, &employee.FirstName, &employee.LastName, &employee.Email, &employee.JobTitle, &employee.HireDate, &employee.Salary, &employee.Status, &employee.DepartmentID, &employee.ManagerID, &employee.CardNumber, &employee.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning employee", err)
 		}
 		employees = append(employees, employee)
 	}
@@ -173,45 +538,63 @@ func (r *SQLEmployeeRepository) GetEmployees(ctx context.Context, q string) ([]E
 }
 
 func (r *SQLEmployeeRepository) GetEmployeeByID(ctx context.Context, id int) (*Employee, error) {
+	defer observeQuery("GetEmployeeByID", time.Now())
+	var employee Employee
+	err := r.db.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, job_title, hire_date, salary, status, COALESCE(department_id, 0), COALESCE(manager_id, 0), COALESCE(card_number, ''), created_at FROM employees WHERE id = ?;", id).Scan(&employee.ID, &employee.FirstName, &employee.LastName, &employee.Email, &employee.JobTitle, &employee.HireDate, &employee.Salary, &employee.Status, &employee.DepartmentID, &employee.ManagerID, &employee.CardNumber, &employee.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying employee by id", err)
+	}
+	return &employee, nil
+}
+
+func (r *SQLEmployeeRepository) GetEmployeeByEmail(ctx context.Context, email string) (*Employee, error) {
+	defer observeQuery("GetEmployeeByEmail", time.Now())
 	var employee Employee
-	err := r.db.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, job_title, hire_date, salary, status, department_id, created_at FROM employees WHERE id = ?;", id).Scan(&employee.ID, &employee.FirstName, &employee.LastName, &employee.Email, &employee.JobTitle, &employee.HireDate, &employee.Salary, &employee.Status, &employee.DepartmentID, &employee.CreatedAt)
+	err := r.db.QueryRowContext(ctx, "SELECT id, first_name, last_name, email, job_title, hire_date, salary, status, COALESCE(department_id, 0), COALESCE(manager_id, 0), COALESCE(card_number, ''), created_at FROM employees WHERE LOWER(email) = LOWER(?) ORDER BY id LIMIT 1;", email).Scan(&employee.ID, &employee.FirstName, &employee.LastName, &employee.Email, &employee.JobTitle, &employee.HireDate, &employee.Salary, &employee.Status, &employee.DepartmentID, &employee.ManagerID, &employee.CardNumber, &employee.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("querying employee by id: %w", err)
+		return nil, repoError(ctx, "querying employee by email", err)
 	}
 	return &employee, nil
 }
 
 func (r *SQLEmployeeRepository) CreateEmployee(ctx context.Context, employee *Employee) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO employees (first_name, last_name, email, job_title, hire_date, salary, status, department_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?);", employee.FirstName, employee.LastName, employee.Email, employee.JobTitle, employee.HireDate, employee.Salary, employee.Status, employee.DepartmentID)
+	defer observeQuery("CreateEmployee", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO employees (first_name, last_name, email, job_title, hire_date, salary, status, department_id, manager_id, card_number) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;", employee.FirstName, employee.LastName, employee.Email, employee.JobTitle, employee.HireDate, employee.Salary, employee.Status, nullID(employee.DepartmentID), nullID(employee.ManagerID), nullString(employee.CardNumber)).Scan(&employee.ID)
 	if err != nil {
-		return fmt.Errorf("creating employee: %w", err)
+		return repoError(ctx, "creating employee", err)
 	}
 	return nil
 }
 
 func (r *SQLEmployeeRepository) UpdateEmployee(ctx context.Context, employee *Employee) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE employees SET first_name = ?, last_name = ?, email = ?, job_title = ?, hire_date = ?, salary = ?, status = ?, department_id = ? WHERE id = ?;", employee.FirstName, employee.LastName, employee.Email, employee.JobTitle, employee.HireDate, employee.Salary, employee.Status, employee.DepartmentID, employee.ID)
+	defer observeQuery("UpdateEmployee", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE employees SET first_name = ?, last_name = ?, email = ?, job_title = ?, hire_date = ?, salary = ?, status = ?, department_id = ?, manager_id = ?, card_number = ? WHERE id = ?;", employee.FirstName, employee.LastName, employee.Email, employee.JobTitle, employee.HireDate, employee.Salary, employee.Status, nullID(employee.DepartmentID), nullID(employee.ManagerID), nullString(employee.CardNumber), employee.ID)
 	if err != nil {
-		return fmt.Errorf("updating employee: %w", err)
+		return repoError(ctx, "updating employee", err)
 	}
 	return nil
 }
 
 func (r *SQLEmployeeRepository) DeleteEmployee(ctx context.Context, id int) error {
+	defer observeQuery("DeleteEmployee", time.Now())
 	_, err := r.db.ExecContext(ctx, "DELETE FROM employees WHERE id = ?;", id)
 	if err != nil {
-		return fmt.Errorf("deleting employee: %w", err)
+		return repoError(ctx, "deleting employee", err)
 	}
 	return nil
 }
 
 func (r *SQLApplicationRepository) GetApplications(ctx context.Context, q string) ([]Application, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, name, email, phone, applied_for, resume_url, status, created_at FROM applications WHERE name LIKE ? OR email LIKE ?;", "%"+q+"%", "%"+q+"%")
+	defer observeQuery("GetApplications", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, email, phone, applied_for, resume_url, status, created_at FROM applications WHERE LOWER(name) LIKE LOWER(?) OR LOWER(email) LIKE LOWER(?) ORDER BY id;", "%"+q+"%", "%"+q+"%")
 	if err != nil {
-		return nil, fmt.Errorf("querying applications: %w", err)
+		return nil, repoError(ctx, "querying applications", err)
 	}
 	defer rows.Close()
 	var applications []Application
@@ -219,7 +602,7 @@ func (r *SQLApplicationRepository) GetApplications(ctx context.Context, q string
 	for rows.Next() {
 		var app Application
 		if err := rows.Scan(&app.ID, &app.Name, &app.Email, &app.Phone, &app.AppliedFor, &app.ResumeURL, &app.Status, &app.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning application: %w", err)
+			return nil, repoError(ctx, "scanning application", err)
 		}
 		applications = append(applications, app)
 	}
@@ -227,45 +610,50 @@ func (r *SQLApplicationRepository) GetApplications(ctx context.Context, q string
 }
 
 func (r *SQLApplicationRepository) GetApplicationByID(ctx context.Context, id int) (*Application, error) {
+	defer observeQuery("GetApplicationByID", time.Now())
 	var app Application
 	err := r.db.QueryRowContext(ctx, "SELECT id, name, email, phone, applied_for, resume_url, status, created_at FROM applications WHERE id = ?;", id).Scan(&app.ID, &app.Name, &app.Email, &app.Phone, &app.AppliedFor, &app.ResumeURL, &app.Status, &app.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("querying application by id: %w", err)
+		return nil, repoError(ctx, "querying application by id", err)
 	}
 	return &app, nil
 }
 
 func (r *SQLApplicationRepository) CreateApplication(ctx context.Context, app *Application) error {
+	defer observeQuery("CreateApplication", time.Now())
 	_, err := r.db.ExecContext(ctx, "INSERT INTO applications (name, email, phone, applied_for, resume_url, status) VALUES (?, ?, ?, ?, ?, ?);", app.Name, app.Email, app.Phone, app.AppliedFor, app.ResumeURL, app.Status)
 	if err != nil {
-		return fmt.Errorf("creating application: %w", err)
+		return repoError(ctx, "creating application", err)
 	}
 	return nil
 }
 
 func (r *SQLApplicationRepository) UpdateApplication(ctx context.Context, app *Application) error {
+	defer observeQuery("UpdateApplication", time.Now())
 	_, err := r.db.ExecContext(ctx, "UPDATE applications SET name = ?, email = ?, phone = ?, applied_for = ?, resume_url = ?, status = ? WHERE id = ?;", app.Name, app.Email, app.Phone, app.AppliedFor, app.ResumeURL, app.Status, app.ID)
 	if err != nil {
-		return fmt.Errorf("updating application: %w", err)
+		return repoError(ctx, "updating application", err)
 	}
 	return nil
 }
 
 func (r *SQLApplicationRepository) DeleteApplication(ctx context.Context, id int) error {
+	defer observeQuery("DeleteApplication", time.Now())
 	_, err := r.db.ExecContext(ctx, "DELETE FROM applications WHERE id = ?;", id)
 	if err != nil {
-		return fmt.Errorf("deleting application: %w", err)
+		return repoError(ctx, "deleting application", err)
 	}
 	return nil
 }
 
 func (r *SQLLeaveRepository) GetLeaves(ctx context.Context, q string) ([]Leave, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, employee_id, leave_type, start_date, end_date, status, reason, created_at FROM leaves WHERE leave_type LIKE ? OR status LIKE ? OR reason LIKE ?;", "%"+q+"%", "%"+q+"%", "%"+q+"%")
+	defer observeQuery("GetLeaves", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, employee_id, leave_type, start_date, end_date, status, reason, created_at FROM leaves WHERE LOWER(leave_type) LIKE LOWER(?) OR LOWER(status) LIKE LOWER(?) OR LOWER(reason) LIKE LOWER(?) ORDER BY id;", "%"+q+"%", "%"+q+"%", "%"+q+"%")
 	if err != nil {
-		return nil, fmt.Errorf("querying leaves: %w", err)
+		return nil, repoError(ctx, "querying leaves", err)
 	}
 	defer rows.Close()
 	var leaves []Leave
@@ -273,7 +661,7 @@ func (r *SQLLeaveRepository) GetLeaves(ctx context.Context, q string) ([]Leave,
 	for rows.Next() {
 		var l Leave
 		if err := rows.Scan(&l.ID, &l.EmployeeID, &l.LeaveType, &l.StartDate, &l.EndDate, &l.Status, &l.Reason, &l.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning leave: %w", err)
+			return nil, repoError(ctx, "scanning leave", err)
 		}
 		leaves = append(leaves, l)
 	}
@@ -281,37 +669,2100 @@ func (r *SQLLeaveRepository) GetLeaves(ctx context.Context, q string) ([]Leave,
 }
 
 func (r *SQLLeaveRepository) GetLeaveByID(ctx context.Context, id int) (*Leave, error) {
+	defer observeQuery("GetLeaveByID", time.Now())
 	var l Leave
 	err := r.db.QueryRowContext(ctx, "SELECT id, employee_id, leave_type, start_date, end_date, status, reason, created_at FROM leaves WHERE id = ?;", id).Scan(&l.ID, &l.EmployeeID, &l.LeaveType, &l.StartDate, &l.EndDate, &l.Status, &l.Reason, &l.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("querying leave by id: %w", err)
+		return nil, repoError(ctx, "querying leave by id", err)
 	}
 	return &l, nil
 }
 
 func (r *SQLLeaveRepository) CreateLeave(ctx context.Context, l *Leave) error {
+	defer observeQuery("CreateLeave", time.Now())
 	_, err := r.db.ExecContext(ctx, "INSERT INTO leaves (employee_id, leave_type, start_date, end_date, status, reason) VALUES (?, ?, ?, ?, ?, ?);", l.EmployeeID, l.LeaveType, l.StartDate, l.EndDate, l.Status, l.Reason)
 	if err != nil {
-		return fmt.Errorf("creating leave: %w", err)
+		return repoError(ctx, "creating leave", err)
 	}
 	return nil
 }
 
 func (r *SQLLeaveRepository) UpdateLeave(ctx context.Context, l *Leave) error {
+	defer observeQuery("UpdateLeave", time.Now())
 	_, err := r.db.ExecContext(ctx, "UPDATE leaves SET employee_id = ?, leave_type = ?, start_date = ?, end_date = ?, status = ?, reason = ? WHERE id = ?;", l.EmployeeID, l.LeaveType, l.StartDate, l.EndDate, l.Status, l.Reason, l.ID)
 	if err != nil {
-		return fmt.Errorf("updating leave: %w", err)
+		return repoError(ctx, "updating leave", err)
 	}
 	return nil
 }
 
 func (r *SQLLeaveRepository) DeleteLeave(ctx context.Context, id int) error {
+	defer observeQuery("DeleteLeave", time.Now())
 	_, err := r.db.ExecContext(ctx, "DELETE FROM leaves WHERE id = ?;", id)
 	if err != nil {
-		return fmt.Errorf("deleting leave: %w", err)
+		return repoError(ctx, "deleting leave", err)
+	}
+	return nil
+}
+
+// nullID stores an unset (zero) foreign key as NULL.
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
+
+// nullString stores an empty optional value as NULL, so that UNIQUE
+// columns allow any number of employees without one.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime stores an unset (zero) time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
+
+func (r *SQLAttendanceRepository) GetAttendanceEvents(ctx context.Context, employeeID int, from, to time.Time) ([]AttendanceEvent, error) {
+	defer observeQuery("GetAttendanceEvents", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, employee_id, kind, occurred_at, source, created_at FROM attendance_events WHERE (? = 0 OR employee_id = ?) AND occurred_at >= ? AND occurred_at < ? ORDER BY occurred_at, id;", employeeID, employeeID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, repoError(ctx, "querying attendance events", err)
+	}
+	defer rows.Close()
+	var events []AttendanceEvent
+
+	for rows.Next() {
+		var e AttendanceEvent
+		if err := rows.Scan(&e.ID, &e.EmployeeID, &e.Kind, &e.OccurredAt, &e.Source, &e.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning attendance event", err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (r *SQLAttendanceRepository) CreateAttendanceEvent(ctx context.Context, e *AttendanceEvent) error {
+	defer observeQuery("CreateAttendanceEvent", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO attendance_events (employee_id, kind, occurred_at, source) VALUES (?, ?, ?, ?);", e.EmployeeID, e.Kind, e.OccurredAt.UTC(), e.Source)
+	if err != nil {
+		return repoError(ctx, "creating attendance event", err)
+	}
+	return nil
+}
+
+func (r *SQLAttendanceRepository) DeleteAttendanceEvent(ctx context.Context, id int) error {
+	defer observeQuery("DeleteAttendanceEvent", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM attendance_events WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting attendance event", err)
+	}
+	return nil
+}
+
+func (r *SQLAttendanceRepository) ReplaceAttendanceEvents(ctx context.Context, employeeID int, from, to time.Time, events []AttendanceEvent) error {
+	defer observeQuery("ReplaceAttendanceEvents", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repoError(ctx, "replacing attendance events", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, r.db.Rebind("DELETE FROM attendance_events WHERE employee_id = ? AND occurred_at >= ? AND occurred_at < ?;"), employeeID, from.UTC(), to.UTC())
+	if err != nil {
+		return repoError(ctx, "replacing attendance events", err)
+	}
+	for _, e := range events {
+		_, err = tx.ExecContext(ctx, r.db.Rebind("INSERT INTO attendance_events (employee_id, kind, occurred_at, source) VALUES (?, ?, ?, ?);"), employeeID, e.Kind, e.OccurredAt.UTC(), e.Source)
+		if err != nil {
+			return repoError(ctx, "replacing attendance events", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return repoError(ctx, "replacing attendance events", err)
+	}
+	return nil
+}
+
+func (r *SQLWorkScheduleRepository) GetWorkSchedules(ctx context.Context) ([]WorkSchedule, error) {
+	defer observeQuery("GetWorkSchedules", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, COALESCE(department_id, 0), start_time, end_time, workdays, grace_minutes, created_at FROM work_schedules ORDER BY id;")
+	if err != nil {
+		return nil, repoError(ctx, "querying work schedules", err)
+	}
+	defer rows.Close()
+	var schedules []WorkSchedule
+
+	for rows.Next() {
+		var ws WorkSchedule
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.DepartmentID, &ws.StartTime, &ws.EndTime, &ws.Workdays, &ws.GraceMinutes, &ws.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning work schedule", err)
+		}
+		schedules = append(schedules, ws)
+	}
+	return schedules, nil
+}
+
+func (r *SQLWorkScheduleRepository) GetWorkScheduleByID(ctx context.Context, id int) (*WorkSchedule, error) {
+	defer observeQuery("GetWorkScheduleByID", time.Now())
+	var ws WorkSchedule
+	err := r.db.QueryRowContext(ctx, "SELECT id, name, COALESCE(department_id, 0), start_time, end_time, workdays, grace_minutes, created_at FROM work_schedules WHERE id = ?;", id).Scan(&ws.ID, &ws.Name, &ws.DepartmentID, &ws.StartTime, &ws.EndTime, &ws.Workdays, &ws.GraceMinutes, &ws.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying work schedule by id", err)
+	}
+	return &ws, nil
+}
+
+func (r *SQLWorkScheduleRepository) CreateWorkSchedule(ctx context.Context, ws *WorkSchedule) error {
+	defer observeQuery("CreateWorkSchedule", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO work_schedules (name, department_id, start_time, end_time, workdays, grace_minutes) VALUES (?, ?, ?, ?, ?, ?);", ws.Name, nullID(ws.DepartmentID), ws.StartTime, ws.EndTime, ws.Workdays, ws.GraceMinutes)
+	if err != nil {
+		return repoError(ctx, "creating work schedule", err)
+	}
+	return nil
+}
+
+func (r *SQLWorkScheduleRepository) UpdateWorkSchedule(ctx context.Context, ws *WorkSchedule) error {
+	defer observeQuery("UpdateWorkSchedule", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE work_schedules SET name = ?, department_id = ?, start_time = ?, end_time = ?, workdays = ?, grace_minutes = ? WHERE id = ?;", ws.Name, nullID(ws.DepartmentID), ws.StartTime, ws.EndTime, ws.Workdays, ws.GraceMinutes, ws.ID)
+	if err != nil {
+		return repoError(ctx, "updating work schedule", err)
+	}
+	return nil
+}
+
+func (r *SQLWorkScheduleRepository) DeleteWorkSchedule(ctx context.Context, id int) error {
+	defer observeQuery("DeleteWorkSchedule", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM work_schedules WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting work schedule", err)
+	}
+	return nil
+}
+
+func (r *SQLAttendanceCorrectionRepository) GetAttendanceCorrections(ctx context.Context, status string) ([]AttendanceCorrection, error) {
+	defer observeQuery("GetAttendanceCorrections", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, employee_id, date, clock_in, clock_out, reason, status, created_at FROM attendance_corrections WHERE ? = '' OR status = ? ORDER BY id DESC;", status, status)
+	if err != nil {
+		return nil, repoError(ctx, "querying attendance corrections", err)
+	}
+	defer rows.Close()
+	var corrections []AttendanceCorrection
+
+	for rows.Next() {
+		var c AttendanceCorrection
+		if err := rows.Scan(&c.ID, &c.EmployeeID, &c.Date, &c.ClockIn, &c.ClockOut, &c.Reason, &c.Status, &c.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning attendance correction", err)
+		}
+		corrections = append(corrections, c)
+	}
+	return corrections, nil
+}
+
+func (r *SQLAttendanceCorrectionRepository) GetAttendanceCorrectionByID(ctx context.Context, id int) (*AttendanceCorrection, error) {
+	defer observeQuery("GetAttendanceCorrectionByID", time.Now())
+	var c AttendanceCorrection
+	err := r.db.QueryRowContext(ctx, "SELECT id, employee_id, date, clock_in, clock_out, reason, status, created_at FROM attendance_corrections WHERE id = ?;", id).Scan(&c.ID, &c.EmployeeID, &c.Date, &c.ClockIn, &c.ClockOut, &c.Reason, &c.Status, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying attendance correction by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLAttendanceCorrectionRepository) CreateAttendanceCorrection(ctx context.Context, c *AttendanceCorrection) error {
+	defer observeQuery("CreateAttendanceCorrection", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO attendance_corrections (employee_id, date, clock_in, clock_out, reason, status) VALUES (?, ?, ?, ?, ?, ?);", c.EmployeeID, c.Date, c.ClockIn.UTC(), c.ClockOut.UTC(), c.Reason, c.Status)
+	if err != nil {
+		return repoError(ctx, "creating attendance correction", err)
+	}
+	return nil
+}
+
+func (r *SQLAttendanceCorrectionRepository) UpdateAttendanceCorrection(ctx context.Context, c *AttendanceCorrection) error {
+	defer observeQuery("UpdateAttendanceCorrection", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE attendance_corrections SET employee_id = ?, date = ?, clock_in = ?, clock_out = ?, reason = ?, status = ? WHERE id = ?;", c.EmployeeID, c.Date, c.ClockIn.UTC(), c.ClockOut.UTC(), c.Reason, c.Status, c.ID)
+	if err != nil {
+		return repoError(ctx, "updating attendance correction", err)
+	}
+	return nil
+}
+
+func (r *SQLBadgeImportRepository) GetBadgeImports(ctx context.Context) ([]BadgeImport, error) {
+	defer observeQuery("GetBadgeImports", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, file_name, source, report, created_at FROM badge_imports ORDER BY id DESC;")
+	if err != nil {
+		return nil, repoError(ctx, "querying badge imports", err)
+	}
+	defer rows.Close()
+	var imports []BadgeImport
+
+	for rows.Next() {
+		var bi BadgeImport
+		var report string
+		if err := rows.Scan(&bi.ID, &bi.FileName, &bi.Source, &report, &bi.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning badge import", err)
+		}
+		if err := json.Unmarshal([]byte(report), &bi.Report); err != nil {
+			return nil, repoError(ctx, "decoding badge import report", err)
+		}
+		imports = append(imports, bi)
+	}
+	return imports, nil
+}
+
+func (r *SQLBadgeImportRepository) GetBadgeImportByID(ctx context.Context, id int) (*BadgeImport, error) {
+	defer observeQuery("GetBadgeImportByID", time.Now())
+	var bi BadgeImport
+	var report string
+	err := r.db.QueryRowContext(ctx, "SELECT id, file_name, source, report, created_at FROM badge_imports WHERE id = ?;", id).Scan(&bi.ID, &bi.FileName, &bi.Source, &report, &bi.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying badge import by id", err)
+	}
+	if err := json.Unmarshal([]byte(report), &bi.Report); err != nil {
+		return nil, repoError(ctx, "decoding badge import report", err)
+	}
+	return &bi, nil
+}
+
+// CreateBadgeImport stores the import and sets its ID, so the caller can
+// link to the report.
+func (r *SQLBadgeImportRepository) CreateBadgeImport(ctx context.Context, bi *BadgeImport) error {
+	defer observeQuery("CreateBadgeImport", time.Now())
+	report, err := json.Marshal(bi.Report)
+	if err != nil {
+		return repoError(ctx, "encoding badge import report", err)
+	}
+	err = r.db.QueryRowContext(ctx, "INSERT INTO badge_imports (file_name, source, report) VALUES (?, ?, ?) RETURNING id;", bi.FileName, bi.Source, string(report)).Scan(&bi.ID)
+	if err != nil {
+		return repoError(ctx, "creating badge import", err)
+	}
+	return nil
+}
+
+func (r *SQLReviewCycleRepository) GetReviewCycles(ctx context.Context) ([]ReviewCycle, error) {
+	defer observeQuery("GetReviewCycles", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, COALESCE(department_id, 0), self_due, manager_due, peer_due, status, created_at FROM review_cycles ORDER BY id DESC;")
+	if err != nil {
+		return nil, repoError(ctx, "querying review cycles", err)
+	}
+	defer rows.Close()
+	var cycles []ReviewCycle
+
+	for rows.Next() {
+		var c ReviewCycle
+		if err := rows.Scan(&c.ID, &c.Name, &c.DepartmentID, &c.SelfDue, &c.ManagerDue, &c.PeerDue, &c.Status, &c.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning review cycle", err)
+		}
+		cycles = append(cycles, c)
+	}
+	return cycles, nil
+}
+
+func (r *SQLReviewCycleRepository) GetReviewCycleByID(ctx context.Context, id int) (*ReviewCycle, error) {
+	defer observeQuery("GetReviewCycleByID", time.Now())
+	var c ReviewCycle
+	err := r.db.QueryRowContext(ctx, "SELECT id, name, COALESCE(department_id, 0), self_due, manager_due, peer_due, status, created_at FROM review_cycles WHERE id = ?;", id).Scan(&c.ID, &c.Name, &c.DepartmentID, &c.SelfDue, &c.ManagerDue, &c.PeerDue, &c.Status, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying review cycle by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLReviewCycleRepository) CreateReviewCycle(ctx context.Context, c *ReviewCycle) error {
+	defer observeQuery("CreateReviewCycle", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO review_cycles (name, department_id, self_due, manager_due, peer_due, status) VALUES (?, ?, ?, ?, ?, ?);", c.Name, nullID(c.DepartmentID), c.SelfDue, c.ManagerDue, c.PeerDue, c.Status)
+	if err != nil {
+		return repoError(ctx, "creating review cycle", err)
+	}
+	return nil
+}
+
+func (r *SQLReviewCycleRepository) UpdateReviewCycle(ctx context.Context, c *ReviewCycle) error {
+	defer observeQuery("UpdateReviewCycle", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE review_cycles SET name = ?, department_id = ?, self_due = ?, manager_due = ?, peer_due = ?, status = ? WHERE id = ?;", c.Name, nullID(c.DepartmentID), c.SelfDue, c.ManagerDue, c.PeerDue, c.Status, c.ID)
+	if err != nil {
+		return repoError(ctx, "updating review cycle", err)
+	}
+	return nil
+}
+
+func (r *SQLReviewCycleRepository) DeleteReviewCycle(ctx context.Context, id int) error {
+	defer observeQuery("DeleteReviewCycle", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM review_cycles WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting review cycle", err)
+	}
+	return nil
+}
+
+func (r *SQLReviewQuestionRepository) GetReviewQuestions(ctx context.Context, cycleID int) ([]ReviewQuestion, error) {
+	defer observeQuery("GetReviewQuestions", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, cycle_id, text, scale, created_at FROM review_questions WHERE cycle_id = ? ORDER BY id;", cycleID)
+	if err != nil {
+		return nil, repoError(ctx, "querying review questions", err)
+	}
+	defer rows.Close()
+	var questions []ReviewQuestion
+
+	for rows.Next() {
+		var q ReviewQuestion
+		if err := rows.Scan(&q.ID, &q.CycleID, &q.Text, &q.Scale, &q.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning review question", err)
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
+
+func (r *SQLReviewQuestionRepository) CreateReviewQuestion(ctx context.Context, q *ReviewQuestion) error {
+	defer observeQuery("CreateReviewQuestion", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO review_questions (cycle_id, text, scale) VALUES (?, ?, ?);", q.CycleID, q.Text, q.Scale)
+	if err != nil {
+		return repoError(ctx, "creating review question", err)
+	}
+	return nil
+}
+
+func (r *SQLReviewQuestionRepository) DeleteReviewQuestion(ctx context.Context, id int) error {
+	defer observeQuery("DeleteReviewQuestion", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM review_questions WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting review question", err)
+	}
+	return nil
+}
+
+// scanReviewAssessment scans a row selected with reviewAssessmentColumns,
+// decoding the ratings and the optional submission time.
+func scanReviewAssessment(ctx context.Context, row interface{ Scan(...any) error }) (ReviewAssessment, error) {
+	var a ReviewAssessment
+	var ratings string
+	var submitted sql.NullTime
+	if err := row.Scan(&a.ID, &a.CycleID, &a.EmployeeID, &a.ReviewerID, &a.Kind, &a.DueDate, &a.Status, &ratings, &a.Comment, &submitted, &a.CreatedAt); err != nil {
+		return a, err
+	}
+	if err := json.Unmarshal([]byte(ratings), &a.Ratings); err != nil {
+		return a, repoError(ctx, "decoding review assessment ratings", err)
+	}
+	a.SubmittedAt = submitted.Time
+	return a, nil
+}
+
+const reviewAssessmentColumns = "id, cycle_id, employee_id, reviewer_id, kind, due_date, status, ratings, comment, submitted_at, created_at"
+
+func (r *SQLReviewAssessmentRepository) GetReviewAssessments(ctx context.Context, cycleID, employeeID int) ([]ReviewAssessment, error) {
+	defer observeQuery("GetReviewAssessments", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reviewAssessmentColumns+" FROM review_assessments WHERE cycle_id = ? AND (? = 0 OR employee_id = ?) ORDER BY id;", cycleID, employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying review assessments", err)
+	}
+	defer rows.Close()
+	var assessments []ReviewAssessment
+
+	for rows.Next() {
+		a, err := scanReviewAssessment(ctx, rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning review assessment", err)
+		}
+		assessments = append(assessments, a)
+	}
+	return assessments, nil
+}
+
+func (r *SQLReviewAssessmentRepository) GetReviewAssessmentByID(ctx context.Context, id int) (*ReviewAssessment, error) {
+	defer observeQuery("GetReviewAssessmentByID", time.Now())
+	a, err := scanReviewAssessment(ctx, r.db.QueryRowContext(ctx, "SELECT "+reviewAssessmentColumns+" FROM review_assessments WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying review assessment by id", err)
+	}
+	return &a, nil
+}
+
+func (r *SQLReviewAssessmentRepository) CreateReviewAssessment(ctx context.Context, a *ReviewAssessment) error {
+	defer observeQuery("CreateReviewAssessment", time.Now())
+	ratings, err := json.Marshal(a.Ratings)
+	if err != nil {
+		return repoError(ctx, "encoding review assessment ratings", err)
+	}
+	_, err = r.db.ExecContext(ctx, "INSERT INTO review_assessments (cycle_id, employee_id, reviewer_id, kind, due_date, status, ratings, comment, submitted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);", a.CycleID, a.EmployeeID, a.ReviewerID, a.Kind, a.DueDate, a.Status, string(ratings), a.Comment, nullTime(a.SubmittedAt))
+	if err != nil {
+		return repoError(ctx, "creating review assessment", err)
+	}
+	return nil
+}
+
+func (r *SQLReviewAssessmentRepository) UpdateReviewAssessment(ctx context.Context, a *ReviewAssessment) error {
+	defer observeQuery("UpdateReviewAssessment", time.Now())
+	ratings, err := json.Marshal(a.Ratings)
+	if err != nil {
+		return repoError(ctx, "encoding review assessment ratings", err)
+	}
+	_, err = r.db.ExecContext(ctx, "UPDATE review_assessments SET cycle_id = ?, employee_id = ?, reviewer_id = ?, kind = ?, due_date = ?, status = ?, ratings = ?, comment = ?, submitted_at = ? WHERE id = ?;", a.CycleID, a.EmployeeID, a.ReviewerID, a.Kind, a.DueDate, a.Status, string(ratings), a.Comment, nullTime(a.SubmittedAt), a.ID)
+	if err != nil {
+		return repoError(ctx, "updating review assessment", err)
+	}
+	return nil
+}
+
+func (r *SQLEmployeeRatingRepository) GetEmployeeRatings(ctx context.Context, cycleID, employeeID int) ([]EmployeeRating, error) {
+	defer observeQuery("GetEmployeeRatings", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, employee_id, cycle_id, rating, comment, created_at FROM employee_ratings WHERE (? = 0 OR cycle_id = ?) AND (? = 0 OR employee_id = ?) ORDER BY cycle_id, id;", cycleID, cycleID, employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying employee ratings", err)
+	}
+	defer rows.Close()
+	var ratings []EmployeeRating
+
+	for rows.Next() {
+		var er EmployeeRating
+		if err := rows.Scan(&er.ID, &er.EmployeeID, &er.CycleID, &er.Rating, &er.Comment, &er.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning employee rating", err)
+		}
+		ratings = append(ratings, er)
+	}
+	return ratings, nil
+}
+
+func (r *SQLEmployeeRatingRepository) SaveEmployeeRating(ctx context.Context, er *EmployeeRating) error {
+	defer observeQuery("SaveEmployeeRating", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO employee_ratings (employee_id, cycle_id, rating, comment) VALUES (?, ?, ?, ?) ON CONFLICT (employee_id, cycle_id) DO UPDATE SET rating = excluded.rating, comment = excluded.comment;", er.EmployeeID, er.CycleID, er.Rating, er.Comment)
+	if err != nil {
+		return repoError(ctx, "saving employee rating", err)
+	}
+	return nil
+}
+
+func (r *SQLObjectiveRepository) GetObjectives(ctx context.Context, quarter string) ([]Objective, error) {
+	defer observeQuery("GetObjectives", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, title, description, quarter, COALESCE(employee_id, 0), COALESCE(department_id, 0), COALESCE(parent_id, 0), created_at FROM objectives WHERE ? = '' OR quarter = ? ORDER BY id;", quarter, quarter)
+	if err != nil {
+		return nil, repoError(ctx, "querying objectives", err)
+	}
+	defer rows.Close()
+	var objectives []Objective
+
+	for rows.Next() {
+		var o Objective
+		if err := rows.Scan(&o.ID, &o.Title, &o.Description, &o.Quarter, &o.EmployeeID, &o.DepartmentID, &o.ParentID, &o.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning objective", err)
+		}
+		objectives = append(objectives, o)
+	}
+	return objectives, nil
+}
+
+func (r *SQLObjectiveRepository) GetObjectiveByID(ctx context.Context, id int) (*Objective, error) {
+	defer observeQuery("GetObjectiveByID", time.Now())
+	var o Objective
+	err := r.db.QueryRowContext(ctx, "SELECT id, title, description, quarter, COALESCE(employee_id, 0), COALESCE(department_id, 0), COALESCE(parent_id, 0), created_at FROM objectives WHERE id = ?;", id).Scan(&o.ID, &o.Title, &o.Description, &o.Quarter, &o.EmployeeID, &o.DepartmentID, &o.ParentID, &o.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying objective by id", err)
+	}
+	return &o, nil
+}
+
+// CreateObjective stores the objective and sets its ID, so the caller can
+// link to it.
+func (r *SQLObjectiveRepository) CreateObjective(ctx context.Context, o *Objective) error {
+	defer observeQuery("CreateObjective", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO objectives (title, description, quarter, employee_id, department_id, parent_id) VALUES (?, ?, ?, ?, ?, ?) RETURNING id;", o.Title, o.Description, o.Quarter, nullID(o.EmployeeID), nullID(o.DepartmentID), nullID(o.ParentID)).Scan(&o.ID)
+	if err != nil {
+		return repoError(ctx, "creating objective", err)
+	}
+	return nil
+}
+
+func (r *SQLObjectiveRepository) UpdateObjective(ctx context.Context, o *Objective) error {
+	defer observeQuery("UpdateObjective", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE objectives SET title = ?, description = ?, quarter = ?, employee_id = ?, department_id = ?, parent_id = ? WHERE id = ?;", o.Title, o.Description, o.Quarter, nullID(o.EmployeeID), nullID(o.DepartmentID), nullID(o.ParentID), o.ID)
+	if err != nil {
+		return repoError(ctx, "updating objective", err)
+	}
+	return nil
+}
+
+func (r *SQLObjectiveRepository) DeleteObjective(ctx context.Context, id int) error {
+	defer observeQuery("DeleteObjective", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM objectives WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting objective", err)
+	}
+	return nil
+}
+
+func (r *SQLKeyResultRepository) GetKeyResults(ctx context.Context, objectiveID int) ([]KeyResult, error) {
+	defer observeQuery("GetKeyResults", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, objective_id, title, unit, start_value, target_value, current_value, created_at FROM key_results WHERE ? = 0 OR objective_id = ? ORDER BY id;", objectiveID, objectiveID)
+	if err != nil {
+		return nil, repoError(ctx, "querying key results", err)
+	}
+	defer rows.Close()
+	var results []KeyResult
+
+	for rows.Next() {
+		var kr KeyResult
+		if err := rows.Scan(&kr.ID, &kr.ObjectiveID, &kr.Title, &kr.Unit, &kr.StartValue, &kr.TargetValue, &kr.CurrentValue, &kr.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning key result", err)
+		}
+		results = append(results, kr)
+	}
+	return results, nil
+}
+
+func (r *SQLKeyResultRepository) GetKeyResultByID(ctx context.Context, id int) (*KeyResult, error) {
+	defer observeQuery("GetKeyResultByID", time.Now())
+	var kr KeyResult
+	err := r.db.QueryRowContext(ctx, "SELECT id, objective_id, title, unit, start_value, target_value, current_value, created_at FROM key_results WHERE id = ?;", id).Scan(&kr.ID, &kr.ObjectiveID, &kr.Title, &kr.Unit, &kr.StartValue, &kr.TargetValue, &kr.CurrentValue, &kr.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying key result by id", err)
+	}
+	return &kr, nil
+}
+
+func (r *SQLKeyResultRepository) CreateKeyResult(ctx context.Context, kr *KeyResult) error {
+	defer observeQuery("CreateKeyResult", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO key_results (objective_id, title, unit, start_value, target_value, current_value) VALUES (?, ?, ?, ?, ?, ?);", kr.ObjectiveID, kr.Title, kr.Unit, kr.StartValue, kr.TargetValue, kr.CurrentValue)
+	if err != nil {
+		return repoError(ctx, "creating key result", err)
+	}
+	return nil
+}
+
+func (r *SQLKeyResultRepository) UpdateKeyResult(ctx context.Context, kr *KeyResult) error {
+	defer observeQuery("UpdateKeyResult", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE key_results SET objective_id = ?, title = ?, unit = ?, start_value = ?, target_value = ?, current_value = ? WHERE id = ?;", kr.ObjectiveID, kr.Title, kr.Unit, kr.StartValue, kr.TargetValue, kr.CurrentValue, kr.ID)
+	if err != nil {
+		return repoError(ctx, "updating key result", err)
+	}
+	return nil
+}
+
+func (r *SQLKeyResultRepository) DeleteKeyResult(ctx context.Context, id int) error {
+	defer observeQuery("DeleteKeyResult", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM key_results WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting key result", err)
+	}
+	return nil
+}
+
+func (r *SQLCheckInRepository) GetCheckIns(ctx context.Context, keyResultID int) ([]CheckIn, error) {
+	defer observeQuery("GetCheckIns", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, key_result_id, value, comment, created_at FROM check_ins WHERE key_result_id = ? ORDER BY id DESC;", keyResultID)
+	if err != nil {
+		return nil, repoError(ctx, "querying check-ins", err)
+	}
+	defer rows.Close()
+	var checkIns []CheckIn
+
+	for rows.Next() {
+		var c CheckIn
+		if err := rows.Scan(&c.ID, &c.KeyResultID, &c.Value, &c.Comment, &c.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning check-in", err)
+		}
+		checkIns = append(checkIns, c)
+	}
+	return checkIns, nil
+}
+
+func (r *SQLCheckInRepository) CreateCheckIn(ctx context.Context, c *CheckIn) error {
+	defer observeQuery("CreateCheckIn", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO check_ins (key_result_id, value, comment) VALUES (?, ?, ?);", c.KeyResultID, c.Value, c.Comment)
+	if err != nil {
+		return repoError(ctx, "creating check-in", err)
+	}
+	return nil
+}
+
+func (r *SQLCheckInRepository) DeleteCheckIns(ctx context.Context, keyResultID int) error {
+	defer observeQuery("DeleteCheckIns", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM check_ins WHERE key_result_id = ?;", keyResultID)
+	if err != nil {
+		return repoError(ctx, "deleting check-ins", err)
+	}
+	return nil
+}
+
+func (r *SQLCourseRepository) GetCourses(ctx context.Context) ([]Course, error) {
+	defer observeQuery("GetCourses", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, title, provider, description, hours, COALESCE(certification_id, 0), created_at FROM courses ORDER BY id;")
+	if err != nil {
+		return nil, repoError(ctx, "querying courses", err)
+	}
+	defer rows.Close()
+	var courses []Course
+
+	for rows.Next() {
+		var c Course
+		if err := rows.Scan(&c.ID, &c.Title, &c.Provider, &c.Description, &c.Hours, &c.CertificationID, &c.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning course", err)
+		}
+		courses = append(courses, c)
+	}
+	return courses, nil
+}
+
+func (r *SQLCourseRepository) GetCourseByID(ctx context.Context, id int) (*Course, error) {
+	defer observeQuery("GetCourseByID", time.Now())
+	var c Course
+	err := r.db.QueryRowContext(ctx, "SELECT id, title, provider, description, hours, COALESCE(certification_id, 0), created_at FROM courses WHERE id = ?;", id).Scan(&c.ID, &c.Title, &c.Provider, &c.Description, &c.Hours, &c.CertificationID, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying course by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLCourseRepository) CreateCourse(ctx context.Context, c *Course) error {
+	defer observeQuery("CreateCourse", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO courses (title, provider, description, hours, certification_id) VALUES (?, ?, ?, ?, ?);", c.Title, c.Provider, c.Description, c.Hours, nullID(c.CertificationID))
+	if err != nil {
+		return repoError(ctx, "creating course", err)
+	}
+	return nil
+}
+
+func (r *SQLCourseRepository) UpdateCourse(ctx context.Context, c *Course) error {
+	defer observeQuery("UpdateCourse", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE courses SET title = ?, provider = ?, description = ?, hours = ?, certification_id = ? WHERE id = ?;", c.Title, c.Provider, c.Description, c.Hours, nullID(c.CertificationID), c.ID)
+	if err != nil {
+		return repoError(ctx, "updating course", err)
+	}
+	return nil
+}
+
+func (r *SQLCourseRepository) DeleteCourse(ctx context.Context, id int) error {
+	defer observeQuery("DeleteCourse", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM courses WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting course", err)
+	}
+	return nil
+}
+
+// scanEnrollment scans a row selected with enrollmentColumns.
+func scanEnrollment(row interface{ Scan(...any) error }) (Enrollment, error) {
+	var e Enrollment
+	var completed sql.NullTime
+	err := row.Scan(&e.ID, &e.CourseID, &e.EmployeeID, &e.Status, &e.EnrolledOn, &completed, &e.CreatedAt)
+	e.CompletedOn = completed.Time
+	return e, err
+}
+
+const enrollmentColumns = "id, course_id, employee_id, status, enrolled_on, completed_on, created_at"
+
+func (r *SQLEnrollmentRepository) GetEnrollments(ctx context.Context, courseID, employeeID int) ([]Enrollment, error) {
+	defer observeQuery("GetEnrollments", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+enrollmentColumns+" FROM enrollments WHERE (? = 0 OR course_id = ?) AND (? = 0 OR employee_id = ?) ORDER BY id;", courseID, courseID, employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying enrollments", err)
+	}
+	defer rows.Close()
+	var enrollments []Enrollment
+
+	for rows.Next() {
+		e, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning enrollment", err)
+		}
+		enrollments = append(enrollments, e)
+	}
+	return enrollments, nil
+}
+
+func (r *SQLEnrollmentRepository) GetEnrollmentByID(ctx context.Context, id int) (*Enrollment, error) {
+	defer observeQuery("GetEnrollmentByID", time.Now())
+	e, err := scanEnrollment(r.db.QueryRowContext(ctx, "SELECT "+enrollmentColumns+" FROM enrollments WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying enrollment by id", err)
+	}
+	return &e, nil
+}
+
+func (r *SQLEnrollmentRepository) CreateEnrollment(ctx context.Context, e *Enrollment) error {
+	defer observeQuery("CreateEnrollment", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO enrollments (course_id, employee_id, status, enrolled_on, completed_on) VALUES (?, ?, ?, ?, ?);", e.CourseID, e.EmployeeID, e.Status, e.EnrolledOn, nullTime(e.CompletedOn))
+	if err != nil {
+		return repoError(ctx, "creating enrollment", err)
+	}
+	return nil
+}
+
+func (r *SQLEnrollmentRepository) UpdateEnrollment(ctx context.Context, e *Enrollment) error {
+	defer observeQuery("UpdateEnrollment", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE enrollments SET status = ?, enrolled_on = ?, completed_on = ? WHERE id = ?;", e.Status, e.EnrolledOn, nullTime(e.CompletedOn), e.ID)
+	if err != nil {
+		return repoError(ctx, "updating enrollment", err)
+	}
+	return nil
+}
+
+func (r *SQLEnrollmentRepository) DeleteEnrollment(ctx context.Context, id int) error {
+	defer observeQuery("DeleteEnrollment", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM enrollments WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting enrollment", err)
+	}
+	return nil
+}
+
+// scanCertification scans a row selected with certificationColumns,
+// decoding the departments that require the certification.
+func scanCertification(ctx context.Context, row interface{ Scan(...any) error }) (Certification, error) {
+	var c Certification
+	var departments string
+	if err := row.Scan(&c.ID, &c.Name, &c.Description, &c.ValidityMonths, &c.ReminderDays, &departments, &c.CreatedAt); err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal([]byte(departments), &c.Departments); err != nil {
+		return c, repoError(ctx, "decoding certification departments", err)
+	}
+	return c, nil
+}
+
+const certificationColumns = "id, name, description, validity_months, reminder_days, departments, created_at"
+
+func (r *SQLCertificationRepository) GetCertifications(ctx context.Context) ([]Certification, error) {
+	defer observeQuery("GetCertifications", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+certificationColumns+" FROM certifications ORDER BY id;")
+	if err != nil {
+		return nil, repoError(ctx, "querying certifications", err)
+	}
+	defer rows.Close()
+	var certifications []Certification
+
+	for rows.Next() {
+		c, err := scanCertification(ctx, rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning certification", err)
+		}
+		certifications = append(certifications, c)
+	}
+	return certifications, nil
+}
+
+func (r *SQLCertificationRepository) GetCertificationByID(ctx context.Context, id int) (*Certification, error) {
+	defer observeQuery("GetCertificationByID", time.Now())
+	c, err := scanCertification(ctx, r.db.QueryRowContext(ctx, "SELECT "+certificationColumns+" FROM certifications WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying certification by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLCertificationRepository) CreateCertification(ctx context.Context, c *Certification) error {
+	defer observeQuery("CreateCertification", time.Now())
+	departments, err := json.Marshal(c.Departments)
+	if err != nil {
+		return repoError(ctx, "encoding certification departments", err)
+	}
+	_, err = r.db.ExecContext(ctx, "INSERT INTO certifications (name, description, validity_months, reminder_days, departments) VALUES (?, ?, ?, ?, ?);", c.Name, c.Description, c.ValidityMonths, c.ReminderDays, string(departments))
+	if err != nil {
+		return repoError(ctx, "creating certification", err)
+	}
+	return nil
+}
+
+func (r *SQLCertificationRepository) UpdateCertification(ctx context.Context, c *Certification) error {
+	defer observeQuery("UpdateCertification", time.Now())
+	departments, err := json.Marshal(c.Departments)
+	if err != nil {
+		return repoError(ctx, "encoding certification departments", err)
+	}
+	_, err = r.db.ExecContext(ctx, "UPDATE certifications SET name = ?, description = ?, validity_months = ?, reminder_days = ?, departments = ? WHERE id = ?;", c.Name, c.Description, c.ValidityMonths, c.ReminderDays, string(departments), c.ID)
+	if err != nil {
+		return repoError(ctx, "updating certification", err)
+	}
+	return nil
+}
+
+func (r *SQLCertificationRepository) DeleteCertification(ctx context.Context, id int) error {
+	defer observeQuery("DeleteCertification", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM certifications WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting certification", err)
+	}
+	return nil
+}
+
+// scanEmployeeCertification scans a row selected with
+// employeeCertificationColumns.
+func scanEmployeeCertification(row interface{ Scan(...any) error }) (EmployeeCertification, error) {
+	var ec EmployeeCertification
+	var expires sql.NullTime
+	err := row.Scan(&ec.ID, &ec.EmployeeID, &ec.CertificationID, &ec.IssuedOn, &expires, &ec.ProofKey, &ec.ProofName, &ec.CreatedAt)
+	ec.ExpiresOn = expires.Time
+	return ec, err
+}
+
+const employeeCertificationColumns = "id, employee_id, certification_id, issued_on, expires_on, proof_key, proof_name, created_at"
+
+func (r *SQLEmployeeCertificationRepository) GetEmployeeCertifications(ctx context.Context, certificationID, employeeID int) ([]EmployeeCertification, error) {
+	defer observeQuery("GetEmployeeCertifications", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+employeeCertificationColumns+" FROM employee_certifications WHERE (? = 0 OR certification_id = ?) AND (? = 0 OR employee_id = ?) ORDER BY issued_on DESC, id DESC;", certificationID, certificationID, employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying employee certifications", err)
+	}
+	defer rows.Close()
+	var certificates []EmployeeCertification
+
+	for rows.Next() {
+		ec, err := scanEmployeeCertification(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning employee certification", err)
+		}
+		certificates = append(certificates, ec)
+	}
+	return certificates, nil
+}
+
+func (r *SQLEmployeeCertificationRepository) GetEmployeeCertificationByID(ctx context.Context, id int) (*EmployeeCertification, error) {
+	defer observeQuery("GetEmployeeCertificationByID", time.Now())
+	ec, err := scanEmployeeCertification(r.db.QueryRowContext(ctx, "SELECT "+employeeCertificationColumns+" FROM employee_certifications WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying employee certification by id", err)
+	}
+	return &ec, nil
+}
+
+func (r *SQLEmployeeCertificationRepository) CreateEmployeeCertification(ctx context.Context, ec *EmployeeCertification) error {
+	defer observeQuery("CreateEmployeeCertification", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO employee_certifications (employee_id, certification_id, issued_on, expires_on, proof_key, proof_name) VALUES (?, ?, ?, ?, ?, ?);", ec.EmployeeID, ec.CertificationID, ec.IssuedOn, nullTime(ec.ExpiresOn), ec.ProofKey, ec.ProofName)
+	if err != nil {
+		return repoError(ctx, "creating employee certification", err)
+	}
+	return nil
+}
+
+func (r *SQLEmployeeCertificationRepository) DeleteEmployeeCertification(ctx context.Context, id int) error {
+	defer observeQuery("DeleteEmployeeCertification", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM employee_certifications WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting employee certification", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentCategoryRepository) GetDocumentCategories(ctx context.Context) ([]DocumentCategory, error) {
+	defer observeQuery("GetDocumentCategories", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, confidential, self_service, created_at FROM document_categories ORDER BY name;")
+	if err != nil {
+		return nil, repoError(ctx, "querying document categories", err)
+	}
+	defer rows.Close()
+	var categories []DocumentCategory
+
+	for rows.Next() {
+		var c DocumentCategory
+		if err := rows.Scan(&c.ID, &c.Name, &c.Confidential, &c.SelfService, &c.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning document category", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+func (r *SQLDocumentCategoryRepository) GetDocumentCategoryByID(ctx context.Context, id int) (*DocumentCategory, error) {
+	defer observeQuery("GetDocumentCategoryByID", time.Now())
+	var c DocumentCategory
+	err := r.db.QueryRowContext(ctx, "SELECT id, name, confidential, self_service, created_at FROM document_categories WHERE id = ?;", id).Scan(&c.ID, &c.Name, &c.Confidential, &c.SelfService, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying document category by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLDocumentCategoryRepository) CreateDocumentCategory(ctx context.Context, c *DocumentCategory) error {
+	defer observeQuery("CreateDocumentCategory", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO document_categories (name, confidential, self_service) VALUES (?, ?, ?);", c.Name, c.Confidential, c.SelfService)
+	if err != nil {
+		return repoError(ctx, "creating document category", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentCategoryRepository) UpdateDocumentCategory(ctx context.Context, c *DocumentCategory) error {
+	defer observeQuery("UpdateDocumentCategory", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE document_categories SET name = ?, confidential = ?, self_service = ? WHERE id = ?;", c.Name, c.Confidential, c.SelfService, c.ID)
+	if err != nil {
+		return repoError(ctx, "updating document category", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentCategoryRepository) DeleteDocumentCategory(ctx context.Context, id int) error {
+	defer observeQuery("DeleteDocumentCategory", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM document_categories WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting document category", err)
+	}
+	return nil
+}
+
+// scanDocument scans a row selected with documentColumns.
+func scanDocument(row interface{ Scan(...any) error }) (Document, error) {
+	var d Document
+	var expires sql.NullTime
+	err := row.Scan(&d.ID, &d.EmployeeID, &d.CategoryID, &d.Title, &expires, &d.CreatedAt)
+	d.ExpiresOn = expires.Time
+	return d, err
+}
+
+const documentColumns = "id, employee_id, category_id, title, expires_on, created_at"
+
+func (r *SQLDocumentRepository) GetDocuments(ctx context.Context, employeeID int) ([]Document, error) {
+	defer observeQuery("GetDocuments", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+documentColumns+" FROM documents WHERE (? = 0 OR employee_id = ?) ORDER BY id;", employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying documents", err)
+	}
+	defer rows.Close()
+	var documents []Document
+
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning document", err)
+		}
+		documents = append(documents, d)
+	}
+	return documents, nil
+}
+
+func (r *SQLDocumentRepository) GetDocumentByID(ctx context.Context, id int) (*Document, error) {
+	defer observeQuery("GetDocumentByID", time.Now())
+	d, err := scanDocument(r.db.QueryRowContext(ctx, "SELECT "+documentColumns+" FROM documents WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying document by id", err)
+	}
+	return &d, nil
+}
+
+func (r *SQLDocumentRepository) CreateDocument(ctx context.Context, d *Document) error {
+	defer observeQuery("CreateDocument", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO documents (employee_id, category_id, title, expires_on) VALUES (?, ?, ?, ?) RETURNING id;", d.EmployeeID, d.CategoryID, d.Title, nullTime(d.ExpiresOn)).Scan(&d.ID)
+	if err != nil {
+		return repoError(ctx, "creating document", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentRepository) UpdateDocument(ctx context.Context, d *Document) error {
+	defer observeQuery("UpdateDocument", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE documents SET category_id = ?, title = ?, expires_on = ? WHERE id = ?;", d.CategoryID, d.Title, nullTime(d.ExpiresOn), d.ID)
+	if err != nil {
+		return repoError(ctx, "updating document", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentRepository) DeleteDocument(ctx context.Context, id int) error {
+	defer observeQuery("DeleteDocument", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM documents WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting document", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentVersionRepository) GetDocumentVersions(ctx context.Context, documentID int) ([]DocumentVersion, error) {
+	defer observeQuery("GetDocumentVersions", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, document_id, version, file_key, file_name, size, uploaded_by, created_at FROM document_versions WHERE (? = 0 OR document_id = ?) ORDER BY document_id, version DESC;", documentID, documentID)
+	if err != nil {
+		return nil, repoError(ctx, "querying document versions", err)
+	}
+	defer rows.Close()
+	var versions []DocumentVersion
+
+	for rows.Next() {
+		var v DocumentVersion
+		if err := rows.Scan(&v.ID, &v.DocumentID, &v.Version, &v.FileKey, &v.FileName, &v.Size, &v.UploadedBy, &v.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning document version", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (r *SQLDocumentVersionRepository) CreateDocumentVersion(ctx context.Context, v *DocumentVersion) error {
+	defer observeQuery("CreateDocumentVersion", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO document_versions (document_id, version, file_key, file_name, size, uploaded_by) VALUES (?, ?, ?, ?, ?, ?);", v.DocumentID, v.Version, v.FileKey, v.FileName, v.Size, v.UploadedBy)
+	if err != nil {
+		return repoError(ctx, "creating document version", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentVersionRepository) DeleteDocumentVersions(ctx context.Context, documentID int) error {
+	defer observeQuery("DeleteDocumentVersions", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM document_versions WHERE document_id = ?;", documentID)
+	if err != nil {
+		return repoError(ctx, "deleting document versions", err)
+	}
+	return nil
+}
+
+func (r *SQLDocumentDownloadRepository) GetDocumentDownloads(ctx context.Context, documentID int) ([]DocumentDownload, error) {
+	defer observeQuery("GetDocumentDownloads", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, document_id, employee_id, title, version, user_name, created_at FROM document_downloads WHERE (? = 0 OR document_id = ?) ORDER BY id DESC;", documentID, documentID)
+	if err != nil {
+		return nil, repoError(ctx, "querying document downloads", err)
+	}
+	defer rows.Close()
+	var downloads []DocumentDownload
+
+	for rows.Next() {
+		var d DocumentDownload
+		if err := rows.Scan(&d.ID, &d.DocumentID, &d.EmployeeID, &d.Title, &d.Version, &d.User, &d.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning document download", err)
+		}
+		downloads = append(downloads, d)
+	}
+	return downloads, nil
+}
+
+func (r *SQLDocumentDownloadRepository) CreateDocumentDownload(ctx context.Context, d *DocumentDownload) error {
+	defer observeQuery("CreateDocumentDownload", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO document_downloads (document_id, employee_id, title, version, user_name) VALUES (?, ?, ?, ?, ?);", d.DocumentID, d.EmployeeID, d.Title, d.Version, d.User)
+	if err != nil {
+		return repoError(ctx, "creating document download", err)
+	}
+	return nil
+}
+
+// scanOnboardingTemplate scans a row selected with onboardingTemplateColumns.
+func scanOnboardingTemplate(row interface{ Scan(...any) error }) (OnboardingTemplate, error) {
+	var t OnboardingTemplate
+	err := row.Scan(&t.ID, &t.Kind, &t.Name, &t.DepartmentID, &t.Position, &t.CreatedAt)
+	return t, err
+}
+
+const onboardingTemplateColumns = "id, kind, name, COALESCE(department_id, 0), position, created_at"
+
+func (r *SQLOnboardingTemplateRepository) GetOnboardingTemplates(ctx context.Context) ([]OnboardingTemplate, error) {
+	defer observeQuery("GetOnboardingTemplates", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+onboardingTemplateColumns+" FROM onboarding_templates ORDER BY name, id;")
+	if err != nil {
+		return nil, repoError(ctx, "querying onboarding templates", err)
+	}
+	defer rows.Close()
+	var templates []OnboardingTemplate
+
+	for rows.Next() {
+		t, err := scanOnboardingTemplate(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning onboarding template", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func (r *SQLOnboardingTemplateRepository) GetOnboardingTemplateByID(ctx context.Context, id int) (*OnboardingTemplate, error) {
+	defer observeQuery("GetOnboardingTemplateByID", time.Now())
+	t, err := scanOnboardingTemplate(r.db.QueryRowContext(ctx, "SELECT "+onboardingTemplateColumns+" FROM onboarding_templates WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying onboarding template by id", err)
+	}
+	return &t, nil
+}
+
+func (r *SQLOnboardingTemplateRepository) CreateOnboardingTemplate(ctx context.Context, t *OnboardingTemplate) error {
+	defer observeQuery("CreateOnboardingTemplate", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO onboarding_templates (kind, name, department_id, position) VALUES (?, ?, ?, ?) RETURNING id;", t.Kind, t.Name, nullID(t.DepartmentID), t.Position).Scan(&t.ID)
+	if err != nil {
+		return repoError(ctx, "creating onboarding template", err)
+	}
+	return nil
+}
+
+func (r *SQLOnboardingTemplateRepository) UpdateOnboardingTemplate(ctx context.Context, t *OnboardingTemplate) error {
+	defer observeQuery("UpdateOnboardingTemplate", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE onboarding_templates SET kind = ?, name = ?, department_id = ?, position = ? WHERE id = ?;", t.Kind, t.Name, nullID(t.DepartmentID), t.Position, t.ID)
+	if err != nil {
+		return repoError(ctx, "updating onboarding template", err)
+	}
+	return nil
+}
+
+func (r *SQLOnboardingTemplateRepository) DeleteOnboardingTemplate(ctx context.Context, id int) error {
+	defer observeQuery("DeleteOnboardingTemplate", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM onboarding_templates WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting onboarding template", err)
+	}
+	return nil
+}
+
+func (r *SQLOnboardingTemplateTaskRepository) GetOnboardingTemplateTasks(ctx context.Context, templateID int) ([]OnboardingTemplateTask, error) {
+	defer observeQuery("GetOnboardingTemplateTasks", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, template_id, title, assignee, due_days, created_at FROM onboarding_template_tasks WHERE (? = 0 OR template_id = ?) ORDER BY template_id, due_days, id;", templateID, templateID)
+	if err != nil {
+		return nil, repoError(ctx, "querying onboarding template tasks", err)
+	}
+	defer rows.Close()
+	var tasks []OnboardingTemplateTask
+
+	for rows.Next() {
+		var t OnboardingTemplateTask
+		if err := rows.Scan(&t.ID, &t.TemplateID, &t.Title, &t.Assignee, &t.DueDays, &t.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning onboarding template task", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func (r *SQLOnboardingTemplateTaskRepository) CreateOnboardingTemplateTask(ctx context.Context, t *OnboardingTemplateTask) error {
+	defer observeQuery("CreateOnboardingTemplateTask", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO onboarding_template_tasks (template_id, title, assignee, due_days) VALUES (?, ?, ?, ?);", t.TemplateID, t.Title, t.Assignee, t.DueDays)
+	if err != nil {
+		return repoError(ctx, "creating onboarding template task", err)
+	}
+	return nil
+}
+
+func (r *SQLOnboardingTemplateTaskRepository) DeleteOnboardingTemplateTask(ctx context.Context, id int) error {
+	defer observeQuery("DeleteOnboardingTemplateTask", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM onboarding_template_tasks WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting onboarding template task", err)
+	}
+	return nil
+}
+
+// scanOnboardingTask scans a row selected with onboardingTaskColumns.
+func scanOnboardingTask(row interface{ Scan(...any) error }) (OnboardingTask, error) {
+	var t OnboardingTask
+	var due, done sql.NullTime
+	err := row.Scan(&t.ID, &t.EmployeeID, &t.Kind, &t.TemplateID, &t.Title, &t.Assignee, &due, &done, &t.DoneBy, &t.CreatedAt)
+	t.DueOn, t.DoneOn = due.Time, done.Time
+	return t, err
+}
+
+const onboardingTaskColumns = "id, employee_id, kind, template_id, title, assignee, due_on, done_on, done_by, created_at"
+
+func (r *SQLOnboardingTaskRepository) GetOnboardingTasks(ctx context.Context, employeeID int) ([]OnboardingTask, error) {
+	defer observeQuery("GetOnboardingTasks", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+onboardingTaskColumns+" FROM onboarding_tasks WHERE (? = 0 OR employee_id = ?) ORDER BY due_on, id;", employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying onboarding tasks", err)
+	}
+	defer rows.Close()
+	var tasks []OnboardingTask
+
+	for rows.Next() {
+		t, err := scanOnboardingTask(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning onboarding task", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func (r *SQLOnboardingTaskRepository) GetOnboardingTaskByID(ctx context.Context, id int) (*OnboardingTask, error) {
+	defer observeQuery("GetOnboardingTaskByID", time.Now())
+	t, err := scanOnboardingTask(r.db.QueryRowContext(ctx, "SELECT "+onboardingTaskColumns+" FROM onboarding_tasks WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying onboarding task by id", err)
+	}
+	return &t, nil
+}
+
+func (r *SQLOnboardingTaskRepository) CreateOnboardingTask(ctx context.Context, t *OnboardingTask) error {
+	defer observeQuery("CreateOnboardingTask", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO onboarding_tasks (employee_id, kind, template_id, title, assignee, due_on, done_on, done_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?);", t.EmployeeID, t.Kind, t.TemplateID, t.Title, t.Assignee, t.DueOn, nullTime(t.DoneOn), t.DoneBy)
+	if err != nil {
+		return repoError(ctx, "creating onboarding task", err)
+	}
+	return nil
+}
+
+func (r *SQLOnboardingTaskRepository) UpdateOnboardingTask(ctx context.Context, t *OnboardingTask) error {
+	defer observeQuery("UpdateOnboardingTask", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE onboarding_tasks SET title = ?, assignee = ?, due_on = ?, done_on = ?, done_by = ? WHERE id = ?;", t.Title, t.Assignee, t.DueOn, nullTime(t.DoneOn), t.DoneBy, t.ID)
+	if err != nil {
+		return repoError(ctx, "updating onboarding task", err)
+	}
+	return nil
+}
+
+func (r *SQLOnboardingTaskRepository) DeleteOnboardingTask(ctx context.Context, id int) error {
+	defer observeQuery("DeleteOnboardingTask", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM onboarding_tasks WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting onboarding task", err)
+	}
+	return nil
+}
+
+// scanTermination scans a row selected with terminationColumns.
+func scanTermination(row interface{ Scan(...any) error }) (Termination, error) {
+	var t Termination
+	var lastDay sql.NullTime
+	err := row.Scan(&t.ID, &t.EmployeeID, &lastDay, &t.Reason, &t.Notes, &t.RehireEligible, &t.UnusedLeaveDays, &t.Severance, &t.Deductions, &t.ProcessedBy, &t.CreatedAt)
+	t.LastDay = lastDay.Time
+	return t, err
+}
+
+const terminationColumns = "id, employee_id, last_day, reason, notes, rehire_eligible, unused_leave_days, severance, deductions, processed_by, created_at"
+
+func (r *SQLTerminationRepository) GetTerminations(ctx context.Context) ([]Termination, error) {
+	defer observeQuery("GetTerminations", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+terminationColumns+" FROM terminations ORDER BY last_day DESC, id DESC;")
+	if err != nil {
+		return nil, repoError(ctx, "querying terminations", err)
+	}
+	defer rows.Close()
+	var terminations []Termination
+
+	for rows.Next() {
+		t, err := scanTermination(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning termination", err)
+		}
+		terminations = append(terminations, t)
+	}
+	return terminations, nil
+}
+
+func (r *SQLTerminationRepository) GetTermination(ctx context.Context, employeeID int) (*Termination, error) {
+	defer observeQuery("GetTermination", time.Now())
+	t, err := scanTermination(r.db.QueryRowContext(ctx, "SELECT "+terminationColumns+" FROM terminations WHERE employee_id = ?;", employeeID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying termination", err)
+	}
+	return &t, nil
+}
+
+func (r *SQLTerminationRepository) CreateTermination(ctx context.Context, t *Termination) error {
+	defer observeQuery("CreateTermination", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO terminations (employee_id, last_day, reason, notes, rehire_eligible, unused_leave_days, severance, deductions, processed_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;", t.EmployeeID, t.LastDay, t.Reason, t.Notes, t.RehireEligible, t.UnusedLeaveDays, t.Severance, t.Deductions, t.ProcessedBy).Scan(&t.ID)
+	if err != nil {
+		return repoError(ctx, "creating termination", err)
+	}
+	return nil
+}
+
+func (r *SQLTerminationRepository) UpdateTermination(ctx context.Context, t *Termination) error {
+	defer observeQuery("UpdateTermination", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE terminations SET last_day = ?, reason = ?, notes = ?, rehire_eligible = ?, unused_leave_days = ?, severance = ?, deductions = ? WHERE employee_id = ?;", t.LastDay, t.Reason, t.Notes, t.RehireEligible, t.UnusedLeaveDays, t.Severance, t.Deductions, t.EmployeeID)
+	if err != nil {
+		return repoError(ctx, "updating termination", err)
+	}
+	return nil
+}
+
+// scanEquipment scans a row selected with equipmentColumns.
+func scanEquipment(row interface{ Scan(...any) error }) (Equipment, error) {
+	var e Equipment
+	err := row.Scan(&e.ID, &e.Category, &e.Name, &e.SerialNumber, &e.Condition, &e.Notes, &e.CreatedAt)
+	return e, err
+}
+
+const equipmentColumns = "id, category, name, COALESCE(serial_number, ''), condition, notes, created_at"
+
+func (r *SQLEquipmentRepository) GetEquipment(ctx context.Context, q string) ([]Equipment, error) {
+	defer observeQuery("GetEquipment", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+equipmentColumns+" FROM equipment WHERE LOWER(name) LIKE LOWER(?) OR LOWER(COALESCE(serial_number, '')) LIKE LOWER(?) ORDER BY category, name, id;", "%"+q+"%", "%"+q+"%")
+	if err != nil {
+		return nil, repoError(ctx, "querying equipment", err)
+	}
+	defer rows.Close()
+	var equipment []Equipment
+
+	for rows.Next() {
+		e, err := scanEquipment(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning equipment", err)
+		}
+		equipment = append(equipment, e)
+	}
+	return equipment, nil
+}
+
+func (r *SQLEquipmentRepository) GetEquipmentByID(ctx context.Context, id int) (*Equipment, error) {
+	defer observeQuery("GetEquipmentByID", time.Now())
+	e, err := scanEquipment(r.db.QueryRowContext(ctx, "SELECT "+equipmentColumns+" FROM equipment WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying equipment by id", err)
+	}
+	return &e, nil
+}
+
+func (r *SQLEquipmentRepository) CreateEquipment(ctx context.Context, e *Equipment) error {
+	defer observeQuery("CreateEquipment", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO equipment (category, name, serial_number, condition, notes) VALUES (?, ?, ?, ?, ?) RETURNING id;", e.Category, e.Name, nullString(e.SerialNumber), e.Condition, e.Notes).Scan(&e.ID)
+	if err != nil {
+		return repoError(ctx, "creating equipment", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentRepository) UpdateEquipment(ctx context.Context, e *Equipment) error {
+	defer observeQuery("UpdateEquipment", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE equipment SET category = ?, name = ?, serial_number = ?, condition = ?, notes = ? WHERE id = ?;", e.Category, e.Name, nullString(e.SerialNumber), e.Condition, e.Notes, e.ID)
+	if err != nil {
+		return repoError(ctx, "updating equipment", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentRepository) DeleteEquipment(ctx context.Context, id int) error {
+	defer observeQuery("DeleteEquipment", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM equipment WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting equipment", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentAssignmentRepository) GetEquipmentAssignments(ctx context.Context, equipmentID, employeeID int) ([]EquipmentAssignment, error) {
+	defer observeQuery("GetEquipmentAssignments", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, equipment_id, employee_id, assigned_on, returned_on, assigned_by, returned_by, created_at FROM equipment_assignments WHERE (? = 0 OR equipment_id = ?) AND (? = 0 OR employee_id = ?) ORDER BY assigned_on DESC, id DESC;", equipmentID, equipmentID, employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying equipment assignments", err)
+	}
+	defer rows.Close()
+	var assignments []EquipmentAssignment
+
+	for rows.Next() {
+		var a EquipmentAssignment
+		var assignedOn, returnedOn sql.NullTime
+		if err := rows.Scan(&a.ID, &a.EquipmentID, &a.EmployeeID, &assignedOn, &returnedOn, &a.AssignedBy, &a.ReturnedBy, &a.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning equipment assignment", err)
+		}
+		a.AssignedOn, a.ReturnedOn = assignedOn.Time, returnedOn.Time
+		assignments = append(assignments, a)
+	}
+	return assignments, nil
+}
+
+func (r *SQLEquipmentAssignmentRepository) CreateEquipmentAssignment(ctx context.Context, a *EquipmentAssignment) error {
+	defer observeQuery("CreateEquipmentAssignment", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO equipment_assignments (equipment_id, employee_id, assigned_on, returned_on, assigned_by, returned_by) VALUES (?, ?, ?, ?, ?, ?) RETURNING id;", a.EquipmentID, a.EmployeeID, a.AssignedOn, nullTime(a.ReturnedOn), a.AssignedBy, a.ReturnedBy).Scan(&a.ID)
+	if err != nil {
+		return repoError(ctx, "creating equipment assignment", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentAssignmentRepository) UpdateEquipmentAssignment(ctx context.Context, a *EquipmentAssignment) error {
+	defer observeQuery("UpdateEquipmentAssignment", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE equipment_assignments SET assigned_on = ?, returned_on = ?, returned_by = ? WHERE id = ?;", a.AssignedOn, nullTime(a.ReturnedOn), a.ReturnedBy, a.ID)
+	if err != nil {
+		return repoError(ctx, "updating equipment assignment", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentAssignmentRepository) DeleteEquipmentAssignments(ctx context.Context, equipmentID int) error {
+	defer observeQuery("DeleteEquipmentAssignments", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM equipment_assignments WHERE equipment_id = ?;", equipmentID)
+	if err != nil {
+		return repoError(ctx, "deleting equipment assignments", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentConditionRepository) GetEquipmentConditions(ctx context.Context, equipmentID int) ([]EquipmentCondition, error) {
+	defer observeQuery("GetEquipmentConditions", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT id, equipment_id, condition, notes, recorded_by, created_at FROM equipment_conditions WHERE equipment_id = ? ORDER BY id DESC;", equipmentID)
+	if err != nil {
+		return nil, repoError(ctx, "querying equipment conditions", err)
+	}
+	defer rows.Close()
+	var conditions []EquipmentCondition
+
+	for rows.Next() {
+		var c EquipmentCondition
+		if err := rows.Scan(&c.ID, &c.EquipmentID, &c.Condition, &c.Notes, &c.RecordedBy, &c.CreatedAt); err != nil {
+			return nil, repoError(ctx, "scanning equipment condition", err)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+func (r *SQLEquipmentConditionRepository) CreateEquipmentCondition(ctx context.Context, c *EquipmentCondition) error {
+	defer observeQuery("CreateEquipmentCondition", time.Now())
+	_, err := r.db.ExecContext(ctx, "INSERT INTO equipment_conditions (equipment_id, condition, notes, recorded_by) VALUES (?, ?, ?, ?);", c.EquipmentID, c.Condition, c.Notes, c.RecordedBy)
+	if err != nil {
+		return repoError(ctx, "creating equipment condition", err)
+	}
+	return nil
+}
+
+func (r *SQLEquipmentConditionRepository) DeleteEquipmentConditions(ctx context.Context, equipmentID int) error {
+	defer observeQuery("DeleteEquipmentConditions", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM equipment_conditions WHERE equipment_id = ?;", equipmentID)
+	if err != nil {
+		return repoError(ctx, "deleting equipment conditions", err)
+	}
+	return nil
+}
+
+// scanExpenseClaim scans a row selected with expenseClaimColumns.
+func scanExpenseClaim(row interface{ Scan(...any) error }) (ExpenseClaim, error) {
+	var c ExpenseClaim
+	var submittedAt, managerApprovedAt, approvedAt, reimbursedOn sql.NullTime
+	err := row.Scan(&c.ID, &c.EmployeeID, &c.ManagerID, &c.Title, &c.Currency, &c.Status, &submittedAt, &c.ManagerApprovedBy, &managerApprovedAt, &c.ApprovedBy, &approvedAt, &reimbursedOn, &c.RejectedBy, &c.RejectionReason, &c.CreatedAt)
+	c.SubmittedAt, c.ManagerApprovedAt, c.ApprovedAt, c.ReimbursedOn = submittedAt.Time, managerApprovedAt.Time, approvedAt.Time, reimbursedOn.Time
+	return c, err
+}
+
+const expenseClaimColumns = "id, employee_id, manager_id, title, currency, status, submitted_at, manager_approved_by, manager_approved_at, approved_by, approved_at, reimbursed_on, rejected_by, rejection_reason, created_at"
+
+func (r *SQLExpenseClaimRepository) GetExpenseClaims(ctx context.Context, employeeID int, status string) ([]ExpenseClaim, error) {
+	defer observeQuery("GetExpenseClaims", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+expenseClaimColumns+" FROM expense_claims WHERE (? = 0 OR employee_id = ?) AND (? = '' OR status = ?) ORDER BY id DESC;", employeeID, employeeID, status, status)
+	if err != nil {
+		return nil, repoError(ctx, "querying expense claims", err)
+	}
+	defer rows.Close()
+	var claims []ExpenseClaim
+
+	for rows.Next() {
+		c, err := scanExpenseClaim(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning expense claim", err)
+		}
+		claims = append(claims, c)
+	}
+	return claims, nil
+}
+
+func (r *SQLExpenseClaimRepository) GetExpenseClaimByID(ctx context.Context, id int) (*ExpenseClaim, error) {
+	defer observeQuery("GetExpenseClaimByID", time.Now())
+	c, err := scanExpenseClaim(r.db.QueryRowContext(ctx, "SELECT "+expenseClaimColumns+" FROM expense_claims WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying expense claim by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLExpenseClaimRepository) CreateExpenseClaim(ctx context.Context, c *ExpenseClaim) error {
+	defer observeQuery("CreateExpenseClaim", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO expense_claims (employee_id, manager_id, title, currency, status, submitted_at, manager_approved_by, manager_approved_at, approved_by, approved_at, reimbursed_on, rejected_by, rejection_reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;", c.EmployeeID, c.ManagerID, c.Title, c.Currency, c.Status, nullTime(c.SubmittedAt), c.ManagerApprovedBy, nullTime(c.ManagerApprovedAt), c.ApprovedBy, nullTime(c.ApprovedAt), nullTime(c.ReimbursedOn), c.RejectedBy, c.RejectionReason).Scan(&c.ID)
+	if err != nil {
+		return repoError(ctx, "creating expense claim", err)
+	}
+	return nil
+}
+
+func (r *SQLExpenseClaimRepository) UpdateExpenseClaim(ctx context.Context, c *ExpenseClaim) error {
+	defer observeQuery("UpdateExpenseClaim", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE expense_claims SET manager_id = ?, title = ?, currency = ?, status = ?, submitted_at = ?, manager_approved_by = ?, manager_approved_at = ?, approved_by = ?, approved_at = ?, reimbursed_on = ?, rejected_by = ?, rejection_reason = ? WHERE id = ?;", c.ManagerID, c.Title, c.Currency, c.Status, nullTime(c.SubmittedAt), c.ManagerApprovedBy, nullTime(c.ManagerApprovedAt), c.ApprovedBy, nullTime(c.ApprovedAt), nullTime(c.ReimbursedOn), c.RejectedBy, c.RejectionReason, c.ID)
+	if err != nil {
+		return repoError(ctx, "updating expense claim", err)
+	}
+	return nil
+}
+
+func (r *SQLExpenseClaimRepository) DeleteExpenseClaim(ctx context.Context, id int) error {
+	defer observeQuery("DeleteExpenseClaim", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM expense_claims WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting expense claim", err)
+	}
+	return nil
+}
+
+// scanExpenseItem scans a row selected with expenseItemColumns.
+func scanExpenseItem(row interface{ Scan(...any) error }) (ExpenseItem, error) {
+	var i ExpenseItem
+	var spentOn sql.NullTime
+	err := row.Scan(&i.ID, &i.ClaimID, &spentOn, &i.Category, &i.Description, &i.Amount, &i.ReceiptKey, &i.ReceiptName, &i.CreatedAt)
+	i.SpentOn = spentOn.Time
+	return i, err
+}
+
+const expenseItemColumns = "id, claim_id, spent_on, category, description, amount, receipt_key, receipt_name, created_at"
+
+func (r *SQLExpenseItemRepository) GetExpenseItems(ctx context.Context, claimID int) ([]ExpenseItem, error) {
+	defer observeQuery("GetExpenseItems", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+expenseItemColumns+" FROM expense_items WHERE (? = 0 OR claim_id = ?) ORDER BY spent_on, id;", claimID, claimID)
+	if err != nil {
+		return nil, repoError(ctx, "querying expense items", err)
+	}
+	defer rows.Close()
+	var items []ExpenseItem
+
+	for rows.Next() {
+		i, err := scanExpenseItem(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning expense item", err)
+		}
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+func (r *SQLExpenseItemRepository) GetExpenseItemByID(ctx context.Context, id int) (*ExpenseItem, error) {
+	defer observeQuery("GetExpenseItemByID", time.Now())
+	i, err := scanExpenseItem(r.db.QueryRowContext(ctx, "SELECT "+expenseItemColumns+" FROM expense_items WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying expense item by id", err)
+	}
+	return &i, nil
+}
+
+func (r *SQLExpenseItemRepository) CreateExpenseItem(ctx context.Context, i *ExpenseItem) error {
+	defer observeQuery("CreateExpenseItem", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO expense_items (claim_id, spent_on, category, description, amount, receipt_key, receipt_name) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id;", i.ClaimID, i.SpentOn, i.Category, i.Description, i.Amount, i.ReceiptKey, i.ReceiptName).Scan(&i.ID)
+	if err != nil {
+		return repoError(ctx, "creating expense item", err)
+	}
+	return nil
+}
+
+func (r *SQLExpenseItemRepository) DeleteExpenseItem(ctx context.Context, id int) error {
+	defer observeQuery("DeleteExpenseItem", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM expense_items WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting expense item", err)
+	}
+	return nil
+}
+
+func (r *SQLExpenseItemRepository) DeleteExpenseItems(ctx context.Context, claimID int) error {
+	defer observeQuery("DeleteExpenseItems", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM expense_items WHERE claim_id = ?;", claimID)
+	if err != nil {
+		return repoError(ctx, "deleting expense items", err)
+	}
+	return nil
+}
+
+// scanBenefitPlan scans a row selected with benefitPlanColumns.
+func scanBenefitPlan(row interface{ Scan(...any) error }) (BenefitPlan, error) {
+	var p BenefitPlan
+	var opensOn, closesOn sql.NullTime
+	err := row.Scan(&p.ID, &p.Name, &p.Kind, &p.Description, &p.Statuses, &p.DepartmentID, &p.MinTenureMonths, &p.CoversDependents, &opensOn, &closesOn, &p.CreatedAt)
+	p.OpensOn, p.ClosesOn = opensOn.Time, closesOn.Time
+	return p, err
+}
+
+const benefitPlanColumns = "id, name, kind, description, statuses, COALESCE(department_id, 0), min_tenure_months, covers_dependents, opens_on, closes_on, created_at"
+
+func (r *SQLBenefitPlanRepository) GetBenefitPlans(ctx context.Context) ([]BenefitPlan, error) {
+	defer observeQuery("GetBenefitPlans", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+benefitPlanColumns+" FROM benefit_plans ORDER BY kind, name, id;")
+	if err != nil {
+		return nil, repoError(ctx, "querying benefit plans", err)
+	}
+	defer rows.Close()
+	var plans []BenefitPlan
+
+	for rows.Next() {
+		p, err := scanBenefitPlan(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning benefit plan", err)
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+func (r *SQLBenefitPlanRepository) GetBenefitPlanByID(ctx context.Context, id int) (*BenefitPlan, error) {
+	defer observeQuery("GetBenefitPlanByID", time.Now())
+	p, err := scanBenefitPlan(r.db.QueryRowContext(ctx, "SELECT "+benefitPlanColumns+" FROM benefit_plans WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying benefit plan by id", err)
+	}
+	return &p, nil
+}
+
+func (r *SQLBenefitPlanRepository) CreateBenefitPlan(ctx context.Context, p *BenefitPlan) error {
+	defer observeQuery("CreateBenefitPlan", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO benefit_plans (name, kind, description, statuses, department_id, min_tenure_months, covers_dependents, opens_on, closes_on) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;", p.Name, p.Kind, p.Description, p.Statuses, nullID(p.DepartmentID), p.MinTenureMonths, p.CoversDependents, p.OpensOn, p.ClosesOn).Scan(&p.ID)
+	if err != nil {
+		return repoError(ctx, "creating benefit plan", err)
+	}
+	return nil
+}
+
+func (r *SQLBenefitPlanRepository) UpdateBenefitPlan(ctx context.Context, p *BenefitPlan) error {
+	defer observeQuery("UpdateBenefitPlan", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE benefit_plans SET name = ?, kind = ?, description = ?, statuses = ?, department_id = ?, min_tenure_months = ?, covers_dependents = ?, opens_on = ?, closes_on = ? WHERE id = ?;", p.Name, p.Kind, p.Description, p.Statuses, nullID(p.DepartmentID), p.MinTenureMonths, p.CoversDependents, p.OpensOn, p.ClosesOn, p.ID)
+	if err != nil {
+		return repoError(ctx, "updating benefit plan", err)
+	}
+	return nil
+}
+
+func (r *SQLBenefitPlanRepository) DeleteBenefitPlan(ctx context.Context, id int) error {
+	defer observeQuery("DeleteBenefitPlan", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM benefit_plans WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting benefit plan", err)
+	}
+	return nil
+}
+
+// scanDependent scans a row selected with dependentColumns.
+func scanDependent(row interface{ Scan(...any) error }) (Dependent, error) {
+	var d Dependent
+	var birthDate sql.NullTime
+	err := row.Scan(&d.ID, &d.EmployeeID, &d.FirstName, &d.LastName, &d.Relationship, &birthDate, &d.CreatedAt)
+	d.BirthDate = birthDate.Time
+	return d, err
+}
+
+const dependentColumns = "id, employee_id, first_name, last_name, relationship, birth_date, created_at"
+
+func (r *SQLDependentRepository) GetDependents(ctx context.Context, employeeID int) ([]Dependent, error) {
+	defer observeQuery("GetDependents", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+dependentColumns+" FROM dependents WHERE (? = 0 OR employee_id = ?) ORDER BY id;", employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying dependents", err)
+	}
+	defer rows.Close()
+	var dependents []Dependent
+
+	for rows.Next() {
+		d, err := scanDependent(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning dependent", err)
+		}
+		dependents = append(dependents, d)
+	}
+	return dependents, nil
+}
+
+func (r *SQLDependentRepository) GetDependentByID(ctx context.Context, id int) (*Dependent, error) {
+	defer observeQuery("GetDependentByID", time.Now())
+	d, err := scanDependent(r.db.QueryRowContext(ctx, "SELECT "+dependentColumns+" FROM dependents WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying dependent by id", err)
+	}
+	return &d, nil
+}
+
+func (r *SQLDependentRepository) CreateDependent(ctx context.Context, d *Dependent) error {
+	defer observeQuery("CreateDependent", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO dependents (employee_id, first_name, last_name, relationship, birth_date) VALUES (?, ?, ?, ?, ?) RETURNING id;", d.EmployeeID, d.FirstName, d.LastName, d.Relationship, nullTime(d.BirthDate)).Scan(&d.ID)
+	if err != nil {
+		return repoError(ctx, "creating dependent", err)
+	}
+	return nil
+}
+
+func (r *SQLDependentRepository) DeleteDependent(ctx context.Context, id int) error {
+	defer observeQuery("DeleteDependent", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM dependents WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting dependent", err)
+	}
+	return nil
+}
+
+// joinIDs stores a list of ids in a text column, e.g. 3,5.
+func joinIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, ",")
+}
+
+// splitIDs reads a list of ids stored with joinIDs.
+func splitIDs(s string) []int {
+	var ids []int
+	for _, item := range splitList(s) {
+		if id, err := strconv.Atoi(item); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// scanBenefitEnrollment scans a row selected with benefitEnrollmentColumns.
+func scanBenefitEnrollment(row interface{ Scan(...any) error }) (BenefitEnrollment, error) {
+	var e BenefitEnrollment
+	var dependentIDs string
+	var effectiveFrom, effectiveTo sql.NullTime
+	err := row.Scan(&e.ID, &e.PlanID, &e.EmployeeID, &dependentIDs, &effectiveFrom, &effectiveTo, &e.EnrolledBy, &e.CreatedAt)
+	e.DependentIDs, e.EffectiveFrom, e.EffectiveTo = splitIDs(dependentIDs), effectiveFrom.Time, effectiveTo.Time
+	return e, err
+}
+
+const benefitEnrollmentColumns = "id, plan_id, employee_id, dependent_ids, effective_from, effective_to, enrolled_by, created_at"
+
+func (r *SQLBenefitEnrollmentRepository) GetBenefitEnrollments(ctx context.Context, planID, employeeID int) ([]BenefitEnrollment, error) {
+	defer observeQuery("GetBenefitEnrollments", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+benefitEnrollmentColumns+" FROM benefit_enrollments WHERE (? = 0 OR plan_id = ?) AND (? = 0 OR employee_id = ?) ORDER BY effective_from DESC, id DESC;", planID, planID, employeeID, employeeID)
+	if err != nil {
+		return nil, repoError(ctx, "querying benefit enrollments", err)
+	}
+	defer rows.Close()
+	var enrollments []BenefitEnrollment
+
+	for rows.Next() {
+		e, err := scanBenefitEnrollment(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning benefit enrollment", err)
+		}
+		enrollments = append(enrollments, e)
+	}
+	return enrollments, nil
+}
+
+func (r *SQLBenefitEnrollmentRepository) GetBenefitEnrollmentByID(ctx context.Context, id int) (*BenefitEnrollment, error) {
+	defer observeQuery("GetBenefitEnrollmentByID", time.Now())
+	e, err := scanBenefitEnrollment(r.db.QueryRowContext(ctx, "SELECT "+benefitEnrollmentColumns+" FROM benefit_enrollments WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying benefit enrollment by id", err)
+	}
+	return &e, nil
+}
+
+func (r *SQLBenefitEnrollmentRepository) CreateBenefitEnrollment(ctx context.Context, e *BenefitEnrollment) error {
+	defer observeQuery("CreateBenefitEnrollment", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO benefit_enrollments (plan_id, employee_id, dependent_ids, effective_from, effective_to, enrolled_by) VALUES (?, ?, ?, ?, ?, ?) RETURNING id;", e.PlanID, e.EmployeeID, joinIDs(e.DependentIDs), e.EffectiveFrom, nullTime(e.EffectiveTo), e.EnrolledBy).Scan(&e.ID)
+	if err != nil {
+		return repoError(ctx, "creating benefit enrollment", err)
+	}
+	return nil
+}
+
+func (r *SQLBenefitEnrollmentRepository) UpdateBenefitEnrollment(ctx context.Context, e *BenefitEnrollment) error {
+	defer observeQuery("UpdateBenefitEnrollment", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE benefit_enrollments SET dependent_ids = ?, effective_from = ?, effective_to = ? WHERE id = ?;", joinIDs(e.DependentIDs), e.EffectiveFrom, nullTime(e.EffectiveTo), e.ID)
+	if err != nil {
+		return repoError(ctx, "updating benefit enrollment", err)
+	}
+	return nil
+}
+
+// scanShiftTemplate scans a row selected with shiftTemplateColumns.
+func scanShiftTemplate(row interface{ Scan(...any) error }) (ShiftTemplate, error) {
+	var t ShiftTemplate
+	err := row.Scan(&t.ID, &t.Name, &t.DepartmentID, &t.StartTime, &t.EndTime, &t.BreakMinutes, &t.CreatedAt)
+	return t, err
+}
+
+const shiftTemplateColumns = "id, name, COALESCE(department_id, 0), start_time, end_time, break_minutes, created_at"
+
+func (r *SQLShiftTemplateRepository) GetShiftTemplates(ctx context.Context) ([]ShiftTemplate, error) {
+	defer observeQuery("GetShiftTemplates", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+shiftTemplateColumns+" FROM shift_templates ORDER BY start_time, name, id;")
+	if err != nil {
+		return nil, repoError(ctx, "querying shift templates", err)
+	}
+	defer rows.Close()
+	var templates []ShiftTemplate
+
+	for rows.Next() {
+		t, err := scanShiftTemplate(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning shift template", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func (r *SQLShiftTemplateRepository) GetShiftTemplateByID(ctx context.Context, id int) (*ShiftTemplate, error) {
+	defer observeQuery("GetShiftTemplateByID", time.Now())
+	t, err := scanShiftTemplate(r.db.QueryRowContext(ctx, "SELECT "+shiftTemplateColumns+" FROM shift_templates WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying shift template by id", err)
+	}
+	return &t, nil
+}
+
+func (r *SQLShiftTemplateRepository) DeleteShiftTemplate(ctx context.Context, id int) error {
+	defer observeQuery("DeleteShiftTemplate", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM shift_templates WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting shift template", err)
+	}
+	return nil
+}
+
+func (r *SQLShiftTemplateRepository) CreateShiftTemplate(ctx context.Context, t *ShiftTemplate) error {
+	defer observeQuery("CreateShiftTemplate", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO shift_templates (name, department_id, start_time, end_time, break_minutes) VALUES (?, ?, ?, ?, ?) RETURNING id;", t.Name, nullID(t.DepartmentID), t.StartTime, t.EndTime, t.BreakMinutes).Scan(&t.ID)
+	if err != nil {
+		return repoError(ctx, "creating shift template", err)
+	}
+	return nil
+}
+
+func (r *SQLShiftTemplateRepository) UpdateShiftTemplate(ctx context.Context, t *ShiftTemplate) error {
+	defer observeQuery("UpdateShiftTemplate", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE shift_templates SET name = ?, department_id = ?, start_time = ?, end_time = ?, break_minutes = ? WHERE id = ?;", t.Name, nullID(t.DepartmentID), t.StartTime, t.EndTime, t.BreakMinutes, t.ID)
+	if err != nil {
+		return repoError(ctx, "updating shift template", err)
+	}
+	return nil
+}
+
+// scanShift scans a row selected with shiftColumns.
+func scanShift(row interface{ Scan(...any) error }) (Shift, error) {
+	var s Shift
+	var publishedAt sql.NullTime
+	err := row.Scan(&s.ID, &s.DepartmentID, &s.EmployeeID, &s.Name, &s.Date, &s.StartTime, &s.EndTime, &s.BreakMinutes, &publishedAt, &s.CreatedAt)
+	s.PublishedAt = publishedAt.Time
+	return s, err
+}
+
+const shiftColumns = "id, department_id, employee_id, name, shift_date, start_time, end_time, break_minutes, published_at, created_at"
+
+func (r *SQLShiftRepository) GetShifts(ctx context.Context, departmentID, employeeID int, from, to time.Time) ([]Shift, error) {
+	defer observeQuery("GetShifts", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+shiftColumns+" FROM shifts WHERE (? = 0 OR department_id = ?) AND (? = 0 OR employee_id = ?) AND shift_date >= ? AND shift_date < ? ORDER BY shift_date, start_time, id;", departmentID, departmentID, employeeID, employeeID, from, to)
+	if err != nil {
+		return nil, repoError(ctx, "querying shifts", err)
+	}
+	defer rows.Close()
+	var shifts []Shift
+
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning shift", err)
+		}
+		shifts = append(shifts, s)
+	}
+	return shifts, nil
+}
+
+func (r *SQLShiftRepository) GetShiftByID(ctx context.Context, id int) (*Shift, error) {
+	defer observeQuery("GetShiftByID", time.Now())
+	s, err := scanShift(r.db.QueryRowContext(ctx, "SELECT "+shiftColumns+" FROM shifts WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying shift by id", err)
+	}
+	return &s, nil
+}
+
+func (r *SQLShiftRepository) CreateShift(ctx context.Context, s *Shift) error {
+	defer observeQuery("CreateShift", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO shifts (department_id, employee_id, name, shift_date, start_time, end_time, break_minutes, published_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;", s.DepartmentID, s.EmployeeID, s.Name, s.Date, s.StartTime, s.EndTime, s.BreakMinutes, nullTime(s.PublishedAt)).Scan(&s.ID)
+	if err != nil {
+		return repoError(ctx, "creating shift", err)
+	}
+	return nil
+}
+
+func (r *SQLShiftRepository) UpdateShift(ctx context.Context, s *Shift) error {
+	defer observeQuery("UpdateShift", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE shifts SET employee_id = ?, shift_date = ?, published_at = ? WHERE id = ?;", s.EmployeeID, s.Date, nullTime(s.PublishedAt), s.ID)
+	if err != nil {
+		return repoError(ctx, "updating shift", err)
+	}
+	return nil
+}
+
+func (r *SQLShiftRepository) DeleteShift(ctx context.Context, id int) error {
+	defer observeQuery("DeleteShift", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM shifts WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting shift", err)
+	}
+	return nil
+}
+
+// scanOvertimeRequest scans a row selected with overtimeRequestColumns.
+func scanOvertimeRequest(row interface{ Scan(...any) error }) (OvertimeRequest, error) {
+	var o OvertimeRequest
+	var decidedAt sql.NullTime
+	err := row.Scan(&o.ID, &o.EmployeeID, &o.Date, &o.Hours, &o.DayKind, &o.Multiplier, &o.Compensation, &o.Reason, &o.Status, &o.DecidedBy, &decidedAt, &o.CreatedAt)
+	o.DecidedAt = decidedAt.Time
+	return o, err
+}
+
+const overtimeRequestColumns = "id, employee_id, work_date, hours, day_kind, multiplier, compensation, reason, status, decided_by, decided_at, created_at"
+
+func (r *SQLOvertimeRequestRepository) GetOvertimeRequests(ctx context.Context, employeeID int, status string) ([]OvertimeRequest, error) {
+	defer observeQuery("GetOvertimeRequests", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+overtimeRequestColumns+" FROM overtime_requests WHERE (? = 0 OR employee_id = ?) AND (? = '' OR status = ?) ORDER BY work_date DESC, id DESC;", employeeID, employeeID, status, status)
+	if err != nil {
+		return nil, repoError(ctx, "querying overtime requests", err)
+	}
+	defer rows.Close()
+	var requests []OvertimeRequest
+
+	for rows.Next() {
+		o, err := scanOvertimeRequest(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning overtime request", err)
+		}
+		requests = append(requests, o)
+	}
+	return requests, nil
+}
+
+func (r *SQLOvertimeRequestRepository) GetOvertimeRequestByID(ctx context.Context, id int) (*OvertimeRequest, error) {
+	defer observeQuery("GetOvertimeRequestByID", time.Now())
+	o, err := scanOvertimeRequest(r.db.QueryRowContext(ctx, "SELECT "+overtimeRequestColumns+" FROM overtime_requests WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying overtime request by id", err)
+	}
+	return &o, nil
+}
+
+func (r *SQLOvertimeRequestRepository) CreateOvertimeRequest(ctx context.Context, o *OvertimeRequest) error {
+	defer observeQuery("CreateOvertimeRequest", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO overtime_requests (employee_id, work_date, hours, day_kind, multiplier, compensation, reason, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;", o.EmployeeID, o.Date, o.Hours, o.DayKind, o.Multiplier, o.Compensation, o.Reason, o.Status).Scan(&o.ID)
+	if err != nil {
+		return repoError(ctx, "creating overtime request", err)
+	}
+	return nil
+}
+
+func (r *SQLOvertimeRequestRepository) UpdateOvertimeRequest(ctx context.Context, o *OvertimeRequest) error {
+	defer observeQuery("UpdateOvertimeRequest", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE overtime_requests SET status = ?, decided_by = ?, decided_at = ? WHERE id = ?;", o.Status, o.DecidedBy, nullTime(o.DecidedAt), o.ID)
+	if err != nil {
+		return repoError(ctx, "updating overtime request", err)
+	}
+	return nil
+}
+
+func (r *SQLOvertimeRequestRepository) DeleteOvertimeRequest(ctx context.Context, id int) error {
+	defer observeQuery("DeleteOvertimeRequest", time.Now())
+	_, err := r.db.ExecContext(ctx, "DELETE FROM overtime_requests WHERE id = ?;", id)
+	if err != nil {
+		return repoError(ctx, "deleting overtime request", err)
+	}
+	return nil
+}
+
+// scanContactChange scans a row selected with contactChangeColumns.
+func scanContactChange(row interface{ Scan(...any) error }) (ContactChange, error) {
+	var c ContactChange
+	var decidedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.EmployeeID, &c.Phone, &c.Address, &c.EmergencyName, &c.EmergencyPhone, &c.Status, &c.DecidedBy, &decidedAt, &c.CreatedAt)
+	c.DecidedAt = decidedAt.Time
+	return c, err
+}
+
+const contactChangeColumns = "id, employee_id, phone, address, emergency_name, emergency_phone, status, decided_by, decided_at, created_at"
+
+func (r *SQLContactChangeRepository) GetContactChanges(ctx context.Context, employeeID int, status string) ([]ContactChange, error) {
+	defer observeQuery("GetContactChanges", time.Now())
+	rows, err := r.db.QueryContext(ctx, "SELECT "+contactChangeColumns+" FROM contact_changes WHERE (? = 0 OR employee_id = ?) AND (? = '' OR status = ?) ORDER BY id DESC;", employeeID, employeeID, status, status)
+	if err != nil {
+		return nil, repoError(ctx, "querying contact changes", err)
+	}
+	defer rows.Close()
+	var changes []ContactChange
+
+	for rows.Next() {
+		c, err := scanContactChange(rows)
+		if err != nil {
+			return nil, repoError(ctx, "scanning contact change", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+func (r *SQLContactChangeRepository) GetContactChangeByID(ctx context.Context, id int) (*ContactChange, error) {
+	defer observeQuery("GetContactChangeByID", time.Now())
+	c, err := scanContactChange(r.db.QueryRowContext(ctx, "SELECT "+contactChangeColumns+" FROM contact_changes WHERE id = ?;", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, repoError(ctx, "querying contact change by id", err)
+	}
+	return &c, nil
+}
+
+func (r *SQLContactChangeRepository) CreateContactChange(ctx context.Context, c *ContactChange) error {
+	defer observeQuery("CreateContactChange", time.Now())
+	err := r.db.QueryRowContext(ctx, "INSERT INTO contact_changes (employee_id, phone, address, emergency_name, emergency_phone, status) VALUES (?, ?, ?, ?, ?, ?) RETURNING id;", c.EmployeeID, c.Phone, c.Address, c.EmergencyName, c.EmergencyPhone, c.Status).Scan(&c.ID)
+	if err != nil {
+		return repoError(ctx, "creating contact change", err)
+	}
+	return nil
+}
+
+func (r *SQLContactChangeRepository) UpdateContactChange(ctx context.Context, c *ContactChange) error {
+	defer observeQuery("UpdateContactChange", time.Now())
+	_, err := r.db.ExecContext(ctx, "UPDATE contact_changes SET status = ?, decided_by = ?, decided_at = ? WHERE id = ?;", c.Status, c.DecidedBy, nullTime(c.DecidedAt), c.ID)
+	if err != nil {
+		return repoError(ctx, "updating contact change", err)
 	}
 	return nil
 }
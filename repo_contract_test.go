@@ -0,0 +1,1457 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) Repositories {
+		return NewMemoryRepositories()
+	})
+}
+
+func TestSQLiteRepositories(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) Repositories {
+		path := filepath.Join(t.TempDir(), "contract.db")
+		db, err := connectToDB(context.Background(), DatabaseConfig{Driver: "sqlite", Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return NewSQLRepositories(db)
+	})
+}
+
+// TestSQLiteAddsColumns opens a database created before employees had a
+// card number.
+func TestSQLiteAddsColumns(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "old.db")
+	old, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`CREATE TABLE employees (id INTEGER PRIMARY KEY AUTOINCREMENT, first_name TEXT NOT NULL, last_name TEXT NOT NULL,
+		email TEXT NOT NULL UNIQUE, department_id INTEGER, job_title TEXT, hire_date DATE, salary REAL, status TEXT DEFAULT 'active',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO employees (first_name, last_name, email, department_id, job_title, hire_date, salary) VALUES ('Jane', 'Smith', 'jane@example.com', 0, 'Engineer', '2023-01-15', 85000);`)
+	old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := connectToDB(ctx, DatabaseConfig{Driver: "sqlite", Path: path})
+	if err != nil {
+		t.Fatalf("connectToDB() on an old database error = %v", err)
+	}
+	defer db.Close()
+	repo := NewEmployeeRepository(db)
+	e, err := repo.GetEmployeeByID(ctx, 1)
+	if err != nil || e == nil || e.CardNumber != "" {
+		t.Fatalf("GetEmployeeByID() = %+v, %v, want Jane without a card", e, err)
+	}
+	e.CardNumber = "0004521"
+	if err := repo.UpdateEmployee(ctx, e); err != nil {
+		t.Fatalf("UpdateEmployee() error = %v", err)
+	}
+	if e, _ := repo.GetEmployeeByID(ctx, 1); e.CardNumber != "0004521" {
+		t.Errorf("CardNumber = %q after update, want 0004521", e.CardNumber)
+	}
+}
+
+// TestPostgresRepositories runs against the database named by
+// HR_TEST_POSTGRES_DSN, e.g. postgres://hr@localhost:5432/hr_test. Its
+// tables are emptied before every subtest.
+func TestPostgresRepositories(t *testing.T) {
+	dsn := os.Getenv("HR_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("HR_TEST_POSTGRES_DSN is not set")
+	}
+
+	testRepositoryContract(t, func(t *testing.T) Repositories {
+		ctx := context.Background()
+		db, err := connectToDB(ctx, DatabaseConfig{Driver: "postgres", DSN: dsn})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if _, err := db.ExecContext(ctx, "TRUNCATE contact_changes, overtime_requests, shifts, shift_templates, benefit_enrollments, dependents, benefit_plans, expense_items, expense_claims, equipment_conditions, equipment_assignments, equipment, terminations, onboarding_tasks, onboarding_template_tasks, onboarding_templates, document_downloads, document_versions, documents, document_categories, employee_certifications, enrollments, courses, certifications, check_ins, key_results, objectives, employee_ratings, review_assessments, review_questions, review_cycles, badge_imports, attendance_events, attendance_corrections, work_schedules, leaves, employees, departments, positions, applications RESTART IDENTITY CASCADE;"); err != nil {
+			t.Fatal(err)
+		}
+		return NewSQLRepositories(db)
+	})
+}
+
+// testRepositoryContract checks the behaviour every backend must share.
+// newRepos must return repositories over an empty store.
+func testRepositoryContract(t *testing.T, newRepos func(t *testing.T) Repositories) {
+	ctx := context.Background()
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	t.Run("Departments", func(t *testing.T) {
+		repo := newRepos(t).Departments
+		for _, name := range []string{"Engineering", "Marketing"} {
+			if err := repo.CreateDepartment(ctx, &Department{Name: name, Description: name + " team"}); err != nil {
+				t.Fatalf("CreateDepartment() error = %v", err)
+			}
+		}
+		if err := repo.CreateDepartment(ctx, &Department{Name: "Engineering"}); err == nil {
+			t.Error("CreateDepartment() with a duplicate name succeeded, want an error")
+		}
+
+		found, err := repo.GetDepartments(ctx, "ENGIN")
+		if err != nil {
+			t.Fatalf("GetDepartments() error = %v", err)
+		}
+		if len(found) != 1 || found[0].Name != "Engineering" {
+			t.Fatalf("GetDepartments(ENGIN) = %+v, want only Engineering (search is case-insensitive)", found)
+		}
+		if found[0].CreatedAt.IsZero() {
+			t.Error("CreatedAt was not set")
+		}
+
+		d := found[0]
+		d.Name = "Platform"
+		if err := repo.UpdateDepartment(ctx, &d); err != nil {
+			t.Fatalf("UpdateDepartment() error = %v", err)
+		}
+		got, err := repo.GetDepartmentByID(ctx, d.ID)
+		if err != nil || got == nil || got.Name != "Platform" {
+			t.Fatalf("GetDepartmentByID() = %+v, %v, want Platform", got, err)
+		}
+
+		if err := repo.DeleteDepartment(ctx, d.ID); err != nil {
+			t.Fatalf("DeleteDepartment() error = %v", err)
+		}
+		got, err = repo.GetDepartmentByID(ctx, d.ID)
+		if err != nil || got != nil {
+			t.Fatalf("GetDepartmentByID() after delete = %+v, %v, want nil, nil", got, err)
+		}
+		all, _ := repo.GetDepartments(ctx, "")
+		if len(all) != 1 {
+			t.Errorf("GetDepartments() after delete returned %d rows, want 1", len(all))
+		}
+	})
+
+	t.Run("Positions", func(t *testing.T) {
+		repo := newRepos(t).Positions
+		if err := repo.CreatePosition(ctx, &Position{Name: "Backend Engineer", Description: "Go"}); err != nil {
+			t.Fatalf("CreatePosition() error = %v", err)
+		}
+		found, err := repo.GetPositions(ctx, "backend")
+		if err != nil || len(found) != 1 {
+			t.Fatalf("GetPositions() = %+v, %v, want one position", found, err)
+		}
+
+		p := found[0]
+		p.Description = "Go and SQL"
+		if err := repo.UpdatePosition(ctx, &p); err != nil {
+			t.Fatalf("UpdatePosition() error = %v", err)
+		}
+		got, err := repo.GetPositionByID(ctx, p.ID)
+		if err != nil || got == nil || got.Description != "Go and SQL" {
+			t.Fatalf("GetPositionByID() = %+v, %v", got, err)
+		}
+		if err := repo.DeletePosition(ctx, p.ID); err != nil {
+			t.Fatalf("DeletePosition() error = %v", err)
+		}
+		if got, _ := repo.GetPositionByID(ctx, p.ID); got != nil {
+			t.Errorf("GetPositionByID() after delete = %+v, want nil", got)
+		}
+	})
+
+	t.Run("Employees", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Engineering"}); err != nil {
+			t.Fatal(err)
+		}
+		depts, _ := repos.Departments.GetDepartments(ctx, "")
+
+		repo := repos.Employees
+		e := Employee{
+			FirstName: "Jane", LastName: "Smith", Email: "jane@example.com", JobTitle: "Engineer",
+			HireDate: day("2023-01-15"), Salary: 85000, Status: "active", DepartmentID: depts[0].ID, CardNumber: "0004521",
+		}
+		if err := repo.CreateEmployee(ctx, &e); err != nil || e.ID == 0 {
+			t.Fatalf("CreateEmployee() = %+v, %v, want an ID", e, err)
+		}
+		if err := repo.CreateEmployee(ctx, &Employee{FirstName: "Ann", LastName: "Lee", Email: "ann@example.com", HireDate: day("2023-01-15"), CardNumber: "0004521"}); err == nil {
+			t.Error("CreateEmployee() with a duplicate card number succeeded, want an error")
+		}
+		var noCard Employee
+		for _, email := range []string{"nocard1@example.com", "nocard2@example.com"} {
+			noCard = Employee{FirstName: "No", LastName: "Card", Email: email, HireDate: day("2023-01-15")}
+			if err := repo.CreateEmployee(ctx, &noCard); err != nil {
+				t.Errorf("CreateEmployee() without a card number error = %v, want several employees without one allowed", err)
+			}
+		}
+		found, err := repo.GetEmployees(ctx, "SMITH")
+		if err != nil || len(found) != 1 {
+			t.Fatalf("GetEmployees() = %+v, %v, want one employee", found, err)
+		}
+		got := found[0]
+		if !got.HireDate.Equal(e.HireDate) || got.Salary != e.Salary || got.DepartmentID != e.DepartmentID || got.CardNumber != e.CardNumber {
+			t.Errorf("GetEmployees() = %+v, want fields of %+v", got, e)
+		}
+		if byEmail, err := repo.GetEmployeeByEmail(ctx, "Jane@Example.com"); err != nil || byEmail == nil || byEmail.ID != e.ID || byEmail.CardNumber != e.CardNumber {
+			t.Errorf("GetEmployeeByEmail() = %+v, %v, want Jane whatever the case", byEmail, err)
+		}
+		if nobody, err := repo.GetEmployeeByEmail(ctx, "nobody@example.com"); err != nil || nobody != nil {
+			t.Errorf("GetEmployeeByEmail(unknown) = %+v, %v, want nil", nobody, err)
+		}
+
+		got.Status = "inactive"
+		got.Salary = 90000
+		got.ManagerID = noCard.ID
+		if err := repo.UpdateEmployee(ctx, &got); err != nil {
+			t.Fatalf("UpdateEmployee() error = %v", err)
+		}
+		updated, err := repo.GetEmployeeByID(ctx, got.ID)
+		if err != nil || updated == nil || updated.Status != "inactive" || updated.Salary != 90000 || updated.ManagerID != noCard.ID {
+			t.Fatalf("GetEmployeeByID() = %+v, %v", updated, err)
+		}
+		if err := repo.DeleteEmployee(ctx, got.ID); err != nil {
+			t.Fatalf("DeleteEmployee() error = %v", err)
+		}
+		if missing, _ := repo.GetEmployeeByID(ctx, got.ID); missing != nil {
+			t.Errorf("GetEmployeeByID() after delete = %+v, want nil", missing)
+		}
+	})
+
+	t.Run("Applications", func(t *testing.T) {
+		repo := newRepos(t).Applications
+		a := Application{Name: "Alice Walker", Email: "alice@example.com", Phone: "123", AppliedFor: "Backend Engineer", ResumeURL: "resume.pdf", Status: "pending"}
+		if err := repo.CreateApplication(ctx, &a); err != nil {
+			t.Fatalf("CreateApplication() error = %v", err)
+		}
+		found, err := repo.GetApplications(ctx, "alice")
+		if err != nil || len(found) != 1 {
+			t.Fatalf("GetApplications() = %+v, %v, want one application", found, err)
+		}
+
+		got := found[0]
+		got.Status = "interviewing"
+		if err := repo.UpdateApplication(ctx, &got); err != nil {
+			t.Fatalf("UpdateApplication() error = %v", err)
+		}
+		updated, err := repo.GetApplicationByID(ctx, got.ID)
+		if err != nil || updated == nil || updated.Status != "interviewing" || updated.Phone != "123" {
+			t.Fatalf("GetApplicationByID() = %+v, %v", updated, err)
+		}
+		if err := repo.DeleteApplication(ctx, got.ID); err != nil {
+			t.Fatalf("DeleteApplication() error = %v", err)
+		}
+		if missing, _ := repo.GetApplicationByID(ctx, got.ID); missing != nil {
+			t.Errorf("GetApplicationByID() after delete = %+v, want nil", missing)
+		}
+	})
+
+	t.Run("Leaves", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Employees.CreateEmployee(ctx, &Employee{FirstName: "John", LastName: "Doe", Email: "john@example.com", HireDate: day("2023-06-01")}); err != nil {
+			t.Fatal(err)
+		}
+		employees, _ := repos.Employees.GetEmployees(ctx, "")
+
+		repo := repos.Leaves
+		l := Leave{EmployeeID: employees[0].ID, LeaveType: "vacation", StartDate: day("2024-03-01"), EndDate: day("2024-03-07"), Status: "pending", Reason: "Annual leave"}
+		if err := repo.CreateLeave(ctx, &l); err != nil {
+			t.Fatalf("CreateLeave() error = %v", err)
+		}
+		found, err := repo.GetLeaves(ctx, "VACATION")
+		if err != nil || len(found) != 1 {
+			t.Fatalf("GetLeaves() = %+v, %v, want one leave", found, err)
+		}
+		got := found[0]
+		if !got.StartDate.Equal(l.StartDate) || !got.EndDate.Equal(l.EndDate) {
+			t.Errorf("leave period = %v - %v, want %v - %v", got.StartDate, got.EndDate, l.StartDate, l.EndDate)
+		}
+
+		got.Status = "approved"
+		if err := repo.UpdateLeave(ctx, &got); err != nil {
+			t.Fatalf("UpdateLeave() error = %v", err)
+		}
+		updated, err := repo.GetLeaveByID(ctx, got.ID)
+		if err != nil || updated == nil || updated.Status != "approved" {
+			t.Fatalf("GetLeaveByID() = %+v, %v", updated, err)
+		}
+		if err := repo.DeleteLeave(ctx, got.ID); err != nil {
+			t.Fatalf("DeleteLeave() error = %v", err)
+		}
+		if missing, _ := repo.GetLeaveByID(ctx, got.ID); missing != nil {
+			t.Errorf("GetLeaveByID() after delete = %+v, want nil", missing)
+		}
+	})
+
+	t.Run("Attendance", func(t *testing.T) {
+		repos := newRepos(t)
+		for _, email := range []string{"john@example.com", "jane@example.com"} {
+			if err := repos.Employees.CreateEmployee(ctx, &Employee{FirstName: "E", LastName: "E", Email: email, HireDate: day("2023-06-01")}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		employees, _ := repos.Employees.GetEmployees(ctx, "")
+		john, jane := employees[0].ID, employees[1].ID
+		at := func(s string) time.Time {
+			ts, err := time.Parse("2006-01-02 15:04", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ts
+		}
+
+		repo := repos.Attendance
+		for _, e := range []AttendanceEvent{
+			{EmployeeID: john, Kind: "out", OccurredAt: at("2024-03-04 17:05"), Source: "web"},
+			{EmployeeID: john, Kind: "in", OccurredAt: at("2024-03-04 08:55"), Source: "web"},
+			{EmployeeID: jane, Kind: "in", OccurredAt: at("2024-03-04 09:30"), Source: "web"},
+			{EmployeeID: john, Kind: "in", OccurredAt: at("2024-03-05 09:00"), Source: "web"},
+		} {
+			if err := repo.CreateAttendanceEvent(ctx, &e); err != nil {
+				t.Fatalf("CreateAttendanceEvent() error = %v", err)
+			}
+		}
+
+		from, to := day("2024-03-04"), day("2024-03-05")
+		all, err := repo.GetAttendanceEvents(ctx, 0, from, to)
+		if err != nil || len(all) != 3 {
+			t.Fatalf("GetAttendanceEvents(all) = %+v, %v, want 3 events", all, err)
+		}
+		if !all[0].OccurredAt.Equal(at("2024-03-04 08:55")) || !all[2].OccurredAt.Equal(at("2024-03-04 17:05")) {
+			t.Errorf("GetAttendanceEvents() not ordered by time: %+v", all)
+		}
+		johns, _ := repo.GetAttendanceEvents(ctx, john, from, to)
+		if len(johns) != 2 || johns[0].Kind != "in" || johns[1].Kind != "out" {
+			t.Fatalf("GetAttendanceEvents(john) = %+v, want in and out", johns)
+		}
+
+		err = repo.ReplaceAttendanceEvents(ctx, john, from, to, []AttendanceEvent{
+			{Kind: "in", OccurredAt: at("2024-03-04 09:00"), Source: "correction"},
+			{Kind: "out", OccurredAt: at("2024-03-04 18:00"), Source: "correction"},
+		})
+		if err != nil {
+			t.Fatalf("ReplaceAttendanceEvents() error = %v", err)
+		}
+		johns, _ = repo.GetAttendanceEvents(ctx, john, from, to)
+		if len(johns) != 2 || johns[0].Source != "correction" || !johns[1].OccurredAt.Equal(at("2024-03-04 18:00")) || johns[0].EmployeeID != john {
+			t.Fatalf("events after replace = %+v", johns)
+		}
+		if others, _ := repo.GetAttendanceEvents(ctx, 0, from, day("2024-03-06")); len(others) != 4 {
+			t.Errorf("replace touched other employees or days: %+v", others)
+		}
+
+		if err := repo.DeleteAttendanceEvent(ctx, johns[0].ID); err != nil {
+			t.Fatalf("DeleteAttendanceEvent() error = %v", err)
+		}
+		if johns, _ = repo.GetAttendanceEvents(ctx, john, from, to); len(johns) != 1 {
+			t.Errorf("events after delete = %+v, want one", johns)
+		}
+	})
+
+	t.Run("WorkSchedules", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Support"}); err != nil {
+			t.Fatal(err)
+		}
+		departments, _ := repos.Departments.GetDepartments(ctx, "")
+
+		repo := repos.Schedules
+		for _, ws := range []WorkSchedule{
+			{Name: "Office", StartTime: "09:00", EndTime: "17:00", Workdays: "mon,tue,wed,thu,fri", GraceMinutes: 10},
+			{Name: "Support", DepartmentID: departments[0].ID, StartTime: "07:00", EndTime: "15:00", Workdays: "sun,mon", GraceMinutes: 0},
+		} {
+			if err := repo.CreateWorkSchedule(ctx, &ws); err != nil {
+				t.Fatalf("CreateWorkSchedule() error = %v", err)
+			}
+		}
+		found, err := repo.GetWorkSchedules(ctx)
+		if err != nil || len(found) != 2 {
+			t.Fatalf("GetWorkSchedules() = %+v, %v, want two schedules", found, err)
+		}
+		if found[0].DepartmentID != 0 || found[1].DepartmentID != departments[0].ID {
+			t.Errorf("department ids = %d, %d", found[0].DepartmentID, found[1].DepartmentID)
+		}
+
+		got := found[1]
+		got.GraceMinutes = 5
+		got.DepartmentID = 0
+		if err := repo.UpdateWorkSchedule(ctx, &got); err != nil {
+			t.Fatalf("UpdateWorkSchedule() error = %v", err)
+		}
+		updated, err := repo.GetWorkScheduleByID(ctx, got.ID)
+		if err != nil || updated == nil || updated.GraceMinutes != 5 || updated.DepartmentID != 0 || updated.Workdays != "sun,mon" {
+			t.Fatalf("GetWorkScheduleByID() = %+v, %v", updated, err)
+		}
+		if err := repo.DeleteWorkSchedule(ctx, got.ID); err != nil {
+			t.Fatalf("DeleteWorkSchedule() error = %v", err)
+		}
+		if missing, _ := repo.GetWorkScheduleByID(ctx, got.ID); missing != nil {
+			t.Errorf("GetWorkScheduleByID() after delete = %+v, want nil", missing)
+		}
+	})
+
+	t.Run("AttendanceCorrections", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Employees.CreateEmployee(ctx, &Employee{FirstName: "John", LastName: "Doe", Email: "john@example.com", HireDate: day("2023-06-01")}); err != nil {
+			t.Fatal(err)
+		}
+		employees, _ := repos.Employees.GetEmployees(ctx, "")
+
+		repo := repos.Corrections
+		clockIn := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+		for _, status := range []string{"pending", "approved"} {
+			c := AttendanceCorrection{EmployeeID: employees[0].ID, Date: day("2024-03-04"), ClockIn: clockIn, ClockOut: clockIn.Add(8 * time.Hour), Reason: "Forgot", Status: status}
+			if err := repo.CreateAttendanceCorrection(ctx, &c); err != nil {
+				t.Fatalf("CreateAttendanceCorrection() error = %v", err)
+			}
+		}
+
+		all, err := repo.GetAttendanceCorrections(ctx, "")
+		if err != nil || len(all) != 2 || all[0].Status != "approved" {
+			t.Fatalf("GetAttendanceCorrections() = %+v, %v, want two, newest first", all, err)
+		}
+		pending, _ := repo.GetAttendanceCorrections(ctx, "pending")
+		if len(pending) != 1 {
+			t.Fatalf("GetAttendanceCorrections(pending) = %+v, want one", pending)
+		}
+		got := pending[0]
+		if !got.ClockIn.Equal(clockIn) || !got.Date.Equal(day("2024-03-04")) {
+			t.Errorf("correction = %+v", got)
+		}
+
+		got.Status = "rejected"
+		if err := repo.UpdateAttendanceCorrection(ctx, &got); err != nil {
+			t.Fatalf("UpdateAttendanceCorrection() error = %v", err)
+		}
+		updated, err := repo.GetAttendanceCorrectionByID(ctx, got.ID)
+		if err != nil || updated == nil || updated.Status != "rejected" || updated.Reason != "Forgot" {
+			t.Fatalf("GetAttendanceCorrectionByID() = %+v, %v", updated, err)
+		}
+	})
+
+	t.Run("BadgeImports", func(t *testing.T) {
+		repo := newRepos(t).BadgeImports
+		first := BadgeImport{FileName: "door-0304.csv", Source: "upload", Report: BadgeReport{Rows: 3, Swipes: 2, Events: 2, Days: 1}}
+		if err := repo.CreateBadgeImport(ctx, &first); err != nil {
+			t.Fatalf("CreateBadgeImport() error = %v", err)
+		}
+		if first.ID == 0 {
+			t.Error("CreateBadgeImport() left ID unset")
+		}
+		seen := time.Date(2024, 3, 4, 8, 1, 0, 0, time.UTC)
+		second := BadgeImport{FileName: "door-0305.csv", Source: "watch", Report: BadgeReport{
+			Rows:      1,
+			Unmatched: []UnmatchedCard{{CardNumber: "X9", Swipes: 1, First: seen, Last: seen}},
+			Errors:    []LineError{{Line: 2, Err: "Invalid time"}},
+		}}
+		if err := repo.CreateBadgeImport(ctx, &second); err != nil {
+			t.Fatalf("CreateBadgeImport() error = %v", err)
+		}
+
+		all, err := repo.GetBadgeImports(ctx)
+		if err != nil || len(all) != 2 || all[0].ID != second.ID {
+			t.Fatalf("GetBadgeImports() = %+v, %v, want two, newest first", all, err)
+		}
+		got, err := repo.GetBadgeImportByID(ctx, second.ID)
+		if err != nil || got == nil {
+			t.Fatalf("GetBadgeImportByID() = %+v, %v", got, err)
+		}
+		if got.Source != "watch" || len(got.Report.Unmatched) != 1 || !got.Report.Unmatched[0].First.Equal(seen) || got.Report.Errors[0].Line != 2 {
+			t.Errorf("badge import = %+v, want the stored report", got)
+		}
+		if missing, _ := repo.GetBadgeImportByID(ctx, 999); missing != nil {
+			t.Errorf("GetBadgeImportByID(999) = %+v, want nil", missing)
+		}
+	})
+
+	t.Run("Reviews", func(t *testing.T) {
+		repos := newRepos(t)
+		for _, e := range []Employee{
+			{FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com", HireDate: day("2023-01-15")},
+			{FirstName: "Omar", LastName: "Saleh", Email: "omar@example.com", HireDate: day("2023-01-15")},
+		} {
+			if err := repos.Employees.CreateEmployee(ctx, &e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		employees, _ := repos.Employees.GetEmployees(ctx, "")
+		lina, omar := employees[0].ID, employees[1].ID
+
+		cycles := repos.ReviewCycles
+		for _, c := range []ReviewCycle{
+			{Name: "2023 Annual", SelfDue: day("2023-12-01"), ManagerDue: day("2023-12-15"), PeerDue: day("2023-12-10"), Status: reviewClosed},
+			{Name: "2024 Annual", SelfDue: day("2024-12-01"), ManagerDue: day("2024-12-15"), PeerDue: day("2024-12-10"), Status: reviewDraft},
+		} {
+			if err := cycles.CreateReviewCycle(ctx, &c); err != nil {
+				t.Fatalf("CreateReviewCycle() error = %v", err)
+			}
+		}
+		all, err := cycles.GetReviewCycles(ctx)
+		if err != nil || len(all) != 2 || all[0].Name != "2024 Annual" {
+			t.Fatalf("GetReviewCycles() = %+v, %v, want two, newest first", all, err)
+		}
+		cycle := all[0]
+		cycle.Status = reviewOpen
+		cycle.PeerDue = day("2024-12-12")
+		if err := cycles.UpdateReviewCycle(ctx, &cycle); err != nil {
+			t.Fatalf("UpdateReviewCycle() error = %v", err)
+		}
+		got, err := cycles.GetReviewCycleByID(ctx, cycle.ID)
+		if err != nil || got == nil || got.Status != reviewOpen || !got.PeerDue.Equal(day("2024-12-12")) || got.DepartmentID != 0 {
+			t.Fatalf("GetReviewCycleByID() = %+v, %v, want the update", got, err)
+		}
+
+		questions := repos.Questions
+		for _, q := range []ReviewQuestion{{CycleID: cycle.ID, Text: "Quality", Scale: 5}, {CycleID: cycle.ID, Text: "Teamwork", Scale: 3}} {
+			if err := questions.CreateReviewQuestion(ctx, &q); err != nil {
+				t.Fatalf("CreateReviewQuestion() error = %v", err)
+			}
+		}
+		qs, err := questions.GetReviewQuestions(ctx, cycle.ID)
+		if err != nil || len(qs) != 2 || qs[1].Text != "Teamwork" || qs[1].Scale != 3 {
+			t.Fatalf("GetReviewQuestions() = %+v, %v", qs, err)
+		}
+		if other, _ := questions.GetReviewQuestions(ctx, all[1].ID); len(other) != 0 {
+			t.Errorf("questions of the other cycle = %+v, want none", other)
+		}
+
+		assessments := repos.Assessments
+		self := ReviewAssessment{CycleID: cycle.ID, EmployeeID: lina, ReviewerID: lina, Kind: "self", DueDate: cycle.SelfDue, Status: "pending"}
+		if err := assessments.CreateReviewAssessment(ctx, &self); err != nil {
+			t.Fatalf("CreateReviewAssessment() error = %v", err)
+		}
+		peer := ReviewAssessment{CycleID: cycle.ID, EmployeeID: lina, ReviewerID: omar, Kind: "peer", DueDate: cycle.PeerDue, Status: "pending"}
+		if err := assessments.CreateReviewAssessment(ctx, &peer); err != nil {
+			t.Fatalf("CreateReviewAssessment() error = %v", err)
+		}
+		if err := assessments.CreateReviewAssessment(ctx, &peer); err == nil {
+			t.Error("CreateReviewAssessment() with the same reviewer twice succeeded, want an error")
+		}
+		forLina, err := assessments.GetReviewAssessments(ctx, cycle.ID, lina)
+		if err != nil || len(forLina) != 2 || forLina[0].Ratings != nil || !forLina[0].SubmittedAt.IsZero() {
+			t.Fatalf("GetReviewAssessments() = %+v, %v, want two pending forms", forLina, err)
+		}
+		if none, _ := assessments.GetReviewAssessments(ctx, cycle.ID, omar); len(none) != 0 {
+			t.Errorf("Omar's assessments = %+v, want none", none)
+		}
+
+		submitted := forLina[1]
+		submitted.Ratings = map[int]int{qs[0].ID: 4, qs[1].ID: 2}
+		submitted.Comment = "Helpful"
+		submitted.Status = "submitted"
+		submitted.SubmittedAt = time.Date(2024, 12, 3, 10, 30, 0, 0, time.UTC)
+		if err := assessments.UpdateReviewAssessment(ctx, &submitted); err != nil {
+			t.Fatalf("UpdateReviewAssessment() error = %v", err)
+		}
+		a, err := assessments.GetReviewAssessmentByID(ctx, submitted.ID)
+		if err != nil || a == nil {
+			t.Fatalf("GetReviewAssessmentByID() = %+v, %v", a, err)
+		}
+		if a.Status != "submitted" || a.Ratings[qs[0].ID] != 4 || a.Ratings[qs[1].ID] != 2 || a.Comment != "Helpful" || !a.SubmittedAt.Equal(submitted.SubmittedAt) {
+			t.Errorf("assessment = %+v, want the submitted form", a)
+		}
+		if missing, _ := assessments.GetReviewAssessmentByID(ctx, 999); missing != nil {
+			t.Errorf("GetReviewAssessmentByID(999) = %+v, want nil", missing)
+		}
+
+		ratings := repos.Ratings
+		for _, er := range []EmployeeRating{
+			{EmployeeID: lina, CycleID: cycle.ID, Rating: 3},
+			{EmployeeID: lina, CycleID: all[1].ID, Rating: 2, Comment: "First year"},
+			{EmployeeID: omar, CycleID: cycle.ID, Rating: 5},
+			{EmployeeID: lina, CycleID: cycle.ID, Rating: 4, Comment: "Calibrated up"},
+		} {
+			if err := ratings.SaveEmployeeRating(ctx, &er); err != nil {
+				t.Fatalf("SaveEmployeeRating() error = %v", err)
+			}
+		}
+		history, err := ratings.GetEmployeeRatings(ctx, 0, lina)
+		if err != nil || len(history) != 2 {
+			t.Fatalf("GetEmployeeRatings(lina) = %+v, %v, want one per cycle", history, err)
+		}
+		if history[0].CycleID != all[1].ID || history[1].Rating != 4 || history[1].Comment != "Calibrated up" {
+			t.Errorf("history = %+v, want the older cycle first and the saved rating replaced", history)
+		}
+		if inCycle, _ := ratings.GetEmployeeRatings(ctx, cycle.ID, 0); len(inCycle) != 2 {
+			t.Errorf("ratings of the cycle = %+v, want two", inCycle)
+		}
+
+		if err := questions.DeleteReviewQuestion(ctx, qs[0].ID); err != nil {
+			t.Fatalf("DeleteReviewQuestion() error = %v", err)
+		}
+		if qs, _ := questions.GetReviewQuestions(ctx, cycle.ID); len(qs) != 1 {
+			t.Errorf("questions after delete = %+v, want one", qs)
+		}
+	})
+
+	t.Run("Goals", func(t *testing.T) {
+		repos := newRepos(t)
+		support := Department{Name: "Support"}
+		if err := repos.Departments.CreateDepartment(ctx, &support); err != nil {
+			t.Fatal(err)
+		}
+		departments, _ := repos.Departments.GetDepartments(ctx, "")
+		deptID := departments[0].ID
+
+		objectives := repos.Objectives
+		parent := Objective{Title: "Faster support", Quarter: "2025-Q1", DepartmentID: deptID}
+		if err := objectives.CreateObjective(ctx, &parent); err != nil || parent.ID == 0 {
+			t.Fatalf("CreateObjective() = %+v, %v, want an ID", parent, err)
+		}
+		other := Objective{Title: "Next quarter", Quarter: "2025-Q2", DepartmentID: deptID}
+		if err := objectives.CreateObjective(ctx, &other); err != nil {
+			t.Fatal(err)
+		}
+		child := Objective{Title: "Answer within a day", Description: "Email queue", Quarter: "2025-Q1", DepartmentID: deptID, ParentID: parent.ID}
+		if err := objectives.CreateObjective(ctx, &child); err != nil {
+			t.Fatal(err)
+		}
+		q1, err := objectives.GetObjectives(ctx, "2025-Q1")
+		if err != nil || len(q1) != 2 || q1[0].ID != parent.ID || q1[1].ParentID != parent.ID {
+			t.Fatalf("GetObjectives(2025-Q1) = %+v, %v, want the parent then its child", q1, err)
+		}
+		if all, _ := objectives.GetObjectives(ctx, ""); len(all) != 3 {
+			t.Errorf("GetObjectives(\"\") = %+v, want all three", all)
+		}
+		child.ParentID, child.Title = 0, "Answer within an hour"
+		if err := objectives.UpdateObjective(ctx, &child); err != nil {
+			t.Fatalf("UpdateObjective() error = %v", err)
+		}
+		got, err := objectives.GetObjectiveByID(ctx, child.ID)
+		if err != nil || got == nil || got.Title != "Answer within an hour" || got.ParentID != 0 || got.Description != "Email queue" {
+			t.Fatalf("GetObjectiveByID() = %+v, %v, want the update", got, err)
+		}
+
+		results := repos.KeyResults
+		kr := KeyResult{ObjectiveID: parent.ID, Title: "First reply", Unit: "hours", StartValue: 24, TargetValue: 4, CurrentValue: 24}
+		if err := results.CreateKeyResult(ctx, &kr); err != nil {
+			t.Fatalf("CreateKeyResult() error = %v", err)
+		}
+		if err := results.CreateKeyResult(ctx, &KeyResult{ObjectiveID: other.ID, Title: "CSAT", StartValue: 80, TargetValue: 90}); err != nil {
+			t.Fatal(err)
+		}
+		krs, _ := results.GetKeyResults(ctx, parent.ID)
+		kr = krs[0]
+		kr.CurrentValue = 12.5
+		if err := results.UpdateKeyResult(ctx, &kr); err != nil {
+			t.Fatalf("UpdateKeyResult() error = %v", err)
+		}
+		krs, err = results.GetKeyResults(ctx, parent.ID)
+		if err != nil || len(krs) != 1 || krs[0].CurrentValue != 12.5 || krs[0].Unit != "hours" {
+			t.Fatalf("GetKeyResults() = %+v, %v, want the updated one", krs, err)
+		}
+		if all, _ := results.GetKeyResults(ctx, 0); len(all) != 2 {
+			t.Errorf("GetKeyResults(0) = %+v, want both", all)
+		}
+
+		checkIns := repos.CheckIns
+		for _, v := range []float64{18, 12.5} {
+			if err := checkIns.CreateCheckIn(ctx, &CheckIn{KeyResultID: kr.ID, Value: v, Comment: "weekly"}); err != nil {
+				t.Fatalf("CreateCheckIn() error = %v", err)
+			}
+		}
+		history, err := checkIns.GetCheckIns(ctx, kr.ID)
+		if err != nil || len(history) != 2 || history[0].Value != 12.5 || history[1].Comment != "weekly" {
+			t.Fatalf("GetCheckIns() = %+v, %v, want two, newest first", history, err)
+		}
+		if err := checkIns.DeleteCheckIns(ctx, kr.ID); err != nil {
+			t.Fatalf("DeleteCheckIns() error = %v", err)
+		}
+		if history, _ := checkIns.GetCheckIns(ctx, kr.ID); len(history) != 0 {
+			t.Errorf("check-ins after delete = %+v, want none", history)
+		}
+		if err := results.DeleteKeyResult(ctx, kr.ID); err != nil {
+			t.Fatalf("DeleteKeyResult() error = %v", err)
+		}
+		if err := objectives.DeleteObjective(ctx, parent.ID); err != nil {
+			t.Fatalf("DeleteObjective() error = %v", err)
+		}
+		if got, err := objectives.GetObjectiveByID(ctx, parent.ID); err != nil || got != nil {
+			t.Errorf("GetObjectiveByID() after delete = %+v, %v, want nil", got, err)
+		}
+	})
+
+	t.Run("Training", func(t *testing.T) {
+		repos := newRepos(t)
+		ana := Employee{FirstName: "Ana", LastName: "Silva", Email: "ana@example.com", HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Status: "active"}
+		if err := repos.Employees.CreateEmployee(ctx, &ana); err != nil {
+			t.Fatal(err)
+		}
+		employees, _ := repos.Employees.GetEmployees(ctx, "")
+		empID := employees[0].ID
+
+		certs := repos.Certifications
+		if err := certs.CreateCertification(ctx, &Certification{Name: "First Aid", ValidityMonths: 24, ReminderDays: 30, Departments: []int{3, 1}}); err != nil {
+			t.Fatalf("CreateCertification() error = %v", err)
+		}
+		if err := certs.CreateCertification(ctx, &Certification{Name: "First Aid", Departments: []int{}}); err == nil {
+			t.Error("CreateCertification() with a duplicate name succeeded")
+		}
+		list, err := certs.GetCertifications(ctx)
+		if err != nil || len(list) != 1 || !list[0].Requires(3) || list[0].ValidityMonths != 24 {
+			t.Fatalf("GetCertifications() = %+v, %v, want First Aid", list, err)
+		}
+		cert := list[0]
+		cert.Departments, cert.ReminderDays = []int{2}, 14
+		if err := certs.UpdateCertification(ctx, &cert); err != nil {
+			t.Fatalf("UpdateCertification() error = %v", err)
+		}
+		if got, err := certs.GetCertificationByID(ctx, cert.ID); err != nil || got == nil || !got.Requires(2) || got.Requires(3) || got.ReminderDays != 14 {
+			t.Fatalf("GetCertificationByID() = %+v, %v, want the update", got, err)
+		}
+
+		courses := repos.Courses
+		if err := courses.CreateCourse(ctx, &Course{Title: "Emergency Response", Provider: "Red Crescent", Hours: 7.5, CertificationID: cert.ID}); err != nil {
+			t.Fatalf("CreateCourse() error = %v", err)
+		}
+		if err := courses.CreateCourse(ctx, &Course{Title: "Excel Basics"}); err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := courses.GetCourses(ctx)
+		if err != nil || len(catalog) != 2 || catalog[0].CertificationID != cert.ID || catalog[0].Hours != 7.5 || catalog[1].CertificationID != 0 {
+			t.Fatalf("GetCourses() = %+v, %v, want both", catalog, err)
+		}
+		course := catalog[1]
+		course.Description = "Formulas and charts"
+		if err := courses.UpdateCourse(ctx, &course); err != nil {
+			t.Fatalf("UpdateCourse() error = %v", err)
+		}
+		if got, err := courses.GetCourseByID(ctx, course.ID); err != nil || got == nil || got.Description != "Formulas and charts" {
+			t.Fatalf("GetCourseByID() = %+v, %v, want the update", got, err)
+		}
+
+		enrollments := repos.Enrollments
+		enrolled := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		if err := enrollments.CreateEnrollment(ctx, &Enrollment{CourseID: catalog[0].ID, EmployeeID: empID, Status: enrollmentEnrolled, EnrolledOn: enrolled}); err != nil {
+			t.Fatalf("CreateEnrollment() error = %v", err)
+		}
+		if err := enrollments.CreateEnrollment(ctx, &Enrollment{CourseID: catalog[0].ID, EmployeeID: empID, Status: enrollmentEnrolled, EnrolledOn: enrolled}); err == nil {
+			t.Error("CreateEnrollment() twice succeeded")
+		}
+		mine, err := enrollments.GetEnrollments(ctx, 0, empID)
+		if err != nil || len(mine) != 1 || !mine[0].EnrolledOn.Equal(enrolled) || !mine[0].CompletedOn.IsZero() {
+			t.Fatalf("GetEnrollments() = %+v, %v, want one open enrollment", mine, err)
+		}
+		e := mine[0]
+		e.Status, e.CompletedOn = enrollmentCompleted, enrolled.AddDate(0, 0, 3)
+		if err := enrollments.UpdateEnrollment(ctx, &e); err != nil {
+			t.Fatalf("UpdateEnrollment() error = %v", err)
+		}
+		if got, err := enrollments.GetEnrollmentByID(ctx, e.ID); err != nil || got == nil || got.Status != enrollmentCompleted || !got.CompletedOn.Equal(e.CompletedOn) {
+			t.Fatalf("GetEnrollmentByID() = %+v, %v, want completed", got, err)
+		}
+		if other, _ := enrollments.GetEnrollments(ctx, course.ID, 0); len(other) != 0 {
+			t.Errorf("GetEnrollments(other course) = %+v, want none", other)
+		}
+
+		held := repos.Certificates
+		for _, issued := range []time.Time{enrolled.AddDate(-2, 0, 0), enrolled} {
+			ec := EmployeeCertification{EmployeeID: empID, CertificationID: cert.ID, IssuedOn: issued, ExpiresOn: cert.Expiry(issued), ProofKey: "0123456789abcdef0123456789abcdef", ProofName: "card.png"}
+			if err := held.CreateEmployeeCertification(ctx, &ec); err != nil {
+				t.Fatalf("CreateEmployeeCertification() error = %v", err)
+			}
+		}
+		if err := held.CreateEmployeeCertification(ctx, &EmployeeCertification{EmployeeID: empID, CertificationID: cert.ID, IssuedOn: enrolled.AddDate(-5, 0, 0)}); err != nil {
+			t.Fatal(err)
+		}
+		history, err := held.GetEmployeeCertifications(ctx, cert.ID, empID)
+		if err != nil || len(history) != 3 || !history[0].IssuedOn.Equal(enrolled) || history[0].ProofName != "card.png" || !history[2].ExpiresOn.IsZero() {
+			t.Fatalf("GetEmployeeCertifications() = %+v, %v, want three, newest first", history, err)
+		}
+		if got, err := held.GetEmployeeCertificationByID(ctx, history[0].ID); err != nil || got == nil || !got.ExpiresOn.Equal(enrolled.AddDate(0, 24, 0)) {
+			t.Fatalf("GetEmployeeCertificationByID() = %+v, %v, want the latest", got, err)
+		}
+		if err := held.DeleteEmployeeCertification(ctx, history[0].ID); err != nil {
+			t.Fatalf("DeleteEmployeeCertification() error = %v", err)
+		}
+		if rest, _ := held.GetEmployeeCertifications(ctx, 0, 0); len(rest) != 2 {
+			t.Errorf("certificates after delete = %+v, want two", rest)
+		}
+
+		if err := enrollments.DeleteEnrollment(ctx, e.ID); err != nil {
+			t.Fatalf("DeleteEnrollment() error = %v", err)
+		}
+		if err := courses.DeleteCourse(ctx, course.ID); err != nil {
+			t.Fatalf("DeleteCourse() error = %v", err)
+		}
+		if got, err := courses.GetCourseByID(ctx, course.ID); err != nil || got != nil {
+			t.Errorf("GetCourseByID() after delete = %+v, %v, want nil", got, err)
+		}
+		if err := held.DeleteEmployeeCertification(ctx, history[1].ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Documents", func(t *testing.T) {
+		repos := newRepos(t)
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Status: "active"}
+		if err := repos.Employees.CreateEmployee(ctx, &lea); err != nil {
+			t.Fatal(err)
+		}
+		employees, _ := repos.Employees.GetEmployees(ctx, "")
+		empID := employees[0].ID
+
+		categories := repos.DocCategories
+		for _, c := range []DocumentCategory{{Name: "Policies"}, {Name: "Contracts", Confidential: true}} {
+			if err := categories.CreateDocumentCategory(ctx, &c); err != nil {
+				t.Fatalf("CreateDocumentCategory() error = %v", err)
+			}
+		}
+		if err := categories.CreateDocumentCategory(ctx, &DocumentCategory{Name: "Policies"}); err == nil {
+			t.Error("CreateDocumentCategory() with a duplicate name succeeded")
+		}
+		list, err := categories.GetDocumentCategories(ctx)
+		if err != nil || len(list) != 2 || list[0].Name != "Contracts" || !list[0].Confidential || list[1].Confidential {
+			t.Fatalf("GetDocumentCategories() = %+v, %v, want both by name", list, err)
+		}
+		policies := list[1]
+		policies.Name, policies.Confidential, policies.SelfService = "Signed policies", true, true
+		if err := categories.UpdateDocumentCategory(ctx, &policies); err != nil {
+			t.Fatalf("UpdateDocumentCategory() error = %v", err)
+		}
+		if got, err := categories.GetDocumentCategoryByID(ctx, policies.ID); err != nil || got == nil || got.Name != "Signed policies" || !got.Confidential || !got.SelfService {
+			t.Fatalf("GetDocumentCategoryByID() = %+v, %v, want the update", got, err)
+		}
+
+		documents := repos.Documents
+		expires := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+		contract := Document{EmployeeID: empID, CategoryID: list[0].ID, Title: "Employment contract", ExpiresOn: expires}
+		if err := documents.CreateDocument(ctx, &contract); err != nil || contract.ID == 0 {
+			t.Fatalf("CreateDocument() = %+v, %v, want an ID", contract, err)
+		}
+		if err := documents.CreateDocument(ctx, &Document{EmployeeID: empID, CategoryID: policies.ID, Title: "Code of conduct"}); err != nil {
+			t.Fatal(err)
+		}
+		mine, err := documents.GetDocuments(ctx, empID)
+		if err != nil || len(mine) != 2 || !mine[0].ExpiresOn.Equal(expires) || !mine[1].ExpiresOn.IsZero() {
+			t.Fatalf("GetDocuments() = %+v, %v, want both", mine, err)
+		}
+		contract.Title, contract.ExpiresOn = "Contract 2025", time.Time{}
+		if err := documents.UpdateDocument(ctx, &contract); err != nil {
+			t.Fatalf("UpdateDocument() error = %v", err)
+		}
+		if got, err := documents.GetDocumentByID(ctx, contract.ID); err != nil || got == nil || got.Title != "Contract 2025" || !got.ExpiresOn.IsZero() || got.EmployeeID != empID {
+			t.Fatalf("GetDocumentByID() = %+v, %v, want the update", got, err)
+		}
+
+		versions := repos.DocVersions
+		for n, key := range []string{"0123456789abcdef0123456789abcde1", "0123456789abcdef0123456789abcde2"} {
+			v := DocumentVersion{DocumentID: contract.ID, Version: n + 1, FileKey: key, FileName: "contract.pdf", Size: 2048, UploadedBy: "hana"}
+			if err := versions.CreateDocumentVersion(ctx, &v); err != nil {
+				t.Fatalf("CreateDocumentVersion() error = %v", err)
+			}
+		}
+		if err := versions.CreateDocumentVersion(ctx, &DocumentVersion{DocumentID: contract.ID, Version: 2, FileKey: "0123456789abcdef0123456789abcde3"}); err == nil {
+			t.Error("CreateDocumentVersion() with a taken number succeeded")
+		}
+		history, err := versions.GetDocumentVersions(ctx, contract.ID)
+		if err != nil || len(history) != 2 || history[0].Version != 2 || history[1].Size != 2048 || history[1].UploadedBy != "hana" {
+			t.Fatalf("GetDocumentVersions() = %+v, %v, want two, newest first", history, err)
+		}
+
+		downloads := repos.Downloads
+		for _, user := range []string{"hana", ""} {
+			if err := downloads.CreateDocumentDownload(ctx, &DocumentDownload{DocumentID: contract.ID, EmployeeID: empID, Title: contract.Title, Version: 2, User: user}); err != nil {
+				t.Fatalf("CreateDocumentDownload() error = %v", err)
+			}
+		}
+		log, err := downloads.GetDocumentDownloads(ctx, contract.ID)
+		if err != nil || len(log) != 2 || log[0].User != "" || log[1].User != "hana" || log[1].Title != "Contract 2025" {
+			t.Fatalf("GetDocumentDownloads() = %+v, %v, want two, newest first", log, err)
+		}
+
+		if err := versions.DeleteDocumentVersions(ctx, contract.ID); err != nil {
+			t.Fatalf("DeleteDocumentVersions() error = %v", err)
+		}
+		if err := documents.DeleteDocument(ctx, contract.ID); err != nil {
+			t.Fatalf("DeleteDocument() error = %v", err)
+		}
+		if got, err := documents.GetDocumentByID(ctx, contract.ID); err != nil || got != nil {
+			t.Errorf("GetDocumentByID() after delete = %+v, %v, want nil", got, err)
+		}
+		if log, _ := downloads.GetDocumentDownloads(ctx, 0); len(log) != 2 {
+			t.Errorf("downloads after delete = %+v, want them kept", log)
+		}
+	})
+
+	t.Run("Onboarding", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Engineering"}); err != nil {
+			t.Fatal(err)
+		}
+		depts, _ := repos.Departments.GetDepartments(ctx, "")
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Status: "active"}
+		if err := repos.Employees.CreateEmployee(ctx, &lea); err != nil {
+			t.Fatal(err)
+		}
+
+		templates := repos.OnboardingTemplates
+		it := OnboardingTemplate{Kind: checklistOnboarding, Name: "IT setup"}
+		if err := templates.CreateOnboardingTemplate(ctx, &it); err != nil || it.ID == 0 {
+			t.Fatalf("CreateOnboardingTemplate() = %+v, %v, want an ID", it, err)
+		}
+		eng := OnboardingTemplate{Kind: checklistOnboarding, Name: "Engineering", DepartmentID: depts[0].ID, Position: "Engineer"}
+		if err := templates.CreateOnboardingTemplate(ctx, &eng); err != nil {
+			t.Fatal(err)
+		}
+		list, err := templates.GetOnboardingTemplates(ctx)
+		if err != nil || len(list) != 2 || list[0].Name != "Engineering" || list[0].DepartmentID != depts[0].ID || list[1].DepartmentID != 0 {
+			t.Fatalf("GetOnboardingTemplates() = %+v, %v, want both by name", list, err)
+		}
+		it.Kind, it.Name, it.Position = checklistOffboarding, "IT accounts", "Developer"
+		if err := templates.UpdateOnboardingTemplate(ctx, &it); err != nil {
+			t.Fatalf("UpdateOnboardingTemplate() error = %v", err)
+		}
+		if got, err := templates.GetOnboardingTemplateByID(ctx, it.ID); err != nil || got == nil || got.Kind != checklistOffboarding || got.Name != "IT accounts" || got.Position != "Developer" {
+			t.Fatalf("GetOnboardingTemplateByID() = %+v, %v, want the update", got, err)
+		}
+
+		defs := repos.TemplateTasks
+		for _, d := range []OnboardingTemplateTask{
+			{TemplateID: it.ID, Title: "Laptop", Assignee: "IT", DueDays: 0},
+			{TemplateID: it.ID, Title: "Email account", Assignee: "IT", DueDays: -3},
+			{TemplateID: eng.ID, Title: "Repository access", DueDays: 2},
+		} {
+			if err := defs.CreateOnboardingTemplateTask(ctx, &d); err != nil {
+				t.Fatalf("CreateOnboardingTemplateTask() error = %v", err)
+			}
+		}
+		itTasks, err := defs.GetOnboardingTemplateTasks(ctx, it.ID)
+		if err != nil || len(itTasks) != 2 || itTasks[0].Title != "Email account" || itTasks[0].DueDays != -3 {
+			t.Fatalf("GetOnboardingTemplateTasks() = %+v, %v, want two in due order", itTasks, err)
+		}
+		if err := defs.DeleteOnboardingTemplateTask(ctx, itTasks[1].ID); err != nil {
+			t.Fatalf("DeleteOnboardingTemplateTask() error = %v", err)
+		}
+		if all, _ := defs.GetOnboardingTemplateTasks(ctx, 0); len(all) != 2 {
+			t.Errorf("template tasks after delete = %+v, want two", all)
+		}
+
+		tasks := repos.OnboardingTasks
+		due := time.Date(2023, 1, 12, 0, 0, 0, 0, time.UTC)
+		for _, task := range []OnboardingTask{
+			{EmployeeID: lea.ID, Kind: checklistOffboarding, TemplateID: it.ID, Title: "Laptop", Assignee: "IT", DueOn: due.AddDate(0, 0, 3)},
+			{EmployeeID: lea.ID, Kind: checklistOnboarding, TemplateID: it.ID, Title: "Email account", Assignee: "IT", DueOn: due},
+		} {
+			if err := tasks.CreateOnboardingTask(ctx, &task); err != nil {
+				t.Fatalf("CreateOnboardingTask() error = %v", err)
+			}
+		}
+		mine, err := tasks.GetOnboardingTasks(ctx, lea.ID)
+		if err != nil || len(mine) != 2 || mine[0].Title != "Email account" || mine[0].Kind != checklistOnboarding || mine[1].Kind != checklistOffboarding || !mine[0].DueOn.Equal(due) || !mine[0].DoneOn.IsZero() {
+			t.Fatalf("GetOnboardingTasks() = %+v, %v, want two in due order", mine, err)
+		}
+		done := mine[0]
+		done.DoneOn, done.DoneBy = due.AddDate(0, 0, 1), "hana"
+		if err := tasks.UpdateOnboardingTask(ctx, &done); err != nil {
+			t.Fatalf("UpdateOnboardingTask() error = %v", err)
+		}
+		if got, err := tasks.GetOnboardingTaskByID(ctx, done.ID); err != nil || got == nil || !got.DoneOn.Equal(due.AddDate(0, 0, 1)) || got.DoneBy != "hana" || got.EmployeeID != lea.ID {
+			t.Fatalf("GetOnboardingTaskByID() = %+v, %v, want it done", got, err)
+		}
+		if err := tasks.DeleteOnboardingTask(ctx, done.ID); err != nil {
+			t.Fatalf("DeleteOnboardingTask() error = %v", err)
+		}
+		if got, err := tasks.GetOnboardingTaskByID(ctx, done.ID); err != nil || got != nil {
+			t.Errorf("GetOnboardingTaskByID() after delete = %+v, %v, want nil", got, err)
+		}
+
+		if err := defs.DeleteOnboardingTemplateTask(ctx, itTasks[0].ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := templates.DeleteOnboardingTemplate(ctx, it.ID); err != nil {
+			t.Fatalf("DeleteOnboardingTemplate() error = %v", err)
+		}
+		if got, err := templates.GetOnboardingTemplateByID(ctx, it.ID); err != nil || got != nil {
+			t.Errorf("GetOnboardingTemplateByID() after delete = %+v, %v, want nil", got, err)
+		}
+		if left, _ := tasks.GetOnboardingTasks(ctx, 0); len(left) != 1 || left[0].TemplateID != it.ID {
+			t.Errorf("tasks after template delete = %+v, want them kept", left)
+		}
+	})
+	t.Run("Terminations", func(t *testing.T) {
+		repos := newRepos(t)
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+		for _, e := range []*Employee{&omar, &lea} {
+			if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		terminations := repos.Terminations
+		if got, err := terminations.GetTermination(ctx, omar.ID); err != nil || got != nil {
+			t.Fatalf("GetTermination() before = %+v, %v, want nil", got, err)
+		}
+		first := Termination{EmployeeID: omar.ID, LastDay: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), Reason: "resignation", RehireEligible: true, UnusedLeaveDays: 4.5, ProcessedBy: "hana"}
+		if err := terminations.CreateTermination(ctx, &first); err != nil || first.ID == 0 {
+			t.Fatalf("CreateTermination() = %+v, %v, want an ID", first, err)
+		}
+		second := Termination{EmployeeID: lea.ID, LastDay: time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), Reason: "redundancy", Severance: 3000, Deductions: 120.5}
+		if err := terminations.CreateTermination(ctx, &second); err != nil {
+			t.Fatal(err)
+		}
+		if err := terminations.CreateTermination(ctx, &Termination{EmployeeID: omar.ID, LastDay: first.LastDay, Reason: "other"}); err == nil {
+			t.Error("CreateTermination() of a terminated employee succeeded, want an error")
+		}
+
+		list, err := terminations.GetTerminations(ctx)
+		if err != nil || len(list) != 2 || list[0].EmployeeID != lea.ID || list[0].Severance != 3000 || list[0].Deductions != 120.5 {
+			t.Fatalf("GetTerminations() = %+v, %v, want both, latest first", list, err)
+		}
+		updated := Termination{EmployeeID: omar.ID, LastDay: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), Reason: "dismissal", Notes: "Policy breach", UnusedLeaveDays: 2}
+		if err := terminations.UpdateTermination(ctx, &updated); err != nil {
+			t.Fatalf("UpdateTermination() error = %v", err)
+		}
+		got, err := terminations.GetTermination(ctx, omar.ID)
+		if err != nil || got == nil || got.ID != first.ID || !got.LastDay.Equal(updated.LastDay) || got.Reason != "dismissal" || got.Notes != "Policy breach" ||
+			got.RehireEligible || got.UnusedLeaveDays != 2 || got.ProcessedBy != "hana" || got.CreatedAt.IsZero() {
+			t.Errorf("GetTermination() = %+v, %v, want the update with the processor kept", got, err)
+		}
+	})
+	t.Run("Equipment", func(t *testing.T) {
+		repos := newRepos(t)
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+		if err := repos.Employees.CreateEmployee(ctx, &omar); err != nil {
+			t.Fatal(err)
+		}
+
+		items := repos.Equipment
+		laptop := Equipment{Category: "laptop", Name: "ThinkPad", SerialNumber: "PF-123", Condition: "new"}
+		if err := items.CreateEquipment(ctx, &laptop); err != nil || laptop.ID == 0 {
+			t.Fatalf("CreateEquipment() = %+v, %v, want an ID", laptop, err)
+		}
+		for _, e := range []Equipment{
+			{Category: "badge", Name: "Badge 7", Condition: "good"},
+			{Category: "badge", Name: "Badge 8", Condition: "good"},
+		} {
+			if err := items.CreateEquipment(ctx, &e); err != nil {
+				t.Fatalf("CreateEquipment() without a serial number error = %v", err)
+			}
+		}
+		if err := items.CreateEquipment(ctx, &Equipment{Category: "phone", Name: "Pixel", SerialNumber: "PF-123", Condition: "new"}); err == nil {
+			t.Error("CreateEquipment() with a taken serial number succeeded, want an error")
+		}
+		list, err := items.GetEquipment(ctx, "")
+		if err != nil || len(list) != 3 || list[0].Name != "Badge 7" || list[2].SerialNumber != "PF-123" || list[0].SerialNumber != "" {
+			t.Fatalf("GetEquipment() = %+v, %v, want three by category and name", list, err)
+		}
+		if found, _ := items.GetEquipment(ctx, "pf-1"); len(found) != 1 || found[0].ID != laptop.ID {
+			t.Errorf("GetEquipment(serial) = %+v, want the laptop", found)
+		}
+		laptop.Condition, laptop.Notes = "damaged", "Cracked hinge"
+		if err := items.UpdateEquipment(ctx, &laptop); err != nil {
+			t.Fatalf("UpdateEquipment() error = %v", err)
+		}
+		if got, err := items.GetEquipmentByID(ctx, laptop.ID); err != nil || got == nil || got.Condition != "damaged" || got.Notes != "Cracked hinge" {
+			t.Fatalf("GetEquipmentByID() = %+v, %v, want the update", got, err)
+		}
+
+		assignments := repos.Assignments
+		first := EquipmentAssignment{EquipmentID: laptop.ID, EmployeeID: omar.ID, AssignedOn: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), ReturnedOn: time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC), AssignedBy: "hana", ReturnedBy: "it-desk"}
+		second := EquipmentAssignment{EquipmentID: laptop.ID, EmployeeID: omar.ID, AssignedOn: time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), AssignedBy: "hana"}
+		for _, a := range []*EquipmentAssignment{&first, &second} {
+			if err := assignments.CreateEquipmentAssignment(ctx, a); err != nil || a.ID == 0 {
+				t.Fatalf("CreateEquipmentAssignment() = %+v, %v, want an ID", a, err)
+			}
+		}
+		held, err := assignments.GetEquipmentAssignments(ctx, laptop.ID, 0)
+		if err != nil || len(held) != 2 || held[0].ID != second.ID || !held[0].ReturnedOn.IsZero() || !held[1].ReturnedOn.Equal(first.ReturnedOn) || held[1].ReturnedBy != "it-desk" {
+			t.Fatalf("GetEquipmentAssignments() = %+v, %v, want both, newest first", held, err)
+		}
+		second.ReturnedOn, second.ReturnedBy = time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), "hana"
+		if err := assignments.UpdateEquipmentAssignment(ctx, &second); err != nil {
+			t.Fatalf("UpdateEquipmentAssignment() error = %v", err)
+		}
+		if mine, _ := assignments.GetEquipmentAssignments(ctx, 0, omar.ID); len(mine) != 2 || !mine[0].ReturnedOn.Equal(second.ReturnedOn) || mine[0].AssignedBy != "hana" {
+			t.Errorf("GetEquipmentAssignments(employee) = %+v, want the return", mine)
+		}
+
+		conditions := repos.Conditions
+		for _, c := range []EquipmentCondition{
+			{EquipmentID: laptop.ID, Condition: "new", RecordedBy: "hana"},
+			{EquipmentID: laptop.ID, Condition: "damaged", Notes: "Cracked hinge", RecordedBy: "it-desk"},
+		} {
+			if err := conditions.CreateEquipmentCondition(ctx, &c); err != nil {
+				t.Fatalf("CreateEquipmentCondition() error = %v", err)
+			}
+		}
+		log, err := conditions.GetEquipmentConditions(ctx, laptop.ID)
+		if err != nil || len(log) != 2 || log[0].Condition != "damaged" || log[0].Notes != "Cracked hinge" || log[0].CreatedAt.IsZero() {
+			t.Fatalf("GetEquipmentConditions() = %+v, %v, want both, newest first", log, err)
+		}
+
+		if err := assignments.DeleteEquipmentAssignments(ctx, laptop.ID); err != nil {
+			t.Fatalf("DeleteEquipmentAssignments() error = %v", err)
+		}
+		if err := conditions.DeleteEquipmentConditions(ctx, laptop.ID); err != nil {
+			t.Fatalf("DeleteEquipmentConditions() error = %v", err)
+		}
+		if err := items.DeleteEquipment(ctx, laptop.ID); err != nil {
+			t.Fatalf("DeleteEquipment() error = %v", err)
+		}
+		if got, err := items.GetEquipmentByID(ctx, laptop.ID); err != nil || got != nil {
+			t.Errorf("GetEquipmentByID() after delete = %+v, %v, want nil", got, err)
+		}
+		if left, _ := assignments.GetEquipmentAssignments(ctx, 0, 0); len(left) != 0 {
+			t.Errorf("assignments after delete = %+v, want none", left)
+		}
+	})
+
+	t.Run("Expenses", func(t *testing.T) {
+		repos := newRepos(t)
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+		for _, e := range []*Employee{&omar, &lea} {
+			if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		claims := repos.ExpenseClaims
+		trip := ExpenseClaim{EmployeeID: omar.ID, ManagerID: lea.ID, Title: "Client visit", Currency: "EUR", Status: "draft"}
+		lunch := ExpenseClaim{EmployeeID: lea.ID, ManagerID: omar.ID, Title: "Team lunch", Currency: "USD", Status: "draft"}
+		for _, c := range []*ExpenseClaim{&trip, &lunch} {
+			if err := claims.CreateExpenseClaim(ctx, c); err != nil || c.ID == 0 {
+				t.Fatalf("CreateExpenseClaim() = %+v, %v, want an ID", c, err)
+			}
+		}
+		if got, err := claims.GetExpenseClaimByID(ctx, trip.ID); err != nil || got == nil || got.Title != "Client visit" || !got.SubmittedAt.IsZero() || !got.ApprovedAt.IsZero() {
+			t.Fatalf("GetExpenseClaimByID() = %+v, %v, want the draft", got, err)
+		}
+
+		approved := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)
+		trip.Status, trip.SubmittedAt = "approved", approved.Add(-48*time.Hour)
+		trip.ManagerApprovedBy, trip.ManagerApprovedAt = "lea@example.com", approved.Add(-24*time.Hour)
+		trip.ApprovedBy, trip.ApprovedAt = "fatima", approved
+		trip.ReimbursedOn = time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)
+		if err := claims.UpdateExpenseClaim(ctx, &trip); err != nil {
+			t.Fatalf("UpdateExpenseClaim() error = %v", err)
+		}
+		got, err := claims.GetExpenseClaimByID(ctx, trip.ID)
+		if err != nil || got == nil || got.Status != "approved" || !got.ApprovedAt.Equal(approved) || got.ManagerApprovedBy != "lea@example.com" || !got.ReimbursedOn.Equal(trip.ReimbursedOn) {
+			t.Fatalf("GetExpenseClaimByID() = %+v, %v, want the update", got, err)
+		}
+		if all, _ := claims.GetExpenseClaims(ctx, 0, ""); len(all) != 2 || all[0].ID != lunch.ID {
+			t.Errorf("GetExpenseClaims() = %+v, want both, newest first", all)
+		}
+		if mine, _ := claims.GetExpenseClaims(ctx, omar.ID, "approved"); len(mine) != 1 || mine[0].ID != trip.ID {
+			t.Errorf("GetExpenseClaims(omar, approved) = %+v, want the trip", mine)
+		}
+		if drafts, _ := claims.GetExpenseClaims(ctx, omar.ID, "draft"); len(drafts) != 0 {
+			t.Errorf("GetExpenseClaims(omar, draft) = %+v, want none", drafts)
+		}
+
+		items := repos.ExpenseItems
+		hotel := ExpenseItem{ClaimID: trip.ID, SpentOn: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), Category: "lodging", Amount: 240.5, ReceiptKey: "0123456789abcdef0123456789abcdef", ReceiptName: "hotel.pdf"}
+		taxi := ExpenseItem{ClaimID: trip.ID, SpentOn: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), Category: "transport", Description: "Airport", Amount: 35}
+		pizza := ExpenseItem{ClaimID: lunch.ID, SpentOn: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), Category: "meals", Amount: 80}
+		for _, i := range []*ExpenseItem{&hotel, &taxi, &pizza} {
+			if err := items.CreateExpenseItem(ctx, i); err != nil || i.ID == 0 {
+				t.Fatalf("CreateExpenseItem() = %+v, %v, want an ID", i, err)
+			}
+		}
+		lines, err := items.GetExpenseItems(ctx, trip.ID)
+		if err != nil || len(lines) != 2 || lines[0].ID != taxi.ID || lines[1].Amount != 240.5 || lines[1].ReceiptName != "hotel.pdf" {
+			t.Fatalf("GetExpenseItems() = %+v, %v, want both by date", lines, err)
+		}
+		if got, err := items.GetExpenseItemByID(ctx, hotel.ID); err != nil || got == nil || got.ReceiptKey != hotel.ReceiptKey || !got.SpentOn.Equal(hotel.SpentOn) {
+			t.Errorf("GetExpenseItemByID() = %+v, %v, want the hotel", got, err)
+		}
+		if err := items.DeleteExpenseItem(ctx, taxi.ID); err != nil {
+			t.Fatalf("DeleteExpenseItem() error = %v", err)
+		}
+		if all, _ := items.GetExpenseItems(ctx, 0); len(all) != 2 {
+			t.Errorf("GetExpenseItems(0) = %+v, want the hotel and the pizza", all)
+		}
+
+		if err := items.DeleteExpenseItems(ctx, lunch.ID); err != nil {
+			t.Fatalf("DeleteExpenseItems() error = %v", err)
+		}
+		if err := claims.DeleteExpenseClaim(ctx, lunch.ID); err != nil {
+			t.Fatalf("DeleteExpenseClaim() error = %v", err)
+		}
+		if got, err := claims.GetExpenseClaimByID(ctx, lunch.ID); err != nil || got != nil {
+			t.Errorf("GetExpenseClaimByID() after delete = %+v, %v, want nil", got, err)
+		}
+		if left, _ := items.GetExpenseItems(ctx, 0); len(left) != 1 || left[0].ID != hotel.ID {
+			t.Errorf("items after delete = %+v, want the hotel", left)
+		}
+	})
+
+	t.Run("Benefits", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Sales"}); err != nil {
+			t.Fatal(err)
+		}
+		departments, _ := repos.Departments.GetDepartments(ctx, "")
+		salesID := departments[0].ID
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+		for _, e := range []*Employee{&omar, &lea} {
+			if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		plans := repos.BenefitPlans
+		opens, closes := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+		health := BenefitPlan{Name: "Family Health", Kind: "health", Statuses: "active", MinTenureMonths: 3, CoversDependents: true, OpensOn: opens, ClosesOn: closes}
+		dental := BenefitPlan{Name: "Dental", Kind: "dental", DepartmentID: salesID, OpensOn: opens, ClosesOn: closes}
+		for _, p := range []*BenefitPlan{&health, &dental} {
+			if err := plans.CreateBenefitPlan(ctx, p); err != nil || p.ID == 0 {
+				t.Fatalf("CreateBenefitPlan() = %+v, %v, want an ID", p, err)
+			}
+		}
+		got, err := plans.GetBenefitPlanByID(ctx, health.ID)
+		if err != nil || got == nil || got.Statuses != "active" || got.MinTenureMonths != 3 || !got.CoversDependents || !got.OpensOn.Equal(opens) || !got.ClosesOn.Equal(closes) {
+			t.Fatalf("GetBenefitPlanByID() = %+v, %v, want the health plan", got, err)
+		}
+		dental.Description, dental.ClosesOn = "Two cleanings a year", closes.AddDate(0, 0, 7)
+		if err := plans.UpdateBenefitPlan(ctx, &dental); err != nil {
+			t.Fatalf("UpdateBenefitPlan() error = %v", err)
+		}
+		all, err := plans.GetBenefitPlans(ctx)
+		if err != nil || len(all) != 2 || all[1].ID != health.ID || all[0].Description != "Two cleanings a year" || all[0].DepartmentID != salesID || !all[0].ClosesOn.Equal(dental.ClosesOn) {
+			t.Fatalf("GetBenefitPlans() = %+v, %v, want both by kind", all, err)
+		}
+
+		dependents := repos.Dependents
+		sara := Dependent{EmployeeID: omar.ID, FirstName: "Sara", LastName: "Khalil", Relationship: "spouse", BirthDate: time.Date(1990, 5, 2, 0, 0, 0, 0, time.UTC)}
+		adam := Dependent{EmployeeID: omar.ID, FirstName: "Adam", LastName: "Khalil", Relationship: "child"}
+		nour := Dependent{EmployeeID: lea.ID, FirstName: "Nour", LastName: "Haddad", Relationship: "child"}
+		for _, d := range []*Dependent{&sara, &adam, &nour} {
+			if err := dependents.CreateDependent(ctx, d); err != nil || d.ID == 0 {
+				t.Fatalf("CreateDependent() = %+v, %v, want an ID", d, err)
+			}
+		}
+		if mine, _ := dependents.GetDependents(ctx, omar.ID); len(mine) != 2 {
+			t.Errorf("GetDependents(omar) = %+v, want Sara and Adam", mine)
+		}
+		if d, err := dependents.GetDependentByID(ctx, sara.ID); err != nil || d == nil || d.Relationship != "spouse" || !d.BirthDate.Equal(sara.BirthDate) {
+			t.Errorf("GetDependentByID() = %+v, %v, want Sara", d, err)
+		}
+		if d, _ := dependents.GetDependentByID(ctx, adam.ID); d == nil || !d.BirthDate.IsZero() {
+			t.Errorf("GetDependentByID() = %+v, want no birth date", d)
+		}
+
+		enrollments := repos.BenefitEnrollments
+		first := BenefitEnrollment{PlanID: health.ID, EmployeeID: omar.ID, DependentIDs: []int{sara.ID}, EffectiveFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EnrolledBy: "hana"}
+		lea1 := BenefitEnrollment{PlanID: dental.ID, EmployeeID: lea.ID, EffectiveFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}
+		for _, e := range []*BenefitEnrollment{&first, &lea1} {
+			if err := enrollments.CreateBenefitEnrollment(ctx, e); err != nil || e.ID == 0 {
+				t.Fatalf("CreateBenefitEnrollment() = %+v, %v, want an ID", e, err)
+			}
+		}
+		first.EffectiveTo = time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+		if err := enrollments.UpdateBenefitEnrollment(ctx, &first); err != nil {
+			t.Fatalf("UpdateBenefitEnrollment() error = %v", err)
+		}
+		second := BenefitEnrollment{PlanID: health.ID, EmployeeID: omar.ID, DependentIDs: []int{sara.ID, adam.ID}, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), EnrolledBy: "hana"}
+		if err := enrollments.CreateBenefitEnrollment(ctx, &second); err != nil {
+			t.Fatal(err)
+		}
+		e, err := enrollments.GetBenefitEnrollmentByID(ctx, first.ID)
+		if err != nil || e == nil || !e.EffectiveTo.Equal(first.EffectiveTo) || !slices.Equal(e.DependentIDs, []int{sara.ID}) || e.EnrolledBy != "hana" {
+			t.Fatalf("GetBenefitEnrollmentByID() = %+v, %v, want the ended enrollment", e, err)
+		}
+		history, err := enrollments.GetBenefitEnrollments(ctx, health.ID, omar.ID)
+		if err != nil || len(history) != 2 || history[0].ID != second.ID || !history[0].EffectiveTo.IsZero() || !slices.Equal(history[0].DependentIDs, []int{sara.ID, adam.ID}) {
+			t.Fatalf("GetBenefitEnrollments(health, omar) = %+v, %v, want both, newest first", history, err)
+		}
+		if inPlan, _ := enrollments.GetBenefitEnrollments(ctx, dental.ID, 0); len(inPlan) != 1 || len(inPlan[0].DependentIDs) != 0 {
+			t.Errorf("GetBenefitEnrollments(dental) = %+v, want Lea without dependents", inPlan)
+		}
+		if everyone, _ := enrollments.GetBenefitEnrollments(ctx, 0, 0); len(everyone) != 3 {
+			t.Errorf("GetBenefitEnrollments() = %+v, want all three", everyone)
+		}
+
+		if err := dependents.DeleteDependent(ctx, nour.ID); err != nil {
+			t.Fatalf("DeleteDependent() error = %v", err)
+		}
+		if left, _ := dependents.GetDependents(ctx, 0); len(left) != 2 {
+			t.Errorf("GetDependents() after delete = %+v, want Omar's", left)
+		}
+		plan := BenefitPlan{Name: "Pension", Kind: "pension", OpensOn: opens, ClosesOn: closes}
+		if err := plans.CreateBenefitPlan(ctx, &plan); err != nil {
+			t.Fatal(err)
+		}
+		if err := plans.DeleteBenefitPlan(ctx, plan.ID); err != nil {
+			t.Fatalf("DeleteBenefitPlan() error = %v", err)
+		}
+		if got, err := plans.GetBenefitPlanByID(ctx, plan.ID); err != nil || got != nil {
+			t.Errorf("GetBenefitPlanByID() after delete = %+v, %v, want nil", got, err)
+		}
+	})
+	t.Run("Shifts", func(t *testing.T) {
+		repos := newRepos(t)
+		if err := repos.Departments.CreateDepartment(ctx, &Department{Name: "Support"}); err != nil {
+			t.Fatal(err)
+		}
+		departments, _ := repos.Departments.GetDepartments(ctx, "")
+		supportID := departments[0].ID
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active", DepartmentID: supportID}
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active", DepartmentID: supportID}
+		for _, e := range []*Employee{&omar, &lea} {
+			if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		templates := repos.ShiftTemplates
+		late := ShiftTemplate{Name: "Late", StartTime: "14:00", EndTime: "22:00", BreakMinutes: 30}
+		early := ShiftTemplate{Name: "Early", DepartmentID: supportID, StartTime: "06:00", EndTime: "14:00"}
+		for _, st := range []*ShiftTemplate{&late, &early} {
+			if err := templates.CreateShiftTemplate(ctx, st); err != nil || st.ID == 0 {
+				t.Fatalf("CreateShiftTemplate() = %+v, %v, want an ID", st, err)
+			}
+		}
+		early.BreakMinutes = 15
+		if err := templates.UpdateShiftTemplate(ctx, &early); err != nil {
+			t.Fatalf("UpdateShiftTemplate() error = %v", err)
+		}
+		all, err := templates.GetShiftTemplates(ctx)
+		if err != nil || len(all) != 2 || all[0].ID != early.ID || all[0].BreakMinutes != 15 || all[0].DepartmentID != supportID || all[1].DepartmentID != 0 {
+			t.Fatalf("GetShiftTemplates() = %+v, %v, want Early before Late", all, err)
+		}
+		if got, err := templates.GetShiftTemplateByID(ctx, late.ID); err != nil || got == nil || got.EndTime != "22:00" || got.BreakMinutes != 30 {
+			t.Errorf("GetShiftTemplateByID() = %+v, %v, want Late", got, err)
+		}
+
+		shifts := repos.Shifts
+		monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+		first := Shift{DepartmentID: supportID, EmployeeID: omar.ID, Name: "Late", Date: monday, StartTime: "14:00", EndTime: "22:00", BreakMinutes: 30}
+		second := Shift{DepartmentID: supportID, EmployeeID: lea.ID, Name: "Early", Date: monday, StartTime: "06:00", EndTime: "14:00"}
+		next := Shift{DepartmentID: supportID, EmployeeID: omar.ID, Name: "Early", Date: monday.AddDate(0, 0, 7), StartTime: "06:00", EndTime: "14:00"}
+		for _, s := range []*Shift{&first, &second, &next} {
+			if err := shifts.CreateShift(ctx, s); err != nil || s.ID == 0 {
+				t.Fatalf("CreateShift() = %+v, %v, want an ID", s, err)
+			}
+		}
+		week, err := shifts.GetShifts(ctx, supportID, 0, monday, monday.AddDate(0, 0, 7))
+		if err != nil || len(week) != 2 || week[0].ID != second.ID || week[1].ID != first.ID || week[1].BreakMinutes != 30 || !week[1].Date.Equal(monday) || week[1].Published() {
+			t.Fatalf("GetShifts(week) = %+v, %v, want the early shift before the late one", week, err)
+		}
+		if mine, _ := shifts.GetShifts(ctx, 0, omar.ID, monday, monday.AddDate(0, 1, 0)); len(mine) != 2 {
+			t.Errorf("GetShifts(omar) = %+v, want both of his", mine)
+		}
+		published := time.Date(2025, 5, 30, 9, 0, 0, 0, time.UTC)
+		first.EmployeeID, first.Date, first.PublishedAt = lea.ID, monday.AddDate(0, 0, 1), published
+		if err := shifts.UpdateShift(ctx, &first); err != nil {
+			t.Fatalf("UpdateShift() error = %v", err)
+		}
+		got, err := shifts.GetShiftByID(ctx, first.ID)
+		if err != nil || got == nil || got.EmployeeID != lea.ID || !got.Date.Equal(first.Date) || !got.PublishedAt.Equal(published) || got.Name != "Late" {
+			t.Fatalf("GetShiftByID() = %+v, %v, want the moved, published shift", got, err)
+		}
+
+		if err := shifts.DeleteShift(ctx, second.ID); err != nil {
+			t.Fatalf("DeleteShift() error = %v", err)
+		}
+		if left, _ := shifts.GetShifts(ctx, 0, lea.ID, monday, monday.AddDate(0, 0, 7)); len(left) != 1 || left[0].ID != first.ID {
+			t.Errorf("GetShifts(lea) after delete = %+v, want the moved shift", left)
+		}
+		if err := templates.DeleteShiftTemplate(ctx, late.ID); err != nil {
+			t.Fatalf("DeleteShiftTemplate() error = %v", err)
+		}
+		if got, err := templates.GetShiftTemplateByID(ctx, late.ID); err != nil || got != nil {
+			t.Errorf("GetShiftTemplateByID() after delete = %+v, %v, want nil", got, err)
+		}
+	})
+	t.Run("Overtime", func(t *testing.T) {
+		repos := newRepos(t)
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+		for _, e := range []*Employee{&omar, &lea} {
+			if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		overtime := repos.Overtime
+		march := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+		first := OvertimeRequest{EmployeeID: omar.ID, Date: march, Hours: 2.5, DayKind: "weekday", Multiplier: 1.5, Compensation: "pay", Reason: "Stocktake", Status: "pending"}
+		later := OvertimeRequest{EmployeeID: omar.ID, Date: march.AddDate(0, 0, 5), Hours: 4, DayKind: "weekend", Multiplier: 2, Compensation: "toil", Status: "pending"}
+		leas := OvertimeRequest{EmployeeID: lea.ID, Date: march, Hours: 1, DayKind: "weekday", Multiplier: 1.5, Compensation: "pay", Status: "pending"}
+		for _, o := range []*OvertimeRequest{&first, &later, &leas} {
+			if err := overtime.CreateOvertimeRequest(ctx, o); err != nil || o.ID == 0 {
+				t.Fatalf("CreateOvertimeRequest() = %+v, %v, want an ID", o, err)
+			}
+		}
+		decided := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+		first.Status, first.DecidedBy, first.DecidedAt = "approved", "hana", decided
+		if err := overtime.UpdateOvertimeRequest(ctx, &first); err != nil {
+			t.Fatalf("UpdateOvertimeRequest() error = %v", err)
+		}
+		got, err := overtime.GetOvertimeRequestByID(ctx, first.ID)
+		if err != nil || got == nil || got.Hours != 2.5 || got.Multiplier != 1.5 || got.Reason != "Stocktake" || got.DecidedBy != "hana" || !got.DecidedAt.Equal(decided) || !got.Date.Equal(march) {
+			t.Fatalf("GetOvertimeRequestByID() = %+v, %v, want the approved request", got, err)
+		}
+		mine, err := overtime.GetOvertimeRequests(ctx, omar.ID, "")
+		if err != nil || len(mine) != 2 || mine[0].ID != later.ID || mine[0].DayKind != "weekend" || mine[0].Compensation != "toil" || !mine[0].DecidedAt.IsZero() {
+			t.Fatalf("GetOvertimeRequests(omar) = %+v, %v, want the latest worked first", mine, err)
+		}
+		if approved, _ := overtime.GetOvertimeRequests(ctx, 0, "approved"); len(approved) != 1 || approved[0].ID != first.ID {
+			t.Errorf("GetOvertimeRequests(approved) = %+v, want the first", approved)
+		}
+		if err := overtime.DeleteOvertimeRequest(ctx, leas.ID); err != nil {
+			t.Fatalf("DeleteOvertimeRequest() error = %v", err)
+		}
+		if all, _ := overtime.GetOvertimeRequests(ctx, 0, ""); len(all) != 2 {
+			t.Errorf("GetOvertimeRequests() after delete = %+v, want Omar's", all)
+		}
+	})
+
+	t.Run("ContactChanges", func(t *testing.T) {
+		repos := newRepos(t)
+		omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+		lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+		for _, e := range []*Employee{&omar, &lea} {
+			if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		changes := repos.ContactChanges
+		first := ContactChange{EmployeeID: omar.ID, Phone: "+961 1 234 567", Address: "Hamra Street, Beirut", EmergencyName: "Sara Khalil", EmergencyPhone: "+961 3 765 432", Status: "pending"}
+		later := ContactChange{EmployeeID: omar.ID, Phone: "+961 1 999 999", Status: "pending"}
+		leas := ContactChange{EmployeeID: lea.ID, Address: "Gemmayze, Beirut", Status: "pending"}
+		for _, c := range []*ContactChange{&first, &later, &leas} {
+			if err := changes.CreateContactChange(ctx, c); err != nil || c.ID == 0 {
+				t.Fatalf("CreateContactChange() = %+v, %v, want an ID", c, err)
+			}
+		}
+		decided := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+		first.Status, first.DecidedBy, first.DecidedAt = "approved", "hana", decided
+		first.Phone = "ignored"
+		if err := changes.UpdateContactChange(ctx, &first); err != nil {
+			t.Fatalf("UpdateContactChange() error = %v", err)
+		}
+		got, err := changes.GetContactChangeByID(ctx, first.ID)
+		if err != nil || got == nil || got.Phone != "+961 1 234 567" || got.EmergencyName != "Sara Khalil" || got.DecidedBy != "hana" || !got.DecidedAt.Equal(decided) || got.CreatedAt.IsZero() {
+			t.Fatalf("GetContactChangeByID() = %+v, %v, want the approved change with its details kept", got, err)
+		}
+		if got, err := changes.GetContactChangeByID(ctx, 999); err != nil || got != nil {
+			t.Errorf("GetContactChangeByID(999) = %+v, %v, want nil", got, err)
+		}
+		mine, err := changes.GetContactChanges(ctx, omar.ID, "")
+		if err != nil || len(mine) != 2 || mine[0].ID != later.ID || !mine[0].DecidedAt.IsZero() {
+			t.Fatalf("GetContactChanges(omar) = %+v, %v, want the latest first", mine, err)
+		}
+		if pending, _ := changes.GetContactChanges(ctx, 0, "pending"); len(pending) != 2 || pending[0].ID != leas.ID {
+			t.Errorf("GetContactChanges(pending) = %+v, want Lea's and Omar's later one", pending)
+		}
+	})
+}
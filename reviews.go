@@ -0,0 +1,976 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Review cycle statuses. Questions can change only while a cycle is a
+// draft; launching it opens the forms, and closing it locks the forms and
+// the final ratings.
+const (
+	reviewDraft  = "draft"
+	reviewOpen   = "open"
+	reviewClosed = "closed"
+)
+
+// reviewKinds are the assessment forms of a cycle, in the order they are
+// shown.
+var reviewKinds = []string{"self", "manager", "peer"}
+
+// ratingLabels names the final ratings, indexed by rating.
+var ratingLabels = []string{"", "Unsatisfactory", "Needs Improvement", "Meets Expectations", "Exceeds Expectations", "Outstanding"}
+
+const (
+	minRating = 1
+	maxRating = 5
+	minScale  = 2
+	maxScale  = 10
+)
+
+func (c *ReviewCycle) validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("Name is required")
+	}
+	if c.SelfDue.IsZero() || c.ManagerDue.IsZero() || c.PeerDue.IsZero() {
+		return errors.New("Every deadline is required")
+	}
+	if c.ManagerDue.Before(c.SelfDue) {
+		return errors.New("Manager deadline can't be before the self-assessment deadline")
+	}
+	return nil
+}
+
+// Covers reports whether emp is reviewed in the cycle.
+func (c ReviewCycle) Covers(emp Employee) bool {
+	return c.DepartmentID == 0 || c.DepartmentID == emp.DepartmentID
+}
+
+// DueFor returns the deadline of the cycle's forms of the given kind.
+func (c ReviewCycle) DueFor(kind string) time.Time {
+	switch kind {
+	case "manager":
+		return c.ManagerDue
+	case "peer":
+		return c.PeerDue
+	}
+	return c.SelfDue
+}
+
+func (q *ReviewQuestion) validate() error {
+	if strings.TrimSpace(q.Text) == "" {
+		return errors.New("Question is required")
+	}
+	if q.Scale < minScale || q.Scale > maxScale {
+		return errors.New("Scale must be between 2 and 10")
+	}
+	return nil
+}
+
+// Overdue reports whether the form is still pending after its deadline.
+func (a ReviewAssessment) Overdue(now time.Time) bool {
+	return a.Status == "pending" && civilDate(now).After(civilDate(a.DueDate))
+}
+
+// Score returns the average rating of the assessment converted to the 1 to
+// 5 scale of final ratings, so that questions on different scales can be
+// averaged together. It returns 0 when nothing was rated.
+func (a ReviewAssessment) Score(questions []ReviewQuestion) float64 {
+	var sum float64
+	var n int
+	for _, q := range questions {
+		r, ok := a.Ratings[q.ID]
+		if !ok || q.Scale < minScale {
+			continue
+		}
+		sum += minRating + float64(maxRating-minRating)*float64(r-1)/float64(q.Scale-1)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// CalibrationRow is one employee on the calibration page: the average
+// score of each kind of submitted form, the rating they suggest and the
+// final rating, if one was set.
+type CalibrationRow struct {
+	Employee Employee
+	Scores   map[string]float64 // by kind, missing when no form was submitted
+	Pending  int                // forms not submitted yet
+	Proposed int
+	Final    EmployeeRating // Rating is 0 until set
+}
+
+// calibrate builds the calibration rows of the employees with forms in the
+// cycle. The proposed rating follows the managers' score, else the peers',
+// else the employee's own.
+func calibrate(employees []Employee, questions []ReviewQuestion, assessments []ReviewAssessment, ratings []EmployeeRating) []CalibrationRow {
+	type total struct {
+		sum float64
+		n   int
+	}
+	totals := make(map[int]map[string]*total)
+	pending := make(map[int]int)
+	for _, a := range assessments {
+		if totals[a.EmployeeID] == nil {
+			totals[a.EmployeeID] = make(map[string]*total)
+		}
+		if a.Status != "submitted" {
+			pending[a.EmployeeID]++
+			continue
+		}
+		t := totals[a.EmployeeID][a.Kind]
+		if t == nil {
+			t = &total{}
+			totals[a.EmployeeID][a.Kind] = t
+		}
+		if s := a.Score(questions); s > 0 {
+			t.sum += s
+			t.n++
+		}
+	}
+	finals := make(map[int]EmployeeRating, len(ratings))
+	for _, er := range ratings {
+		finals[er.EmployeeID] = er
+	}
+
+	var rows []CalibrationRow
+	for _, emp := range employees {
+		kinds, ok := totals[emp.ID]
+		if !ok {
+			continue
+		}
+		row := CalibrationRow{Employee: emp, Scores: make(map[string]float64), Pending: pending[emp.ID], Final: finals[emp.ID]}
+		for kind, t := range kinds {
+			if t.n > 0 {
+				row.Scores[kind] = t.sum / float64(t.n)
+			}
+		}
+		for _, kind := range []string{"manager", "peer", "self"} {
+			if s, ok := row.Scores[kind]; ok {
+				row.Proposed = min(max(int(math.Round(s)), minRating), maxRating)
+				break
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// RatingCount is one bar of the distribution of final ratings.
+type RatingCount struct {
+	Rating  int
+	Label   string
+	Count   int
+	Percent float64
+}
+
+// ratingDistribution counts the final ratings of rows, from the lowest
+// rating to the highest.
+func ratingDistribution(rows []CalibrationRow) []RatingCount {
+	counts := make([]RatingCount, 0, maxRating)
+	var rated int
+	for r := minRating; r <= maxRating; r++ {
+		c := RatingCount{Rating: r, Label: ratingLabels[r]}
+		for _, row := range rows {
+			if row.Final.Rating == r {
+				c.Count++
+			}
+		}
+		rated += c.Count
+		counts = append(counts, c)
+	}
+	for i := range counts {
+		if rated > 0 {
+			counts[i].Percent = 100 * float64(counts[i].Count) / float64(rated)
+		}
+	}
+	return counts
+}
+
+// reviewCycle fetches the cycle named by the request's id path value and
+// writes the error response when there is none.
+func (app *App) reviewCycle(w http.ResponseWriter, r *http.Request) (*ReviewCycle, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	cycle, err := app.ReviewCycleRepository.GetReviewCycleByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch review cycle", err)
+		return nil, false
+	}
+	if cycle == nil {
+		app.clientError(w, r, http.StatusNotFound, "Review cycle not found")
+		return nil, false
+	}
+	return cycle, true
+}
+
+func (app *App) handleReviewCycles(w http.ResponseWriter, r *http.Request) {
+	cycles, err := app.ReviewCycleRepository.GetReviewCycles(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch review cycles", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage":  "reviews",
+		"Cycles":      cycles,
+		"Departments": departments,
+	}
+	app.render(w, r, "reviews.html", "", data)
+}
+
+// reviewCycleForm reads the fields of the add and update review cycle
+// forms. Deadlines that don't parse are left zero for validate to report.
+func reviewCycleForm(r *http.Request) ReviewCycle {
+	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	selfDue, _ := time.Parse("2006-01-02", r.FormValue("self_due"))
+	managerDue, _ := time.Parse("2006-01-02", r.FormValue("manager_due"))
+	peerDue, _ := time.Parse("2006-01-02", r.FormValue("peer_due"))
+	return ReviewCycle{
+		Name:         strings.TrimSpace(r.FormValue("name")),
+		DepartmentID: deptID,
+		SelfDue:      selfDue,
+		ManagerDue:   managerDue,
+		PeerDue:      peerDue,
+	}
+}
+
+func (app *App) handleAddReviewCycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":  "reviews",
+			"Departments": departments,
+			"Cycle":       ReviewCycle{Status: reviewDraft},
+		}
+		app.render(w, r, "add_review_cycle.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	cycle := reviewCycleForm(r)
+	cycle.Status = reviewDraft
+	if err := cycle.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.ReviewCycleRepository.CreateReviewCycle(r.Context(), &cycle); err != nil {
+		app.serverError(w, r, "Failed to add review cycle", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/reviews")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleUpdateReviewCycle renames a cycle and moves its deadlines, which
+// also moves the deadlines of its pending forms. The department is fixed
+// once the cycle is launched, since the forms were made for it.
+func (app *App) handleUpdateReviewCycle(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":  "reviews",
+			"Departments": departments,
+			"Cycle":       cycle,
+		}
+		app.render(w, r, "update_review_cycle.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	if cycle.Status == reviewClosed {
+		app.clientError(w, r, http.StatusConflict, "Review cycle is closed")
+		return
+	}
+	updated := reviewCycleForm(r)
+	updated.ID, updated.Status = cycle.ID, cycle.Status
+	if cycle.Status != reviewDraft {
+		updated.DepartmentID = cycle.DepartmentID
+	}
+	if err := updated.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.ReviewCycleRepository.UpdateReviewCycle(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update review cycle", err)
+		return
+	}
+
+	assessments, err := app.AssessmentRepository.GetReviewAssessments(r.Context(), cycle.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch assessments", err)
+		return
+	}
+	for _, a := range assessments {
+		if a.Status != "pending" || a.DueDate.Equal(updated.DueFor(a.Kind)) {
+			continue
+		}
+		a.DueDate = updated.DueFor(a.Kind)
+		if err := app.AssessmentRepository.UpdateReviewAssessment(r.Context(), &a); err != nil {
+			app.serverError(w, r, "Failed to update assessment", err)
+			return
+		}
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteReviewCycle deletes a draft cycle with its questionnaire.
+// Launched cycles have forms and ratings and are kept.
+func (app *App) handleDeleteReviewCycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	cycle, err := app.ReviewCycleRepository.GetReviewCycleByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch review cycle", err)
+		return
+	}
+	if cycle == nil {
+		app.clientError(w, r, http.StatusNotFound, "Review cycle not found")
+		return
+	}
+	if cycle.Status != reviewDraft {
+		app.clientError(w, r, http.StatusConflict, "Only draft review cycles can be deleted")
+		return
+	}
+
+	questions, err := app.QuestionRepository.GetReviewQuestions(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch questions", err)
+		return
+	}
+	for _, q := range questions {
+		if err := app.QuestionRepository.DeleteReviewQuestion(r.Context(), q.ID); err != nil {
+			app.serverError(w, r, "Failed to delete question", err)
+			return
+		}
+	}
+	if err := app.ReviewCycleRepository.DeleteReviewCycle(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete review cycle", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/reviews")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleReviewCycle(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	questions, err := app.QuestionRepository.GetReviewQuestions(r.Context(), cycle.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch questions", err)
+		return
+	}
+	assessments, err := app.AssessmentRepository.GetReviewAssessments(r.Context(), cycle.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch assessments", err)
+		return
+	}
+	employees, byID, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	var reviewees []Employee
+	for _, e := range employees {
+		if cycle.Covers(e) {
+			reviewees = append(reviewees, e)
+		}
+	}
+	var submitted int
+	for _, a := range assessments {
+		if a.Status == "submitted" {
+			submitted++
+		}
+	}
+
+	data := map[string]any{
+		"ActivePage":   "reviews",
+		"Cycle":        cycle,
+		"Questions":    questions,
+		"Assessments":  assessments,
+		"Submitted":    submitted,
+		"Employees":    byID,
+		"AllEmployees": employees,
+		"Reviewees":    reviewees,
+		"Departments":  departments,
+		"Now":          time.Now(),
+	}
+	app.render(w, r, "review_cycle.html", "", data)
+}
+
+func (app *App) handleAddReviewQuestion(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	if cycle.Status != reviewDraft {
+		app.clientError(w, r, http.StatusConflict, "Questions can't change after the cycle is launched")
+		return
+	}
+	scale, _ := strconv.Atoi(r.FormValue("scale"))
+	question := ReviewQuestion{CycleID: cycle.ID, Text: strings.TrimSpace(r.FormValue("text")), Scale: scale}
+	if err := question.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.QuestionRepository.CreateReviewQuestion(r.Context(), &question); err != nil {
+		app.serverError(w, r, "Failed to add question", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteReviewQuestion(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	if cycle.Status != reviewDraft {
+		app.clientError(w, r, http.StatusConflict, "Questions can't change after the cycle is launched")
+		return
+	}
+	if err := app.QuestionRepository.DeleteReviewQuestion(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete question", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleLaunchReviewCycle opens a draft cycle and hands every active
+// employee it covers a self-assessment form. Manager and peer forms are
+// assigned on the cycle page afterwards.
+func (app *App) handleLaunchReviewCycle(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	if cycle.Status != reviewDraft {
+		app.clientError(w, r, http.StatusConflict, "Review cycle was already launched")
+		return
+	}
+	questions, err := app.QuestionRepository.GetReviewQuestions(r.Context(), cycle.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch questions", err)
+		return
+	}
+	if len(questions) == 0 {
+		app.clientError(w, r, http.StatusConflict, "Add at least one question before launching")
+		return
+	}
+	employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+
+	var reviewees []Employee
+	for _, e := range employees {
+		if cycle.Covers(e) && (e.Status == "" || e.Status == "active") {
+			reviewees = append(reviewees, e)
+		}
+	}
+	if len(reviewees) == 0 {
+		app.clientError(w, r, http.StatusConflict, "No active employees to review")
+		return
+	}
+	for _, e := range reviewees {
+		a := ReviewAssessment{
+			CycleID:    cycle.ID,
+			EmployeeID: e.ID,
+			ReviewerID: e.ID,
+			Kind:       "self",
+			DueDate:    cycle.SelfDue,
+			Status:     "pending",
+		}
+		if err := app.AssessmentRepository.CreateReviewAssessment(r.Context(), &a); err != nil {
+			app.serverError(w, r, "Failed to create assessments", err)
+			return
+		}
+	}
+	cycle.Status = reviewOpen
+	if err := app.ReviewCycleRepository.UpdateReviewCycle(r.Context(), cycle); err != nil {
+		app.serverError(w, r, "Failed to launch review cycle", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleCloseReviewCycle(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	if cycle.Status != reviewOpen {
+		app.clientError(w, r, http.StatusConflict, "Review cycle is not open")
+		return
+	}
+	cycle.Status = reviewClosed
+	if err := app.ReviewCycleRepository.UpdateReviewCycle(r.Context(), cycle); err != nil {
+		app.serverError(w, r, "Failed to close review cycle", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleAssignReviewer adds a manager or peer form to an open cycle.
+func (app *App) handleAssignReviewer(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	if cycle.Status != reviewOpen {
+		app.clientError(w, r, http.StatusConflict, "Review cycle is not open")
+		return
+	}
+	employeeID, err1 := strconv.Atoi(r.FormValue("employee_id"))
+	reviewerID, err2 := strconv.Atoi(r.FormValue("reviewer_id"))
+	if err1 != nil || err2 != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	kind := r.FormValue("kind")
+	if kind != "manager" && kind != "peer" {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid reviewer type")
+		return
+	}
+	if employeeID == reviewerID {
+		app.clientError(w, r, http.StatusBadRequest, "Employees can't review themselves here")
+		return
+	}
+
+	for _, id := range []int{employeeID, reviewerID} {
+		emp, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), id)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employee", err)
+			return
+		}
+		if emp == nil {
+			app.clientError(w, r, http.StatusNotFound, "Employee not found")
+			return
+		}
+		if id == employeeID && !cycle.Covers(*emp) {
+			app.clientError(w, r, http.StatusBadRequest, "Employee is not part of this review cycle")
+			return
+		}
+	}
+	existing, err := app.AssessmentRepository.GetReviewAssessments(r.Context(), cycle.ID, employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch assessments", err)
+		return
+	}
+	for _, a := range existing {
+		if a.ReviewerID == reviewerID && a.Kind == kind {
+			app.clientError(w, r, http.StatusConflict, "Reviewer is already assigned")
+			return
+		}
+	}
+
+	a := ReviewAssessment{
+		CycleID:    cycle.ID,
+		EmployeeID: employeeID,
+		ReviewerID: reviewerID,
+		Kind:       kind,
+		DueDate:    cycle.DueFor(kind),
+		Status:     "pending",
+	}
+	if err := app.AssessmentRepository.CreateReviewAssessment(r.Context(), &a); err != nil {
+		app.serverError(w, r, "Failed to assign reviewer", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// AssessmentAnswer is one question on the assessment form with the
+// rating given so far.
+type AssessmentAnswer struct {
+	Question ReviewQuestion
+	Rating   int
+	Options  []int
+}
+
+// handleReviewAssessment shows an assessment form and, on POST, submits
+// it. Forms can be submitted, and resubmitted, for as long as the cycle is
+// open; late ones are flagged as overdue but still accepted.
+func (app *App) handleReviewAssessment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	assessment, err := app.AssessmentRepository.GetReviewAssessmentByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch assessment", err)
+		return
+	}
+	if assessment == nil {
+		app.clientError(w, r, http.StatusNotFound, "Assessment not found")
+		return
+	}
+	cycle, err := app.ReviewCycleRepository.GetReviewCycleByID(r.Context(), assessment.CycleID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch review cycle", err)
+		return
+	}
+	if cycle == nil {
+		app.clientError(w, r, http.StatusNotFound, "Review cycle not found")
+		return
+	}
+	questions, err := app.QuestionRepository.GetReviewQuestions(r.Context(), cycle.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch questions", err)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		_, employees, err := app.employeesByID(r.Context())
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employees", err)
+			return
+		}
+		answers := make([]AssessmentAnswer, len(questions))
+		for i, q := range questions {
+			answers[i] = AssessmentAnswer{Question: q, Rating: assessment.Ratings[q.ID]}
+			for n := 1; n <= q.Scale; n++ {
+				answers[i].Options = append(answers[i].Options, n)
+			}
+		}
+		data := map[string]any{
+			"ActivePage": "reviews",
+			"Cycle":      cycle,
+			"Assessment": assessment,
+			"Answers":    answers,
+			"Employees":  employees,
+			"Overdue":    assessment.Overdue(time.Now()),
+		}
+		app.render(w, r, "review_assessment.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	if cycle.Status != reviewOpen {
+		app.clientError(w, r, http.StatusConflict, "Review cycle is not open")
+		return
+	}
+	ratings := make(map[int]int, len(questions))
+	for _, q := range questions {
+		n, err := strconv.Atoi(r.FormValue(fmt.Sprintf("q%d", q.ID)))
+		if err != nil || n < 1 || n > q.Scale {
+			app.clientError(w, r, http.StatusBadRequest, "Rate every question")
+			return
+		}
+		ratings[q.ID] = n
+	}
+	assessment.Ratings = ratings
+	assessment.Comment = strings.TrimSpace(r.FormValue("comment"))
+	assessment.Status = "submitted"
+	assessment.SubmittedAt = time.Now().UTC().Truncate(time.Second)
+	if err := app.AssessmentRepository.UpdateReviewAssessment(r.Context(), assessment); err != nil {
+		app.serverError(w, r, "Failed to submit assessment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/reviews/cycles/%d", cycle.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// calibrationFor returns the calibration rows of the cycle, limited to one
+// department unless deptID is 0.
+func (app *App) calibrationFor(ctx context.Context, cycle *ReviewCycle, deptID int) ([]CalibrationRow, error) {
+	employees, err := app.EmployeeRepository.GetEmployees(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if deptID != 0 {
+		var inDept []Employee
+		for _, e := range employees {
+			if e.DepartmentID == deptID {
+				inDept = append(inDept, e)
+			}
+		}
+		employees = inDept
+	}
+	questions, err := app.QuestionRepository.GetReviewQuestions(ctx, cycle.ID)
+	if err != nil {
+		return nil, err
+	}
+	assessments, err := app.AssessmentRepository.GetReviewAssessments(ctx, cycle.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := app.RatingRepository.GetEmployeeRatings(ctx, cycle.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return calibrate(employees, questions, assessments, ratings), nil
+}
+
+// handleCalibration shows the scores of a cycle side by side, one
+// department at a time, for the final ratings to be settled.
+func (app *App) handleCalibration(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	deptID := cycle.DepartmentID
+	if s := r.URL.Query().Get("department"); s != "" {
+		deptID, _ = strconv.Atoi(s)
+	}
+	rows, err := app.calibrationFor(r.Context(), cycle, deptID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch calibration", err)
+		return
+	}
+	departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage":   "reviews",
+		"Cycle":        cycle,
+		"Rows":         rows,
+		"Distribution": ratingDistribution(rows),
+		"Departments":  departments,
+		"DepartmentID": deptID,
+		"Kinds":        reviewKinds,
+		"RatingLabels": ratingLabels,
+	}
+	app.render(w, r, "calibration.html", "", data)
+}
+
+// handleSaveRating stores the final rating of one employee. Ratings are
+// settled while the cycle is open and locked once it is closed.
+func (app *App) handleSaveRating(w http.ResponseWriter, r *http.Request) {
+	cycle, ok := app.reviewCycle(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	if cycle.Status != reviewOpen {
+		app.clientError(w, r, http.StatusConflict, "Review cycle is not open")
+		return
+	}
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	rating, err := strconv.Atoi(r.FormValue("rating"))
+	if err != nil || rating < minRating || rating > maxRating {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid rating")
+		return
+	}
+	assessments, err := app.AssessmentRepository.GetReviewAssessments(r.Context(), cycle.ID, employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch assessments", err)
+		return
+	}
+	if len(assessments) == 0 {
+		app.clientError(w, r, http.StatusBadRequest, "Employee is not part of this review cycle")
+		return
+	}
+
+	er := EmployeeRating{
+		EmployeeID: employeeID,
+		CycleID:    cycle.ID,
+		Rating:     rating,
+		Comment:    strings.TrimSpace(r.FormValue("comment")),
+	}
+	if err := app.RatingRepository.SaveEmployeeRating(r.Context(), &er); err != nil {
+		app.serverError(w, r, "Failed to save rating", err)
+		return
+	}
+	target := fmt.Sprintf("/reviews/cycles/%d/calibration", cycle.ID)
+	if d := r.FormValue("department"); d != "" {
+		target += "?" + url.Values{"department": {d}}.Encode()
+	}
+	w.Header().Set("HX-Redirect", target)
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleEmployeeReviews shows an employee's review history: the final
+// rating of every cycle they were rated in.
+func (app *App) handleEmployeeReviews(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	if employee == nil {
+		app.clientError(w, r, http.StatusNotFound, "Employee not found")
+		return
+	}
+	ratings, err := app.RatingRepository.GetEmployeeRatings(r.Context(), 0, id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch ratings", err)
+		return
+	}
+	cycles, err := app.reviewCyclesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch review cycles", err)
+		return
+	}
+
+	data := map[string]any{
+		"ActivePage":   "reviews",
+		"Employee":     employee,
+		"Ratings":      ratings,
+		"Cycles":       cycles,
+		"RatingLabels": ratingLabels,
+	}
+	app.render(w, r, "employee_reviews.html", "", data)
+}
+
+func (app *App) reviewCyclesByID(ctx context.Context) (map[int]ReviewCycle, error) {
+	cycles, err := app.ReviewCycleRepository.GetReviewCycles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]ReviewCycle, len(cycles))
+	for _, c := range cycles {
+		byID[c.ID] = c
+	}
+	return byID, nil
+}
+
+// handleExportRatings exports the final ratings of every cycle, or of the
+// one given by the cycle query parameter.
+func (app *App) handleExportRatings(w http.ResponseWriter, r *http.Request) {
+	var cycleID int
+	if s := r.URL.Query().Get("cycle"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+			return
+		}
+		cycleID = id
+	}
+	ratings, err := app.RatingRepository.GetEmployeeRatings(r.Context(), cycleID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch ratings", err)
+		return
+	}
+	cycles, err := app.reviewCyclesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch review cycles", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	headers := []string{"Review Cycle", "Employee ID", "Employee", "Department", "Rating", "Rating Label", "Comment", "Rated At"}
+	mapper := func(er EmployeeRating) []string {
+		emp := employees[er.EmployeeID]
+		return []string{
+			cycles[er.CycleID].Name,
+			fmt.Sprintf("%d", er.EmployeeID),
+			emp.FirstName + " " + emp.LastName,
+			departments[emp.DepartmentID].Name,
+			fmt.Sprintf("%d", er.Rating),
+			ratingLabels[er.Rating],
+			er.Comment,
+			er.CreatedAt.Format("2006-01-02"),
+		}
+	}
+
+	writeExport(w, r, "Ratings", ratings, headers, mapper)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCalibrate(t *testing.T) {
+	questions := []ReviewQuestion{{ID: 1, Scale: 5}, {ID: 2, Scale: 3}}
+	if got := (ReviewAssessment{Ratings: map[int]int{1: 5, 2: 2}}).Score(questions); got != 4 {
+		t.Errorf("Score() = %v, want 4 (5 of 5 and 2 of 3)", got)
+	}
+	if got := (ReviewAssessment{}).Score(questions); got != 0 {
+		t.Errorf("Score() of an empty form = %v, want 0", got)
+	}
+
+	employees := []Employee{{ID: 1, FirstName: "Lina"}, {ID: 2, FirstName: "Omar"}, {ID: 3, FirstName: "Sara"}}
+	assessments := []ReviewAssessment{
+		{EmployeeID: 1, Kind: "self", Status: "submitted", Ratings: map[int]int{1: 5, 2: 3}},
+		{EmployeeID: 1, Kind: "manager", Status: "submitted", Ratings: map[int]int{1: 3, 2: 2}},
+		{EmployeeID: 1, Kind: "peer", Status: "submitted", Ratings: map[int]int{1: 4, 2: 2}},
+		{EmployeeID: 1, Kind: "peer", Status: "submitted", Ratings: map[int]int{1: 2, 2: 2}},
+		{EmployeeID: 2, Kind: "self", Status: "submitted", Ratings: map[int]int{1: 2, 2: 1}},
+		{EmployeeID: 2, Kind: "manager", Status: "pending"},
+	}
+	ratings := []EmployeeRating{{EmployeeID: 2, Rating: 2}}
+	rows := calibrate(employees, questions, assessments, ratings)
+	if len(rows) != 2 {
+		t.Fatalf("calibrate() = %+v, want Lina and Omar only", rows)
+	}
+	lina, omar := rows[0], rows[1]
+	if lina.Scores["self"] != 5 || lina.Scores["manager"] != 3 || lina.Scores["peer"] != 3 || lina.Proposed != 3 {
+		t.Errorf("Lina = %+v, want the manager's 3 proposed", lina)
+	}
+	if _, ok := omar.Scores["manager"]; ok || omar.Pending != 1 || omar.Proposed != 2 || omar.Final.Rating != 2 {
+		t.Errorf("Omar = %+v, want his own score proposed while the manager's form is pending", omar)
+	}
+
+	dist := ratingDistribution(rows)
+	if len(dist) != 5 || dist[1].Count != 1 || dist[1].Percent != 100 || dist[2].Count != 0 {
+		t.Errorf("ratingDistribution() = %+v, want the one final rating of 2", dist)
+	}
+}
+
+func TestReviewCycle(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	for _, d := range []Department{{Name: "Support"}, {Name: "Sales"}} {
+		if err := repos.Departments.CreateDepartment(ctx, &d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	departments, _ := repos.Departments.GetDepartments(ctx, "")
+	support, sales := departments[0].ID, departments[1].ID
+	hired := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, e := range []Employee{
+		{FirstName: "Lina", LastName: "Haddad", Email: "lina@example.com", HireDate: hired, Status: "active", DepartmentID: support},
+		{FirstName: "Omar", LastName: "Saleh", Email: "omar@example.com", HireDate: hired, Status: "active", DepartmentID: support},
+		{FirstName: "Sara", LastName: "Nasser", Email: "sara@example.com", HireDate: hired, Status: "active", DepartmentID: sales},
+		{FirstName: "Karim", LastName: "Aziz", Email: "karim@example.com", HireDate: hired, Status: "inactive", DepartmentID: support},
+	} {
+		if err := repos.Employees.CreateEmployee(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+	lina, omar, sara := employees[0].ID, employees[1].ID, employees[2].ID
+
+	cycleForm := url.Values{"name": {"Support 2024"}, "department_id": {strconv.Itoa(support)}, "self_due": {"2024-12-01"}, "manager_due": {"2024-12-15"}, "peer_due": {"2024-12-10"}}
+	if w := send(h, "POST", "/reviews/add", cycleForm, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add cycle = %d %s", w.Code, w.Body)
+	}
+	bad := url.Values{"name": {"Backwards"}, "self_due": {"2024-12-15"}, "manager_due": {"2024-12-01"}, "peer_due": {"2024-12-10"}}
+	if w := send(h, "POST", "/reviews/add", bad, hrHeaders); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Manager deadline") {
+		t.Errorf("manager deadline first = %d %s, want 400", w.Code, w.Body)
+	}
+	cycles, _ := repos.ReviewCycles.GetReviewCycles(ctx)
+	if len(cycles) != 1 || cycles[0].Status != reviewDraft {
+		t.Fatalf("cycles = %+v, want one draft", cycles)
+	}
+	base := "/reviews/cycles/" + strconv.Itoa(cycles[0].ID)
+
+	if w := send(h, "POST", base+"/launch", nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("launch without questions = %d, want 409", w.Code)
+	}
+	for _, q := range []url.Values{{"text": {"Quality of work"}, "scale": {"5"}}, {"text": {"Teamwork"}, "scale": {"3"}}} {
+		if w := send(h, "POST", base+"/questions", q, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add question = %d %s", w.Code, w.Body)
+		}
+	}
+	if w := send(h, "POST", base+"/questions", url.Values{"text": {"Too wide"}, "scale": {"100"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("scale of 100 = %d, want 400", w.Code)
+	}
+	questions, _ := repos.Questions.GetReviewQuestions(ctx, cycles[0].ID)
+
+	if w := send(h, "POST", base+"/launch", nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("launch = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", base+"/questions", url.Values{"text": {"Late"}, "scale": {"5"}}, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("question after launch = %d, want 409", w.Code)
+	}
+	if w := send(h, "DELETE", "/reviews/delete?id="+strconv.Itoa(cycles[0].ID), nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete launched cycle = %d, want 409", w.Code)
+	}
+	assessments, _ := repos.Assessments.GetReviewAssessments(ctx, cycles[0].ID, 0)
+	if len(assessments) != 2 || assessments[0].EmployeeID != lina || assessments[0].Kind != "self" || !assessments[0].DueDate.Equal(cycles[0].SelfDue) {
+		t.Fatalf("assessments = %+v, want self-assessments for the active support staff", assessments)
+	}
+
+	assign := func(employee, reviewer int, kind string) int {
+		form := url.Values{"employee_id": {strconv.Itoa(employee)}, "reviewer_id": {strconv.Itoa(reviewer)}, "kind": {kind}}
+		return send(h, "POST", base+"/assessments", form, hrHeaders).Code
+	}
+	if code := assign(lina, omar, "manager"); code != http.StatusSeeOther {
+		t.Fatalf("assign manager = %d", code)
+	}
+	if code := assign(lina, omar, "manager"); code != http.StatusConflict {
+		t.Errorf("assign the same manager again = %d, want 409", code)
+	}
+	if code := assign(sara, lina, "peer"); code != http.StatusBadRequest {
+		t.Errorf("assign outside the department = %d, want 400", code)
+	}
+	if code := assign(lina, lina, "peer"); code != http.StatusBadRequest {
+		t.Errorf("assign to oneself = %d, want 400", code)
+	}
+
+	// Moving the deadline moves the pending forms with it.
+	cycleForm.Set("manager_due", "2024-12-20")
+	cycleForm.Set("department_id", strconv.Itoa(sales))
+	if w := send(h, "PUT", "/reviews/update/"+strconv.Itoa(cycles[0].ID), cycleForm, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("update cycle = %d %s", w.Code, w.Body)
+	}
+	assessments, _ = repos.Assessments.GetReviewAssessments(ctx, cycles[0].ID, lina)
+	manager := assessments[1]
+	if manager.Kind != "manager" || !manager.DueDate.Equal(time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("manager form = %+v, want the new deadline", manager)
+	}
+	if cycle, _ := repos.ReviewCycles.GetReviewCycleByID(ctx, cycles[0].ID); cycle.DepartmentID != support {
+		t.Errorf("department after launch = %d, want it kept", cycle.DepartmentID)
+	}
+
+	form := "/reviews/assessments/" + strconv.Itoa(manager.ID)
+	if w := send(h, "GET", form, nil, hrHeaders); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Overdue") || !strings.Contains(w.Body.String(), "Quality of work") {
+		t.Errorf("GET %s = %d, want the questions and the overdue flag:\n%s", form, w.Code, w.Body)
+	}
+	answers := url.Values{"q" + strconv.Itoa(questions[0].ID): {"5"}, "q" + strconv.Itoa(questions[1].ID): {"3"}, "comment": {"Reliable"}}
+	if w := send(h, "POST", form, url.Values{"q" + strconv.Itoa(questions[0].ID): {"5"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("submit with a question unanswered = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", form, answers, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("submit = %d %s", w.Code, w.Body)
+	}
+	submitted, _ := repos.Assessments.GetReviewAssessmentByID(ctx, manager.ID)
+	if submitted.Status != "submitted" || submitted.Comment != "Reliable" || submitted.SubmittedAt.IsZero() {
+		t.Errorf("submitted form = %+v", submitted)
+	}
+
+	w := send(h, "GET", base+"/calibration?department="+strconv.Itoa(support), nil, hrHeaders)
+	if body := w.Body.String(); w.Code != http.StatusOK || !strings.Contains(body, "Lina Haddad") || strings.Contains(body, "Sara") {
+		t.Errorf("calibration = %d, want the support staff:\n%s", w.Code, body)
+	}
+	rate := func(employee int, rating string) int {
+		form := url.Values{"employee_id": {strconv.Itoa(employee)}, "rating": {rating}, "comment": {"Calibrated"}}
+		return send(h, "POST", base+"/ratings", form, hrHeaders).Code
+	}
+	if code := rate(lina, "5"); code != http.StatusSeeOther {
+		t.Fatalf("rate = %d", code)
+	}
+	escaped := url.Values{"employee_id": {strconv.Itoa(lina)}, "rating": {"5"}, "department": {"1&x=<y>"}}
+	if w := send(h, "POST", base+"/ratings", escaped, hrHeaders); w.Header().Get("HX-Redirect") != base+"/calibration?department=1%26x%3D%3Cy%3E" {
+		t.Errorf("redirect = %q, want the department escaped", w.Header().Get("HX-Redirect"))
+	}
+	if code := rate(lina, "9"); code != http.StatusBadRequest {
+		t.Errorf("rating of 9 = %d, want 400", code)
+	}
+	if code := rate(sara, "3"); code != http.StatusBadRequest {
+		t.Errorf("rating someone outside the cycle = %d, want 400", code)
+	}
+
+	if w := send(h, "POST", base+"/close", nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("close = %d", w.Code)
+	}
+	if w := send(h, "POST", form, answers, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("submit after close = %d, want 409", w.Code)
+	}
+	if code := rate(lina, "4"); code != http.StatusConflict {
+		t.Errorf("rate after close = %d, want 409", code)
+	}
+
+	w = send(h, "GET", "/reviews/history/"+strconv.Itoa(lina), nil, hrHeaders)
+	if body := w.Body.String(); w.Code != http.StatusOK || !strings.Contains(body, "Support 2024") || !strings.Contains(body, "Outstanding") {
+		t.Errorf("history = %d, want the final rating:\n%s", w.Code, body)
+	}
+
+	w = send(h, "GET", "/reviews/export?cycle="+strconv.Itoa(cycles[0].ID), nil, hrHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export = %d", w.Code)
+	}
+	f, err := excelize.OpenReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := f.GetRows("Sheet1")
+	if len(rows) != 2 || rows[1][0] != "Support 2024" || rows[1][2] != "Lina Haddad" || rows[1][3] != "Support" || rows[1][4] != "5" {
+		t.Errorf("export rows = %v", rows)
+	}
+
+	for _, page := range []string{"/reviews", base, "/reviews/update/" + strconv.Itoa(cycles[0].ID), "/reviews/add"} {
+		if w := send(h, "GET", page, nil, hrHeaders); w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", page, w.Code)
+		}
+	}
+	cycleForm.Set("name", "Abandoned")
+	if w := send(h, "POST", "/reviews/add", cycleForm, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add cycle = %d %s", w.Code, w.Body)
+	}
+	cycles, _ = repos.ReviewCycles.GetReviewCycles(ctx)
+	draft := strconv.Itoa(cycles[0].ID)
+	send(h, "POST", "/reviews/cycles/"+draft+"/questions", url.Values{"text": {"Quality"}, "scale": {"5"}}, hrHeaders)
+	if w := send(h, "DELETE", "/reviews/delete?id="+draft, nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Errorf("delete draft = %d %s", w.Code, w.Body)
+	}
+	if qs, _ := repos.Questions.GetReviewQuestions(ctx, cycles[0].ID); len(qs) != 0 {
+		t.Errorf("questions of the deleted draft = %+v, want none", qs)
+	}
+	if w := send(h, "GET", "/reviews/cycles/99", nil, hrHeaders); w.Code != http.StatusNotFound {
+		t.Errorf("missing cycle = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// selfServiceLeaveTypes are the leaves employees can request themselves.
+var selfServiceLeaveTypes = []string{"vacation", "sick", "personal", "toil"}
+
+// LeaveUsage is the working days of one leave type an employee took, or
+// is waiting to have approved, in a year.
+type LeaveUsage struct {
+	LeaveType string
+	// Entitlement is the days of the type allowed in the year; it only
+	// applies when Capped.
+	Entitlement int
+	Capped      bool
+	Taken       int
+	Pending     int
+}
+
+// Remaining returns the days of a capped type that can still be
+// requested. Pending requests count against it.
+func (u LeaveUsage) Remaining() int {
+	return u.Entitlement - u.Taken - u.Pending
+}
+
+// leaveUsage sums the leaves of an employee starting in year by type, in
+// the order of selfServiceLeaveTypes.
+func leaveUsage(leaves []Leave, employeeID, year int, cal CalendarConfig, cfg LeaveConfig) []LeaveUsage {
+	usage := make([]LeaveUsage, len(selfServiceLeaveTypes))
+	for i, t := range selfServiceLeaveTypes {
+		usage[i].LeaveType = t
+		usage[i].Entitlement, usage[i].Capped = cfg.Entitlements[t]
+	}
+	for _, l := range leaves {
+		i := slices.Index(selfServiceLeaveTypes, l.LeaveType)
+		if l.EmployeeID != employeeID || l.StartDate.Year() != year || i < 0 {
+			continue
+		}
+		switch l.Status {
+		case "approved":
+			usage[i].Taken += cal.leaveDays(l)
+		case "pending":
+			usage[i].Pending += cal.leaveDays(l)
+		}
+	}
+	return usage
+}
+
+// canCancel reports whether an employee may still cancel their leave:
+// while it waits for approval, or once approved until the day it starts.
+func canCancel(l Leave, today time.Time) bool {
+	return l.Status == "pending" || l.Status == "approved" && civilDate(l.StartDate).After(today)
+}
+
+// selfEmployee returns the employee whose email is the viewer's user
+// name. Self-service shows nothing but the data of that employee; the
+// error response has been written when it returns false.
+func (app *App) selfEmployee(w http.ResponseWriter, r *http.Request) (*Employee, bool) {
+	if user := app.viewer(r).User; user != "" {
+		employee, err := app.EmployeeRepository.GetEmployeeByEmail(r.Context(), user)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch employee", err)
+			return nil, false
+		}
+		if employee != nil {
+			return employee, true
+		}
+	}
+	app.clientError(w, r, http.StatusForbidden, "Your account is not linked to an employee")
+	return nil, false
+}
+
+// contactDetails returns the current contact details of an employee, from
+// their latest approved change, and the change waiting for HR, if any.
+func (app *App) contactDetails(ctx context.Context, employeeID int) (current, pending *ContactChange, err error) {
+	changes, err := app.ContactChangeRepository.GetContactChanges(ctx, employeeID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range changes {
+		switch {
+		case c.Status == "pending" && pending == nil:
+			pending = &c
+		case c.Status == "approved" && current == nil:
+			current = &c
+		}
+	}
+	return current, pending, nil
+}
+
+// handleSelfProfile shows the viewer their employee record and contact
+// details.
+func (app *App) handleSelfProfile(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	department, err := app.DepartmentRepository.GetDepartmentByID(r.Context(), employee.DepartmentID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch department", err)
+		return
+	}
+	contact, pending, err := app.contactDetails(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch contact details", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "me",
+		"Employee":   employee,
+		"Department": department,
+		"Contact":    contact,
+		"Pending":    pending,
+	}
+	app.render(w, r, "self_profile.html", "", data)
+}
+
+// handleSelfContact submits new contact details. They replace the current
+// ones once HR approves them, and only one change can wait at a time.
+func (app *App) handleSelfContact(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	change := ContactChange{
+		EmployeeID:     employee.ID,
+		Phone:          strings.TrimSpace(r.FormValue("phone")),
+		Address:        strings.TrimSpace(r.FormValue("address")),
+		EmergencyName:  strings.TrimSpace(r.FormValue("emergency_name")),
+		EmergencyPhone: strings.TrimSpace(r.FormValue("emergency_phone")),
+		Status:         "pending",
+	}
+	if change.Phone == "" && change.Address == "" && change.EmergencyName == "" && change.EmergencyPhone == "" {
+		app.clientError(w, r, http.StatusBadRequest, "Contact details are required")
+		return
+	}
+	if (change.EmergencyName == "") != (change.EmergencyPhone == "") {
+		app.clientError(w, r, http.StatusBadRequest, "Emergency contact needs a name and a phone")
+		return
+	}
+	_, pending, err := app.contactDetails(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch contact details", err)
+		return
+	}
+	if pending != nil {
+		app.clientError(w, r, http.StatusConflict, "A change of contact details is already waiting for HR")
+		return
+	}
+	if err := app.ContactChangeRepository.CreateContactChange(r.Context(), &change); err != nil {
+		app.serverError(w, r, "Failed to add contact change", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/me")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleSelfLeaves lists the viewer's leaves with the days used this year
+// and their time off in lieu.
+func (app *App) handleSelfLeaves(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	leaves, err := app.LeaveRepository.GetLeaves(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch leaves", err)
+		return
+	}
+	balance, err := app.toilBalance(r.Context(), employee.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch overtime", err)
+		return
+	}
+	leaves = slices.DeleteFunc(leaves, func(l Leave) bool { return l.EmployeeID != employee.ID })
+	slices.Reverse(leaves)
+	now := today()
+	cancellable := make(map[int]bool)
+	for _, l := range leaves {
+		cancellable[l.ID] = canCancel(l, now)
+	}
+	data := map[string]any{
+		"ActivePage":  "me",
+		"Employee":    employee,
+		"Leaves":      leaves,
+		"Usage":       leaveUsage(leaves, employee.ID, now.Year(), app.Config.Calendar, app.Config.Leave),
+		"Year":        now.Year(),
+		"Toil":        balance,
+		"Cancellable": cancellable,
+		"LeaveTypes":  selfServiceLeaveTypes,
+	}
+	app.render(w, r, "self_leaves.html", "", data)
+}
+
+// handleSelfRequestLeave files a leave request of the viewer for HR to
+// approve. Leave can't be requested beyond the yearly entitlement of its
+// type, nor time off in lieu beyond what is available.
+func (app *App) handleSelfRequestLeave(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	startDate, err := time.Parse("2006-01-02", r.FormValue("start_date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid start date")
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", r.FormValue("end_date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid end date")
+		return
+	}
+	if endDate.Before(startDate) {
+		app.clientError(w, r, http.StatusBadRequest, "End date can't be before the start date")
+		return
+	}
+	leave := Leave{
+		EmployeeID: employee.ID,
+		LeaveType:  r.FormValue("leave_type"),
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Status:     "pending",
+		Reason:     strings.TrimSpace(r.FormValue("reason")),
+	}
+	if !slices.Contains(selfServiceLeaveTypes, leave.LeaveType) {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid leave type")
+		return
+	}
+	if !isActive(*employee) {
+		app.clientError(w, r, http.StatusConflict, "Only active employees can request leave")
+		return
+	}
+	if leave.LeaveType == "toil" {
+		balance, err := app.toilBalance(r.Context(), employee.ID, 0)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch overtime", err)
+			return
+		}
+		if float64(app.Config.Calendar.leaveDays(leave))*balance.DayHours > balance.Available() {
+			app.clientError(w, r, http.StatusConflict, "Not enough time off in lieu")
+			return
+		}
+	}
+	if _, capped := app.Config.Leave.Entitlements[leave.LeaveType]; capped {
+		leaves, err := app.LeaveRepository.GetLeaves(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch leaves", err)
+			return
+		}
+		usage := leaveUsage(leaves, employee.ID, startDate.Year(), app.Config.Calendar, app.Config.Leave)
+		if app.Config.Calendar.leaveDays(leave) > usage[slices.Index(selfServiceLeaveTypes, leave.LeaveType)].Remaining() {
+			app.clientError(w, r, http.StatusConflict, "Not enough leave left this year")
+			return
+		}
+	}
+	if err := app.LeaveRepository.CreateLeave(r.Context(), &leave); err != nil {
+		app.serverError(w, r, "Failed to add leave", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/me/leaves")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleSelfCancelLeave cancels one of the viewer's leaves. Other
+// employees' leaves are reported as not found.
+func (app *App) handleSelfCancelLeave(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	leave, err := app.LeaveRepository.GetLeaveByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch leave", err)
+		return
+	}
+	if leave == nil || leave.EmployeeID != employee.ID {
+		app.clientError(w, r, http.StatusNotFound, "Leave not found")
+		return
+	}
+	if !canCancel(*leave, today()) {
+		app.clientError(w, r, http.StatusConflict, "This leave can no longer be cancelled")
+		return
+	}
+	leave.Status = "cancelled"
+	if err := app.LeaveRepository.UpdateLeave(r.Context(), leave); err != nil {
+		app.serverError(w, r, "Failed to update leave", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/me/leaves")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// selfDocuments returns the viewer's documents in the categories HR
+// opened to self-service, such as payslips.
+func (app *App) selfDocuments(ctx context.Context, employeeID int) ([]DocumentView, error) {
+	documents, err := app.DocumentRepository.GetDocuments(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	// Self-service categories are shown even when confidential: they
+	// hold the employee's own files.
+	views, err := app.documentViewsIn(ctx, func(c DocumentCategory) bool { return c.SelfService }, documents, today())
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(views, func(d DocumentView) bool { return d.Latest.ID == 0 }), nil
+}
+
+func (app *App) handleSelfDocuments(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	documents, err := app.selfDocuments(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "me",
+		"Employee":   employee,
+		"Documents":  documents,
+	}
+	app.render(w, r, "self_documents.html", "", data)
+}
+
+// handleSelfDownloadDocument sends the latest version of one of the
+// viewer's self-service documents and records the download.
+func (app *App) handleSelfDownloadDocument(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.selfEmployee(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	documents, err := app.selfDocuments(r.Context(), employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch documents", err)
+		return
+	}
+	i := slices.IndexFunc(documents, func(d DocumentView) bool { return d.ID == id })
+	if i < 0 {
+		app.clientError(w, r, http.StatusNotFound, "Document not found")
+		return
+	}
+	document := documents[i]
+
+	download := DocumentDownload{
+		DocumentID: document.ID,
+		EmployeeID: document.EmployeeID,
+		Title:      document.Title,
+		Version:    document.Latest.Version,
+		User:       app.viewer(r).User,
+	}
+	if err := app.DownloadRepository.CreateDocumentDownload(r.Context(), &download); err != nil {
+		app.serverError(w, r, "Failed to record download", err)
+		return
+	}
+	app.serveUpload(w, r, document.Latest.FileKey, document.Latest.FileName)
+}
+
+// handleContactChanges lists the contact details employees changed from
+// self-service, pending ones first, for HR to review.
+func (app *App) handleContactChanges(w http.ResponseWriter, r *http.Request) {
+	if !app.requireHR(w, r) {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if !r.URL.Query().Has("status") {
+		status = "pending"
+	}
+	changes, err := app.ContactChangeRepository.GetContactChanges(r.Context(), 0, status)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch contact changes", err)
+		return
+	}
+	_, employees, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "employees",
+		"Changes":    changes,
+		"Employees":  employees,
+		"Status":     status,
+	}
+	app.render(w, r, "contact_changes.html", "", data)
+}
+
+// handleReviewContactChange approves or rejects a pending change of
+// contact details. Nobody approves their own.
+func (app *App) handleReviewContactChange(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	decision := r.PathValue("decision")
+	if decision != "approve" && decision != "reject" {
+		http.NotFound(w, r)
+		return
+	}
+	if !app.requireHR(w, r) {
+		return
+	}
+
+	change, err := app.ContactChangeRepository.GetContactChangeByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch contact change", err)
+		return
+	}
+	if change == nil {
+		app.clientError(w, r, http.StatusNotFound, "Contact change not found")
+		return
+	}
+	if change.Status != "pending" {
+		app.clientError(w, r, http.StatusConflict, "Contact change was already reviewed")
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), change.EmployeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	viewer := app.viewer(r)
+	if employee != nil && strings.EqualFold(viewer.User, employee.Email) {
+		app.clientError(w, r, http.StatusForbidden, "You can't approve your own contact details")
+		return
+	}
+
+	change.Status = "rejected"
+	if decision == "approve" {
+		change.Status = "approved"
+	}
+	change.DecidedBy, change.DecidedAt = viewer.User, time.Now()
+	if err := app.ContactChangeRepository.UpdateContactChange(r.Context(), change); err != nil {
+		app.serverError(w, r, "Failed to update contact change", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/contact-changes")
+	w.WriteHeader(http.StatusSeeOther)
+}
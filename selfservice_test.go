@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeaveUsage(t *testing.T) {
+	cal := CalendarConfig{WeekendDays: []string{"sat", "sun"}, Holidays: []string{"2026-05-01"}}
+	cfg := LeaveConfig{Entitlements: map[string]int{"vacation": 20, "personal": 0}}
+	monday := time.Date(2026, 4, 27, 0, 0, 0, 0, time.UTC)
+	leaves := []Leave{
+		// Monday to the next Monday: a weekend and a holiday in between.
+		{EmployeeID: 1, LeaveType: "vacation", StartDate: monday, EndDate: monday.AddDate(0, 0, 7), Status: "approved"},
+		{EmployeeID: 1, LeaveType: "vacation", StartDate: monday.AddDate(0, 1, 0), EndDate: monday.AddDate(0, 1, 1), Status: "pending"},
+		{EmployeeID: 1, LeaveType: "sick", StartDate: monday, EndDate: monday, Status: "cancelled"},
+		{EmployeeID: 1, LeaveType: "sick", StartDate: monday.AddDate(-1, 0, 0), EndDate: monday.AddDate(-1, 0, 0), Status: "approved"},
+		{EmployeeID: 2, LeaveType: "personal", StartDate: monday, EndDate: monday, Status: "approved"},
+	}
+	usage := leaveUsage(leaves, 1, 2026, cal, cfg)
+	want := []LeaveUsage{{"vacation", 20, true, 5, 2}, {"sick", 0, false, 0, 0}, {"personal", 0, true, 0, 0}, {"toil", 0, false, 0, 0}}
+	if len(usage) != len(want) {
+		t.Fatalf("leaveUsage() = %+v, want %+v", usage, want)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("leaveUsage()[%d] = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+	if n := usage[0].Remaining(); n != 13 {
+		t.Errorf("Remaining() = %d, want 13 vacation days left", n)
+	}
+}
+
+func TestSelfService(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	now := today()
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", JobTitle: "Accountant", Salary: 4200, Status: "active"}
+	lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active"}
+	for _, e := range []*Employee{&omar, &lea} {
+		if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	me := map[string]string{"X-Forwarded-User": "Omar@Example.com", "X-Forwarded-Groups": "staff"}
+	hr := map[string]string{"X-Forwarded-User": "hana", "X-Forwarded-Groups": "hr"}
+
+	for _, header := range []map[string]string{nil, {"X-Forwarded-User": "nobody@example.com"}} {
+		if w := send(h, "GET", "/me", nil, header); w.Code != http.StatusForbidden {
+			t.Errorf("GET /me as %v = %d, want 403", header, w.Code)
+		}
+	}
+	w := send(h, "GET", "/me", nil, me)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Accountant") {
+		t.Fatalf("GET /me = %d, want Omar's profile", w.Code)
+	}
+
+	// Leaves: request, the balance of time off in lieu, and cancellation.
+	start := now.AddDate(0, 0, 14)
+	vacation := url.Values{"leave_type": {"vacation"}, "start_date": {start.Format("2006-01-02")}, "end_date": {start.AddDate(0, 0, 2).Format("2006-01-02")}, "status": {"approved"}}
+	backwards := url.Values{"leave_type": {"vacation"}, "start_date": vacation["end_date"], "end_date": vacation["start_date"]}
+	if w := send(h, "POST", "/me/leaves", backwards, me); w.Code != http.StatusBadRequest {
+		t.Errorf("request a leave ending before it starts = %d, want 400", w.Code)
+	}
+	if w := send(h, "POST", "/me/leaves", url.Values{"leave_type": {"unpaid"}, "start_date": vacation["start_date"], "end_date": vacation["end_date"]}, me); w.Code != http.StatusBadRequest {
+		t.Errorf("request an unknown leave type = %d, want 400", w.Code)
+	}
+	toil := url.Values{"leave_type": {"toil"}, "start_date": vacation["start_date"], "end_date": vacation["start_date"]}
+	if w := send(h, "POST", "/me/leaves", toil, me); w.Code != http.StatusConflict {
+		t.Errorf("request time off in lieu without a balance = %d, want 409", w.Code)
+	}
+	personal := url.Values{"leave_type": {"personal"}, "start_date": vacation["start_date"], "end_date": {start.AddDate(0, 0, 13).Format("2006-01-02")}}
+	if w := send(h, "POST", "/me/leaves", personal, me); w.Code != http.StatusConflict {
+		t.Errorf("request two weeks of personal leave = %d, want 409 beyond the 3 days a year", w.Code)
+	}
+	if w := send(h, "POST", "/me/leaves", vacation, me); w.Code != http.StatusSeeOther {
+		t.Fatalf("request leave = %d %s", w.Code, w.Body)
+	}
+	past := Leave{EmployeeID: omar.ID, LeaveType: "sick", StartDate: now.AddDate(0, 0, -3), EndDate: now.AddDate(0, 0, -3), Status: "approved"}
+	leas := Leave{EmployeeID: lea.ID, LeaveType: "vacation", StartDate: start, EndDate: start, Status: "pending"}
+	for _, l := range []*Leave{&past, &leas} {
+		if err := repos.Leaves.CreateLeave(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	leaves, _ := repos.Leaves.GetLeaves(ctx, "")
+	requested := leaves[0]
+	if requested.EmployeeID != omar.ID || requested.Status != "pending" {
+		t.Fatalf("leave = %+v, want Omar's request pending whatever the form says", requested)
+	}
+	w = send(h, "GET", "/me/leaves", nil, me)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "/me/leaves/"+strconv.Itoa(past.ID)+"/cancel") || !strings.Contains(w.Body.String(), "/me/leaves/"+strconv.Itoa(requested.ID)+"/cancel") {
+		t.Errorf("GET /me/leaves = %d, want only the request cancellable", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "3 of 3") {
+		t.Error("GET /me/leaves doesn't show the personal days remaining")
+	}
+	cancel := func(id int) int { return send(h, "POST", "/me/leaves/"+strconv.Itoa(id)+"/cancel", nil, me).Code }
+	if code := cancel(leas.ID); code != http.StatusNotFound {
+		t.Errorf("cancel someone else's leave = %d, want 404", code)
+	}
+	if code := cancel(past.ID); code != http.StatusConflict {
+		t.Errorf("cancel a leave already taken = %d, want 409", code)
+	}
+	if code := cancel(requested.ID); code != http.StatusSeeOther {
+		t.Fatalf("cancel = %d", code)
+	}
+	if got, _ := repos.Leaves.GetLeaveByID(ctx, requested.ID); got.Status != "cancelled" {
+		t.Errorf("status = %q, want cancelled", got.Status)
+	}
+
+	// Contact details wait for HR before they show on the profile.
+	contact := url.Values{"phone": {"+961 1 234 567"}, "emergency_name": {"Sara Khalil"}}
+	if w := send(h, "POST", "/me/contact", contact, me); w.Code != http.StatusBadRequest {
+		t.Errorf("emergency contact without a phone = %d, want 400", w.Code)
+	}
+	contact.Set("emergency_phone", "+961 3 765 432")
+	if w := send(h, "POST", "/me/contact", contact, me); w.Code != http.StatusSeeOther {
+		t.Fatalf("change contact details = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", "/me/contact", contact, me); w.Code != http.StatusConflict {
+		t.Errorf("change contact details again before HR = %d, want 409", w.Code)
+	}
+	changes, _ := repos.ContactChanges.GetContactChanges(ctx, omar.ID, "pending")
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v, want one pending", changes)
+	}
+	review := "/contact-changes/" + strconv.Itoa(changes[0].ID)
+	if w := send(h, "POST", review+"/approve", nil, me); w.Code != http.StatusForbidden {
+		t.Errorf("approve as staff = %d, want 403", w.Code)
+	}
+	if w := send(h, "POST", review+"/approve", nil, map[string]string{"X-Forwarded-User": "omar@example.com", "X-Forwarded-Groups": "hr"}); w.Code != http.StatusForbidden {
+		t.Errorf("approve own change = %d, want 403", w.Code)
+	}
+	if w := send(h, "GET", "/contact-changes", nil, hr); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Sara Khalil") {
+		t.Errorf("GET /contact-changes = %d, want the pending change", w.Code)
+	}
+	if w := send(h, "POST", review+"/approve", nil, hr); w.Code != http.StatusSeeOther {
+		t.Fatalf("approve = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", review+"/reject", nil, hr); w.Code != http.StatusConflict {
+		t.Errorf("review twice = %d, want 409", w.Code)
+	}
+	if w := send(h, "GET", "/me", nil, me); !strings.Contains(w.Body.String(), "3 765 432") {
+		t.Error("GET /me doesn't show the approved contact details")
+	}
+
+	// Documents: only the employee's own, in self-service categories.
+	for _, form := range []url.Values{{"name": {"Payslips"}, "confidential": {"1"}, "self_service": {"1"}}, {"name": {"Contracts"}, "confidential": {"1"}}} {
+		if w := send(h, "POST", "/documents/categories/add", form, hr); w.Code != http.StatusSeeOther {
+			t.Fatalf("add category = %d %s", w.Code, w.Body)
+		}
+	}
+	categories, _ := repos.DocCategories.GetDocumentCategories(ctx)
+	contracts, payslips := strconv.Itoa(categories[0].ID), strconv.Itoa(categories[1].ID)
+	uploads := []struct {
+		employee, category, name string
+	}{{strconv.Itoa(omar.ID), payslips, "payslip-omar.pdf"}, {strconv.Itoa(omar.ID), contracts, "contract.pdf"}, {strconv.Itoa(lea.ID), payslips, "payslip-lea.pdf"}}
+	for _, u := range uploads {
+		if w := sendFile(h, "/documents/employees/"+u.employee, url.Values{"category_id": {u.category}}, "file", u.name, u.name, hr); w.Code != http.StatusSeeOther {
+			t.Fatalf("upload %s = %d %s", u.name, w.Code, w.Body)
+		}
+	}
+	w = send(h, "GET", "/me/documents", nil, me)
+	if body := w.Body.String(); w.Code != http.StatusOK || !strings.Contains(body, "payslip-omar.pdf") || strings.Contains(body, "contract.pdf") || strings.Contains(body, "payslip-lea.pdf") {
+		t.Errorf("GET /me/documents = %d, want Omar's payslip only", w.Code)
+	}
+	documents, _ := repos.Documents.GetDocuments(ctx, 0)
+	for _, d := range documents {
+		w := send(h, "GET", "/me/documents/"+strconv.Itoa(d.ID)+"/download", nil, me)
+		switch d.Title {
+		case "payslip-omar.pdf":
+			if w.Code != http.StatusOK || w.Body.String() != "payslip-omar.pdf" {
+				t.Errorf("download own payslip = %d %q", w.Code, w.Body)
+			}
+		default:
+			if w.Code != http.StatusNotFound {
+				t.Errorf("download %s = %d, want 404", d.Title, w.Code)
+			}
+		}
+	}
+	if downloads, _ := repos.Downloads.GetDocumentDownloads(ctx, 0); len(downloads) != 1 || downloads[0].User != "Omar@Example.com" {
+		t.Errorf("downloads = %+v, want Omar's", downloads)
+	}
+}
+
+func TestAdminPagesAreHROnly(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active"}
+	if err := repos.Employees.CreateEmployee(ctx, &omar); err != nil {
+		t.Fatal(err)
+	}
+	staff := map[string]string{"X-Forwarded-User": "omar@example.com", "X-Forwarded-Groups": "staff"}
+	id := strconv.Itoa(omar.ID)
+
+	tests := []struct {
+		method, target string
+		form           url.Values
+	}{
+		{"GET", "/employees", nil},
+		{"POST", "/employees/add", url.Values{"first_name": {"Eve"}, "email": {"eve@example.com"}}},
+		{"PUT", "/employees/update/" + id, url.Values{"first_name": {"Omar"}, "salary": {"999999"}}},
+		{"GET", "/leaves", nil},
+		{"POST", "/leaves/add", url.Values{"employee_id": {id}, "status": {"approved"}}},
+		{"PUT", "/leaves/update/1", url.Values{"status": {"approved"}}},
+		{"GET", "/documents/employees/" + id, nil},
+		{"GET", "/admin/backups", nil},
+		{"GET", "/no-such-page", nil},
+	}
+	for _, tt := range tests {
+		if w := send(h, tt.method, tt.target, tt.form, staff); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s as staff = %d, want 403", tt.method, tt.target, w.Code)
+		}
+	}
+	if employee, _ := repos.Employees.GetEmployeeByID(ctx, omar.ID); employee.FirstName != "Omar" || employee.Salary != 0 {
+		t.Errorf("employee = %+v, want unchanged", employee)
+	}
+
+	if w := send(h, "GET", "/", nil, staff); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/me" {
+		t.Errorf("GET / as staff = %d to %q, want a redirect to /me", w.Code, w.Header().Get("Location"))
+	}
+	for _, page := range []string{"/", "/employees", "/leaves"} {
+		if w := send(h, "GET", page, nil, hrHeaders); w.Code != http.StatusOK {
+			t.Errorf("GET %s as HR = %d, want 200", page, w.Code)
+		}
+	}
+	if w := send(h, "GET", "/me", nil, staff); w.Code != http.StatusOK {
+		t.Errorf("GET /me as staff = %d, want 200", w.Code)
+	}
+}
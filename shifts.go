@@ -0,0 +1,723 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekStart returns the Monday of day's week as a civil date. Rotas are
+// planned a week at a time, from Monday to Sunday.
+func weekStart(day time.Time) time.Time {
+	day = civilDate(day)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// clockOffset returns the time after midnight of hhmm, which has been
+// validated.
+func clockOffset(hhmm string) time.Duration {
+	t, _ := time.Parse("15:04", hhmm)
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+// shiftLength returns the time from start to end, running past midnight
+// when end is earlier in the day than start.
+func shiftLength(start, end string) time.Duration {
+	d := clockOffset(end) - clockOffset(start)
+	if d <= 0 {
+		d += 24 * time.Hour
+	}
+	return d
+}
+
+func (t *ShiftTemplate) validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("Name is required")
+	}
+	if _, err := time.Parse("15:04", t.StartTime); err != nil {
+		return errors.New("Invalid start time")
+	}
+	if _, err := time.Parse("15:04", t.EndTime); err != nil {
+		return errors.New("Invalid end time")
+	}
+	if t.StartTime == t.EndTime {
+		return errors.New("A shift can't start and end at the same time")
+	}
+	if t.BreakMinutes < 0 {
+		return errors.New("Break can't be negative")
+	}
+	if time.Duration(t.BreakMinutes)*time.Minute >= shiftLength(t.StartTime, t.EndTime) {
+		return errors.New("The break must be shorter than the shift")
+	}
+	return nil
+}
+
+// Hours returns the paid hours of the shift, without the break.
+func (t ShiftTemplate) Hours() float64 {
+	return (shiftLength(t.StartTime, t.EndTime) - time.Duration(t.BreakMinutes)*time.Minute).Hours()
+}
+
+// Start returns when the shift begins. Like Date it is a civil time, to be
+// read in the local zone.
+func (s Shift) Start() time.Time {
+	return s.Date.Add(clockOffset(s.StartTime))
+}
+
+// End returns when the shift is over, which is on the next day for
+// overnight shifts.
+func (s Shift) End() time.Time {
+	return s.Start().Add(shiftLength(s.StartTime, s.EndTime))
+}
+
+// Hours returns the paid hours of the shift, without the break.
+func (s Shift) Hours() float64 {
+	return (s.End().Sub(s.Start()) - time.Duration(s.BreakMinutes)*time.Minute).Hours()
+}
+
+// Published reports whether the employee can see the shift.
+func (s Shift) Published() bool {
+	return !s.PublishedAt.IsZero()
+}
+
+// shiftConflict returns why the employee of s can't work it, or nil if
+// they can. others are the shifts planned around it, from the day before
+// its week to the day after; s itself is skipped among them.
+func shiftConflict(s Shift, others []Shift, leaves []Leave, rules ShiftConfig) error {
+	if onLeave(leaves, s.EmployeeID, s.Date) {
+		return errors.New("The employee is on leave that day")
+	}
+	daily, weekly := s.Hours(), s.Hours()
+	week := weekStart(s.Date)
+	rest := time.Duration(rules.MinRestHours) * time.Hour
+	for _, o := range others {
+		if o.ID == s.ID || o.EmployeeID != s.EmployeeID {
+			continue
+		}
+		if o.Start().Before(s.End()) && s.Start().Before(o.End()) {
+			return errors.New("The shift overlaps another shift of the employee")
+		}
+		if rest > 0 && (!o.End().After(s.Start()) && s.Start().Sub(o.End()) < rest || !s.End().After(o.Start()) && o.Start().Sub(s.End()) < rest) {
+			return errors.New("The employee wouldn't get enough rest between shifts")
+		}
+		if o.Date.Equal(s.Date) {
+			daily += o.Hours()
+		}
+		if weekStart(o.Date).Equal(week) {
+			weekly += o.Hours()
+		}
+	}
+	if rules.MaxDailyHours > 0 && daily > float64(rules.MaxDailyHours) {
+		return errors.New("The shift goes over the maximum daily hours")
+	}
+	if rules.MaxWeeklyHours > 0 && weekly > float64(rules.MaxWeeklyHours) {
+		return errors.New("The shift goes over the maximum weekly hours")
+	}
+	return nil
+}
+
+// shiftsAround returns every shift from the day before week to the day
+// after it, which is what shiftConflict needs for the shifts of the week.
+func (app *App) shiftsAround(ctx context.Context, week time.Time) ([]Shift, error) {
+	return app.ShiftRepository.GetShifts(ctx, 0, 0, week.AddDate(0, 0, -1), week.AddDate(0, 0, 8))
+}
+
+// checkShift writes the error response and returns false when the
+// employee of s can't work it.
+func (app *App) checkShift(w http.ResponseWriter, r *http.Request, s Shift) bool {
+	others, err := app.shiftsAround(r.Context(), weekStart(s.Date))
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shifts", err)
+		return false
+	}
+	leaves, err := app.LeaveRepository.GetLeaves(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch leaves", err)
+		return false
+	}
+	if err := shiftConflict(s, others, leaves, app.Config.Shifts); err != nil {
+		app.clientError(w, r, http.StatusConflict, err.Error())
+		return false
+	}
+	return true
+}
+
+// rotaURL returns the rota page of a department's week.
+func rotaURL(departmentID int, week time.Time) string {
+	return fmt.Sprintf("/rota?department_id=%d&week=%s", departmentID, week.Format("2006-01-02"))
+}
+
+// RotaShift is a shift on the rota, with the reason it can't be worked
+// if a leave was approved or the rules changed after it was planned.
+type RotaShift struct {
+	Shift
+	Conflict string
+}
+
+// RotaRow is an employee's week on the rota.
+type RotaRow struct {
+	Employee Employee
+	Days     [7][]RotaShift
+	OnLeave  [7]bool
+	Hours    float64
+}
+
+// handleRota is the weekly rota of a department. Shift templates are
+// dragged onto an employee's day to plan a shift, and planned shifts
+// to another day or employee to move them.
+func (app *App) handleRota(w http.ResponseWriter, r *http.Request) {
+	departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+	departmentID, _ := strconv.Atoi(r.FormValue("department_id"))
+	if departmentID == 0 && len(departments) > 0 {
+		departmentID = departments[0].ID
+	}
+	week := weekStart(today())
+	if v := r.FormValue("week"); v != "" {
+		day, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+			return
+		}
+		week = weekStart(day)
+	}
+
+	employees, byID, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	shifts, err := app.shiftsAround(r.Context(), week)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shifts", err)
+		return
+	}
+	leaves, err := app.LeaveRepository.GetLeaves(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch leaves", err)
+		return
+	}
+	allTemplates, err := app.ShiftTemplateRepository.GetShiftTemplates(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shift templates", err)
+		return
+	}
+
+	var rows []*RotaRow
+	rowOf := make(map[int]*RotaRow)
+	addRow := func(e Employee) *RotaRow {
+		row := &RotaRow{Employee: e}
+		for i := range row.OnLeave {
+			row.OnLeave[i] = onLeave(leaves, e.ID, week.AddDate(0, 0, i))
+		}
+		rows = append(rows, row)
+		rowOf[e.ID] = row
+		return row
+	}
+	for _, e := range employees {
+		if e.DepartmentID == departmentID && isActive(e) {
+			addRow(e)
+		}
+	}
+	drafts, conflicts := 0, 0
+	for _, s := range shifts {
+		day := int(s.Date.Sub(week).Hours() / 24)
+		if s.DepartmentID != departmentID || day < 0 || day > 6 {
+			continue
+		}
+		// Shifts stay on the rota of employees who have since left the
+		// department, so they can be moved or removed.
+		row := rowOf[s.EmployeeID]
+		if row == nil {
+			row = addRow(byID[s.EmployeeID])
+		}
+		rs := RotaShift{Shift: s}
+		if err := shiftConflict(s, shifts, leaves, app.Config.Shifts); err != nil {
+			rs.Conflict = err.Error()
+			conflicts++
+		}
+		if !s.Published() {
+			drafts++
+		}
+		row.Days[day] = append(row.Days[day], rs)
+		row.Hours += s.Hours()
+	}
+
+	var templates []ShiftTemplate
+	for _, t := range allTemplates {
+		if t.DepartmentID == 0 || t.DepartmentID == departmentID {
+			templates = append(templates, t)
+		}
+	}
+	var days [7]time.Time
+	var weekdays [7]string
+	for i := range days {
+		days[i] = week.AddDate(0, 0, i)
+		weekdays[i] = weekdayNames[days[i].Weekday()]
+	}
+	data := map[string]any{
+		"ActivePage":   "rota",
+		"Departments":  departments,
+		"DepartmentID": departmentID,
+		"Week":         week,
+		"PrevWeek":     week.AddDate(0, 0, -7),
+		"NextWeek":     week.AddDate(0, 0, 7),
+		"Days":         days,
+		"Weekdays":     weekdays,
+		"Rows":         rows,
+		"Templates":    templates,
+		"Drafts":       drafts,
+		"Conflicts":    conflicts,
+		"Rules":        app.Config.Shifts,
+	}
+	app.render(w, r, "rota.html", "", data)
+}
+
+// rotaEmployee reads the employee_id form value and returns the employee
+// if they can be put on the department's rota. The error response has
+// been written when it returns false.
+func (app *App) rotaEmployee(w http.ResponseWriter, r *http.Request, departmentID int) (*Employee, bool) {
+	id, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Employee not found")
+		return nil, false
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return nil, false
+	}
+	if employee == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Employee not found")
+		return nil, false
+	}
+	if !isActive(*employee) {
+		app.clientError(w, r, http.StatusBadRequest, "Only active employees can be put on the rota")
+		return nil, false
+	}
+	if employee.DepartmentID != departmentID {
+		app.clientError(w, r, http.StatusBadRequest, "The employee is not in this department")
+		return nil, false
+	}
+	return employee, true
+}
+
+// handleAddShift plans a shift from a template, unless it conflicts with
+// a leave, another shift or the working-time rules.
+func (app *App) handleAddShift(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	departmentID, _ := strconv.Atoi(r.FormValue("department_id"))
+	employee, ok := app.rotaEmployee(w, r, departmentID)
+	if !ok {
+		return
+	}
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	templateID, _ := strconv.Atoi(r.FormValue("template_id"))
+	template, err := app.ShiftTemplateRepository.GetShiftTemplateByID(r.Context(), templateID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shift template", err)
+		return
+	}
+	if template == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Shift template not found")
+		return
+	}
+	if template.DepartmentID != 0 && template.DepartmentID != departmentID {
+		app.clientError(w, r, http.StatusBadRequest, "The shift template is for another department")
+		return
+	}
+
+	shift := Shift{
+		DepartmentID: departmentID,
+		EmployeeID:   employee.ID,
+		Name:         template.Name,
+		Date:         date,
+		StartTime:    template.StartTime,
+		EndTime:      template.EndTime,
+		BreakMinutes: template.BreakMinutes,
+	}
+	if !app.checkShift(w, r, shift) {
+		return
+	}
+	if err := app.ShiftRepository.CreateShift(r.Context(), &shift); err != nil {
+		app.serverError(w, r, "Failed to add shift", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", rotaURL(departmentID, weekStart(date)))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleMoveShift moves a shift to another day or employee. A published
+// shift goes back to draft, so the change reaches employees with the
+// next publish.
+func (app *App) handleMoveShift(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	shift, err := app.ShiftRepository.GetShiftByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shift", err)
+		return
+	}
+	if shift == nil {
+		app.clientError(w, r, http.StatusNotFound, "Shift not found")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	employee, ok := app.rotaEmployee(w, r, shift.DepartmentID)
+	if !ok {
+		return
+	}
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+
+	shift.EmployeeID, shift.Date, shift.PublishedAt = employee.ID, date, time.Time{}
+	if !app.checkShift(w, r, *shift) {
+		return
+	}
+	if err := app.ShiftRepository.UpdateShift(r.Context(), shift); err != nil {
+		app.serverError(w, r, "Failed to update shift", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", rotaURL(shift.DepartmentID, weekStart(date)))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteShift(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	shift, err := app.ShiftRepository.GetShiftByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shift", err)
+		return
+	}
+	if shift == nil {
+		app.clientError(w, r, http.StatusNotFound, "Shift not found")
+		return
+	}
+	if err := app.ShiftRepository.DeleteShift(r.Context(), shift.ID); err != nil {
+		app.serverError(w, r, "Failed to delete shift", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", rotaURL(shift.DepartmentID, weekStart(shift.Date)))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handlePublishRota shows the draft shifts of a department's week to its
+// employees. A week with conflicts is not published.
+func (app *App) handlePublishRota(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	departmentID, _ := strconv.Atoi(r.FormValue("department_id"))
+	day, err := time.Parse("2006-01-02", r.FormValue("week"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	week := weekStart(day)
+	shifts, err := app.shiftsAround(r.Context(), week)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shifts", err)
+		return
+	}
+	leaves, err := app.LeaveRepository.GetLeaves(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch leaves", err)
+		return
+	}
+
+	var drafts []Shift
+	for _, s := range shifts {
+		if s.DepartmentID != departmentID || s.Date.Before(week) || !s.Date.Before(week.AddDate(0, 0, 7)) {
+			continue
+		}
+		if shiftConflict(s, shifts, leaves, app.Config.Shifts) != nil {
+			app.clientError(w, r, http.StatusConflict, "Resolve the conflicts before publishing")
+			return
+		}
+		if !s.Published() {
+			drafts = append(drafts, s)
+		}
+	}
+	if len(drafts) == 0 {
+		app.clientError(w, r, http.StatusConflict, "There are no draft shifts to publish")
+		return
+	}
+	now := time.Now()
+	for _, s := range drafts {
+		s.PublishedAt = now
+		if err := app.ShiftRepository.UpdateShift(r.Context(), &s); err != nil {
+			app.serverError(w, r, "Failed to update shift", err)
+			return
+		}
+	}
+	w.Header().Set("HX-Redirect", rotaURL(departmentID, week))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleShiftTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := app.ShiftTemplateRepository.GetShiftTemplates(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shift templates", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage":  "rota",
+		"Templates":   templates,
+		"Departments": departments,
+	}
+	app.render(w, r, "shift_templates.html", "", data)
+}
+
+// shiftTemplateForm reads the fields of the add and update template forms.
+func shiftTemplateForm(r *http.Request) (ShiftTemplate, error) {
+	deptID, _ := strconv.Atoi(r.FormValue("department_id"))
+	breakMinutes, _ := strconv.Atoi(r.FormValue("break_minutes"))
+	template := ShiftTemplate{
+		Name:         strings.TrimSpace(r.FormValue("name")),
+		DepartmentID: deptID,
+		StartTime:    r.FormValue("start_time"),
+		EndTime:      r.FormValue("end_time"),
+		BreakMinutes: breakMinutes,
+	}
+	return template, template.validate()
+}
+
+func (app *App) shiftTemplateData(ctx context.Context, template *ShiftTemplate) (map[string]any, error) {
+	departments, err := app.DepartmentRepository.GetDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"ActivePage":  "rota",
+		"Template":    template,
+		"Departments": departments,
+	}, nil
+}
+
+func (app *App) handleAddShiftTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		data, err := app.shiftTemplateData(r.Context(), &ShiftTemplate{StartTime: "09:00", EndTime: "17:00", BreakMinutes: 30})
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		app.render(w, r, "add_shift_template.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	template, err := shiftTemplateForm(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.ShiftTemplateRepository.CreateShiftTemplate(r.Context(), &template); err != nil {
+		app.serverError(w, r, "Failed to add shift template", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/rota/templates")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleUpdateShiftTemplate edits a template. Shifts already planned
+// from it keep their times.
+func (app *App) handleUpdateShiftTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	template, err := app.ShiftTemplateRepository.GetShiftTemplateByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shift template", err)
+		return
+	}
+	if template == nil {
+		app.clientError(w, r, http.StatusNotFound, "Shift template not found")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		data, err := app.shiftTemplateData(r.Context(), template)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		app.render(w, r, "update_shift_template.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated, err := shiftTemplateForm(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated.ID = template.ID
+	if err := app.ShiftTemplateRepository.UpdateShiftTemplate(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update shift template", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/rota/templates")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteShiftTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	if err := app.ShiftTemplateRepository.DeleteShiftTemplate(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete shift template", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/rota/templates")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// publishedShifts returns the published shifts of an employee dated in
+// [from, to).
+func (app *App) publishedShifts(ctx context.Context, employeeID int, from, to time.Time) ([]Shift, error) {
+	shifts, err := app.ShiftRepository.GetShifts(ctx, 0, employeeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	published := shifts[:0]
+	for _, s := range shifts {
+		if s.Published() {
+			published = append(published, s)
+		}
+	}
+	return published, nil
+}
+
+// handleEmployeeShifts lists the published shifts of an employee from
+// this week on, with the address of their calendar feed.
+func (app *App) handleEmployeeShifts(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	week := weekStart(today())
+	shifts, err := app.publishedShifts(r.Context(), employee.ID, week, week.AddDate(0, 0, 28))
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shifts", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage":  "rota",
+		"Employee":    employee,
+		"Shifts":      shifts,
+		"Departments": departments,
+	}
+	app.render(w, r, "employee_shifts.html", "", data)
+}
+
+// icsText escapes s for a text value of an iCalendar property.
+var icsText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace
+
+// handleShiftFeed serves the published shifts of an employee as an
+// iCalendar feed that calendar apps can subscribe to. Shift times are
+// written as floating local times, like they are shown on the rota.
+func (app *App) handleShiftFeed(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	now := today()
+	shifts, err := app.publishedShifts(r.Context(), employee.ID, now.AddDate(0, -3, 0), now.AddDate(1, 0, 0))
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch shifts", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	const floating = "20060102T150405"
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\r\n", args...)
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//HR Manager//Rota//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icsText(employee.FirstName+" "+employee.LastName))
+	for _, s := range shifts {
+		line("BEGIN:VEVENT")
+		line("UID:shift-%d@%s", s.ID, r.Host)
+		line("DTSTAMP:%s", s.PublishedAt.UTC().Format("20060102T150405Z"))
+		line("DTSTART:%s", s.Start().Format(floating))
+		line("DTEND:%s", s.End().Format(floating))
+		line("SUMMARY:%s", icsText(s.Name))
+		if d, ok := departments[s.DepartmentID]; ok {
+			line("LOCATION:%s", icsText(d.Name))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=shifts-%d.ics", employee.ID))
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShiftConflict(t *testing.T) {
+	monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	shift := func(id, employeeID, day int, start, end string) Shift {
+		return Shift{ID: id, EmployeeID: employeeID, Date: monday.AddDate(0, 0, day), StartTime: start, EndTime: end}
+	}
+	rules := ShiftConfig{MaxDailyHours: 12, MaxWeeklyHours: 40, MinRestHours: 11}
+	leaves := []Leave{{EmployeeID: 1, Status: "approved", StartDate: monday.AddDate(0, 0, 4), EndDate: monday.AddDate(0, 0, 4)}}
+	var fullWeek []Shift
+	for day := range 4 {
+		fullWeek = append(fullWeek, shift(10+day, 1, day, "08:00", "18:00"))
+	}
+	tests := []struct {
+		name   string
+		shift  Shift
+		others []Shift
+		want   string
+	}{
+		{"free", shift(1, 1, 0, "08:00", "16:00"), nil, ""},
+		{"on leave", shift(1, 1, 4, "08:00", "16:00"), nil, "on leave"},
+		{"overlap", shift(1, 1, 0, "08:00", "16:00"), []Shift{shift(2, 1, 0, "14:00", "22:00")}, "overlaps"},
+		{"another employee", shift(1, 1, 0, "08:00", "16:00"), []Shift{shift(2, 2, 0, "14:00", "22:00")}, ""},
+		{"itself", shift(1, 1, 0, "08:00", "16:00"), []Shift{shift(1, 1, 0, "08:00", "16:00")}, ""},
+		{"overnight overlap", shift(1, 1, 1, "06:00", "14:00"), []Shift{shift(2, 1, 0, "22:00", "07:00")}, "overlaps"},
+		{"short rest", shift(1, 1, 1, "06:00", "14:00"), []Shift{shift(2, 1, 0, "14:00", "22:00")}, "rest"},
+		{"short rest before", shift(1, 1, 0, "14:00", "22:00"), []Shift{shift(2, 1, 1, "06:00", "14:00")}, "rest"},
+		{"enough rest", shift(1, 1, 1, "09:00", "17:00"), []Shift{shift(2, 1, 0, "14:00", "22:00")}, ""},
+		{"daily", shift(1, 1, 0, "00:00", "13:00"), nil, "daily"},
+		{"weekly", shift(1, 1, 5, "08:00", "10:00"), fullWeek, "weekly"},
+		{"last week's hours", shift(1, 1, 0, "08:00", "18:00"), []Shift{shift(2, 1, -7, "08:00", "18:00"), shift(3, 1, -6, "08:00", "18:00"), shift(4, 1, -5, "08:00", "18:00"), shift(5, 1, -4, "08:00", "18:00")}, ""},
+	}
+	for _, tt := range tests {
+		err := shiftConflict(tt.shift, tt.others, leaves, rules)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: shiftConflict() = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if err := shiftConflict(shift(1, 1, 0, "00:00", "13:00"), nil, nil, ShiftConfig{}); err != nil {
+		t.Errorf("shiftConflict() without rules = %v, want nil", err)
+	}
+	if h := shift(1, 1, 0, "22:00", "06:30").Hours(); h != 8.5 {
+		t.Errorf("Hours() of an overnight shift = %v, want 8.5", h)
+	}
+}
+
+func TestRota(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+
+	for _, name := range []string{"Support", "Warehouse"} {
+		if err := repos.Departments.CreateDepartment(ctx, &Department{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	departments, _ := repos.Departments.GetDepartments(ctx, "")
+	support, warehouse := departments[0].ID, departments[1].ID
+	if departments[0].Name != "Support" {
+		support, warehouse = warehouse, support
+	}
+	week := weekStart(today()).AddDate(0, 0, 7)
+	omar := Employee{FirstName: "Omar", LastName: "Khalil", Email: "omar@example.com", Status: "active", DepartmentID: support}
+	lea := Employee{FirstName: "Lea", LastName: "Haddad", Email: "lea@example.com", Status: "active", DepartmentID: support}
+	sami := Employee{FirstName: "Sami", LastName: "Aziz", Email: "sami@example.com", Status: "active", DepartmentID: warehouse}
+	for _, e := range []*Employee{&omar, &lea, &sami} {
+		if err := repos.Employees.CreateEmployee(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	leave := Leave{EmployeeID: lea.ID, LeaveType: "annual", Status: "approved", StartDate: week.AddDate(0, 0, 2), EndDate: week.AddDate(0, 0, 2)}
+	if err := repos.Leaves.CreateLeave(ctx, &leave); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := send(h, "POST", "/rota/templates/add", url.Values{"name": {"Late"}, "start_time": {"14:00"}, "end_time": {"14:00"}}, hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("add a template without length = %d, want 400", w.Code)
+	}
+	for _, form := range []url.Values{
+		{"name": {"Early"}, "start_time": {"06:00"}, "end_time": {"14:00"}, "break_minutes": {"30"}},
+		{"name": {"Late"}, "start_time": {"14:00"}, "end_time": {"22:00"}, "break_minutes": {"30"}},
+		{"name": {"Night"}, "start_time": {"22:00"}, "end_time": {"06:00"}, "department_id": {strconv.Itoa(warehouse)}},
+	} {
+		if w := send(h, "POST", "/rota/templates/add", form, hrHeaders); w.Code != http.StatusSeeOther {
+			t.Fatalf("add template = %d %s", w.Code, w.Body)
+		}
+	}
+	templates, _ := repos.ShiftTemplates.GetShiftTemplates(ctx)
+	if len(templates) != 3 || templates[0].Name != "Early" || templates[2].Name != "Night" {
+		t.Fatalf("templates = %+v, want Early, Late and Night", templates)
+	}
+	early, late, night := strconv.Itoa(templates[0].ID), strconv.Itoa(templates[1].ID), strconv.Itoa(templates[2].ID)
+
+	day := func(i int) string { return week.AddDate(0, 0, i).Format("2006-01-02") }
+	assign := func(e Employee, date, template string) int {
+		return send(h, "POST", "/rota/shifts", url.Values{"department_id": {strconv.Itoa(support)}, "employee_id": {strconv.Itoa(e.ID)}, "date": {date}, "template_id": {template}}, hrHeaders).Code
+	}
+	if code := assign(omar, day(0), night); code != http.StatusBadRequest {
+		t.Errorf("assign another department's template = %d, want 400", code)
+	}
+	if code := assign(sami, day(0), early); code != http.StatusBadRequest {
+		t.Errorf("assign an employee of another department = %d, want 400", code)
+	}
+	if code := assign(lea, day(2), early); code != http.StatusConflict {
+		t.Errorf("assign on a leave = %d, want 409", code)
+	}
+	if code := assign(omar, day(0), late); code != http.StatusSeeOther {
+		t.Fatalf("assign = %d", code)
+	}
+	if code := assign(omar, day(1), early); code != http.StatusConflict {
+		t.Errorf("assign without enough rest = %d, want 409", code)
+	}
+	if code := assign(lea, day(1), early); code != http.StatusSeeOther {
+		t.Fatalf("assign = %d", code)
+	}
+	shifts, _ := repos.Shifts.GetShifts(ctx, support, 0, week, week.AddDate(0, 0, 7))
+	if len(shifts) != 2 || shifts[0].Name != "Late" || shifts[0].Hours() != 7.5 || shifts[0].Published() {
+		t.Fatalf("shifts = %+v, want Omar's late and Lea's early drafts", shifts)
+	}
+	leas := "/rota/shifts/" + strconv.Itoa(shifts[1].ID) + "/move"
+	if w := send(h, "POST", leas, url.Values{"employee_id": {strconv.Itoa(lea.ID)}, "date": {day(2)}}, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("move onto a leave = %d, want 409", w.Code)
+	}
+	if w := send(h, "POST", leas, url.Values{"employee_id": {strconv.Itoa(lea.ID)}, "date": {day(3)}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("move = %d %s", w.Code, w.Body)
+	}
+
+	feed := "/rota/employees/" + strconv.Itoa(lea.ID) + "/shifts.ics"
+	if w := send(h, "GET", feed, nil, hrHeaders); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "BEGIN:VEVENT") {
+		t.Errorf("feed before publishing = %d %s, want no events", w.Code, w.Body)
+	}
+	publish := url.Values{"department_id": {strconv.Itoa(support)}, "week": {day(4)}}
+	if w := send(h, "POST", "/rota/publish", publish, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("publish = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", "/rota/publish", publish, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("publish twice = %d, want 409", w.Code)
+	}
+	w := send(h, "GET", feed, nil, hrHeaders)
+	if body := w.Body.String(); w.Code != http.StatusOK || strings.Count(body, "BEGIN:VEVENT") != 1 || !strings.Contains(body, "DTSTART:"+week.AddDate(0, 0, 3).Format("20060102")+"T060000") || !strings.Contains(body, "LOCATION:Support") {
+		t.Errorf("feed = %d %s, want Lea's early shift", w.Code, body)
+	}
+
+	// A leave approved after publishing shows up as a conflict and holds
+	// back the next publish of the week.
+	if w := send(h, "POST", leas, url.Values{"employee_id": {strconv.Itoa(lea.ID)}, "date": {day(4)}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("move = %d %s", w.Code, w.Body)
+	}
+	if moved, _ := repos.Shifts.GetShiftByID(ctx, shifts[1].ID); moved == nil || moved.Published() {
+		t.Errorf("moved shift = %+v, want a draft again", moved)
+	}
+	leave2 := Leave{EmployeeID: lea.ID, LeaveType: "sick", Status: "approved", StartDate: week.AddDate(0, 0, 4), EndDate: week.AddDate(0, 0, 4)}
+	if err := repos.Leaves.CreateLeave(ctx, &leave2); err != nil {
+		t.Fatal(err)
+	}
+	if w := send(h, "POST", "/rota/publish", publish, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("publish with a conflict = %d, want 409", w.Code)
+	}
+	w = send(h, "GET", "/rota?department_id="+strconv.Itoa(support)+"&week="+day(0), nil, hrHeaders)
+	if body := w.Body.String(); w.Code != http.StatusOK || !strings.Contains(body, "Omar") || strings.Contains(body, "Sami") || !strings.Contains(body, "badge-error") {
+		t.Errorf("rota = %d, want Omar and Lea with a conflict", w.Code)
+	}
+	if w := send(h, "DELETE", "/rota/shifts?id="+strconv.Itoa(shifts[1].ID), nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Errorf("delete = %d %s", w.Code, w.Body)
+	}
+	if left, _ := repos.Shifts.GetShifts(ctx, 0, 0, week, week.AddDate(0, 0, 7)); len(left) != 1 {
+		t.Errorf("shifts after delete = %+v, want Omar's", left)
+	}
+
+	for _, page := range []string{"/rota", "/rota/templates", "/rota/templates/add", "/rota/templates/update/" + early, "/rota/employees/" + strconv.Itoa(omar.ID)} {
+		if w := send(h, "GET", page, nil, hrHeaders); w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", page, w.Code)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/**
+ * HR Dashboard - Rota Planner
+ * Drag a shift template onto an employee's day to plan a shift, or a
+ * planned shift onto another day or employee to move it.
+ */
+
+document.addEventListener('dragstart', (event) => {
+    const item = event.target.closest('[data-template-id], [data-shift-id]');
+    if (!item) return;
+    event.dataTransfer.setData('application/json', JSON.stringify(item.dataset));
+    event.dataTransfer.effectAllowed = item.dataset.shiftId ? 'move' : 'copy';
+});
+
+document.addEventListener('dragover', (event) => {
+    const cell = event.target.closest('[data-rota-cell]');
+    if (!cell) return;
+    event.preventDefault();
+    cell.classList.add('drag-over');
+});
+
+document.addEventListener('dragleave', (event) => {
+    const cell = event.target.closest('[data-rota-cell]');
+    if (cell && !cell.contains(event.relatedTarget)) cell.classList.remove('drag-over');
+});
+
+document.addEventListener('drop', (event) => {
+    const cell = event.target.closest('[data-rota-cell]');
+    if (!cell) return;
+    event.preventDefault();
+    cell.classList.remove('drag-over');
+
+    const item = JSON.parse(event.dataTransfer.getData('application/json') || '{}');
+    const values = { employee_id: cell.dataset.employeeId, date: cell.dataset.date };
+    if (item.shiftId) {
+        htmx.ajax('POST', `/rota/shifts/${item.shiftId}/move`, { values, swap: 'none' });
+    } else if (item.templateId) {
+        values.template_id = item.templateId;
+        values.department_id = cell.dataset.departmentId;
+        htmx.ajax('POST', '/rota/shifts', { values, swap: 'none' });
+    }
+});
+
+// Conflicts with leaves, other shifts or the working-time rules come back
+// as plain text errors.
+document.addEventListener('htmx:responseError', (event) => {
+    alert(event.detail.xhr.responseText);
+});
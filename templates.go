@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// templateSet is the message catalog and, for every locale in it, the
+// page templates parsed with that locale's formatting functions.
+type templateSet struct {
+	catalog *Catalog
+	pages   map[string]map[string]*template.Template
+}
+
+// TemplateManager holds the parsed page templates. Handlers read the
+// current set without locking; Reload parses a complete new set and swaps
+// it in, so a request never sees a half-built set and a broken edit keeps
+// the last good one in service.
+type TemplateManager struct {
+	assets *Assets
+	i18n   I18nConfig
+	set    atomic.Pointer[templateSet]
+
+	mu      sync.Mutex // serializes reloads
+	lastErr atomic.Pointer[error]
+}
+
+// NewTemplateManager parses the templates and message catalogs in assets.
+// Unlike Reload it fails when they don't parse, since there is no good set
+// to fall back to.
+func NewTemplateManager(assets *Assets, i18n I18nConfig) (*TemplateManager, error) {
+	m := &TemplateManager{assets: assets, i18n: i18n}
+	set, err := m.parse()
+	if err != nil {
+		return nil, err
+	}
+	m.set.Store(set)
+	return m, nil
+}
+
+func (m *TemplateManager) parse() (*templateSet, error) {
+	catalog, err := loadCatalog(m.assets.fsys, m.i18n)
+	if err != nil {
+		return nil, err
+	}
+	set := &templateSet{catalog: catalog, pages: make(map[string]map[string]*template.Template)}
+	for _, l := range catalog.Locales() {
+		pages, err := m.assets.parseTemplates(l.funcs(catalog))
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %w", l.Tag, err)
+		}
+		set.pages[l.Tag] = pages
+	}
+	return set, nil
+}
+
+// Lookup returns the template of a page such as "departments.html" in the
+// given locale.
+func (m *TemplateManager) Lookup(locale, page string) (*template.Template, bool) {
+	tmpl, ok := m.set.Load().pages[locale][page]
+	return tmpl, ok
+}
+
+// Catalog returns the message catalog the current templates use.
+func (m *TemplateManager) Catalog() *Catalog {
+	return m.set.Load().catalog
+}
+
+// Len returns the number of pages loaded per locale.
+func (m *TemplateManager) Len() int {
+	set := m.set.Load()
+	return len(set.pages[set.catalog.fallback.Tag])
+}
+
+// Reload parses the templates and catalogs again. On success the new set
+// replaces the current one; on failure the current set stays and the
+// error is kept for Err until a later reload succeeds.
+func (m *TemplateManager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.parse()
+	if err != nil {
+		slog.Warn("template reload failed, keeping previous templates", "err", err)
+		m.lastErr.Store(&err)
+		return err
+	}
+	m.set.Store(set)
+	m.lastErr.Store(nil)
+	slog.Debug("templates reloaded", "pages", m.Len(), "locales", len(set.pages))
+	return nil
+}
+
+// Err returns the error of the last reload, or nil if it succeeded.
+func (m *TemplateManager) Err() error {
+	if err := m.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
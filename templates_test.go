@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// copyTemplates copies the templates and message catalogs into a temp dir
+// and returns assets reading from there, so a test can break them.
+func copyTemplates(t *testing.T) (*Assets, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, sub := range []string{"templates", "locales"} {
+		if err := os.CopyFS(filepath.Join(dir, sub), os.DirFS(sub)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Assets{fsys: os.DirFS(dir), hashes: map[string]string{}}, dir
+}
+
+func TestTemplateManagerKeepsLastGoodSet(t *testing.T) {
+	assets, dir := copyTemplates(t)
+	m, err := NewTemplateManager(assets, defaultConfig().I18n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := m.Lookup("en", "departments.html")
+
+	page := filepath.Join(dir, "templates/dashboard/departments/departments.html")
+	good, err := os.ReadFile(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(page, append(good, []byte("{{ .Broken ")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Reload()
+	if err == nil || !strings.Contains(err.Error(), "departments.html") {
+		t.Fatalf("Reload() error = %v, want a parse error naming the file", err)
+	}
+	if m.Err() == nil {
+		t.Error("Err() = nil after a failed reload")
+	}
+	if after, ok := m.Lookup("en", "departments.html"); !ok || after != before {
+		t.Error("failed reload replaced the last good templates")
+	}
+
+	if err := os.WriteFile(page, good, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(); err != nil {
+		t.Fatalf("Reload() after fix error = %v", err)
+	}
+	if m.Err() != nil {
+		t.Errorf("Err() = %v after a successful reload, want nil", m.Err())
+	}
+	if after, _ := m.Lookup("en", "departments.html"); after == before {
+		t.Error("successful reload did not swap in the new templates")
+	}
+}
+
+func TestTemplateManagerConcurrentReload(t *testing.T) {
+	assets, _ := copyTemplates(t)
+	m, err := NewTemplateManager(assets, defaultConfig().I18n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 20 {
+				if _, ok := m.Lookup("en", "employees.html"); !ok {
+					t.Error("Lookup() missed a page during reload")
+					return
+				}
+			}
+		})
+	}
+	wg.Go(func() {
+		for range 3 {
+			if err := m.Reload(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	wg.Wait()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Tenant is one company served by this process. Every tenant has its own
+// database and its own App whose repositories are bound to that database,
+// so a handler can only ever see the data of the tenant it was routed to.
+type Tenant struct {
+	Config  TenantConfig
+	App     *App
+	db      *DB
+	handler http.Handler
+}
+
+func newTenant(tc TenantConfig, app *App, db *DB) *Tenant {
+	return &Tenant{Config: tc, App: app, db: db, handler: instrument(app.routes())}
+}
+
+// TenantID returns the tenant the request ctx belongs to, or "".
+func TenantID(ctx context.Context) string {
+	id, _ := ctx.Value(tenantIDKey).(string)
+	return id
+}
+
+// TenantRouter dispatches each request to the App of the tenant it is for.
+type TenantRouter struct {
+	tenants  []*Tenant
+	byID     map[string]*Tenant
+	byHost   map[string]*Tenant
+	fallback *Tenant
+	draining atomic.Bool
+}
+
+// NewTenantRouter routes to tenants; requests for unknown hosts go to the
+// tenant with id fallbackID, or get a 404 when it is empty.
+func NewTenantRouter(tenants []*Tenant, fallbackID string) *TenantRouter {
+	tr := &TenantRouter{
+		tenants: tenants,
+		byID:    make(map[string]*Tenant),
+		byHost:  make(map[string]*Tenant),
+	}
+	for _, t := range tenants {
+		tr.byID[t.Config.ID] = t
+		for _, h := range t.Config.Hosts {
+			tr.byHost[strings.ToLower(h)] = t
+		}
+	}
+	tr.fallback = tr.byID[fallbackID]
+	return tr
+}
+
+// Resolve picks the tenant for r: an exact host match first, then the
+// first label of the host as tenant id, then the fallback tenant.
+func (tr *TenantRouter) Resolve(r *http.Request) *Tenant {
+	host := strings.ToLower(r.Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if t, ok := tr.byHost[host]; ok {
+		return t
+	}
+	if sub, _, ok := strings.Cut(host, "."); ok {
+		if t, ok := tr.byID[sub]; ok {
+			return t
+		}
+	}
+	return tr.fallback
+}
+
+func (tr *TenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t := tr.Resolve(r)
+	if t == nil {
+		slog.InfoContext(r.Context(), "no tenant for host", "host", r.Host)
+		http.Error(w, "Unknown company", http.StatusNotFound)
+		return
+	}
+	ctx := context.WithValue(r.Context(), tenantIDKey, t.Config.ID)
+	t.handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Drain makes /readyz fail so load balancers stop sending traffic.
+func (tr *TenantRouter) Drain() {
+	tr.draining.Store(true)
+}
+
+// handleReadyz reports whether this instance should receive traffic: every
+// tenant database answers a ping, the page templates are loaded and the
+// server is not shutting down.
+func (tr *TenantRouter) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	var problems []string
+
+	if tr.draining.Load() {
+		problems = append(problems, "shutting down")
+	}
+	for _, t := range tr.tenants {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		err := t.db.PingContext(ctx)
+		cancel()
+		if err != nil {
+			slog.WarnContext(r.Context(), "readiness database ping failed", "tenant", t.Config.ID, "err", err)
+			problems = append(problems, fmt.Sprintf("database of tenant %s unreachable", t.Config.ID))
+		}
+		if t.App.Templates.Len() == 0 {
+			problems = append(problems, fmt.Sprintf("templates of tenant %s not loaded", t.Config.ID))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		for _, p := range problems {
+			w.Write([]byte(p + "\n"))
+		}
+		return
+	}
+	w.Write([]byte("ready\n"))
+}
+
+// Close closes every tenant database.
+func (tr *TenantRouter) Close() error {
+	var errs []error
+	for _, t := range tr.tenants {
+		errs = append(errs, t.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// openTenants connects to the database of every configured tenant and
+// builds its App with newApp. On error the databases opened so far are
+// closed again.
+func openTenants(ctx context.Context, cfg *Config, newApp func(tc TenantConfig, db *DB) *App) ([]*Tenant, error) {
+	var tenants []*Tenant
+	for _, tc := range cfg.TenantList() {
+		db, err := connectToDB(ctx, cfg.tenantDatabase(tc))
+		if err != nil {
+			for _, t := range tenants {
+				t.db.Close()
+			}
+			return nil, fmt.Errorf("connecting to database of tenant %s: %w", tc.ID, err)
+		}
+		tenants = append(tenants, newTenant(tc, newApp(tc, db), db))
+	}
+	return tenants, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestTenants opens one SQLite database per tenant id in a temp dir.
+func newTestTenants(t *testing.T, ids ...string) *TenantRouter {
+	t.Helper()
+	dir := t.TempDir()
+
+	cfg := defaultConfig()
+	cfg.Server.DevMode = false
+	for _, id := range ids {
+		cfg.Tenants = append(cfg.Tenants, TenantConfig{
+			ID:     id,
+			Hosts:  []string{id + ".example.com"},
+			DBPath: filepath.Join(dir, id+".db"),
+		})
+	}
+
+	templates := testTemplates(t)
+	tenants, err := openTenants(context.Background(), &cfg, func(tc TenantConfig, db *DB) *App {
+		return NewApp(&cfg, NewSQLRepositories(db), templates)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewTenantRouter(tenants, "")
+	t.Cleanup(func() { router.Close() })
+	return router
+}
+
+func TestTenantRouterResolve(t *testing.T) {
+	router := newTestTenants(t, "acme", "globex")
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "acme.example.com", want: "acme"},
+		{host: "ACME.example.com:8443", want: "acme"},
+		{host: "globex.hr.internal", want: "globex"},
+		{host: "initech.example.com", want: ""},
+		{host: "localhost:8080", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.host, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Host = tc.host
+			got := ""
+			if tenant := router.Resolve(r); tenant != nil {
+				got = tenant.Config.ID
+			}
+			if got != tc.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tc.host, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	router := newTestTenants(t, "acme", "globex")
+
+	do := func(method, host, target string, form url.Values) *httptest.ResponseRecorder {
+		var body io.Reader
+		if form != nil {
+			body = strings.NewReader(form.Encode())
+		}
+		r := httptest.NewRequest(method, target, body)
+		r.Host = host
+		r.Header.Set("X-Forwarded-Groups", "hr")
+		if form != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do(http.MethodPost, "acme.example.com", "/departments/add", url.Values{"name": {"Acme Secret Lab"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("adding department: status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	// The same name must be accepted by the other tenant's database.
+	w = do(http.MethodPost, "globex.example.com", "/departments/add", url.Values{"name": {"Shared Name"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("adding department: status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+
+	acme := do(http.MethodGet, "acme.example.com", "/departments", nil).Body.String()
+	globex := do(http.MethodGet, "globex.example.com", "/departments", nil).Body.String()
+	if !strings.Contains(acme, "Acme Secret Lab") {
+		t.Error("acme does not see its own department")
+	}
+	if strings.Contains(globex, "Acme Secret Lab") {
+		t.Error("globex sees a department created by acme")
+	}
+	if strings.Contains(acme, "Shared Name") {
+		t.Error("acme sees a department created by globex")
+	}
+
+	for _, tenant := range router.tenants {
+		departments, err := tenant.App.DepartmentRepository.GetDepartments(context.Background(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(departments) != 1 {
+			t.Errorf("tenant %s has %d departments, want 1", tenant.Config.ID, len(departments))
+		}
+	}
+
+	if w := do(http.MethodGet, "initech.example.com", "/departments", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown tenant: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,1056 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	enrollmentEnrolled  = "enrolled"
+	enrollmentCompleted = "completed"
+)
+
+// States of things that expire, such as certificates and documents.
+const (
+	expiryValid    = "valid"
+	expiryExpiring = "expiring" // within the reminder days
+	expiryExpired  = "expired"
+	expiryMissing  = "missing" // a required certificate was never held
+)
+
+func (c *Course) validate() error {
+	if strings.TrimSpace(c.Title) == "" {
+		return errors.New("Title is required")
+	}
+	if c.Hours < 0 {
+		return errors.New("Hours must not be negative")
+	}
+	return nil
+}
+
+func (c *Certification) validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("Name is required")
+	}
+	if c.ValidityMonths < 0 || c.ReminderDays < 0 {
+		return errors.New("Validity and reminder days must not be negative")
+	}
+	return nil
+}
+
+// Expiry returns when a certificate issued on issued expires, or the zero
+// time if the certification never expires.
+func (c Certification) Expiry(issued time.Time) time.Time {
+	if c.ValidityMonths == 0 {
+		return time.Time{}
+	}
+	return issued.AddDate(0, c.ValidityMonths, 0)
+}
+
+// Requires reports whether staff of the department must hold c.
+func (c Certification) Requires(departmentID int) bool {
+	return slices.Contains(c.Departments, departmentID)
+}
+
+// certificateStatus returns the state of a certificate of c on the civil
+// date today.
+func certificateStatus(c Certification, ec *EmployeeCertification, today time.Time) string {
+	if ec == nil {
+		return expiryMissing
+	}
+	return expiryStatus(ec.ExpiresOn, today, c.ReminderDays)
+}
+
+// expiryStatus returns the state on the civil date today of something
+// that expires on expires, warning reminderDays ahead. It is valid
+// through its expiry date; a zero expiry never expires.
+func expiryStatus(expires, today time.Time, reminderDays int) string {
+	switch {
+	case expires.IsZero():
+		return expiryValid
+	case today.After(expires):
+		return expiryExpired
+	case !today.Before(expires.AddDate(0, 0, -reminderDays)):
+		return expiryExpiring
+	}
+	return expiryValid
+}
+
+// certificateKey identifies the certificates of one employee for one
+// certification.
+type certificateKey struct{ employeeID, certificationID int }
+
+// bestCertificates returns, for every employee and certification, the
+// certificate that stays valid the longest. Renewals add certificates, so
+// older ones are kept as history but no longer count.
+func bestCertificates(certificates []EmployeeCertification) map[certificateKey]EmployeeCertification {
+	best := make(map[certificateKey]EmployeeCertification)
+	for _, ec := range certificates {
+		k := certificateKey{ec.EmployeeID, ec.CertificationID}
+		cur, ok := best[k]
+		if !ok || !cur.ExpiresOn.IsZero() && (ec.ExpiresOn.IsZero() || ec.ExpiresOn.After(cur.ExpiresOn)) {
+			best[k] = ec
+		}
+	}
+	return best
+}
+
+// ComplianceCell is one department and certification of the compliance
+// matrix.
+type ComplianceCell struct {
+	Required bool
+	Staff    int // active employees of the department
+	Valid    int // those holding a valid certificate, expiring ones included
+	Expiring int
+}
+
+// Percent returns the share of the staff holding a valid certificate.
+func (c ComplianceCell) Percent() float64 {
+	if c.Staff == 0 {
+		return 100
+	}
+	return float64(100 * c.Valid / c.Staff)
+}
+
+// ComplianceRow is one department of the compliance matrix, with a cell
+// for every certification.
+type ComplianceRow struct {
+	Department Department
+	Cells      []ComplianceCell
+}
+
+// complianceMatrix counts, for every department and every certification
+// it requires, how many of its active employees hold a valid certificate.
+func complianceMatrix(departments []Department, certifications []Certification, employees []Employee, certificates []EmployeeCertification, today time.Time) []ComplianceRow {
+	best := bestCertificates(certificates)
+	rows := make([]ComplianceRow, len(departments))
+	for i, d := range departments {
+		rows[i] = ComplianceRow{Department: d, Cells: make([]ComplianceCell, len(certifications))}
+		for j, c := range certifications {
+			cell := &rows[i].Cells[j]
+			if cell.Required = c.Requires(d.ID); !cell.Required {
+				continue
+			}
+			for _, e := range employees {
+				if e.DepartmentID != d.ID || !isActive(e) {
+					continue
+				}
+				cell.Staff++
+				var held *EmployeeCertification
+				if ec, ok := best[certificateKey{e.ID, c.ID}]; ok {
+					held = &ec
+				}
+				switch certificateStatus(c, held, today) {
+				case expiryValid:
+					cell.Valid++
+				case expiryExpiring:
+					cell.Valid++
+					cell.Expiring++
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// isActive reports whether e still works for the company.
+func isActive(e Employee) bool {
+	return e.Status == "" || e.Status == "active"
+}
+
+// CertificateReminder is a certificate of an active employee that expires
+// within its certification's reminder days or has already expired.
+type CertificateReminder struct {
+	Employee      Employee
+	Certification Certification
+	Certificate   EmployeeCertification
+	Status        string
+	DaysLeft      int // negative once expired
+}
+
+// certificateReminders returns the certificates due for renewal, the
+// soonest to expire first. Certificates already renewed are left out.
+func certificateReminders(certifications []Certification, employees map[int]Employee, certificates []EmployeeCertification, today time.Time) []CertificateReminder {
+	byID := make(map[int]Certification, len(certifications))
+	for _, c := range certifications {
+		byID[c.ID] = c
+	}
+	var reminders []CertificateReminder
+	for _, ec := range bestCertificates(certificates) {
+		e, ok := employees[ec.EmployeeID]
+		if !ok || !isActive(e) {
+			continue
+		}
+		c := byID[ec.CertificationID]
+		status := certificateStatus(c, &ec, today)
+		if status != expiryExpiring && status != expiryExpired {
+			continue
+		}
+		reminders = append(reminders, CertificateReminder{
+			Employee:      e,
+			Certification: c,
+			Certificate:   ec,
+			Status:        status,
+			DaysLeft:      int(ec.ExpiresOn.Sub(today).Hours() / 24),
+		})
+	}
+	slices.SortFunc(reminders, func(a, b CertificateReminder) int {
+		if c := a.Certificate.ExpiresOn.Compare(b.Certificate.ExpiresOn); c != 0 {
+			return c
+		}
+		return a.Certificate.ID - b.Certificate.ID
+	})
+	return reminders
+}
+
+func (app *App) certificationsByID(ctx context.Context) ([]Certification, map[int]Certification, error) {
+	certifications, err := app.CertificationRepository.GetCertifications(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int]Certification, len(certifications))
+	for _, c := range certifications {
+		byID[c.ID] = c
+	}
+	return certifications, byID, nil
+}
+
+// certificateReminders loads the certificates due for renewal.
+func (app *App) certificateReminders(ctx context.Context) ([]CertificateReminder, error) {
+	certifications, err := app.CertificationRepository.GetCertifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, employees, err := app.employeesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	certificates, err := app.CertificateRepository.GetEmployeeCertifications(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return certificateReminders(certifications, employees, certificates, today()), nil
+}
+
+// CourseView is a course in the catalog with its enrollment counts.
+type CourseView struct {
+	Course
+	Enrolled  int
+	Completed int
+}
+
+func (app *App) handleTraining(w http.ResponseWriter, r *http.Request) {
+	courses, err := app.CourseRepository.GetCourses(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch courses", err)
+		return
+	}
+	enrollments, err := app.EnrollmentRepository.GetEnrollments(r.Context(), 0, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch enrollments", err)
+		return
+	}
+	_, certifications, err := app.certificationsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certifications", err)
+		return
+	}
+	reminders, err := app.certificateReminders(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificates", err)
+		return
+	}
+
+	views := make([]CourseView, len(courses))
+	for i, c := range courses {
+		views[i].Course = c
+		for _, e := range enrollments {
+			if e.CourseID != c.ID {
+				continue
+			}
+			views[i].Enrolled++
+			if e.Status == enrollmentCompleted {
+				views[i].Completed++
+			}
+		}
+	}
+	data := map[string]any{
+		"ActivePage":     "training",
+		"Courses":        views,
+		"Certifications": certifications,
+		"Reminders":      len(reminders),
+	}
+	app.render(w, r, "training.html", "", data)
+}
+
+// courseForm reads the fields of the add and update course forms.
+func courseForm(r *http.Request) Course {
+	hours, _ := strconv.ParseFloat(r.FormValue("hours"), 64)
+	certID, _ := strconv.Atoi(r.FormValue("certification_id"))
+	return Course{
+		Title:           strings.TrimSpace(r.FormValue("title")),
+		Provider:        strings.TrimSpace(r.FormValue("provider")),
+		Description:     strings.TrimSpace(r.FormValue("description")),
+		Hours:           hours,
+		CertificationID: certID,
+	}
+}
+
+func (app *App) handleAddCourse(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		certifications, err := app.CertificationRepository.GetCertifications(r.Context())
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch certifications", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":     "training",
+			"Course":         Course{},
+			"Certifications": certifications,
+		}
+		app.render(w, r, "add_course.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	course := courseForm(r)
+	if err := course.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.CourseRepository.CreateCourse(r.Context(), &course); err != nil {
+		app.serverError(w, r, "Failed to add course", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/training")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleUpdateCourse(w http.ResponseWriter, r *http.Request) {
+	course, ok := app.course(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		certifications, err := app.CertificationRepository.GetCertifications(r.Context())
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch certifications", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":     "training",
+			"Course":         course,
+			"Certifications": certifications,
+		}
+		app.render(w, r, "update_course.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated := courseForm(r)
+	updated.ID = course.ID
+	if err := updated.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.CourseRepository.UpdateCourse(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update course", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/training/courses/%d", course.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteCourse deletes a course with its enrollments. Certificates
+// granted by completing it are kept.
+func (app *App) handleDeleteCourse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	enrollments, err := app.EnrollmentRepository.GetEnrollments(r.Context(), id, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch enrollments", err)
+		return
+	}
+	for _, e := range enrollments {
+		if err := app.EnrollmentRepository.DeleteEnrollment(r.Context(), e.ID); err != nil {
+			app.serverError(w, r, "Failed to delete enrollment", err)
+			return
+		}
+	}
+	if err := app.CourseRepository.DeleteCourse(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete course", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/training")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// course fetches the course named by the request's id path value and
+// writes the error response when there is none.
+func (app *App) course(w http.ResponseWriter, r *http.Request) (*Course, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	course, err := app.CourseRepository.GetCourseByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch course", err)
+		return nil, false
+	}
+	if course == nil {
+		app.clientError(w, r, http.StatusNotFound, "Course not found")
+		return nil, false
+	}
+	return course, true
+}
+
+func (app *App) handleCourse(w http.ResponseWriter, r *http.Request) {
+	course, ok := app.course(w, r)
+	if !ok {
+		return
+	}
+	enrollments, err := app.EnrollmentRepository.GetEnrollments(r.Context(), course.ID, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch enrollments", err)
+		return
+	}
+	employees, byID, err := app.employeesByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	var certification *Certification
+	if course.CertificationID != 0 {
+		certification, err = app.CertificationRepository.GetCertificationByID(r.Context(), course.CertificationID)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch certification", err)
+			return
+		}
+	}
+
+	enrolled := make(map[int]bool, len(enrollments))
+	for _, e := range enrollments {
+		enrolled[e.EmployeeID] = true
+	}
+	var candidates []Employee
+	for _, e := range employees {
+		if isActive(e) && !enrolled[e.ID] {
+			candidates = append(candidates, e)
+		}
+	}
+	data := map[string]any{
+		"ActivePage":    "training",
+		"Course":        course,
+		"Certification": certification,
+		"Enrollments":   enrollments,
+		"Employees":     byID,
+		"Candidates":    candidates,
+		"Today":         today(),
+	}
+	app.render(w, r, "course.html", "", data)
+}
+
+func (app *App) handleEnroll(w http.ResponseWriter, r *http.Request) {
+	course, ok := app.course(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return
+	}
+	if employee == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid employee")
+		return
+	}
+	existing, err := app.EnrollmentRepository.GetEnrollments(r.Context(), course.ID, employeeID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch enrollments", err)
+		return
+	}
+	if len(existing) > 0 {
+		app.clientError(w, r, http.StatusConflict, "Employee is already enrolled in this course")
+		return
+	}
+
+	enrollment := Enrollment{CourseID: course.ID, EmployeeID: employeeID, Status: enrollmentEnrolled, EnrolledOn: today()}
+	if err := app.EnrollmentRepository.CreateEnrollment(r.Context(), &enrollment); err != nil {
+		app.serverError(w, r, "Failed to enroll employee", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/training/courses/%d", course.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteEnrollment(w http.ResponseWriter, r *http.Request) {
+	course, ok := app.course(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	if err := app.EnrollmentRepository.DeleteEnrollment(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete enrollment", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/training/courses/%d", course.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleCompleteEnrollment records that an employee completed a course.
+// When the course grants a certification, the employee gets a
+// certificate issued on the completion date.
+func (app *App) handleCompleteEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	completed, err := time.Parse("2006-01-02", r.FormValue("completed_on"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	enrollment, err := app.EnrollmentRepository.GetEnrollmentByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch enrollment", err)
+		return
+	}
+	if enrollment == nil {
+		app.clientError(w, r, http.StatusNotFound, "Enrollment not found")
+		return
+	}
+	if enrollment.Status == enrollmentCompleted {
+		app.clientError(w, r, http.StatusConflict, "Course is already completed")
+		return
+	}
+	if completed.Before(enrollment.EnrolledOn) {
+		app.clientError(w, r, http.StatusBadRequest, "Completion can't be before enrollment")
+		return
+	}
+	course, err := app.CourseRepository.GetCourseByID(r.Context(), enrollment.CourseID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch course", err)
+		return
+	}
+
+	enrollment.Status, enrollment.CompletedOn = enrollmentCompleted, completed
+	if err := app.EnrollmentRepository.UpdateEnrollment(r.Context(), enrollment); err != nil {
+		app.serverError(w, r, "Failed to update enrollment", err)
+		return
+	}
+	if course != nil && course.CertificationID != 0 {
+		certification, err := app.CertificationRepository.GetCertificationByID(r.Context(), course.CertificationID)
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch certification", err)
+			return
+		}
+		if certification != nil {
+			certificate := EmployeeCertification{
+				EmployeeID:      enrollment.EmployeeID,
+				CertificationID: certification.ID,
+				IssuedOn:        completed,
+				ExpiresOn:       certification.Expiry(completed),
+			}
+			if err := app.CertificateRepository.CreateEmployeeCertification(r.Context(), &certificate); err != nil {
+				app.serverError(w, r, "Failed to add certificate", err)
+				return
+			}
+		}
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/training/courses/%d", enrollment.CourseID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleCertifications(w http.ResponseWriter, r *http.Request) {
+	certifications, err := app.CertificationRepository.GetCertifications(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certifications", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage":     "training",
+		"Certifications": certifications,
+		"Departments":    departments,
+	}
+	app.render(w, r, "certifications.html", "", data)
+}
+
+// certificationForm reads the fields of the add and update certification
+// forms.
+func certificationForm(r *http.Request) Certification {
+	validity, _ := strconv.Atoi(r.FormValue("validity_months"))
+	reminder, _ := strconv.Atoi(r.FormValue("reminder_days"))
+	c := Certification{
+		Name:           strings.TrimSpace(r.FormValue("name")),
+		Description:    strings.TrimSpace(r.FormValue("description")),
+		ValidityMonths: validity,
+		ReminderDays:   reminder,
+		Departments:    []int{},
+	}
+	for _, v := range r.Form["departments"] {
+		if id, err := strconv.Atoi(v); err == nil {
+			c.Departments = append(c.Departments, id)
+		}
+	}
+	return c
+}
+
+func (app *App) handleAddCertification(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":    "training",
+			"Certification": Certification{ValidityMonths: 12, ReminderDays: 30},
+			"Departments":   departments,
+		}
+		app.render(w, r, "add_certification.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	certification := certificationForm(r)
+	if err := certification.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.CertificationRepository.CreateCertification(r.Context(), &certification); err != nil {
+		app.serverError(w, r, "Failed to add certification", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/certifications")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleUpdateCertification(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	certification, err := app.CertificationRepository.GetCertificationByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certification", err)
+		return
+	}
+	if certification == nil {
+		app.clientError(w, r, http.StatusNotFound, "Certification not found")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+		if err != nil {
+			app.serverError(w, r, "Failed to fetch departments", err)
+			return
+		}
+		data := map[string]any{
+			"ActivePage":    "training",
+			"Certification": certification,
+			"Departments":   departments,
+		}
+		app.render(w, r, "update_certification.html", "", data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	updated := certificationForm(r)
+	updated.ID = certification.ID
+	if err := updated.validate(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := app.CertificationRepository.UpdateCertification(r.Context(), &updated); err != nil {
+		app.serverError(w, r, "Failed to update certification", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/certifications")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleDeleteCertification deletes a certification nobody holds and no
+// course grants.
+func (app *App) handleDeleteCertification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		app.methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	held, err := app.CertificateRepository.GetEmployeeCertifications(r.Context(), id, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificates", err)
+		return
+	}
+	if len(held) > 0 {
+		app.clientError(w, r, http.StatusConflict, "Employees hold this certification")
+		return
+	}
+	courses, err := app.CourseRepository.GetCourses(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch courses", err)
+		return
+	}
+	for _, c := range courses {
+		if c.CertificationID == id {
+			app.clientError(w, r, http.StatusConflict, "A course grants this certification")
+			return
+		}
+	}
+	if err := app.CertificationRepository.DeleteCertification(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete certification", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/certifications")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleCompliance(w http.ResponseWriter, r *http.Request) {
+	departments, err := app.DepartmentRepository.GetDepartments(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+	certifications, err := app.CertificationRepository.GetCertifications(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certifications", err)
+		return
+	}
+	employees, err := app.EmployeeRepository.GetEmployees(r.Context(), "")
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employees", err)
+		return
+	}
+	certificates, err := app.CertificateRepository.GetEmployeeCertifications(r.Context(), 0, 0)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificates", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage":     "training",
+		"Certifications": certifications,
+		"Rows":           complianceMatrix(departments, certifications, employees, certificates, today()),
+	}
+	app.render(w, r, "compliance.html", "", data)
+}
+
+func (app *App) handleCertificateReminders(w http.ResponseWriter, r *http.Request) {
+	reminders, err := app.certificateReminders(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificates", err)
+		return
+	}
+	data := map[string]any{
+		"ActivePage": "training",
+		"Reminders":  reminders,
+	}
+	app.render(w, r, "certificate_reminders.html", "", data)
+}
+
+func (app *App) handleExportCertificateReminders(w http.ResponseWriter, r *http.Request) {
+	reminders, err := app.certificateReminders(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificates", err)
+		return
+	}
+	departments, err := app.departmentsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch departments", err)
+		return
+	}
+
+	headers := []string{"Employee ID", "Employee", "Email", "Department", "Certification", "Issued On", "Expires On", "Days Left", "Status"}
+	mapper := func(cr CertificateReminder) []string {
+		return []string{
+			fmt.Sprintf("%d", cr.Employee.ID),
+			cr.Employee.FirstName + " " + cr.Employee.LastName,
+			cr.Employee.Email,
+			departments[cr.Employee.DepartmentID].Name,
+			cr.Certification.Name,
+			cr.Certificate.IssuedOn.Format("2006-01-02"),
+			cr.Certificate.ExpiresOn.Format("2006-01-02"),
+			fmt.Sprintf("%d", cr.DaysLeft),
+			cr.Status,
+		}
+	}
+
+	writeExport(w, r, "Certificate Reminders", reminders, headers, mapper)
+}
+
+// CertificateView is a certificate on the employee training page.
+type CertificateView struct {
+	EmployeeCertification
+	Certification Certification
+	Status        string
+	Current       bool // the certificate that counts; others are history
+}
+
+func (app *App) handleEmployeeTraining(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	enrollments, err := app.EnrollmentRepository.GetEnrollments(r.Context(), 0, employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch enrollments", err)
+		return
+	}
+	courses, err := app.CourseRepository.GetCourses(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch courses", err)
+		return
+	}
+	coursesByID := make(map[int]Course, len(courses))
+	for _, c := range courses {
+		coursesByID[c.ID] = c
+	}
+	certifications, certificationsByID, err := app.certificationsByID(r.Context())
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certifications", err)
+		return
+	}
+	certificates, err := app.CertificateRepository.GetEmployeeCertifications(r.Context(), 0, employee.ID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificates", err)
+		return
+	}
+
+	now := today()
+	best := bestCertificates(certificates)
+	views := make([]CertificateView, len(certificates))
+	for i, ec := range certificates {
+		c := certificationsByID[ec.CertificationID]
+		views[i] = CertificateView{
+			EmployeeCertification: ec,
+			Certification:         c,
+			Status:                certificateStatus(c, &ec, now),
+			Current:               best[certificateKey{ec.EmployeeID, ec.CertificationID}].ID == ec.ID,
+		}
+	}
+	// Required certifications the employee has never held.
+	var missing []Certification
+	for _, c := range certifications {
+		if _, ok := best[certificateKey{employee.ID, c.ID}]; !ok && c.Requires(employee.DepartmentID) {
+			missing = append(missing, c)
+		}
+	}
+
+	data := map[string]any{
+		"ActivePage":     "training",
+		"Employee":       employee,
+		"Enrollments":    enrollments,
+		"Courses":        coursesByID,
+		"Certificates":   views,
+		"Missing":        missing,
+		"Certifications": certifications,
+		"Today":          now,
+	}
+	app.render(w, r, "employee_training.html", "", data)
+}
+
+// pathEmployee fetches the employee named by the request's id path
+// value and writes the error response when there is none.
+func (app *App) pathEmployee(w http.ResponseWriter, r *http.Request) (*Employee, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, false
+	}
+	employee, err := app.EmployeeRepository.GetEmployeeByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch employee", err)
+		return nil, false
+	}
+	if employee == nil {
+		app.clientError(w, r, http.StatusNotFound, "Employee not found")
+		return nil, false
+	}
+	return employee, true
+}
+
+// handleAddCertificate records a certificate an employee holds, with an
+// optional scan of it as proof. The expiry date defaults to the end of
+// the certification's validity.
+func (app *App) handleAddCertificate(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	if !app.parseUploadForm(w, r) {
+		return
+	}
+	certID, _ := strconv.Atoi(r.FormValue("certification_id"))
+	certification, err := app.CertificationRepository.GetCertificationByID(r.Context(), certID)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certification", err)
+		return
+	}
+	if certification == nil {
+		app.clientError(w, r, http.StatusBadRequest, "Certification not found")
+		return
+	}
+	issued, err := time.Parse("2006-01-02", r.FormValue("issued_on"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+		return
+	}
+	expires := certification.Expiry(issued)
+	if s := r.FormValue("expires_on"); s != "" {
+		if expires, err = time.Parse("2006-01-02", s); err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "Invalid date")
+			return
+		}
+		if expires.Before(issued) {
+			app.clientError(w, r, http.StatusBadRequest, "Expiry can't be before the issue date")
+			return
+		}
+	}
+
+	key, name, ok := app.saveUpload(w, r, "proof")
+	if !ok {
+		return
+	}
+	certificate := EmployeeCertification{
+		EmployeeID:      employee.ID,
+		CertificationID: certification.ID,
+		IssuedOn:        issued,
+		ExpiresOn:       expires,
+		ProofKey:        key,
+		ProofName:       name,
+	}
+	if err := app.CertificateRepository.CreateEmployeeCertification(r.Context(), &certificate); err != nil {
+		if key != "" {
+			app.Files.Remove(key)
+		}
+		app.serverError(w, r, "Failed to add certificate", err)
+		return
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/training/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+func (app *App) handleDeleteCertificate(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.pathEmployee(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return
+	}
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse id")
+		return
+	}
+	certificate, err := app.CertificateRepository.GetEmployeeCertificationByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificate", err)
+		return
+	}
+	if certificate == nil || certificate.EmployeeID != employee.ID {
+		app.clientError(w, r, http.StatusNotFound, "Certificate not found")
+		return
+	}
+	if err := app.CertificateRepository.DeleteEmployeeCertification(r.Context(), id); err != nil {
+		app.serverError(w, r, "Failed to delete certificate", err)
+		return
+	}
+	if certificate.ProofKey != "" {
+		if err := app.Files.Remove(certificate.ProofKey); err != nil {
+			app.serverError(w, r, "Failed to delete file", err)
+			return
+		}
+	}
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/training/employees/%d", employee.ID))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleCertificateProof downloads the proof uploaded with a certificate.
+func (app *App) handleCertificateProof(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	certificate, err := app.CertificateRepository.GetEmployeeCertificationByID(r.Context(), id)
+	if err != nil {
+		app.serverError(w, r, "Failed to fetch certificate", err)
+		return
+	}
+	if certificate == nil || certificate.ProofKey == "" {
+		app.clientError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	app.serveUpload(w, r, certificate.ProofKey, certificate.ProofName)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertificateStatus(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	c := Certification{ValidityMonths: 12, ReminderDays: 10}
+	tests := []struct {
+		name string
+		ec   *EmployeeCertification
+		want string
+	}{
+		{"missing", nil, expiryMissing},
+		{"never expires", &EmployeeCertification{}, expiryValid},
+		{"valid", &EmployeeCertification{ExpiresOn: day(30)}, expiryValid},
+		{"reminder window", &EmployeeCertification{ExpiresOn: day(20)}, expiryExpiring},
+		{"expiry day", &EmployeeCertification{ExpiresOn: day(10)}, expiryExpiring},
+		{"expired", &EmployeeCertification{ExpiresOn: day(9)}, expiryExpired},
+	}
+	for _, tt := range tests {
+		if got := certificateStatus(c, tt.ec, day(10)); got != tt.want {
+			t.Errorf("%s: certificateStatus() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := c.Expiry(day(1)); !got.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expiry() = %v, want a year later", got)
+	}
+}
+
+func TestComplianceMatrix(t *testing.T) {
+	today := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	departments := []Department{{ID: 1, Name: "Warehouse"}, {ID: 2, Name: "Sales"}}
+	certifications := []Certification{{ID: 1, Name: "Forklift", ReminderDays: 30, Departments: []int{1}}}
+	employees := []Employee{
+		{ID: 1, DepartmentID: 1, Status: "active"},
+		{ID: 2, DepartmentID: 1, Status: "active"},
+		{ID: 3, DepartmentID: 1, Status: "active"},
+		{ID: 4, DepartmentID: 1, Status: "terminated"},
+		{ID: 5, DepartmentID: 2, Status: "active"},
+	}
+	certificates := []EmployeeCertification{
+		// Employee 1 renewed an expired certificate.
+		{ID: 1, EmployeeID: 1, CertificationID: 1, ExpiresOn: today.AddDate(0, 0, -5)},
+		{ID: 2, EmployeeID: 1, CertificationID: 1, ExpiresOn: today.AddDate(1, 0, 0)},
+		{ID: 3, EmployeeID: 2, CertificationID: 1, ExpiresOn: today.AddDate(0, 0, 10)},
+		{ID: 4, EmployeeID: 4, CertificationID: 1, ExpiresOn: today.AddDate(0, 0, -1)},
+	}
+
+	rows := complianceMatrix(departments, certifications, employees, certificates, today)
+	if got := rows[0].Cells[0]; got != (ComplianceCell{Required: true, Staff: 3, Valid: 2, Expiring: 1}) || got.Percent() != 66 {
+		t.Errorf("warehouse cell = %+v (%v%%), want 2 of 3 valid", got, got.Percent())
+	}
+	if got := rows[1].Cells[0]; got.Required {
+		t.Errorf("sales cell = %+v, want not required", got)
+	}
+
+	byID := map[int]Employee{}
+	for _, e := range employees {
+		byID[e.ID] = e
+	}
+	reminders := certificateReminders(certifications, byID, certificates, today)
+	if len(reminders) != 1 || reminders[0].Employee.ID != 2 || reminders[0].DaysLeft != 10 {
+		t.Errorf("certificateReminders() = %+v, want employee 2 with 10 days left", reminders)
+	}
+}
+
+func TestTraining(t *testing.T) {
+	ctx := context.Background()
+	h, repos := newTestApp(t)
+	warehouse := Department{Name: "Warehouse"}
+	if err := repos.Departments.CreateDepartment(ctx, &warehouse); err != nil {
+		t.Fatal(err)
+	}
+	departments, _ := repos.Departments.GetDepartments(ctx, "")
+	sami := Employee{FirstName: "Sami", LastName: "Nasser", Email: "sami@example.com", HireDate: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Status: "active", DepartmentID: departments[0].ID}
+	if err := repos.Employees.CreateEmployee(ctx, &sami); err != nil {
+		t.Fatal(err)
+	}
+	employees, _ := repos.Employees.GetEmployees(ctx, "")
+	employee := strconv.Itoa(employees[0].ID)
+
+	cert := url.Values{"name": {"Forklift Licence"}, "validity_months": {"12"}, "reminder_days": {"30"}, "departments": {strconv.Itoa(departments[0].ID)}}
+	if w := send(h, "POST", "/certifications/add", cert, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add certification = %d %s", w.Code, w.Body)
+	}
+	certifications, _ := repos.Certifications.GetCertifications(ctx)
+	certID := strconv.Itoa(certifications[0].ID)
+	if w := send(h, "GET", "/training/employees/"+employee, nil, hrHeaders); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Missing required certifications") {
+		t.Errorf("employee training = %d, want the missing certification", w.Code)
+	}
+
+	course := url.Values{"title": {"Forklift Safety"}, "hours": {"8"}, "certification_id": {certID}}
+	if w := send(h, "POST", "/training/add", course, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("add course = %d %s", w.Code, w.Body)
+	}
+	courses, _ := repos.Courses.GetCourses(ctx)
+	coursePath := "/training/courses/" + strconv.Itoa(courses[0].ID)
+	enroll := url.Values{"employee_id": {employee}}
+	if w := send(h, "POST", coursePath+"/enrollments", enroll, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("enroll = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", coursePath+"/enrollments", enroll, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("enroll twice = %d, want 409", w.Code)
+	}
+	enrollments, _ := repos.Enrollments.GetEnrollments(ctx, courses[0].ID, 0)
+	complete := "/training/enrollments/" + strconv.Itoa(enrollments[0].ID) + "/complete"
+	completedOn := today().Format("2006-01-02")
+	if w := send(h, "POST", complete, url.Values{"completed_on": {completedOn}}, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("complete = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "POST", complete, url.Values{"completed_on": {completedOn}}, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("complete twice = %d, want 409", w.Code)
+	}
+	granted, _ := repos.Certificates.GetEmployeeCertifications(ctx, 0, employees[0].ID)
+	if len(granted) != 1 || !granted[0].ExpiresOn.Equal(today().AddDate(1, 0, 0)) {
+		t.Fatalf("certificates after completion = %+v, want one valid for a year", granted)
+	}
+
+	// An older certificate with proof, already expired.
+	upload := url.Values{"certification_id": {certID}, "issued_on": {"2020-03-01"}}
+	w := sendFile(h, "/training/employees/"+employee+"/certificates", upload, "proof", "licence.pdf", "%PDF-1.4", hrHeaders)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("add certificate = %d %s", w.Code, w.Body)
+	}
+	upload.Set("expires_on", "2020-01-01")
+	if w := sendFile(h, "/training/employees/"+employee+"/certificates", upload, "proof", "licence.pdf", "%PDF-1.4", hrHeaders); w.Code != http.StatusBadRequest {
+		t.Errorf("expiry before issue = %d, want 400", w.Code)
+	}
+	all, _ := repos.Certificates.GetEmployeeCertifications(ctx, 0, employees[0].ID)
+	if len(all) != 2 || all[1].ProofName != "licence.pdf" {
+		t.Fatalf("certificates = %+v, want the upload last", all)
+	}
+	proof := "/training/certificates/" + strconv.Itoa(all[1].ID) + "/proof"
+	w = send(h, "GET", proof, nil, hrHeaders)
+	if w.Code != http.StatusOK || w.Body.String() != "%PDF-1.4" || !strings.Contains(w.Header().Get("Content-Disposition"), "licence.pdf") {
+		t.Errorf("proof download = %d %q %q", w.Code, w.Body, w.Header().Get("Content-Disposition"))
+	}
+
+	w = send(h, "GET", "/certifications/compliance", nil, hrHeaders)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "1 / 1") {
+		t.Errorf("compliance = %d, want the warehouse fully certified", w.Code)
+	}
+	if w := send(h, "GET", "/certifications/reminders", nil, hrHeaders); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Sami") {
+		t.Errorf("reminders = %d, want none after the renewal", w.Code)
+	}
+	if w := send(h, "DELETE", "/certifications/delete?id="+certID, nil, hrHeaders); w.Code != http.StatusConflict {
+		t.Errorf("delete held certification = %d, want 409", w.Code)
+	}
+
+	if w := send(h, "DELETE", "/training/employees/"+employee+"/certificates?id="+strconv.Itoa(all[1].ID), nil, hrHeaders); w.Code != http.StatusSeeOther {
+		t.Fatalf("delete certificate = %d %s", w.Code, w.Body)
+	}
+	if w := send(h, "GET", proof, nil, hrHeaders); w.Code != http.StatusNotFound {
+		t.Errorf("proof after delete = %d, want 404", w.Code)
+	}
+}
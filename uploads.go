@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore keeps uploaded files on local disk. Files are stored under a
+// random key rather than their name, so names never reach the file
+// system; the name is kept with the record that refers to the file.
+type FileStore struct {
+	dir     string
+	maxSize int64
+}
+
+func NewFileStore(cfg UploadConfig) *FileStore {
+	return &FileStore{dir: cfg.Dir, maxSize: cfg.MaxSize}
+}
+
+// errUploadTooLarge is returned by Save for files over the size limit.
+var errUploadTooLarge = errors.New("Uploaded file is too large")
+
+func (s *FileStore) path(key string) (string, error) {
+	if len(key) != 32 || strings.Trim(key, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("invalid file key %q", key)
+	}
+	return filepath.Join(s.dir, key[:2], key), nil
+}
+
+// Save copies r to a new file and returns its key. Files larger than the
+// configured limit are rejected with errUploadTooLarge.
+func (s *FileStore) Save(r io.Reader) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(b)
+	path, _ := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("creating upload directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("creating upload: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(r, s.maxSize+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("writing upload: %w", err)
+	}
+	if n > s.maxSize {
+		return "", errUploadTooLarge
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("storing upload: %w", err)
+	}
+	return key, nil
+}
+
+// Open opens the file stored under key.
+func (s *FileStore) Open(key string) (*os.File, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Remove deletes the file stored under key. Removing a file that is
+// already gone is not an error.
+func (s *FileStore) Remove(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// saveUpload stores the file sent in the given multipart form field and
+// returns its key and name. An empty key means no file was sent. The
+// error response has been written when ok is false.
+func (app *App) saveUpload(w http.ResponseWriter, r *http.Request, field string) (key, name string, ok bool) {
+	file, header, err := r.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return "", "", true
+	}
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return "", "", false
+	}
+	defer file.Close()
+
+	key, err = app.Files.Save(file)
+	if errors.Is(err, errUploadTooLarge) {
+		app.clientError(w, r, http.StatusRequestEntityTooLarge, err.Error())
+		return "", "", false
+	}
+	if err != nil {
+		app.serverError(w, r, "Failed to store file", err)
+		return "", "", false
+	}
+	return key, filepath.Base(header.Filename), true
+}
+
+// parseUploadForm parses a multipart form whose files are limited to the
+// configured upload size. The error response has been written when it
+// returns false.
+func (app *App) parseUploadForm(w http.ResponseWriter, r *http.Request) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, app.Files.maxSize+1<<20)
+	err := r.ParseMultipartForm(1 << 20)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		app.clientError(w, r, http.StatusRequestEntityTooLarge, errUploadTooLarge.Error())
+		return false
+	case errors.Is(err, http.ErrNotMultipart):
+		// Forms without a file input are sent url-encoded.
+		if err := r.ParseForm(); err != nil {
+			app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+			return false
+		}
+	case err != nil:
+		app.clientError(w, r, http.StatusBadRequest, "can't parse form")
+		return false
+	}
+	return true
+}
+
+// serveUpload sends the file stored under key as a download named name.
+func (app *App) serveUpload(w http.ResponseWriter, r *http.Request, key, name string) {
+	f, err := app.Files.Open(key)
+	if errors.Is(err, os.ErrNotExist) {
+		app.clientError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	if err != nil {
+		app.serverError(w, r, "Failed to open file", err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		app.serverError(w, r, "Failed to open file", err)
+		return
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}